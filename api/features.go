@@ -143,6 +143,46 @@ const (
 	// Note: The instruction list is too long to enumerate in godoc.
 	// See https://github.com/WebAssembly/spec/blob/wg-2.0.draft1/proposals/simd/SIMD.md
 	CoreFeatureSIMD
+
+	// CoreFeatureExtendedConst allows integer arithmetic in constant
+	// expressions, such as global initializers and data or element segment
+	// offsets ("extended-const"). This is not included in CoreFeaturesV2.
+	//
+	// Adds instructions to constant expressions:
+	//   - `i32.add`
+	//   - `i32.sub`
+	//   - `i32.mul`
+	//   - `i64.add`
+	//   - `i64.sub`
+	//   - `i64.mul`
+	//
+	// See https://github.com/WebAssembly/extended-const/blob/main/proposals/extended-const/Overview.md
+	CoreFeatureExtendedConst
+
+	// CoreFeatureGC enables struct and array types, and the instructions
+	// which allocate and access them ("gc"). This is not included in
+	// CoreFeaturesV2, and requires CoreFeatureReferenceTypes.
+	//
+	// Support is partial and only implemented by the interpreter: use
+	// wazero.NewRuntimeConfigInterpreter. With the compiler, Runtime
+	// CompileModule fails for a module using these instructions, with an
+	// error saying GC requires the interpreter. Currently, this includes:
+	//   - Recursive type groups, subtypes, struct and array types.
+	//   - Reference types of the "any" hierarchy, e.g. `(ref null $t)`, which
+	//     are checked at runtime instead of during validation.
+	//   - Instructions `struct.new`, `struct.new_default`, `struct.get`,
+	//     `struct.get_s`, `struct.get_u`, `struct.set`, `array.new`,
+	//     `array.new_default`, `array.new_fixed`, `array.get`, `array.get_s`,
+	//     `array.get_u`, `array.set`, `array.len`, `ref.test`, `ref.cast`,
+	//     `ref.i31`, `i31.get_s`, `i31.get_u`, `ref.eq` and `ref.as_non_null`.
+	//
+	// Objects are never collected while the module which allocated them is
+	// open. They are released when it is closed, and count against the limit
+	// of experimental/usage WithLimit. Using an object of a closed module
+	// traps with a null reference error.
+	//
+	// See https://github.com/WebAssembly/gc/blob/main/proposals/gc/Overview.md
+	CoreFeatureGC
 )
 
 // SetEnabled enables or disables the feature or group of features.
@@ -207,6 +247,12 @@ func featureName(f CoreFeatures) string {
 	case CoreFeatureSIMD:
 		// match https://github.com/WebAssembly/spec/blob/wg-2.0.draft1/proposals/simd/SIMD.md
 		return "simd"
+	case CoreFeatureExtendedConst:
+		// match https://github.com/WebAssembly/extended-const/blob/main/proposals/extended-const/Overview.md
+		return "extended-const"
+	case CoreFeatureGC:
+		// match https://github.com/WebAssembly/gc/blob/main/proposals/gc/Overview.md
+		return "gc"
 	}
 	return ""
 }
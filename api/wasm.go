@@ -526,6 +526,28 @@ type MutableGlobal interface {
 	internalapi.WazeroOnly
 }
 
+// MemoryView is a parameter type of host functions defined with
+// wazero.HostFunctionBuilder WithFunc, which wazero injects from two i32
+// parameters: the offset and the byte count of a range of the caller's
+// memory. For example, this function is imported as (i32, i32) -> (i32):
+//
+//	builder.WithFunc(func(ctx context.Context, m api.Module, name api.MemoryView) uint32 {
+//		return uint32(bytes.Count(name, []byte{'/'}))
+//	})
+//
+// The range is validated before calling the function: if it is out of the
+// bounds of the memory, the call traps like an out-of-bounds memory access in
+// WebAssembly.
+//
+// # Notes
+//
+//   - This is not a copy: writes are visible to the guest.
+//   - Like Memory Read, the view is only valid until the memory grows, so it
+//     must not be retained after the host function returns.
+//   - The function must declare api.Module as its second parameter, as that
+//     is the memory the view refers to.
+type MemoryView []byte
+
 // Memory allows restricted access to a module's memory. Notably, this does not allow growing.
 //
 // See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#storage%E2%91%A0
@@ -105,6 +105,14 @@ type HostFunctionBuilder interface {
 	//		return x + y
 	//	})
 	//
+	// api.MemoryView parameters avoid decoding offsets by hand: each is
+	// imported as two i32 parameters, the offset and the byte count, which are
+	// validated against the memory of the api.Module before the call.
+	//
+	//	builder.WithFunc(func(ctx context.Context, m api.Module, buf api.MemoryView) {
+	//		os.Stdout.Write(buf)
+	//	})
+	//
 	// This example propagates context properly when calling other functions
 	// exported in the api.Module:
 	//
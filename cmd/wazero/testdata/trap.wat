@@ -0,0 +1,8 @@
+(module $trap
+  (func $main (export "_start")
+    call $fail
+  )
+  (func $fail
+    unreachable
+  )
+)
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"flag"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +25,8 @@ import (
 	"github.com/tetratelabs/wazero/internal/platform"
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/version"
+	"github.com/tetratelabs/wazero/internal/wasmdebug"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -56,6 +58,8 @@ func doMain(stdOut io.Writer, stdErr logging.Writer) int {
 	switch subCmd {
 	case "compile":
 		return doCompile(flag.Args()[1:], stdErr)
+	case "inspect":
+		return doInspect(flag.Args()[1:], stdOut, stdErr)
 	case "run":
 		return doRun(flag.Args()[1:], stdOut, stdErr)
 	case "version":
@@ -149,6 +153,134 @@ func doCompile(args []string, stdErr io.Writer) int {
 	return 0
 }
 
+func doInspect(args []string, stdOut, stdErr io.Writer) int {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	flags.SetOutput(stdErr)
+
+	var help bool
+	flags.BoolVar(&help, "h", false, "Prints usage.")
+
+	var compile bool
+	flags.BoolVar(&compile, "compile", false,
+		"Compiles to native code when supported, and prints the compile time and the size of each function.")
+
+	_ = flags.Parse(args)
+
+	if help {
+		printInspectUsage(stdErr, flags)
+		return 0
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Fprintln(stdErr, "missing path to wasm file")
+		printInspectUsage(stdErr, flags)
+		return 1
+	}
+
+	wasmPath := flags.Arg(0)
+
+	wasm, err := os.ReadFile(wasmPath)
+	if err != nil {
+		fmt.Fprintf(stdErr, "error reading wasm binary: %v\n", err)
+		return 1
+	}
+
+	// Inspecting doesn't need native code, so use the interpreter to skip the
+	// compilation unless asked for.
+	c := wazero.NewRuntimeConfigInterpreter()
+	if compile {
+		c = wazero.NewRuntimeConfig()
+	}
+	ctx := context.Background()
+	rt := wazero.NewRuntimeWithConfig(ctx, c.WithCustomSections(true))
+	defer rt.Close(ctx)
+
+	start := time.Now()
+	compiled, err := rt.CompileModule(ctx, wasm)
+	if err != nil {
+		fmt.Fprintf(stdErr, "error compiling wasm binary: %v\n", err)
+		return 1
+	}
+	elapsed := time.Since(start)
+
+	printModule(stdOut, compiled)
+	if compile {
+		printFunctions(stdOut, compiled, elapsed)
+	}
+	return 0
+}
+
+// printFunctions writes the compile time of the module, then the body and
+// native code size of each function defined in it.
+//
+// Note: Engines compile all functions in one pass, so the compile time is
+// only known for the whole module. The compiled size is zero if the
+// compiler isn't supported on this platform.
+func printFunctions(w io.Writer, compiled wazero.CompiledModule, elapsed time.Duration) {
+	fmt.Fprintf(w, "compile time: %s\n", elapsed)
+	fmt.Fprintln(w, "functions:")
+	for _, f := range compiled.Functions() {
+		d := f.Definition
+		fmt.Fprintf(w, "\tfunc %s body=%d compiled=%d\n",
+			wasmdebug.Signature(d.DebugName(), d.ParamTypes(), d.ResultTypes()), f.BodySize, f.CompiledSize)
+	}
+}
+
+// printModule writes the imports, exports and custom sections of the compiled module to w.
+// Exports are sorted by name as they are held in maps.
+func printModule(w io.Writer, compiled wazero.CompiledModule) {
+	if name := compiled.Name(); name != "" {
+		fmt.Fprintf(w, "name: %s\n", name)
+	}
+
+	fmt.Fprintln(w, "imports:")
+	for _, f := range compiled.ImportedFunctions() {
+		moduleName, name, _ := f.Import()
+		fmt.Fprintf(w, "\tfunc %s\n", wasmdebug.Signature(moduleName+"."+name, f.ParamTypes(), f.ResultTypes()))
+	}
+	for _, m := range compiled.ImportedMemories() {
+		moduleName, name, _ := m.Import()
+		fmt.Fprintf(w, "\tmemory %s.%s %s\n", moduleName, name, memoryLimits(m))
+	}
+
+	fmt.Fprintln(w, "exports:")
+	funcs := compiled.ExportedFunctions()
+	for _, name := range sortedKeys(funcs) {
+		f := funcs[name]
+		fmt.Fprintf(w, "\tfunc %s\n", wasmdebug.Signature(name, f.ParamTypes(), f.ResultTypes()))
+	}
+	mems := compiled.ExportedMemories()
+	for _, name := range sortedKeys(mems) {
+		fmt.Fprintf(w, "\tmemory %s %s\n", name, memoryLimits(mems[name]))
+	}
+
+	fmt.Fprintln(w, "custom sections:")
+	for _, c := range compiled.CustomSections() {
+		fmt.Fprintf(w, "\t%s (%d bytes)\n", c.Name(), len(c.Data()))
+	}
+}
+
+func memoryLimits(m api.MemoryDefinition) string {
+	if max, ok := m.Max(); ok {
+		return fmt.Sprintf("min=%d max=%d", m.Min(), max)
+	}
+	return fmt.Sprintf("min=%d", m.Min())
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// exitCodeTrap is the exit code when the wasm binary traps, e.g. executes
+// "unreachable". This is the same as a process aborted by SIGABRT on POSIX,
+// which distinguishes a trap from failing to instantiate the wasm binary.
+const exitCodeTrap = 134
+
 func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 	flags := flag.NewFlagSet("run", flag.ExitOnError)
 	flags.SetOutput(stdErr)
@@ -169,6 +301,12 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 		"Inherits any environment variables from the calling process. "+
 			"Variables specified with the <env> flag are appended to the inherited list.")
 
+	var envFiles sliceFlag
+	flags.Var(&envFiles, "env-file", "Path to a file of key=value pairs, one per line, of environment variables "+
+		"to expose to the binary. Empty lines and lines starting with '#' are ignored. "+
+		"Variables specified with the <env> flag are appended to the ones read from files. "+
+		"Can be specified multiple times.")
+
 	var mounts sliceFlag
 	flags.Var(&mounts, "mount",
 		"Filesystem path to expose to the binary in the form of <path>[:<wasm path>][:ro]. "+
@@ -190,6 +328,11 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 			"Valid time units are \"ns\", \"us\" (or \"µs\"), \"ms\", \"s\", \"m\", \"h\". "+
 			"If the duration is 0, the timeout is disabled. The default is disabled.")
 
+	var backtrace bool
+	flags.BoolVar(&backtrace, "backtrace", false,
+		"Prints the wasm stack trace when the wasm binary traps, using function names from the name section "+
+			"and source locations from DWARF, when present. Traps exit with code "+strconv.Itoa(exitCodeTrap)+".")
+
 	var hostlogging logScopesFlag
 	flags.Var(&hostlogging, "hostlogging",
 		"A comma-separated list of host function scopes to log to stderr. "+
@@ -240,6 +383,14 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 
 	// Don't use map to preserve order
 	var env []string
+	for i := len(envFiles) - 1; i >= 0; i-- {
+		fileEnvs, err := readEnvFile(envFiles[i])
+		if err != nil {
+			fmt.Fprintf(stdErr, "invalid env-file: %v\n", err)
+			return 1
+		}
+		envs = append(fileEnvs, envs...)
+	}
 	if envInherit {
 		envs = append(os.Environ(), envs...)
 	}
@@ -306,7 +457,7 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 		WithStdout(stdOut).
 		WithStderr(stdErr).
 		WithStdin(os.Stdin).
-		WithRandSource(rand.Reader).
+		WithSysRandSource().
 		WithFSConfig(fsConfig).
 		WithSysNanosleep().
 		WithSysNanotime().
@@ -372,6 +523,18 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 			}
 			return int(exitCode)
 		}
+		// Traps are raised while executing wasm, such as "unreachable", or
+		// by a host function which panicked.
+		var wasmErr *wasmruntime.Error
+		var hostErr *sys.HostFunctionError
+		if errors.As(err, &wasmErr) || errors.As(err, &hostErr) {
+			msg := err.Error()
+			if i := strings.Index(msg, "\n"+wasmdebug.StackTracePrefix); i != -1 && !backtrace {
+				msg = msg[:i]
+			}
+			fmt.Fprintf(stdErr, "error: %s\n", msg)
+			return exitCodeTrap
+		}
 		fmt.Fprintf(stdErr, "error instantiating wasm binary: %v\n", err)
 		return 1
 	}
@@ -380,6 +543,24 @@ func doRun(args []string, stdOut io.Writer, stdErr logging.Writer) int {
 	return 0
 }
 
+// readEnvFile returns the key=value pairs in the file at path, skipping empty
+// lines and comments.
+func readEnvFile(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var envs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		envs = append(envs, line)
+	}
+	return envs, nil
+}
+
 func validateMounts(mounts sliceFlag, stdErr logging.Writer) (rc int, rootPath string, config wazero.FSConfig) {
 	config = wazero.NewFSConfig()
 	for _, mount := range mounts {
@@ -507,6 +688,7 @@ func printUsage(stdErr io.Writer) {
 	fmt.Fprintln(stdErr)
 	fmt.Fprintln(stdErr, "Commands:")
 	fmt.Fprintln(stdErr, "  compile\tPre-compiles a WebAssembly binary")
+	fmt.Fprintln(stdErr, "  inspect\tPrints the imports, exports and custom sections of a WebAssembly binary")
 	fmt.Fprintln(stdErr, "  run\t\tRuns a WebAssembly binary")
 	fmt.Fprintln(stdErr, "  version\tDisplays the version of wazero CLI")
 }
@@ -520,6 +702,15 @@ func printCompileUsage(stdErr io.Writer, flags *flag.FlagSet) {
 	flags.PrintDefaults()
 }
 
+func printInspectUsage(stdErr io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(stdErr, "wazero CLI")
+	fmt.Fprintln(stdErr)
+	fmt.Fprintln(stdErr, "Usage:\n  wazero inspect <options> <path to wasm file>")
+	fmt.Fprintln(stdErr)
+	fmt.Fprintln(stdErr, "Options:")
+	flags.PrintDefaults()
+}
+
 func printRunUsage(stdErr io.Writer, flags *flag.FlagSet) {
 	fmt.Fprintln(stdErr, "wazero CLI")
 	fmt.Fprintln(stdErr)
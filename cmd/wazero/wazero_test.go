@@ -27,6 +27,9 @@ import (
 //go:embed testdata/infinite_loop.wasm
 var wasmInfiniteLoop []byte
 
+//go:embed testdata/trap.wasm
+var wasmTrap []byte
+
 //go:embed testdata/wasi_arg.wasm
 var wasmWasiArg []byte
 
@@ -204,6 +207,71 @@ func TestCompile_Errors(t *testing.T) {
 	}
 }
 
+func TestInspect(t *testing.T) {
+	wasmPath := filepath.Join(t.TempDir(), "test.wasm")
+	require.NoError(t, os.WriteFile(wasmPath, wasmWasiArg, 0o600))
+
+	exitCode, stdout, stderr := runMain(t, "", []string{"inspect", wasmPath})
+	require.Equal(t, 0, exitCode, stderr)
+	require.Zero(t, stderr)
+	require.Equal(t, `imports:
+	func wasi_snapshot_preview1.args_get(i32,i32) i32
+	func wasi_snapshot_preview1.args_sizes_get(i32,i32) i32
+	func wasi_snapshot_preview1.fd_write(i32,i32,i32,i32) i32
+exports:
+	func _start()
+	memory memory min=1
+custom sections:
+`, stdout)
+}
+
+func TestInspect_Compile(t *testing.T) {
+	wasmPath := filepath.Join(t.TempDir(), "test.wasm")
+	require.NoError(t, os.WriteFile(wasmPath, wasmWasiArg, 0o600))
+
+	exitCode, stdout, stderr := runMain(t, "", []string{"inspect", "-compile", wasmPath})
+	require.Equal(t, 0, exitCode, stderr)
+	require.Zero(t, stderr)
+	require.Contains(t, stdout, "compile time: ")
+	require.Contains(t, stdout, "functions:\n\tfunc .$3() body=")
+	if platform.CompilerSupported() {
+		require.False(t, strings.Contains(stdout, "compiled=0\n"))
+	}
+}
+
+func TestInspect_Errors(t *testing.T) {
+	notWasmPath := filepath.Join(t.TempDir(), "bears.wasm")
+	require.NoError(t, os.WriteFile(notWasmPath, []byte("pooh"), 0o600))
+
+	tests := []struct {
+		message string
+		args    []string
+	}{
+		{
+			message: "missing path to wasm file",
+			args:    []string{},
+		},
+		{
+			message: "error reading wasm binary",
+			args:    []string{"non-existent.wasm"},
+		},
+		{
+			message: "error compiling wasm binary",
+			args:    []string{notWasmPath},
+		},
+	}
+
+	for _, tc := range tests {
+		tt := tc
+		t.Run(tt.message, func(t *testing.T) {
+			exitCode, _, stderr := runMain(t, "", append([]string{"inspect"}, tt.args...))
+
+			require.Equal(t, 1, exitCode)
+			require.Contains(t, stderr, tt.message)
+		})
+	}
+}
+
 func TestRun(t *testing.T) {
 	tmpDir, oldwd := requireChdirToTemp(t)
 	defer os.Chdir(oldwd) //nolint
@@ -243,6 +311,9 @@ func TestRun(t *testing.T) {
 	existingDir2 := filepath.Join(tmpDir, "existing2")
 	require.NoError(t, os.Mkdir(existingDir2, 0o700))
 
+	envFile := filepath.Join(tmpDir, "test.env")
+	require.NoError(t, os.WriteFile(envFile, []byte("# comment\nANIMAL=bear\n\nFOOD=sushi\n"), 0o600))
+
 	cpuProfile := filepath.Join(t.TempDir(), "cpu.out")
 	memProfile := filepath.Join(t.TempDir(), "mem.out")
 
@@ -291,6 +362,18 @@ func TestRun(t *testing.T) {
 			wazeroOpts:     []string{"-env-inherit", "--env=ANIMAL=bear"},
 			expectedStdout: "ANIMAL=bear\x00INHERITED=wazero\u0000", // not ANIMAL=kitten
 		},
+		{
+			name:           "env-file",
+			wasm:           wasmWasiEnv,
+			wazeroOpts:     []string{"--env-file=" + envFile},
+			expectedStdout: "ANIMAL=bear\x00FOOD=sushi\x00",
+		},
+		{
+			name:           "env-file with env",
+			wasm:           wasmWasiEnv,
+			wazeroOpts:     []string{"--env-file=" + envFile, "--env=ANIMAL=cat"},
+			expectedStdout: "ANIMAL=cat\x00FOOD=sushi\x00", // not ANIMAL=bear
+		},
 		{
 			name:           "interpreter",
 			wasm:           wasmWasiArg,
@@ -496,6 +579,23 @@ Consider switching to GOOS=wasip1.
 				require.NoError(t, err)
 			},
 		},
+		{
+			name:             "trap",
+			wasm:             wasmTrap,
+			expectedStderr:   "error: module[trap] function[_start] failed: wasm error: unreachable\n",
+			expectedExitCode: exitCodeTrap,
+		},
+		{
+			name:       "trap with backtrace",
+			wazeroOpts: []string{"-backtrace"},
+			wasm:       wasmTrap,
+			expectedStderr: `error: module[trap] function[_start] failed: wasm error: unreachable
+wasm stack trace:
+	trap.fail()
+	trap.main()
+`,
+			expectedExitCode: exitCodeTrap,
+		},
 		{
 			name:       "timeout: a binary that ends before the deadline should not print a timeout error",
 			wazeroOpts: []string{"-timeout=10s"},
@@ -606,6 +706,10 @@ func TestRun_Errors(t *testing.T) {
 			message: "invalid environment variable",
 			args:    []string{"--env=ANIMAL", "testdata/wasi_env.wasm"},
 		},
+		{
+			message: "invalid env-file", // not found
+			args:    []string{"--env-file=non-existent.env", "testdata/wasi_env.wasm"},
+		},
 		{
 			message: "invalid mount", // not found
 			args:    []string{"--mount=te", "testdata/wasi_env.wasm"},
@@ -784,6 +888,7 @@ Usage:
 
 Commands:
   compile	Pre-compiles a WebAssembly binary
+  inspect	Prints the imports, exports and custom sections of a WebAssembly binary
   run		Runs a WebAssembly binary
   version	Displays the version of wazero CLI
 `, stderr)
@@ -168,8 +168,91 @@ type RuntimeConfig interface {
 	// When the invocations of api.Function are closed due to this, sys.ExitError is raised to the callers and
 	// the api.Module from which the functions are derived is made closed.
 	WithCloseOnContextDone(bool) RuntimeConfig
+
+	// WithExecutionLimit limits the number of loop iterations each call of api.Function can execute,
+	// including the iterations in the functions it calls. Zero, the default, means unlimited.
+	//
+	// When a call exceeds the limit, it traps with the "execution limit exceeded" error. Unlike
+	// WithCloseOnContextDone, the api.Module stays open, so it can be called again with a fresh budget.
+	//
+	// This can be used to meter untrusted Wasm binaries deterministically, regardless of the speed of the host.
+	//
+	// # Notes
+	//
+	//   - The limit is enforced by the same periodical checks as WithCloseOnContextDone, so this also enables
+	//     closing the module when the context.Context passed to the Call method is done.
+	//   - Calls into host functions are not metered.
+	WithExecutionLimit(limit uint64) RuntimeConfig
+
+	// WithNaNCanonicalization makes floating-point operations which result in
+	// NaN return the canonical NaN of their type: 0x7fc00000 for f32 and
+	// 0x7ff8000000000000 for f64. This is disabled by default.
+	//
+	// WebAssembly allows the bits of a NaN result, such as its sign and
+	// payload, to differ between executions, so they depend on the host CPU.
+	// Enable this when all hosts must agree on the results, such as for
+	// blockchain consensus.
+	//
+	// # Notes
+	//
+	//   - This applies to arithmetic, rounding, min, max, promote and demote
+	//     operations, including their SIMD forms. Operations which only move
+	//     bits, such as abs, neg, copysign, loads and reinterpret, preserve
+	//     NaN bits as the specification requires.
+	//   - Only the interpreter implements this: use it with
+	//     NewRuntimeConfigInterpreter. NewRuntimeWithConfig panics if this is
+	//     enabled with the compiler, including the default of NewRuntimeConfig
+	//     on platforms which support it.
+	WithNaNCanonicalization(enabled bool) RuntimeConfig
+
+	// WithHostFunctionPanicTrap makes a panic in a host function trap the
+	// call with a sys.HostFunctionError, which has the Go stack trace of the
+	// panic. This is disabled by default.
+	//
+	// Without this, the panic is still recovered by api.Function Call, but
+	// the error only includes the Go stack trace when it is a runtime.Error,
+	// and the caller can't tell a host panic apart from other errors.
+	//
+	// Note: Panics with sys.ExitError, such as from "proc_exit", are not
+	// converted, so they still close the module.
+	WithHostFunctionPanicTrap(bool) RuntimeConfig
+
+	// WithPreStartHook sets a function called with each guest module
+	// instantiated by Runtime.InstantiateModule, before the start functions
+	// configured by ModuleConfig WithStartFunctions, such as "_start".
+	//
+	// This allows initializing the module before its main code runs, e.g.
+	// writing a configuration blob into its memory. The WebAssembly start
+	// section, if any, already ran at this point.
+	//
+	// If the hook returns an error, the module is closed and
+	// Runtime.InstantiateModule returns it wrapped with the module name, e.g.
+	// "module[guest] pre-start hook failed: <error>". Use errors.Is or
+	// errors.As to match it.
+	WithPreStartHook(InstantiationHook) RuntimeConfig
+
+	// WithPostStartHook sets a function called with each guest module
+	// instantiated by Runtime.InstantiateModule, after its start functions
+	// returned without error.
+	//
+	// This allows publishing the module once it is initialized, e.g.
+	// registering its exports in a service registry.
+	//
+	// # Notes
+	//
+	//   - This isn't called when a start function exits, e.g. with
+	//     "proc_exit", as the module is closed.
+	//   - If the hook returns an error, the module is closed and
+	//     Runtime.InstantiateModule returns it wrapped with the module name,
+	//     e.g. "module[guest] post-start hook failed: <error>". Use errors.Is
+	//     or errors.As to match it.
+	WithPostStartHook(InstantiationHook) RuntimeConfig
 }
 
+// InstantiationHook is a function called with a module while it is
+// instantiated. See RuntimeConfig WithPreStartHook and WithPostStartHook.
+type InstantiationHook func(ctx context.Context, mod api.Module) error
+
 // NewRuntimeConfig returns a RuntimeConfig using the compiler if it is supported in this environment,
 // or the interpreter otherwise.
 func NewRuntimeConfig() RuntimeConfig {
@@ -188,6 +271,11 @@ type runtimeConfig struct {
 	cache                 CompilationCache
 	storeCustomSections   bool
 	ensureTermination     bool
+	executionLimit        uint64
+	canonicalizeNaN       bool
+	trapHostPanics        bool
+	preStartHook          InstantiationHook
+	postStartHook         InstantiationHook
 }
 
 // engineLessConfig helps avoid copy/pasting the wrong defaults.
@@ -220,6 +308,9 @@ const (
 // Warning: This panics at runtime if the runtime.GOOS or runtime.GOARCH does not
 // support Compiler. Use NewRuntimeConfig to safely detect and fallback to
 // NewRuntimeConfigInterpreter if needed.
+//
+// Note: The compiler doesn't implement api.CoreFeatureGC instructions, so
+// Runtime.CompileModule returns an error for modules which use them.
 func NewRuntimeConfigCompiler() RuntimeConfig {
 	ret := engineLessConfig.clone()
 	ret.engineKind = engineKindCompiler
@@ -255,6 +346,41 @@ func (c *runtimeConfig) WithCloseOnContextDone(ensure bool) RuntimeConfig {
 	return ret
 }
 
+// WithExecutionLimit implements RuntimeConfig.WithExecutionLimit
+func (c *runtimeConfig) WithExecutionLimit(limit uint64) RuntimeConfig {
+	ret := c.clone()
+	ret.executionLimit = limit
+	return ret
+}
+
+// WithNaNCanonicalization implements RuntimeConfig.WithNaNCanonicalization
+func (c *runtimeConfig) WithNaNCanonicalization(enabled bool) RuntimeConfig {
+	ret := c.clone()
+	ret.canonicalizeNaN = enabled
+	return ret
+}
+
+// WithHostFunctionPanicTrap implements RuntimeConfig.WithHostFunctionPanicTrap
+func (c *runtimeConfig) WithHostFunctionPanicTrap(enabled bool) RuntimeConfig {
+	ret := c.clone()
+	ret.trapHostPanics = enabled
+	return ret
+}
+
+// WithPreStartHook implements RuntimeConfig.WithPreStartHook
+func (c *runtimeConfig) WithPreStartHook(hook InstantiationHook) RuntimeConfig {
+	ret := c.clone()
+	ret.preStartHook = hook
+	return ret
+}
+
+// WithPostStartHook implements RuntimeConfig.WithPostStartHook
+func (c *runtimeConfig) WithPostStartHook(hook InstantiationHook) RuntimeConfig {
+	ret := c.clone()
+	ret.postStartHook = hook
+	return ret
+}
+
 // WithMemoryLimitPages implements RuntimeConfig.WithMemoryLimitPages
 func (c *runtimeConfig) WithMemoryLimitPages(memoryLimitPages uint32) RuntimeConfig {
 	ret := c.clone()
@@ -336,10 +462,22 @@ type CompiledModule interface {
 	// memory.
 	ExportedMemories() map[string]api.MemoryDefinition
 
-	// CustomSections returns all the custom sections
-	// (api.CustomSection) in this module keyed on the section name.
+	// CustomSections returns all the custom sections (api.CustomSection) in
+	// this module, in the order they were decoded. This is empty unless
+	// RuntimeConfig.WithCustomSections is enabled.
+	//
+	// Note: The "name" section isn't included, as it is decoded into the
+	// definitions of this module, e.g. Name and api.FunctionDefinition Name.
 	CustomSections() []api.CustomSection
 
+	// Functions returns information about the functions defined in this
+	// module, excluding imports, in index order. This is nil if there are
+	// none.
+	//
+	// This is for tooling such as profilers or size analyzers, which would
+	// otherwise have to parse the binary again.
+	Functions() []FunctionInfo
+
 	// Close releases all the allocated resources for this CompiledModule.
 	//
 	// Note: It is safe to call Close while having outstanding calls from an
@@ -347,6 +485,27 @@ type CompiledModule interface {
 	Close(context.Context) error
 }
 
+// FunctionInfo describes a function defined in a CompiledModule.
+type FunctionInfo struct {
+	// Definition is the definition of the function, including its index,
+	// name from the name section, and signature.
+	Definition api.FunctionDefinition
+
+	// LocalTypes are the types of the locals declared by the function,
+	// excluding its parameters.
+	LocalTypes []api.ValueType
+
+	// BodySize is the size in bytes of the instructions of the function
+	// in the binary, excluding its local declarations. This is zero for a
+	// host function defined in Go.
+	BodySize uint32
+
+	// CompiledSize is the size in bytes of the native code of the function,
+	// including alignment padding. This is zero when unknown, e.g. when the
+	// module is interpreted.
+	CompiledSize uint64
+}
+
 // compile-time check to ensure compiledModule implements CompiledModule
 var _ CompiledModule = &compiledModule{}
 
@@ -403,6 +562,32 @@ func (c *compiledModule) CustomSections() []api.CustomSection {
 	return ret
 }
 
+// Functions implements CompiledModule.Functions
+func (c *compiledModule) Functions() []FunctionInfo {
+	codes := c.module.CodeSection
+	if len(codes) == 0 {
+		return nil
+	}
+	var sizes []uint64
+	if c.compiledEngine != nil {
+		sizes = c.compiledEngine.CompiledCodeSizes(c.module)
+	}
+	ret := make([]FunctionInfo, len(codes))
+	for i := range codes {
+		code := &codes[i]
+		info := FunctionInfo{
+			Definition: c.module.FunctionDefinition(c.module.ImportFunctionCount + wasm.Index(i)),
+			LocalTypes: code.LocalTypes,
+			BodySize:   uint32(len(code.Body)),
+		}
+		if i < len(sizes) {
+			info.CompiledSize = sizes[i]
+		}
+		ret[i] = info
+	}
+	return ret
+}
+
 // customSection implements wasm.CustomSection
 type customSection struct {
 	internalapi.WazeroOnlyType
@@ -442,6 +627,16 @@ func (c *customSection) Data() []byte {
 //   - ModuleConfig is immutable. Each WithXXX function returns a new instance
 //     including the corresponding change.
 type ModuleConfig interface {
+	// WithArgMax limits the combined size of args and environment variables,
+	// including null terminators, like ARG_MAX on POSIX systems. Defaults to
+	// zero, which means no limit other than what fits in a uint32.
+	//
+	// Runtime.InstantiateModule errs if WithArgs and WithEnv exceed this, so
+	// that a guest doesn't have to handle sizes it can't allocate.
+	//
+	// See https://man7.org/linux/man-pages/man3/sysconf.3.html
+	WithArgMax(uint32) ModuleConfig
+
 	// WithArgs assigns command-line arguments visible to an imported function that reads an arg vector (argv). Defaults to
 	// none. Runtime.InstantiateModule errs if any arg is empty.
 	//
@@ -628,6 +823,28 @@ type ModuleConfig interface {
 	// Note: The caller is responsible to close any io.Reader they supply: It
 	// is not closed on api.Module Close.
 	WithRandSource(io.Reader) ModuleConfig
+
+	// WithRandSeed configures a deterministic source of random bytes, which
+	// starts over from `seed` in each module instantiated with this config.
+	// This overrides WithRandSource.
+	//
+	// Use this for reproducible tests. Unlike sharing a math/rand.Rand via
+	// WithRandSource, instances don't consume the same sequence, so the bytes
+	// an instance reads don't depend on other instances.
+	//
+	// Note: This is not cryptographically secure. Use WithSysRandSource
+	// instead, in production.
+	WithRandSeed(seed int64) ModuleConfig
+
+	// WithSysRandSource uses crypto/rand.Reader as the source of random
+	// bytes, buffered per module instance. This overrides WithRandSource.
+	//
+	// Guests commonly read a few random bytes at a time, e.g. "random_get"
+	// to seed each hash map. The buffer avoids a system call for each of
+	// these, while larger reads still go directly to crypto/rand.Reader.
+	//
+	// See WithRandSource
+	WithSysRandSource() ModuleConfig
 }
 
 type moduleConfig struct {
@@ -638,6 +855,7 @@ type moduleConfig struct {
 	stdout             io.Writer
 	stderr             io.Writer
 	randSource         io.Reader
+	newRandSource      func() io.Reader
 	walltime           sys.Walltime
 	walltimeResolution sys.ClockResolution
 	nanotime           sys.Nanotime
@@ -645,6 +863,8 @@ type moduleConfig struct {
 	nanosleep          sys.Nanosleep
 	osyield            sys.Osyield
 	args               [][]byte
+	// argMax limits the size of args and environ. Zero is no limit.
+	argMax uint32
 	// environ is pair-indexed to retain order similar to os.Environ.
 	environ [][]byte
 	// environKeys allow overwriting of existing values.
@@ -653,6 +873,10 @@ type moduleConfig struct {
 	fsConfig FSConfig
 	// sockConfig is the network listener configuration for ABI like WASI.
 	sockConfig *internalsock.Config
+	// pipeConfig is the pipe configuration for ABI like WASI.
+	pipeConfig *internalsys.PipeConfig
+	// stdioConfig is the stdout and stderr configuration for ABI like WASI.
+	stdioConfig *internalsys.StdioConfig
 }
 
 // NewModuleConfig returns a ModuleConfig that can be used for configuring module instantiation.
@@ -673,6 +897,13 @@ func (c *moduleConfig) clone() *moduleConfig {
 	return &ret
 }
 
+// WithArgMax implements ModuleConfig.WithArgMax
+func (c *moduleConfig) WithArgMax(argMax uint32) ModuleConfig {
+	ret := c.clone()
+	ret.argMax = argMax
+	return ret
+}
+
 // WithArgs implements ModuleConfig.WithArgs
 func (c *moduleConfig) WithArgs(args ...string) ModuleConfig {
 	ret := c.clone()
@@ -809,15 +1040,45 @@ func (c *moduleConfig) WithSysNanosleep() ModuleConfig {
 func (c *moduleConfig) WithRandSource(source io.Reader) ModuleConfig {
 	ret := c.clone()
 	ret.randSource = source
+	ret.newRandSource = nil
+	return ret
+}
+
+// WithRandSeed implements ModuleConfig.WithRandSeed
+func (c *moduleConfig) WithRandSeed(seed int64) ModuleConfig {
+	ret := c.clone()
+	ret.randSource = nil
+	ret.newRandSource = func() io.Reader { return platform.NewSeededRandSource(seed) }
+	return ret
+}
+
+// WithSysRandSource implements ModuleConfig.WithSysRandSource
+func (c *moduleConfig) WithSysRandSource() ModuleConfig {
+	ret := c.clone()
+	ret.randSource = nil
+	ret.newRandSource = platform.NewSysRandSource
 	return ret
 }
 
 // toSysContext creates a baseline wasm.Context configured by ModuleConfig.
 func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
+	pairs := c.environ
+	stdout, stderr := c.stdout, c.stderr
+	if s := c.stdioConfig; s != nil {
+		stdout, stderr = s.WrapWriter(stdout), s.WrapWriter(stderr)
+		// Variables set with WithEnv take precedence.
+		extra := s.Environ()
+		for i := 0; i < len(extra); i += 2 {
+			if _, ok := c.environKeys[string(extra[i])]; !ok {
+				pairs = append(pairs[:len(pairs):len(pairs)], extra[i], extra[i+1])
+			}
+		}
+	}
+
 	var environ [][]byte // Intentionally doesn't pre-allocate to reduce logic to default to nil.
 	// Same validation as syscall.Setenv for Linux
-	for i := 0; i < len(c.environ); i += 2 {
-		key, value := c.environ[i], c.environ[i+1]
+	for i := 0; i < len(pairs); i += 2 {
+		key, value := pairs[i], pairs[i+1]
 		keyLen := len(key)
 		if keyLen == 0 {
 			err = errors.New("environ invalid: empty key")
@@ -839,6 +1100,21 @@ func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
 		environ = append(environ, result)
 	}
 
+	if c.argMax != 0 {
+		// The size includes null terminators, as written by "args_get".
+		size := uint64(0) // uint64 to allow summing without overflow
+		for _, e := range c.args {
+			size += uint64(len(e)) + 1
+		}
+		for _, e := range environ {
+			size += uint64(len(e)) + 1
+		}
+		if size > uint64(c.argMax) {
+			err = fmt.Errorf("args and environ exceed maximum size: %d > %d", size, c.argMax)
+			return
+		}
+	}
+
 	var fs []fsapi.FS
 	var guestPaths []string
 	if f, ok := c.fsConfig.(*fsConfig); ok {
@@ -852,18 +1128,29 @@ func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
 		}
 	}
 
+	var pipes []internalsys.PipeEnd
+	if p := c.pipeConfig; p != nil {
+		pipes = p.Ends
+	}
+
+	randSource := c.randSource
+	if c.newRandSource != nil { // state is per instance
+		randSource = c.newRandSource()
+	}
+
 	return internalsys.NewContext(
 		math.MaxUint32,
 		c.args,
 		environ,
 		c.stdin,
-		c.stdout,
-		c.stderr,
-		c.randSource,
+		stdout,
+		stderr,
+		randSource,
 		c.walltime, c.walltimeResolution,
 		c.nanotime, c.nanotimeResolution,
 		c.nanosleep, c.osyield,
 		fs, guestPaths,
 		listeners,
+		pipes,
 	)
 }
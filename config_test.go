@@ -1,9 +1,11 @@
 package wazero
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	_ "embed"
+	"io/fs"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/platform"
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/sysfs"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
 	testfs "github.com/tetratelabs/wazero/internal/testing/fs"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	"github.com/tetratelabs/wazero/internal/wasm"
@@ -74,6 +77,21 @@ func TestRuntimeConfig(t *testing.T) {
 			with:     func(c RuntimeConfig) RuntimeConfig { return c.WithCloseOnContextDone(true) },
 			expected: &runtimeConfig{ensureTermination: true},
 		},
+		{
+			name:     "WithExecutionLimit",
+			with:     func(c RuntimeConfig) RuntimeConfig { return c.WithExecutionLimit(100) },
+			expected: &runtimeConfig{executionLimit: 100},
+		},
+		{
+			name:     "WithNaNCanonicalization",
+			with:     func(c RuntimeConfig) RuntimeConfig { return c.WithNaNCanonicalization(true) },
+			expected: &runtimeConfig{canonicalizeNaN: true},
+		},
+		{
+			name:     "WithHostFunctionPanicTrap",
+			with:     func(c RuntimeConfig) RuntimeConfig { return c.WithHostFunctionPanicTrap(true) },
+			expected: &runtimeConfig{trapHostPanics: true},
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +179,20 @@ func TestModuleConfig(t *testing.T) {
 
 // TestModuleConfig_toSysContext only tests the cases that change the inputs to
 // sys.NewContext.
+func TestModuleConfig_WithRandSeed(t *testing.T) {
+	config := NewModuleConfig().WithRandSeed(1).(*moduleConfig)
+
+	// Each instance reads the same bytes, regardless of the others.
+	var reads [2][8]byte
+	for i := range reads {
+		sysCtx, err := config.toSysContext()
+		require.NoError(t, err)
+		_, err = sysCtx.RandSource().Read(reads[i][:])
+		require.NoError(t, err)
+	}
+	require.Equal(t, reads[0], reads[1])
+}
+
 func TestModuleConfig_toSysContext(t *testing.T) {
 	base := NewModuleConfig()
 
@@ -371,6 +403,38 @@ func TestModuleConfig_toSysContext(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "WithRandSeed",
+			input: func() (ModuleConfig, func(t *testing.T, sys *internalsys.Context)) {
+				config := base.WithRandSource(bytes.NewReader(nil)).WithRandSeed(42)
+				return config, func(t *testing.T, sys *internalsys.Context) {
+					actual := sys.RandSource()
+					require.Equal(t, platform.NewSeededRandSource(42), actual)
+				}
+			},
+		},
+		{
+			name: "WithSysRandSource",
+			input: func() (ModuleConfig, func(t *testing.T, sys *internalsys.Context)) {
+				config := base.WithSysRandSource()
+				return config, func(t *testing.T, sys *internalsys.Context) {
+					actual := sys.RandSource()
+					_, ok := actual.(*bufio.Reader)
+					require.True(t, ok)
+				}
+			},
+		},
+		{
+			name: "WithRandSource overrides WithSysRandSource",
+			input: func() (ModuleConfig, func(t *testing.T, sys *internalsys.Context)) {
+				r := bytes.NewReader([]byte{1, 2, 3, 4})
+				config := base.WithSysRandSource().WithRandSource(r)
+				return config, func(t *testing.T, sys *internalsys.Context) {
+					actual := sys.RandSource()
+					require.Equal(t, r, actual)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -541,6 +605,40 @@ func TestModuleConfig_toSysContext_WithOsyield(t *testing.T) {
 	require.True(t, yielded)
 }
 
+func TestModuleConfig_toSysContext_WithArgMax(t *testing.T) {
+	// "a\x00bc\x00a=b\x00" is exactly 9 bytes.
+	sysCtx, err := NewModuleConfig().WithArgs("a", "bc").WithEnv("a", "b").
+		WithArgMax(9).(*moduleConfig).toSysContext()
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), sysCtx.ArgsSize())
+	require.Equal(t, uint32(4), sysCtx.EnvironSize())
+}
+
+func TestModuleConfig_toSysContext_WithStdioConfig(t *testing.T) {
+	var out bytes.Buffer
+	config := NewModuleConfig().WithStdout(&out).WithEnv("LINES", "50").(*moduleConfig)
+	config.stdioConfig = &internalsys.StdioConfig{TTY: true, Cols: 80, Rows: 24, LineBuffered: true}
+
+	sysCtx, err := config.toSysContext()
+	require.NoError(t, err)
+	// WithEnv takes precedence over the terminal size.
+	require.Equal(t, [][]byte{[]byte("LINES=50"), []byte("COLUMNS=80")}, sysCtx.Environ())
+	// The config isn't changed.
+	require.Equal(t, [][]byte{[]byte("LINES"), []byte("50")}, config.environ)
+
+	f, ok := sysCtx.FS().LookupFile(internalsys.FdStdout)
+	require.True(t, ok)
+	st, errno := f.File.Stat()
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, fs.ModeCharDevice, st.Mode&fs.ModeCharDevice)
+
+	_, errno = f.File.Write([]byte("wazero"))
+	require.EqualErrno(t, 0, errno)
+	require.Zero(t, out.Len())
+	require.NoError(t, sysCtx.FS().Close())
+	require.Equal(t, "wazero", out.String())
+}
+
 func TestModuleConfig_toSysContext_Errors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -572,6 +670,11 @@ func TestModuleConfig_toSysContext_Errors(t *testing.T) {
 			input:       NewModuleConfig().WithEnv("", "a"),
 			expectedErr: "environ invalid: empty key",
 		},
+		{
+			name:        "WithArgMax exceeded",
+			input:       NewModuleConfig().WithArgs("a", "bc").WithEnv("a", "b").WithArgMax(8),
+			expectedErr: "args and environ exceed maximum size: 9 > 8",
+		},
 	}
 	for _, tt := range tests {
 		tc := tt
@@ -674,6 +777,66 @@ func Test_compiledModule_CustomSections(t *testing.T) {
 	}
 }
 
+func Test_compiledModule_Functions(t *testing.T) {
+	i32, i64, f32 := wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeF32
+
+	// add is imported, so the functions are double and nop.
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection: []wasm.FunctionType{
+			{Params: []wasm.ValueType{i32}, Results: []wasm.ValueType{i32}},
+			{},
+		},
+		ImportSection:   []wasm.Import{{Module: "env", Name: "add", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{0, 1},
+		CodeSection: []wasm.Code{
+			{
+				LocalTypes: []wasm.ValueType{i64, f32},
+				Body:       []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Add, wasm.OpcodeEnd},
+			},
+			{Body: []byte{wasm.OpcodeEnd}},
+		},
+		NameSection: &wasm.NameSection{FunctionNames: wasm.NameMap{{Index: 1, Name: "double"}}},
+	})
+
+	configs := map[string]RuntimeConfig{"interpreter": NewRuntimeConfigInterpreter()}
+	if platform.CompilerSupported() {
+		configs["compiler"] = NewRuntimeConfigCompiler()
+	}
+
+	for name, config := range configs {
+		name, config := name, config
+		t.Run(name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, config)
+			defer r.Close(testCtx)
+
+			compiled, err := r.CompileModule(testCtx, bin)
+			require.NoError(t, err)
+
+			functions := compiled.Functions()
+			require.Equal(t, 2, len(functions))
+
+			double, nop := functions[0], functions[1]
+			require.Equal(t, uint32(1), double.Definition.Index())
+			require.Equal(t, "double", double.Definition.Name())
+			require.Equal(t, []api.ValueType{i32}, double.Definition.ParamTypes())
+			require.Equal(t, []api.ValueType{i64, f32}, double.LocalTypes)
+			require.Equal(t, uint32(6), double.BodySize)
+
+			require.Equal(t, uint32(2), nop.Definition.Index())
+			require.Zero(t, len(nop.LocalTypes))
+			require.Equal(t, uint32(1), nop.BodySize)
+
+			if name == "compiler" {
+				require.True(t, double.CompiledSize > 0)
+				require.True(t, nop.CompiledSize > 0)
+			} else {
+				require.Zero(t, double.CompiledSize)
+				require.Zero(t, nop.CompiledSize)
+			}
+		})
+	}
+}
+
 func Test_compiledModule_Close(t *testing.T) {
 	for _, ctx := range []context.Context{nil, testCtx} { // Ensure it doesn't crash on nil!
 		e := &mockEngine{name: "1", cachedModules: map[*wasm.Module]struct{}{}}
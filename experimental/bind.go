@@ -0,0 +1,211 @@
+package experimental
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Value is a Go type which maps to a numeric WebAssembly value type:
+//
+//   - int32 and uint32 map to api.ValueTypeI32.
+//   - int64 and uint64 map to api.ValueTypeI64.
+//   - float32 maps to api.ValueTypeF32.
+//   - float64 maps to api.ValueTypeF64.
+type Value interface {
+	~int32 | ~uint32 | ~int64 | ~uint64 | ~float32 | ~float64
+}
+
+// Bind0 returns a Go func which calls the function exported by mod as name,
+// which must have no parameters and a single result of type R.
+//
+// Unlike api.Function Call, the returned func doesn't allocate per call, as
+// values are encoded without boxing into a stack kept by the binding.
+//
+// # Notes
+//
+//   - The signature is checked once, when binding.
+//   - Like api.Function, the returned func is not goroutine-safe.
+//   - Bind0, Bind1, Bind2 and Bind3 are for functions with a single result.
+//     BindVoid0, BindVoid1, BindVoid2 and BindVoid3 are for functions
+//     without results.
+func Bind0[R Value](mod api.Module, name string) (func(context.Context) (R, error), error) {
+	f, stack, err := bind(mod, name, []api.ValueType{valueType[R]()})
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) (R, error) {
+		err := f.CallWithStack(ctx, stack)
+		return decode[R](stack[0]), err
+	}, nil
+}
+
+// Bind1 is like Bind0, except for a function with a single parameter.
+func Bind1[P1, R Value](mod api.Module, name string) (func(context.Context, P1) (R, error), error) {
+	f, stack, err := bind(mod, name, []api.ValueType{valueType[R]()}, valueType[P1]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1) (R, error) {
+		stack[0] = encode(p1)
+		err := f.CallWithStack(ctx, stack)
+		return decode[R](stack[0]), err
+	}, nil
+}
+
+// Bind2 is like Bind0, except for a function with two parameters.
+func Bind2[P1, P2, R Value](mod api.Module, name string) (func(context.Context, P1, P2) (R, error), error) {
+	f, stack, err := bind(mod, name, []api.ValueType{valueType[R]()}, valueType[P1](), valueType[P2]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1, p2 P2) (R, error) {
+		stack[0], stack[1] = encode(p1), encode(p2)
+		err := f.CallWithStack(ctx, stack)
+		return decode[R](stack[0]), err
+	}, nil
+}
+
+// Bind3 is like Bind0, except for a function with three parameters.
+func Bind3[P1, P2, P3, R Value](mod api.Module, name string) (func(context.Context, P1, P2, P3) (R, error), error) {
+	f, stack, err := bind(mod, name, []api.ValueType{valueType[R]()}, valueType[P1](), valueType[P2](), valueType[P3]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1, p2 P2, p3 P3) (R, error) {
+		stack[0], stack[1], stack[2] = encode(p1), encode(p2), encode(p3)
+		err := f.CallWithStack(ctx, stack)
+		return decode[R](stack[0]), err
+	}, nil
+}
+
+// BindVoid0 is like Bind0, except for a function without results.
+func BindVoid0(mod api.Module, name string) (func(context.Context) error, error) {
+	f, stack, err := bind(mod, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return f.CallWithStack(ctx, stack)
+	}, nil
+}
+
+// BindVoid1 is like Bind1, except for a function without results.
+func BindVoid1[P1 Value](mod api.Module, name string) (func(context.Context, P1) error, error) {
+	f, stack, err := bind(mod, name, nil, valueType[P1]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1) error {
+		stack[0] = encode(p1)
+		return f.CallWithStack(ctx, stack)
+	}, nil
+}
+
+// BindVoid2 is like Bind2, except for a function without results.
+func BindVoid2[P1, P2 Value](mod api.Module, name string) (func(context.Context, P1, P2) error, error) {
+	f, stack, err := bind(mod, name, nil, valueType[P1](), valueType[P2]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1, p2 P2) error {
+		stack[0], stack[1] = encode(p1), encode(p2)
+		return f.CallWithStack(ctx, stack)
+	}, nil
+}
+
+// BindVoid3 is like Bind3, except for a function without results.
+func BindVoid3[P1, P2, P3 Value](mod api.Module, name string) (func(context.Context, P1, P2, P3) error, error) {
+	f, stack, err := bind(mod, name, nil, valueType[P1](), valueType[P2](), valueType[P3]())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, p1 P1, p2 P2, p3 P3) error {
+		stack[0], stack[1], stack[2] = encode(p1), encode(p2), encode(p3)
+		return f.CallWithStack(ctx, stack)
+	}, nil
+}
+
+// bind returns the function exported as name and a stack for calling it, or
+// an error if its signature isn't (params) -> (results).
+func bind(mod api.Module, name string, results []api.ValueType, params ...api.ValueType) (api.Function, []uint64, error) {
+	f := mod.ExportedFunction(name)
+	if f == nil {
+		return nil, nil, fmt.Errorf("function[%s] is not exported", name)
+	}
+	def := f.Definition()
+	if !equalTypes(def.ParamTypes(), params) || !equalTypes(def.ResultTypes(), results) {
+		return nil, nil, fmt.Errorf("function[%s] signature mismatch: %s != %s",
+			name, signature(def.ParamTypes(), def.ResultTypes()), signature(params, results))
+	}
+	stackLen := len(params)
+	if len(results) > stackLen {
+		stackLen = len(results)
+	}
+	return f, make([]uint64, stackLen), nil
+}
+
+func equalTypes(a, b []api.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// signature formats the types like "(i32,i64) -> (f32)".
+func signature(params, results []api.ValueType) string {
+	return fmt.Sprintf("(%s) -> (%s)", typeNames(params), typeNames(results))
+}
+
+func typeNames(types []api.ValueType) (ret string) {
+	for i, t := range types {
+		if i > 0 {
+			ret += ","
+		}
+		ret += api.ValueTypeName(t)
+	}
+	return
+}
+
+// valueType returns the api.ValueType of T. This uses reflection, so it is
+// only called when binding.
+func valueType[T Value]() api.ValueType {
+	var zero T
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.Int32, reflect.Uint32:
+		return api.ValueTypeI32
+	case reflect.Int64, reflect.Uint64:
+		return api.ValueTypeI64
+	case reflect.Float32:
+		return api.ValueTypeF32
+	default: // reflect.Float64 is the only remaining case of Value.
+		return api.ValueTypeF64
+	}
+}
+
+// encode returns the bits of v, which is the same as api.EncodeI32 and
+// friends for the corresponding Value type.
+func encode[T Value](v T) uint64 {
+	if unsafe.Sizeof(v) == 4 {
+		return uint64(*(*uint32)(unsafe.Pointer(&v)))
+	}
+	return *(*uint64)(unsafe.Pointer(&v))
+}
+
+// decode is the inverse of encode.
+func decode[T Value](v uint64) (ret T) {
+	if unsafe.Sizeof(ret) == 4 {
+		*(*uint32)(unsafe.Pointer(&ret)) = uint32(v)
+	} else {
+		*(*uint64)(unsafe.Pointer(&ret)) = v
+	}
+	return
+}
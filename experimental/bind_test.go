@@ -0,0 +1,105 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+var bindWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{
+		{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32}, ParamNumInUint64: 2, ResultNumInUint64: 1,
+		},
+		{
+			Params:  []wasm.ValueType{wasm.ValueTypeF64},
+			Results: []wasm.ValueType{wasm.ValueTypeF64}, ParamNumInUint64: 1, ResultNumInUint64: 1,
+		},
+		{Results: []wasm.ValueType{wasm.ValueTypeI64}, ResultNumInUint64: 1},
+		{},
+	},
+	FunctionSection: []wasm.Index{0, 1, 2, 3},
+	CodeSection: []wasm.Code{
+		{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Sub, wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeF64Neg, wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeI64Const, 0x7f, wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeEnd}},
+	},
+	ExportSection: []wasm.Export{
+		{Name: "sub", Type: wasm.ExternTypeFunc, Index: 0},
+		{Name: "neg", Type: wasm.ExternTypeFunc, Index: 1},
+		{Name: "minus_one", Type: wasm.ExternTypeFunc, Index: 2},
+		{Name: "noop", Type: wasm.ExternTypeFunc, Index: 3},
+	},
+})
+
+func TestBind(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, bindWasm)
+	require.NoError(t, err)
+
+	sub, err := experimental.Bind2[int32, int32, int32](mod, "sub")
+	require.NoError(t, err)
+	diff, err := sub(ctx, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, int32(-2), diff)
+
+	// Unsigned types share the same value type.
+	subU, err := experimental.Bind2[uint32, uint32, uint32](mod, "sub")
+	require.NoError(t, err)
+	diffU, err := subU(ctx, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint32(0xfffffffe), diffU)
+
+	neg, err := experimental.Bind1[float64, float64](mod, "neg")
+	require.NoError(t, err)
+	negated, err := neg(ctx, 1.5)
+	require.NoError(t, err)
+	require.Equal(t, -1.5, negated)
+
+	minusOne, err := experimental.Bind0[int64](mod, "minus_one")
+	require.NoError(t, err)
+	v, err := minusOne(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), v)
+
+	noop, err := experimental.BindVoid0(mod, "noop")
+	require.NoError(t, err)
+	require.NoError(t, noop(ctx))
+
+	t.Run("no allocations", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := sub(ctx, 1, 3); err != nil {
+				t.Fatal(err)
+			}
+		})
+		require.Zero(t, allocs)
+	})
+}
+
+func TestBind_Errors(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, bindWasm)
+	require.NoError(t, err)
+
+	_, err = experimental.Bind0[int32](mod, "missing")
+	require.EqualError(t, err, "function[missing] is not exported")
+
+	_, err = experimental.Bind2[int64, int32, int32](mod, "sub")
+	require.EqualError(t, err, "function[sub] signature mismatch: (i32,i32) -> (i32) != (i64,i32) -> (i32)")
+
+	_, err = experimental.BindVoid1[float32](mod, "neg")
+	require.EqualError(t, err, "function[neg] signature mismatch: (f64) -> (f64) != (f32) -> ()")
+}
@@ -0,0 +1,82 @@
+// Package clock includes a virtual clock for deterministic time in guests.
+//
+// A virtual clock only advances when it is read, slept on, or explicitly
+// advanced by the host. Unlike the default fake clocks, the progression is
+// under the control of the host, which is useful for deterministic replay.
+//
+// Here's an example which pins the time seen by the guest:
+//
+//	c := clock.New(time.Unix(0, 0), 0)
+//	res := sys.ClockResolution(time.Microsecond.Nanoseconds())
+//	moduleConfig = moduleConfig.
+//		WithWalltime(c.Walltime, res).
+//		WithNanotime(c.Nanotime, res).
+//		WithNanosleep(c.Nanosleep)
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a virtual clock which implements sys.Walltime, sys.Nanotime and
+// sys.Nanosleep. Both the wall clock and the monotonic clock are derived from
+// the same elapsed time, so they advance together.
+//
+// Clock is safe for concurrent use.
+type Clock struct {
+	// elapsed is the nanoseconds elapsed since the epoch.
+	//
+	// Note: Exclusively reading and updating this with atomics guarantees cross-goroutine observations.
+	elapsed int64
+	// epochNanos is the unix time in nanoseconds when elapsed is zero.
+	epochNanos int64
+	// step is the nanoseconds the clock advances on each reading.
+	step int64
+}
+
+// New returns a Clock whose wall clock starts at epoch, and which advances by
+// step on each reading of Walltime or Nanotime. A zero step freezes the clock
+// unless Advance or Nanosleep is called. A negative step is treated as zero,
+// as the monotonic clock must not go backwards.
+func New(epoch time.Time, step time.Duration) *Clock {
+	if step < 0 {
+		step = 0
+	}
+	return &Clock{epochNanos: epoch.UnixNano(), step: int64(step)}
+}
+
+// Walltime implements sys.Walltime.
+func (c *Clock) Walltime() (sec int64, nsec int32) {
+	wt := c.epochNanos + c.read()
+	// Floor the division, so that nsec is never negative before 1970.
+	sec, nsec = wt/1e9, int32(wt%1e9)
+	if nsec < 0 {
+		sec, nsec = sec-1, nsec+1e9
+	}
+	return
+}
+
+// Nanotime implements sys.Nanotime.
+func (c *Clock) Nanotime() int64 {
+	return c.read()
+}
+
+// Nanosleep implements sys.Nanosleep by advancing the clock by ns instead of
+// blocking the calling goroutine.
+func (c *Clock) Nanosleep(ns int64) {
+	if ns > 0 {
+		atomic.AddInt64(&c.elapsed, ns)
+	}
+}
+
+// Advance advances the clock by d. Negative durations are ignored, as the
+// monotonic clock must not go backwards.
+func (c *Clock) Advance(d time.Duration) {
+	c.Nanosleep(int64(d))
+}
+
+// read returns the current elapsed time, then advances it by the step.
+func (c *Clock) read() int64 {
+	return atomic.AddInt64(&c.elapsed, c.step) - c.step
+}
@@ -0,0 +1,82 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero/experimental/clock"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// compile-time check to ensure Clock implements the sys clock functions.
+var (
+	_ sys.Walltime  = (&clock.Clock{}).Walltime
+	_ sys.Nanotime  = (&clock.Clock{}).Nanotime
+	_ sys.Nanosleep = (&clock.Clock{}).Nanosleep
+)
+
+func TestClock_Frozen(t *testing.T) {
+	epoch := time.Date(2022, 1, 1, 0, 0, 0, 500, time.UTC)
+	c := clock.New(epoch, 0)
+
+	for i := 0; i < 2; i++ {
+		sec, nsec := c.Walltime()
+		require.Equal(t, epoch.Unix(), sec)
+		require.Equal(t, int32(500), nsec)
+		require.Equal(t, int64(0), c.Nanotime())
+	}
+
+	c.Advance(time.Second)
+	sec, nsec := c.Walltime()
+	require.Equal(t, epoch.Unix()+1, sec)
+	require.Equal(t, int32(500), nsec)
+	require.Equal(t, time.Second.Nanoseconds(), c.Nanotime())
+
+	// Sleeping advances the clock instead of blocking.
+	c.Nanosleep(10)
+	require.Equal(t, time.Second.Nanoseconds()+10, c.Nanotime())
+
+	// The clock never goes backwards.
+	c.Advance(-time.Second)
+	c.Nanosleep(-10)
+	require.Equal(t, time.Second.Nanoseconds()+10, c.Nanotime())
+}
+
+func TestClock_Step(t *testing.T) {
+	c := clock.New(time.Unix(0, 0), time.Millisecond)
+
+	// Each reading returns the current time, then advances it by the step.
+	require.Equal(t, int64(0), c.Nanotime())
+	require.Equal(t, time.Millisecond.Nanoseconds(), c.Nanotime())
+
+	// The wall clock shares the same elapsed time.
+	sec, nsec := c.Walltime()
+	require.Equal(t, int64(0), sec)
+	require.Equal(t, int32(2*time.Millisecond), nsec)
+	require.Equal(t, 3*time.Millisecond.Nanoseconds(), c.Nanotime())
+}
+
+func TestClock_BeforeEpoch(t *testing.T) {
+	epoch := time.Unix(-2, 250)
+	c := clock.New(epoch, 0)
+
+	// nsec is never negative, so the second is floored.
+	sec, nsec := c.Walltime()
+	require.Equal(t, int64(-2), sec)
+	require.Equal(t, int32(250), nsec)
+	require.Equal(t, epoch, time.Unix(sec, int64(nsec)))
+
+	c = clock.New(time.Unix(0, -250), 0)
+	sec, nsec = c.Walltime()
+	require.Equal(t, int64(-1), sec)
+	require.Equal(t, int32(1e9-250), nsec)
+}
+
+func TestClock_NegativeStep(t *testing.T) {
+	c := clock.New(time.Unix(0, 0), -time.Millisecond)
+
+	// A negative step is treated as zero, so the clock never goes backwards.
+	require.Equal(t, int64(0), c.Nanotime())
+	require.Equal(t, int64(0), c.Nanotime())
+}
@@ -0,0 +1,121 @@
+// Package coverage counts the calls to each guest function, for test coverage
+// and fuzzing tools targeting WebAssembly binaries which can't be
+// instrumented at the source.
+//
+// Coverage is collected per function: the engines don't instrument basic
+// blocks, so a Collector tells which functions ran, not which branches.
+//
+// Note: This is an experimental API and may change in any release. Counting
+// relies on function listeners, which slow down calls.
+package coverage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// Collector is a experimental.FunctionListenerFactory which counts the calls
+// to the functions it listens to. It is safe to use concurrently.
+//
+// For example:
+//
+//	c := coverage.NewCollector()
+//	ctx = context.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, c)
+//	compiled, _ := r.CompileModule(ctx, wasm)
+//	--snip--
+//	for _, h := range c.Hits() {
+//		fmt.Println(h.Name, h.Count)
+//	}
+//
+// Listeners are created when compiling, so the counts of a function include
+// calls from all instances of the same compiled module.
+type Collector struct {
+	mux      sync.Mutex
+	counters []*counter
+}
+
+// Hit is the number of calls to a function.
+type Hit struct {
+	// ModuleName is the api.FunctionDefinition ModuleName.
+	ModuleName string
+	// Index is the api.FunctionDefinition Index, which is the position of
+	// the function in the index space of its module.
+	Index uint32
+	// Name is the api.FunctionDefinition DebugName.
+	Name string
+	// Count is the number of calls, including the ones which didn't return.
+	Count uint64
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// NewFunctionListener implements the same method as documented on
+// experimental.FunctionListenerFactory.
+func (c *Collector) NewFunctionListener(def api.FunctionDefinition) experimental.FunctionListener {
+	ctr := &counter{def: def}
+	c.mux.Lock()
+	c.counters = append(c.counters, ctr)
+	c.mux.Unlock()
+	return ctr
+}
+
+// Hits returns the count of every function listened to, including the ones
+// never called, ordered by module name then index.
+func (c *Collector) Hits() []Hit {
+	c.mux.Lock()
+	hits := make([]Hit, 0, len(c.counters))
+	for _, ctr := range c.counters {
+		hits = append(hits, Hit{
+			ModuleName: ctr.def.ModuleName(),
+			Index:      ctr.def.Index(),
+			Name:       ctr.def.DebugName(),
+			Count:      atomic.LoadUint64(&ctr.count),
+		})
+	}
+	c.mux.Unlock()
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].ModuleName != hits[j].ModuleName {
+			return hits[i].ModuleName < hits[j].ModuleName
+		}
+		return hits[i].Index < hits[j].Index
+	})
+	return hits
+}
+
+// Reset sets all counts to zero, e.g. between fuzzing inputs.
+func (c *Collector) Reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, ctr := range c.counters {
+		atomic.StoreUint64(&ctr.count, 0)
+	}
+}
+
+// counter implements experimental.FunctionListener for a single function.
+type counter struct {
+	def   api.FunctionDefinition
+	count uint64
+}
+
+// Before implements the same method as documented on
+// experimental.FunctionListener.
+func (c *counter) Before(context.Context, api.Module, api.FunctionDefinition, []uint64, experimental.StackIterator) {
+	atomic.AddUint64(&c.count, 1)
+}
+
+// After implements the same method as documented on
+// experimental.FunctionListener.
+func (c *counter) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+// Abort implements the same method as documented on
+// experimental.FunctionListener.
+func (c *counter) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
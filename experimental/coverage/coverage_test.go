@@ -0,0 +1,75 @@
+package coverage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/experimental/coverage"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// branchWasm exports "main", which calls "even" if its param is even, or
+// "odd" otherwise.
+var branchWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection:     []wasm.FunctionType{{}, {Params: []wasm.ValueType{wasm.ValueTypeI32}, ParamNumInUint64: 1}},
+	FunctionSection: []wasm.Index{0, 0, 1},
+	CodeSection: []wasm.Code{
+		{Body: []byte{wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeEnd}},
+		{Body: []byte{
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32And,
+			wasm.OpcodeIf, 0x40, wasm.OpcodeCall, 1, wasm.OpcodeElse, wasm.OpcodeCall, 0, wasm.OpcodeEnd,
+			wasm.OpcodeEnd,
+		}},
+	},
+	ExportSection: []wasm.Export{{Name: "main", Type: wasm.ExternTypeFunc, Index: 2}},
+	NameSection: &wasm.NameSection{
+		ModuleName:    "test",
+		FunctionNames: wasm.NameMap{{Index: 0, Name: "even"}, {Index: 1, Name: "odd"}, {Index: 2, Name: "main"}},
+	},
+})
+
+func TestCollector(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := coverage.NewCollector()
+			ctx := context.WithValue(context.Background(), experimental.FunctionListenerFactoryKey{}, c)
+			r := wazero.NewRuntimeWithConfig(ctx, tc.config)
+			defer r.Close(ctx)
+
+			mod, err := r.Instantiate(ctx, branchWasm)
+			require.NoError(t, err)
+
+			main := mod.ExportedFunction("main")
+			for _, p := range []uint64{2, 4} {
+				_, err = main.Call(ctx, p)
+				require.NoError(t, err)
+			}
+			require.Equal(t, []coverage.Hit{
+				{ModuleName: "test", Index: 0, Name: "test.even", Count: 2},
+				{ModuleName: "test", Index: 1, Name: "test.odd", Count: 0},
+				{ModuleName: "test", Index: 2, Name: "test.main", Count: 2},
+			}, c.Hits())
+
+			c.Reset()
+			_, err = main.Call(ctx, 1)
+			require.NoError(t, err)
+			require.Equal(t, []coverage.Hit{
+				{ModuleName: "test", Index: 0, Name: "test.even", Count: 0},
+				{ModuleName: "test", Index: 1, Name: "test.odd", Count: 1},
+				{ModuleName: "test", Index: 2, Name: "test.main", Count: 1},
+			}, c.Hits())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package discard returns pages of linear memory to the operating system.
+//
+// WebAssembly memory can't shrink: "memory.grow" only grows it. A long-lived
+// module instance whose guest heap once peaked keeps that memory resident,
+// even after the guest frees it. When the guest, or its host, knows pages are
+// unused, Pages releases them while keeping the memory size the same.
+//
+// Note: This is an experimental API and may change in any release.
+package discard
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// Pages zeroes countPages pages of mem starting at offsetPages, and returns
+// them to the operating system where supported.
+//
+// An error is returned if mem isn't implemented by wazero or the range is out
+// of bounds.
+//
+// # Notes
+//
+//   - Discarded pages read back as zero, in both the guest and the host.
+//   - On Linux, whole operating system pages are released with madvise
+//     MADV_DONTNEED. Elsewhere, the pages are only zeroed, so the resident
+//     memory of the process doesn't shrink.
+//   - Don't call this while the guest uses the pages, for example
+//     concurrently with a function call of the module.
+func Pages(mem api.Memory, offsetPages, countPages uint32) error {
+	mi, ok := mem.(*wasm.MemoryInstance)
+	if !ok {
+		return fmt.Errorf("unsupported memory: %T", mem)
+	}
+	if !mi.Discard(offsetPages, countPages) {
+		return fmt.Errorf("out of range discarding %d pages at page %d", countPages, offsetPages)
+	}
+	return nil
+}
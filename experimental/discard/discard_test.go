@@ -0,0 +1,71 @@
+package discard_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/discard"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// loadWasm exports a memory of two pages, and "load", which returns the i32
+// at its parameter.
+var loadWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Params:            []wasm.ValueType{wasm.ValueTypeI32},
+		ParamNumInUint64:  1,
+		Results:           []wasm.ValueType{wasm.ValueTypeI32},
+		ResultNumInUint64: 1,
+	}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection: []wasm.Code{{Body: []byte{
+		wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Load, 0x2, 0x0, wasm.OpcodeEnd,
+	}}},
+	MemorySection: &wasm.Memory{Min: 2, Max: 2, IsMaxEncoded: true},
+	ExportSection: []wasm.Export{
+		{Name: "load", Type: wasm.ExternTypeFunc, Index: 0},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+	},
+})
+
+func TestPages(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			mod, err := r.Instantiate(testCtx, loadWasm)
+			require.NoError(t, err)
+			mem := mod.ExportedMemory("memory")
+			load := mod.ExportedFunction("load")
+
+			require.True(t, mem.WriteUint32Le(8, 42))
+			require.True(t, mem.WriteUint32Le(wasm.MemoryPageSize+8, 42))
+
+			require.EqualError(t, discard.Pages(mem, 1, 2), "out of range discarding 2 pages at page 1")
+			require.NoError(t, discard.Pages(mem, 1, 1))
+
+			// Only the discarded page reads back as zero.
+			results, err := load.Call(testCtx, 8)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{42}, results)
+			results, err = load.Call(testCtx, uint64(wasm.MemoryPageSize+8))
+			require.NoError(t, err)
+			require.Equal(t, []uint64{0}, results)
+			require.Equal(t, uint32(2*wasm.MemoryPageSize), mem.Size())
+		})
+	}
+}
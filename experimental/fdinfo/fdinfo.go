@@ -0,0 +1,71 @@
+// Package fdinfo lists the file descriptors open in a module instance, like
+// lsof does for a process. This helps debugging guests which leak files or
+// sockets.
+//
+// Note: This is an experimental API and may change in any release.
+package fdinfo
+
+import (
+	"io/fs"
+
+	"github.com/tetratelabs/wazero/api"
+	socketapi "github.com/tetratelabs/wazero/internal/sock"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// FileDescriptor describes a file descriptor open in a module instance.
+type FileDescriptor struct {
+	// FD is the file descriptor number the guest uses.
+	FD int32
+
+	// Name is the path of the file up to its pre-open, or the pre-open name
+	// itself when IsPreopen. This is empty for sockets, and can be stale if
+	// the file was renamed.
+	Name string
+
+	// IsPreopen is true for the standard I/O and the directories mounted by
+	// the host, e.g. with wazero.FSConfig WithDirMount.
+	IsPreopen bool
+
+	// Type is the type bits of the file mode, e.g. fs.ModeDir for a
+	// directory, zero for a regular file, or fs.ModeSocket for a socket.
+	// This is fs.ModeIrregular if the type could not be read.
+	Type fs.FileMode
+}
+
+// Of returns the file descriptors open in the module instance m, in ascending
+// order of FD. This is nil if m has no file descriptors, or it wasn't
+// instantiated by wazero.
+//
+// The file table of a module isn't safe for concurrent use, so this should be
+// called by a host function of m, or while none of its functions are running.
+func Of(m api.Module) []FileDescriptor {
+	mi, ok := m.(*wasm.ModuleInstance)
+	if !ok || mi.Sys == nil {
+		return nil
+	}
+	snapshot := mi.Sys.FS().Snapshot()
+	if len(snapshot) == 0 {
+		return nil
+	}
+	ret := make([]FileDescriptor, len(snapshot))
+	for i, e := range snapshot {
+		fd := FileDescriptor{FD: e.Key, Name: e.Item.Name, IsPreopen: e.Item.IsPreopen}
+		switch e.Item.File.(type) {
+		case socketapi.TCPSock, socketapi.TCPConn:
+			fd.Type = fs.ModeSocket
+		default:
+			// Check IsDir first, as Stat opens a directory pre-open which the
+			// guest didn't use yet.
+			if isDir, _ := e.Item.File.IsDir(); isDir {
+				fd.Type = fs.ModeDir
+			} else if st, errno := e.Item.File.Stat(); errno != 0 {
+				fd.Type = fs.ModeIrregular
+			} else {
+				fd.Type = st.Mode.Type()
+			}
+		}
+		ret[i] = fd
+	}
+	return ret
+}
@@ -0,0 +1,40 @@
+package fdinfo_test
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/fdinfo"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+func TestOf(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	bin := binaryencoding.EncodeModule(&wasm.Module{})
+	m, err := r.InstantiateWithConfig(testCtx, bin, wazero.NewModuleConfig().
+		WithFSConfig(wazero.NewFSConfig().WithDirMount(t.TempDir(), "/tmp")))
+	require.NoError(t, err)
+
+	fds := fdinfo.Of(m)
+	require.Equal(t, 4, len(fds))
+	for i, name := range []string{"stdin", "stdout", "stderr"} {
+		require.Equal(t, int32(i), fds[i].FD)
+		require.Equal(t, name, fds[i].Name)
+		require.True(t, fds[i].IsPreopen)
+		require.NotEqual(t, fs.ModeDir, fds[i].Type)
+	}
+	require.Equal(t, fdinfo.FileDescriptor{FD: 3, Name: "/tmp", IsPreopen: true, Type: fs.ModeDir}, fds[3])
+}
+
+func TestOf_NotModuleInstance(t *testing.T) {
+	require.Nil(t, fdinfo.Of(nil))
+}
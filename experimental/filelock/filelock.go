@@ -0,0 +1,99 @@
+// Package filelock lets guests take advisory locks on their open files, for
+// example to implement the locking of a SQLite VFS.
+//
+// wasi_snapshot_preview1 has no function to lock files, and wasi-libc doesn't
+// emulate flock or fcntl F_SETLK. Instead, this instantiates a host module
+// named ModuleName, which exports the function "flock":
+//
+//	(import "wazero_filelock" "flock"
+//	  (func $flock (param $fd i32) (param $operation i32) (result (;errno;) i32)))
+//
+// The parameters and behavior are the same as `flock` in BSD and Linux, with
+// the operation a combination of LOCK_SH, LOCK_EX, LOCK_NB and LOCK_UN. The
+// result is a WASI errno, e.g. 6 (EAGAIN) when LOCK_NB is set and another
+// open file holds a conflicting lock. The file descriptor is one the guest
+// opened with wasi_snapshot_preview1, such as with path_open.
+//
+// # Notes
+//
+//   - Files of a fs.FS, such as wazero.FSConfig WithFSMount, return 52
+//     (ENOSYS), as do platforms without flock (js, illumos and solaris).
+//   - Windows locks are mandatory, so conflicting reads or writes from other
+//     processes fail until the lock is released.
+//   - This is an experimental API and may change in any release.
+//
+// See https://man7.org/linux/man-pages/man2/flock.2.html
+package filelock
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// ModuleName is the module name guests import "flock" from.
+const ModuleName = "wazero_filelock"
+
+// Operations of "flock", which have the same values as in BSD and Linux.
+const (
+	// LOCK_SH requests a shared lock.
+	LOCK_SH = 1 //nolint
+	// LOCK_EX requests an exclusive lock.
+	LOCK_EX = 2 //nolint
+	// LOCK_NB fails with EAGAIN instead of waiting for a conflicting lock.
+	LOCK_NB = 4 //nolint
+	// LOCK_UN releases the lock.
+	LOCK_UN = 8 //nolint
+)
+
+// MustInstantiate calls Instantiate or panics on error.
+func MustInstantiate(ctx context.Context, r wazero.Runtime) {
+	if _, err := Instantiate(ctx, r); err != nil {
+		panic(err)
+	}
+}
+
+// Instantiate instantiates the ModuleName module into the runtime.
+//
+// # Notes
+//
+//   - Failure cases are documented on wazero.Runtime InstantiateModule.
+//   - Closing the wazero.Runtime has the same effect as closing the result.
+func Instantiate(ctx context.Context, r wazero.Runtime) (api.Closer, error) {
+	return r.NewHostModuleBuilder(ModuleName).
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(flock),
+			[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}).
+		WithParameterNames("fd", "operation").
+		WithResultNames("errno").
+		Export("flock").
+		Instantiate(ctx)
+}
+
+func flock(_ context.Context, mod api.Module, stack []uint64) {
+	fd, operation := int32(stack[0]), uint32(stack[1])
+	stack[0] = uint64(wasip1.ToErrno(flockFn(mod, fd, operation)))
+}
+
+func flockFn(mod api.Module, fd int32, operation uint32) syscall.Errno {
+	f, ok := mod.(*wasm.ModuleInstance).Sys.FS().LookupFile(fd)
+	if !ok {
+		return syscall.EBADF
+	}
+
+	nonblock := operation&LOCK_NB != 0
+	switch operation &^ LOCK_NB {
+	case LOCK_SH:
+		return f.File.Lock(false, nonblock)
+	case LOCK_EX:
+		return f.File.Lock(true, nonblock)
+	case LOCK_UN:
+		return f.File.Unlock()
+	default:
+		return syscall.EINVAL
+	}
+}
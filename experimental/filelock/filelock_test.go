@@ -0,0 +1,134 @@
+package filelock_test
+
+import (
+	"context"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/filelock"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// flockWasm exports "open", which opens "file" in the pre-open fd 3 and
+// returns its fd, and "flock", which calls the imported flock.
+var flockWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{
+		{
+			Params: []wasm.ValueType{
+				wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32,
+				wasm.ValueTypeI64, wasm.ValueTypeI64, wasm.ValueTypeI32, wasm.ValueTypeI32,
+			},
+			ParamNumInUint64: 9,
+			Results:          []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1,
+		},
+		{
+			Params: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32}, ParamNumInUint64: 2,
+			Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1,
+		},
+		{Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1},
+	},
+	ImportSection: []wasm.Import{
+		{Module: wasi_snapshot_preview1.ModuleName, Name: wasip1.PathOpenName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+		{Module: filelock.ModuleName, Name: "flock", Type: wasm.ExternTypeFunc, DescFunc: 1},
+	},
+	FunctionSection: []wasm.Index{2, 1},
+	CodeSection: []wasm.Code{
+		{Body: []byte{
+			// path_open(fd=3, dirflags=0, path=0, path_len=4, oflags=0, rights=0, rights=0, fdflags=0, result.fd=16)
+			wasm.OpcodeI32Const, 3, wasm.OpcodeI32Const, 0, wasm.OpcodeI32Const, 0, wasm.OpcodeI32Const, 4,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeI64Const, 0, wasm.OpcodeI64Const, 0, wasm.OpcodeI32Const, 0,
+			wasm.OpcodeI32Const, 16, wasm.OpcodeCall, 0,
+			wasm.OpcodeIf, 0x40, wasm.OpcodeUnreachable, wasm.OpcodeEnd, // trap on error.
+			wasm.OpcodeI32Const, 16, wasm.OpcodeI32Load, 2, 0,
+			wasm.OpcodeEnd,
+		}},
+		{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeCall, 1, wasm.OpcodeEnd}},
+	},
+	MemorySection: &wasm.Memory{Min: 1},
+	DataSection: []wasm.DataSegment{{
+		OffsetExpression: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+		Init:             []byte("file"),
+	}},
+	ExportSection: []wasm.Export{
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+		{Name: "open", Type: wasm.ExternTypeFunc, Index: 2},
+		{Name: "flock", Type: wasm.ExternTypeFunc, Index: 3},
+	},
+})
+
+func TestInstantiate(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "illumos" || runtime.GOOS == "solaris" {
+		t.Skip("file locking is not supported on " + runtime.GOOS)
+	}
+
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+	filelock.MustInstantiate(testCtx, r)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "file"), []byte("wazero"), 0o600))
+
+	mod, err := r.InstantiateWithConfig(testCtx, flockWasm, wazero.NewModuleConfig().
+		WithFSConfig(wazero.NewFSConfig().WithDirMount(dir, "/")))
+	require.NoError(t, err)
+
+	open := func() uint64 {
+		results, err := mod.ExportedFunction("open").Call(testCtx)
+		require.NoError(t, err)
+		return results[0]
+	}
+	flock := func(fd uint64, operation uint64) wasip1.Errno {
+		results, err := mod.ExportedFunction("flock").Call(testCtx, fd, operation)
+		require.NoError(t, err)
+		return wasip1.Errno(results[0])
+	}
+
+	// Locks conflict between separate opens of the same file.
+	fd1, fd2 := open(), open()
+	require.Equal(t, wasip1.ErrnoSuccess, flock(fd1, filelock.LOCK_EX|filelock.LOCK_NB))
+	require.Equal(t, wasip1.ErrnoAgain, flock(fd2, filelock.LOCK_SH|filelock.LOCK_NB))
+
+	// Shared locks don't conflict with each other.
+	require.Equal(t, wasip1.ErrnoSuccess, flock(fd1, filelock.LOCK_UN))
+	require.Equal(t, wasip1.ErrnoSuccess, flock(fd2, filelock.LOCK_SH|filelock.LOCK_NB))
+	require.Equal(t, wasip1.ErrnoSuccess, flock(fd1, filelock.LOCK_SH|filelock.LOCK_NB))
+	require.Equal(t, wasip1.ErrnoAgain, flock(fd1, filelock.LOCK_EX|filelock.LOCK_NB))
+
+	require.Equal(t, wasip1.ErrnoInval, flock(fd1, filelock.LOCK_SH|filelock.LOCK_EX))
+	require.Equal(t, wasip1.ErrnoBadf, flock(100, filelock.LOCK_SH))
+}
+
+func TestInstantiate_FSMount(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+	filelock.MustInstantiate(testCtx, r)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "file"), []byte("wazero"), 0o600))
+
+	mod, err := r.InstantiateWithConfig(testCtx, flockWasm, wazero.NewModuleConfig().
+		WithFSConfig(wazero.NewFSConfig().WithFSMount(os.DirFS(dir), "/")))
+	require.NoError(t, err)
+
+	results, err := mod.ExportedFunction("open").Call(testCtx)
+	require.NoError(t, err)
+
+	// fs.FS files can't be locked.
+	results, err = mod.ExportedFunction("flock").Call(testCtx, results[0], filelock.LOCK_SH)
+	require.NoError(t, err)
+	require.Equal(t, uint64(wasip1.ErrnoNosys), results[0])
+}
@@ -0,0 +1,187 @@
+// Package fswatch notifies the host of changes to a directory mounted into a
+// guest, keyed by the path the guest sees. For example, a host serving static
+// content from a long-running guest can invalidate its caches, without
+// emulating inotify inside the guest.
+//
+// Changes are found by periodically comparing the directory tree with the
+// previous scan, so wazero doesn't need a dependency on a platform specific
+// notification API. Changes within one interval are coalesced, and a file
+// which is created and removed within one interval isn't reported.
+//
+// Note: This is an experimental API and may change in any release.
+package fswatch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Op is the kind of change in an Event.
+type Op uint8
+
+const (
+	// Create means the path was added.
+	Create Op = iota + 1
+	// Write means the size or modification time of the file changed.
+	Write
+	// Remove means the path was removed.
+	Remove
+)
+
+// String implements fmt.Stringer
+func (o Op) String() string {
+	switch o {
+	case Create:
+		return "CREATE"
+	case Write:
+		return "WRITE"
+	case Remove:
+		return "REMOVE"
+	}
+	return "UNKNOWN"
+}
+
+// Event is a change to a file or directory in a watched mount.
+type Event struct {
+	// Path is the absolute path of the change in the guest, e.g.
+	// "/static/index.html" when "/static" is the guest path of the mount.
+	Path string
+
+	// Op is the kind of change.
+	Op Op
+
+	// IsDir is true when Path is a directory.
+	IsDir bool
+}
+
+// Watch starts watching hostDir, mounted in the guest at guestDir, for
+// example with wazero.FSConfig WithDirMount, and calls fn with each change.
+//
+// The directory is scanned every interval until ctx is done or stop is
+// called. Events of one scan are delivered sequentially, sorted by Path, on a
+// goroutine owned by the watcher. fn must not call stop.
+//
+// An error is returned if hostDir can't be scanned initially. Later, if
+// hostDir itself is removed, its entries are reported as Remove events. Other
+// errors reading hostDir, such as a permission error, skip the scan until the
+// next interval without an event. Entries which can't be read while walking
+// hostDir are treated as removed.
+func Watch(ctx context.Context, hostDir, guestDir string, interval time.Duration, fn func(Event)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	w := &watcher{hostDir: hostDir, guestDir: path.Clean("/" + filepath.ToSlash(guestDir)), fn: fn}
+	if w.last, err = w.scan(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}, nil
+}
+
+// fileState is what is compared between scans to detect a change.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+type watcher struct {
+	hostDir, guestDir string
+	fn                func(Event)
+	// last is the previous scan, keyed by the slash-separated path relative to hostDir.
+	last map[string]fileState
+}
+
+// scan returns the state of all files under hostDir.
+func (w *watcher) scan() (map[string]fileState, error) {
+	ret := map[string]fileState{}
+	err := filepath.WalkDir(w.hostDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == w.hostDir {
+				return err
+			}
+			return nil // Skip paths removed or made inaccessible while walking.
+		}
+		if p == w.hostDir {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(w.hostDir, p)
+		if err != nil {
+			return nil
+		}
+		ret[filepath.ToSlash(rel)] = fileState{modTime: info.ModTime(), size: info.Size(), isDir: d.IsDir()}
+		return nil
+	})
+	return ret, err
+}
+
+// poll scans hostDir and calls fn with the changes since the last scan.
+func (w *watcher) poll() {
+	current, err := w.scan()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return // Try again next interval.
+		}
+		current = map[string]fileState{}
+	}
+
+	var events []Event
+	for p, s := range current {
+		if old, ok := w.last[p]; !ok || old.isDir != s.isDir {
+			events = append(events, Event{Path: path.Join(w.guestDir, p), Op: Create, IsDir: s.isDir})
+		} else if !s.isDir && (old.size != s.size || !old.modTime.Equal(s.modTime)) {
+			events = append(events, Event{Path: path.Join(w.guestDir, p), Op: Write})
+		}
+	}
+	for p, s := range w.last {
+		if c, ok := current[p]; !ok || c.isDir != s.isDir {
+			events = append(events, Event{Path: path.Join(w.guestDir, p), Op: Remove, IsDir: s.isDir})
+		}
+	}
+	w.last = current
+
+	// Sort by path, and remove before create when the type of a path changed.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Path != events[j].Path {
+			return events[i].Path < events[j].Path
+		}
+		return events[i].Op > events[j].Op
+	})
+	for _, e := range events {
+		w.fn(e)
+	}
+}
@@ -0,0 +1,85 @@
+package fswatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+func TestWatcher_poll(t *testing.T) {
+	hostDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "index.html"), []byte("a"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(hostDir, "css"), 0o700))
+
+	var events []Event
+	w := &watcher{hostDir: hostDir, guestDir: "/static", fn: func(e Event) { events = append(events, e) }}
+	var err error
+	w.last, err = w.scan()
+	require.NoError(t, err)
+
+	// No changes
+	w.poll()
+	require.Zero(t, len(events))
+
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "index.html"), []byte("ab"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "css", "site.css"), nil, 0o600))
+	w.poll()
+	require.Equal(t, []Event{
+		{Path: "/static/css/site.css", Op: Create},
+		{Path: "/static/index.html", Op: Write},
+	}, events)
+
+	events = nil
+	require.NoError(t, os.RemoveAll(filepath.Join(hostDir, "css")))
+	w.poll()
+	require.Equal(t, []Event{
+		{Path: "/static/css", Op: Remove, IsDir: true},
+		{Path: "/static/css/site.css", Op: Remove},
+	}, events)
+
+	// A file replaced by a directory is removed, then created.
+	events = nil
+	require.NoError(t, os.Remove(filepath.Join(hostDir, "index.html")))
+	require.NoError(t, os.Mkdir(filepath.Join(hostDir, "index.html"), 0o700))
+	w.poll()
+	require.Equal(t, []Event{
+		{Path: "/static/index.html", Op: Remove},
+		{Path: "/static/index.html", Op: Create, IsDir: true},
+	}, events)
+
+	// Removing the mounted directory removes everything in it.
+	events = nil
+	require.NoError(t, os.RemoveAll(hostDir))
+	w.poll()
+	require.Equal(t, []Event{{Path: "/static/index.html", Op: Remove, IsDir: true}}, events)
+}
+
+func TestWatch(t *testing.T) {
+	hostDir := t.TempDir()
+
+	events := make(chan Event, 1)
+	stop, err := Watch(testCtx, hostDir, "/", time.Millisecond, func(e Event) { events <- e })
+	require.NoError(t, err)
+	defer stop()
+
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "a.txt"), nil, 0o600))
+	require.Equal(t, Event{Path: "/a.txt", Op: Create}, <-events)
+
+	stop()
+	stop() // idempotent
+}
+
+func TestWatch_Errors(t *testing.T) {
+	_, err := Watch(testCtx, t.TempDir(), "/", 0, func(Event) {})
+	require.EqualError(t, err, "interval must be positive")
+
+	_, err = Watch(testCtx, filepath.Join(t.TempDir(), "missing"), "/", time.Second, func(Event) {})
+	require.Error(t, err)
+}
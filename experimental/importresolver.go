@@ -0,0 +1,33 @@
+package experimental
+
+import "context"
+
+// ImportResolverKey is a context.Context Value key. Its associated value
+// should be an ImportResolver.
+type ImportResolverKey struct{}
+
+// ImportResolver is called when instantiating a module which imports a
+// module named moduleName, that isn't yet instantiated in the runtime.
+//
+// The resolver should instantiate moduleName, for example by compiling it on
+// demand or building a host module, and return nil. If it returns an error,
+// instantiation fails with it wrapped.
+//
+// # Notes
+//
+//   - Instantiate the providing module with the ctx passed to the resolver,
+//     so that its own imports are resolved the same way. Cyclic imports
+//     fail instead of recursing forever.
+//   - The resolver isn't called when the module is already instantiated, so
+//     it is called at most once per name, unless that module is closed.
+type ImportResolver func(ctx context.Context, moduleName string) error
+
+// WithImportResolver registers the given ImportResolver into the given
+// context.Context, which should be used with wazero.Runtime
+// InstantiateModule.
+func WithImportResolver(ctx context.Context, resolver ImportResolver) context.Context {
+	if resolver == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ImportResolverKey{}, resolver)
+}
@@ -0,0 +1,99 @@
+package experimental_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// importerWasm imports "env.get" and exports a function calling it as "get".
+var importerWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection:     []wasm.FunctionType{{Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1}},
+	ImportSection:   []wasm.Import{{Module: "env", Name: "get", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection:     []wasm.Code{{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeEnd}}},
+	ExportSection:   []wasm.Export{{Name: "get", Type: wasm.ExternTypeFunc, Index: 1}},
+})
+
+func TestWithImportResolver(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "compiler", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := wazero.NewRuntimeWithConfig(ctx, tc.config)
+			defer r.Close(ctx)
+
+			var resolved []string
+			ctx = experimental.WithImportResolver(ctx, func(ctx context.Context, moduleName string) error {
+				resolved = append(resolved, moduleName)
+				_, err := r.NewHostModuleBuilder(moduleName).
+					NewFunctionBuilder().WithFunc(func() uint32 { return 42 }).Export("get").
+					Instantiate(ctx)
+				return err
+			})
+
+			mod, err := r.Instantiate(ctx, importerWasm)
+			require.NoError(t, err)
+			require.Equal(t, []string{"env"}, resolved)
+
+			results, err := mod.ExportedFunction("get").Call(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{42}, results)
+
+			// The resolver isn't called again once the module exists.
+			_, err = r.InstantiateWithConfig(ctx, importerWasm, wazero.NewModuleConfig().WithName("again"))
+			require.NoError(t, err)
+			require.Equal(t, []string{"env"}, resolved)
+		})
+	}
+}
+
+func TestWithImportResolver_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("resolver error", func(t *testing.T) {
+		r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+		defer r.Close(ctx)
+
+		ctx := experimental.WithImportResolver(ctx, func(context.Context, string) error {
+			return errors.New("unavailable")
+		})
+		_, err := r.Instantiate(ctx, importerWasm)
+		require.EqualError(t, err, "module[env] not resolved: unavailable")
+	})
+
+	t.Run("cyclic import", func(t *testing.T) {
+		r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+		defer r.Close(ctx)
+
+		// Resolving "env" instantiates a module which also imports "env".
+		var resolver experimental.ImportResolver
+		resolver = func(ctx context.Context, moduleName string) error {
+			_, err := r.InstantiateWithConfig(ctx, importerWasm, wazero.NewModuleConfig().WithName(moduleName))
+			return err
+		}
+		ctx := experimental.WithImportResolver(ctx, resolver)
+		_, err := r.Instantiate(ctx, importerWasm)
+		require.EqualError(t, err, "module[env] not resolved: module[env] has a cyclic import")
+	})
+
+	t.Run("no resolver", func(t *testing.T) {
+		r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+		defer r.Close(ctx)
+
+		_, err := r.Instantiate(ctx, importerWasm)
+		require.EqualError(t, err, "module[env] not instantiated")
+	})
+}
@@ -0,0 +1,86 @@
+package experimental
+
+import "context"
+
+// MemoryWatchKey is a context.Context Value key. Its associated value should
+// be a *MemoryWatch.
+type MemoryWatchKey struct{}
+
+// MemoryRange is a range of linear memory, starting at Offset.
+type MemoryRange struct {
+	Offset, Size uint32
+}
+
+// MemoryAccess is a load or store of linear memory by a guest function.
+type MemoryAccess struct {
+	// Function is the function which accesses the memory.
+	Function InternalFunction
+
+	// PC is the program counter of the load or store. Use
+	// Function.SourceOffsetForPC to find its offset in the wasm binary.
+	PC ProgramCounter
+
+	// Offset is the effective address: the address operand plus the offset
+	// immediate of the instruction.
+	Offset uint32
+
+	// Size is the count of bytes accessed, e.g. 4 for "i32.load".
+	Size uint32
+
+	// Write is true for stores and false for loads.
+	Write bool
+}
+
+// MemoryWatch reports loads and stores which overlap any of its ranges, to
+// debug memory corruption in the guest.
+type MemoryWatch struct {
+	ranges []MemoryRange
+	fn     func(MemoryAccess)
+}
+
+// WithMemoryWatch registers fn into the given context.Context, to be called
+// before each load or store which overlaps any of the given ranges. Use the
+// returned context to call api.Function.
+//
+// For example, to find which functions write the first 4 bytes of memory:
+//
+//	ctx = experimental.WithMemoryWatch(ctx, func(a experimental.MemoryAccess) {
+//		if a.Write {
+//			fmt.Println(a.Function.Definition().DebugName(), a.Offset)
+//		}
+//	}, experimental.MemoryRange{Offset: 0, Size: 4})
+//
+// # Notes
+//
+//   - Only the interpreter reports memory accesses, so create the runtime
+//     with wazero.NewRuntimeConfigInterpreter. Other engines ignore this.
+//   - Accesses are reported before they are bounds checked, so an access
+//     which traps as out of bounds is still reported.
+//   - Bulk memory instructions, such as "memory.copy", and host functions
+//     aren't reported.
+//   - fn is called synchronously, so it slows down execution, and must not
+//     call into the module.
+func WithMemoryWatch(ctx context.Context, fn func(MemoryAccess), ranges ...MemoryRange) context.Context {
+	if fn == nil || len(ranges) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, MemoryWatchKey{}, &MemoryWatch{ranges: ranges, fn: fn})
+}
+
+// Watches returns true if an access of size bytes at offset overlaps any of
+// the ranges of this watch.
+func (w *MemoryWatch) Watches(offset, size uint32) bool {
+	start, end := uint64(offset), uint64(offset)+uint64(size)
+	for _, r := range w.ranges {
+		if start < uint64(r.Offset)+uint64(r.Size) && uint64(r.Offset) < end {
+			return true
+		}
+	}
+	return false
+}
+
+// Report calls the function registered by WithMemoryWatch. Engines call this
+// when Watches returns true.
+func (w *MemoryWatch) Report(access MemoryAccess) {
+	w.fn(access)
+}
@@ -0,0 +1,83 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// memoryWatchWasm exports "copy", which loads an i32 at address 0 and stores
+// it as an i64 at address 8.
+var memoryWatchWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection:     []wasm.FunctionType{{}},
+	FunctionSection: []wasm.Index{0},
+	MemorySection:   &wasm.Memory{Min: 1, Max: 1},
+	CodeSection: []wasm.Code{{Body: []byte{
+		wasm.OpcodeI32Const, 8,
+		wasm.OpcodeI32Const, 0,
+		wasm.OpcodeI64Load32U, 0x2, 0x0, // align=2, offset=0
+		wasm.OpcodeI64Store, 0x3, 0x0, // align=3, offset=0
+		wasm.OpcodeEnd,
+	}}},
+	ExportSection: []wasm.Export{{Name: "copy", Type: wasm.ExternTypeFunc, Index: 0}},
+})
+
+func TestWithMemoryWatch(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(ctx)
+
+	mod, err := r.Instantiate(ctx, memoryWatchWasm)
+	require.NoError(t, err)
+	copyFn := mod.ExportedFunction("copy")
+
+	tests := []struct {
+		name     string
+		ranges   []experimental.MemoryRange
+		expected []experimental.MemoryAccess
+	}{
+		{
+			name:     "load",
+			ranges:   []experimental.MemoryRange{{Offset: 3, Size: 1}},
+			expected: []experimental.MemoryAccess{{Offset: 0, Size: 4}},
+		},
+		{
+			name:     "store",
+			ranges:   []experimental.MemoryRange{{Offset: 15, Size: 10}},
+			expected: []experimental.MemoryAccess{{Offset: 8, Size: 8, Write: true}},
+		},
+		{
+			name:   "both",
+			ranges: []experimental.MemoryRange{{Offset: 0, Size: 1}, {Offset: 8, Size: 1}},
+			expected: []experimental.MemoryAccess{
+				{Offset: 0, Size: 4},
+				{Offset: 8, Size: 8, Write: true},
+			},
+		},
+		{
+			name:   "neither",
+			ranges: []experimental.MemoryRange{{Offset: 4, Size: 4}, {Offset: 16, Size: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []experimental.MemoryAccess
+			ctx := experimental.WithMemoryWatch(ctx, func(a experimental.MemoryAccess) {
+				require.Equal(t, "copy", a.Function.Definition().ExportNames()[0])
+				a.Function, a.PC = nil, 0
+				actual = append(actual, a)
+			}, tc.ranges...)
+
+			_, err := copyFn.Call(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package memview returns views of linear memory which detect when they
+// become stale.
+//
+// api.Memory Read returns bytes aliasing the memory, without copying them.
+// However, when the memory grows beyond its capacity, it is re-allocated, and
+// the bytes no longer reflect what the guest reads or writes. A View detects
+// this, so that the host can re-read instead of silently using stale bytes.
+//
+// For zero-copy I/O which outlives a function call, such as a pending socket
+// read, use Pin to prevent the memory from moving until the I/O completes.
+//
+// Note: This is an experimental API and may change in any release.
+package memview
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// ErrStale is returned by View.Validate and View.Bytes after the memory was
+// re-allocated or shrunk since the View was created.
+var ErrStale = errors.New("memory view is stale")
+
+// View is a range of linear memory. It is valid until the memory is
+// re-allocated or shrunk, such as by "memory.grow" beyond the capacity, or
+// when the module instance is reset.
+//
+// A View is not a lock: the guest can still write to the bytes.
+type View struct {
+	mem        *wasm.MemoryInstance
+	buf        []byte
+	generation uint64
+}
+
+// New returns a View of byteCount bytes at offset in mem, or an error if the
+// range is out of bounds.
+func New(mem api.Memory, offset, byteCount uint32) (*View, error) {
+	mi, ok := mem.(*wasm.MemoryInstance)
+	if !ok {
+		return nil, fmt.Errorf("unsupported memory: %T", mem)
+	}
+	buf, generation, ok := mi.View(offset, byteCount)
+	if !ok {
+		return nil, fmt.Errorf("out of range reading %d bytes at offset %d", byteCount, offset)
+	}
+	return &View{mem: mi, buf: buf, generation: generation}, nil
+}
+
+// Validate returns ErrStale if the bytes of this View no longer alias the
+// memory.
+func (v *View) Validate() error {
+	if v.mem.Generation() != v.generation {
+		return ErrStale
+	}
+	return nil
+}
+
+// Bytes returns the bytes of this View, or ErrStale if they no longer alias
+// the memory.
+//
+// Note: The result is only guaranteed current until the guest runs again,
+// unless the memory is pinned.
+func (v *View) Bytes() ([]byte, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	return v.buf, nil
+}
+
+// Pin prevents mem from being re-allocated until the returned function is
+// called, so that Views of it stay valid. Calling unpin more than once has
+// no effect. Meanwhile, growing beyond the
+// current capacity fails as if the maximum size were reached:
+// "memory.grow" returns -1, and api.Memory Grow returns false.
+//
+// To allow growing while pinned, reserve the capacity upfront, for example
+// with wazero.RuntimeConfig WithMemoryCapacityFromMax.
+//
+// Note: Resetting a module instance whose memory grew still invalidates its
+// Views, as the memory shrinks.
+func Pin(mem api.Memory) (unpin func(), err error) {
+	mi, ok := mem.(*wasm.MemoryInstance)
+	if !ok {
+		return nil, fmt.Errorf("unsupported memory: %T", mem)
+	}
+	mi.Pin()
+	var once sync.Once
+	return func() { once.Do(mi.Unpin) }, nil
+}
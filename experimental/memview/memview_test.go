@@ -0,0 +1,101 @@
+package memview_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/memview"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// growWasm exports a memory of one page, and "grow", which calls
+// "memory.grow" with its parameter.
+var growWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Params:            []wasm.ValueType{wasm.ValueTypeI32},
+		ParamNumInUint64:  1,
+		Results:           []wasm.ValueType{wasm.ValueTypeI32},
+		ResultNumInUint64: 1,
+	}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection: []wasm.Code{{Body: []byte{
+		wasm.OpcodeLocalGet, 0, wasm.OpcodeMemoryGrow, 0, wasm.OpcodeEnd,
+	}}},
+	MemorySection: &wasm.Memory{Min: 1, Max: 10, IsMaxEncoded: true},
+	ExportSection: []wasm.Export{
+		{Name: "grow", Type: wasm.ExternTypeFunc, Index: 0},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+	},
+})
+
+func TestView(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			mod, err := r.Instantiate(testCtx, growWasm)
+			require.NoError(t, err)
+			mem := mod.ExportedMemory("memory")
+			grow := mod.ExportedFunction("grow")
+
+			_, err = memview.New(mem, 65535, 2)
+			require.EqualError(t, err, "out of range reading 2 bytes at offset 65535")
+
+			v, err := memview.New(mem, 8, 4)
+			require.NoError(t, err)
+			require.NoError(t, v.Validate())
+			buf, err := v.Bytes()
+			require.NoError(t, err)
+			copy(buf, "abcd")
+			b, _ := mem.Read(8, 4)
+			require.Equal(t, []byte("abcd"), b)
+
+			// Growing by zero pages doesn't move the memory.
+			results, err := grow.Call(testCtx, 0)
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), results[0])
+			require.NoError(t, v.Validate())
+
+			// While pinned, the guest can't grow beyond the capacity.
+			unpin, err := memview.Pin(mem)
+			require.NoError(t, err)
+			results, err = grow.Call(testCtx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0xffffffff), uint64(uint32(results[0])))
+			_, ok := mem.Grow(1)
+			require.False(t, ok)
+			require.NoError(t, v.Validate())
+
+			// Unpinning allows it to, which invalidates the view.
+			unpin()
+			unpin() // no-op
+			results, err = grow.Call(testCtx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), results[0])
+			require.Equal(t, memview.ErrStale, v.Validate())
+			_, err = v.Bytes()
+			require.Equal(t, memview.ErrStale, err)
+
+			// A new view sees the current memory.
+			v, err = memview.New(mem, 8, 4)
+			require.NoError(t, err)
+			buf, err = v.Bytes()
+			require.NoError(t, err)
+			require.Equal(t, []byte("abcd"), buf)
+		})
+	}
+}
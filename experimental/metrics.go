@@ -0,0 +1,59 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// MetricsKey is a context.Context Value key. Its associated value should be
+// a Metrics.
+//
+// See package metrics for an implementation which counts events.
+type MetricsKey struct{}
+
+// Metrics receives events about compilation and execution, for example to
+// update counters exported to a monitoring system.
+//
+// Events are reported to the Metrics in the context.Context of the operation
+// that caused them:
+//   - ModuleCompiled: wazero.Runtime CompileModule or Instantiate.
+//   - FunctionCalled, Trapped and HostFunctionCalled: api.Function Call.
+//   - MemoryGrown: wazero.Runtime InstantiateModule of the module defining
+//     the memory.
+//
+// # Notes
+//
+//   - Methods are called synchronously, possibly concurrently, so they must
+//     be fast and safe for concurrent use.
+//   - Calls between guest functions aren't reported, as that would slow them
+//     down.
+type Metrics interface {
+	// ModuleCompiled is called when a module is compiled. cached is true when
+	// the compiled module was found in the compilation cache.
+	ModuleCompiled(cached bool)
+
+	// FunctionCalled is called when api.Function Call is invoked.
+	FunctionCalled(def api.FunctionDefinition)
+
+	// Trapped is called when a call returns a trap, with its code. For
+	// example, "unreachable" or "out of bounds memory access".
+	Trapped(code string)
+
+	// HostFunctionCalled is called when a host function is called, for
+	// example "fd_write" of "wasi_snapshot_preview1". This includes calls by
+	// api.Function Call, which also report FunctionCalled.
+	HostFunctionCalled(def api.FunctionDefinition)
+
+	// MemoryGrown is called when a memory grows by deltaPages, by either
+	// "memory.grow" or api.Memory Grow.
+	MemoryGrown(deltaPages uint32)
+}
+
+// WithMetrics registers the given Metrics into the given context.Context.
+func WithMetrics(ctx context.Context, metrics Metrics) context.Context {
+	if metrics == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, MetricsKey{}, metrics)
+}
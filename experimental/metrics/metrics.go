@@ -0,0 +1,151 @@
+// Package metrics counts compilation and execution events, for operational
+// visibility of the modules a host runs.
+//
+// Counters implements experimental.Metrics, and expvar.Var, so it can be
+// published with expvar.Publish. To export to another monitoring system, such
+// as Prometheus, either read a Snapshot periodically, or implement
+// experimental.Metrics to update its counters directly.
+//
+// For example:
+//
+//	counters := metrics.NewCounters()
+//	expvar.Publish("wazero", counters)
+//	ctx = experimental.WithMetrics(ctx, counters)
+//	mod, _ := r.Instantiate(ctx, wasm)
+//	_, err := mod.ExportedFunction("run").Call(ctx)
+//
+// Note: This is an experimental API and may change in any release.
+package metrics
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// Snapshot is a copy of the values of Counters. Functions are keyed by
+// module and function name, separated by a dot, e.g. "wasi_snapshot_preview1.fd_write".
+type Snapshot struct {
+	// CompileCacheHits is the count of modules found in the compilation
+	// cache.
+	CompileCacheHits uint64 `json:"compile_cache_hits"`
+	// CompileCacheMisses is the count of modules compiled from scratch.
+	CompileCacheMisses uint64 `json:"compile_cache_misses"`
+	// Calls is the count of calls by api.Function Call, by function.
+	Calls map[string]uint64 `json:"calls"`
+	// Traps is the count of calls which trapped, by trap code.
+	Traps map[string]uint64 `json:"traps"`
+	// HostCalls is the count of calls to host functions, such as WASI
+	// functions, by function.
+	HostCalls map[string]uint64 `json:"host_calls"`
+	// MemoryGrows is the count of successful memory grows.
+	MemoryGrows uint64 `json:"memory_grows"`
+	// MemoryGrowPages is the sum of the pages grown by MemoryGrows.
+	MemoryGrowPages uint64 `json:"memory_grow_pages"`
+}
+
+// Counters counts the events reported to it. It is safe for concurrent use.
+type Counters struct {
+	mux sync.Mutex
+	s   Snapshot
+}
+
+// compile-time check to ensure Counters implements experimental.Metrics.
+var _ experimental.Metrics = (*Counters)(nil)
+
+// NewCounters returns Counters with all values zero.
+func NewCounters() *Counters {
+	return &Counters{s: Snapshot{
+		Calls:     map[string]uint64{},
+		Traps:     map[string]uint64{},
+		HostCalls: map[string]uint64{},
+	}}
+}
+
+// ModuleCompiled implements the same method as documented on
+// experimental.Metrics.
+func (c *Counters) ModuleCompiled(cached bool) {
+	c.mux.Lock()
+	if cached {
+		c.s.CompileCacheHits++
+	} else {
+		c.s.CompileCacheMisses++
+	}
+	c.mux.Unlock()
+}
+
+// FunctionCalled implements the same method as documented on
+// experimental.Metrics.
+func (c *Counters) FunctionCalled(def api.FunctionDefinition) {
+	key := functionKey(def)
+	c.mux.Lock()
+	c.s.Calls[key]++
+	c.mux.Unlock()
+}
+
+// Trapped implements the same method as documented on experimental.Metrics.
+func (c *Counters) Trapped(code string) {
+	c.mux.Lock()
+	c.s.Traps[code]++
+	c.mux.Unlock()
+}
+
+// HostFunctionCalled implements the same method as documented on
+// experimental.Metrics.
+func (c *Counters) HostFunctionCalled(def api.FunctionDefinition) {
+	key := functionKey(def)
+	c.mux.Lock()
+	c.s.HostCalls[key]++
+	c.mux.Unlock()
+}
+
+// MemoryGrown implements the same method as documented on
+// experimental.Metrics.
+func (c *Counters) MemoryGrown(deltaPages uint32) {
+	c.mux.Lock()
+	c.s.MemoryGrows++
+	c.s.MemoryGrowPages += uint64(deltaPages)
+	c.mux.Unlock()
+}
+
+// Snapshot returns a copy of the current values.
+func (c *Counters) Snapshot() Snapshot {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ret := c.s
+	ret.Calls = copyMap(c.s.Calls)
+	ret.Traps = copyMap(c.s.Traps)
+	ret.HostCalls = copyMap(c.s.HostCalls)
+	return ret
+}
+
+// String implements expvar.Var, returning the Snapshot as JSON.
+func (c *Counters) String() string {
+	b, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		panic(err) // unexpected as all fields are numbers and strings.
+	}
+	return string(b)
+}
+
+// functionKey returns the module and function name of def. The export name
+// is used when the function has no name in the name section.
+func functionKey(def api.FunctionDefinition) string {
+	name := def.Name()
+	if name == "" {
+		if names := def.ExportNames(); len(names) > 0 {
+			name = names[0]
+		}
+	}
+	return def.ModuleName() + "." + name
+}
+
+func copyMap(m map[string]uint64) map[string]uint64 {
+	ret := make(map[string]uint64, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+package metrics_test
+
+import (
+	"context"
+	"expvar"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/experimental/metrics"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// guestWasm imports "env.tick", and exports "run", which calls it then grows
+// memory by one page, and "trap", which is unreachable.
+var guestWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection:     []wasm.FunctionType{{}},
+	ImportSection:   []wasm.Import{{Module: "env", Name: "tick", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+	FunctionSection: []wasm.Index{0, 0},
+	MemorySection:   &wasm.Memory{Min: 1, Max: 2, IsMaxEncoded: true},
+	CodeSection: []wasm.Code{
+		{Body: []byte{
+			wasm.OpcodeCall, 0,
+			wasm.OpcodeI32Const, 1, wasm.OpcodeMemoryGrow, 0, wasm.OpcodeDrop,
+			wasm.OpcodeEnd,
+		}},
+		{Body: []byte{wasm.OpcodeUnreachable, wasm.OpcodeEnd}},
+	},
+	ExportSection: []wasm.Export{
+		{Name: "run", Type: wasm.ExternTypeFunc, Index: 1},
+		{Name: "trap", Type: wasm.ExternTypeFunc, Index: 2},
+	},
+})
+
+func TestCounters(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			counters := metrics.NewCounters()
+			ctx := experimental.WithMetrics(testCtx, counters)
+
+			r := wazero.NewRuntimeWithConfig(ctx, tc.config)
+			defer r.Close(ctx)
+
+			_, err := r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(func() {}).Export("tick").
+				Instantiate(ctx)
+			require.NoError(t, err)
+
+			// The second compilation is a cache hit.
+			_, err = r.CompileModule(ctx, guestWasm)
+			require.NoError(t, err)
+			mod, err := r.Instantiate(ctx, guestWasm)
+			require.NoError(t, err)
+
+			_, err = mod.ExportedFunction("run").Call(ctx)
+			require.NoError(t, err)
+			_, err = mod.ExportedFunction("trap").Call(ctx)
+			require.Error(t, err)
+
+			// Calls with a context without metrics aren't counted.
+			_, err = mod.ExportedFunction("trap").Call(testCtx)
+			require.Error(t, err)
+
+			require.Equal(t, metrics.Snapshot{
+				CompileCacheHits:   1,
+				CompileCacheMisses: 2, // env and the guest
+				Calls:              map[string]uint64{".run": 1, ".trap": 1},
+				Traps:              map[string]uint64{"unreachable": 1},
+				HostCalls:          map[string]uint64{"env.tick": 1},
+				MemoryGrows:        1,
+				MemoryGrowPages:    1,
+			}, counters.Snapshot())
+		})
+	}
+}
+
+func TestCounters_String(t *testing.T) {
+	counters := metrics.NewCounters()
+	counters.ModuleCompiled(true)
+	counters.Trapped("unreachable")
+	counters.MemoryGrown(3)
+
+	var _ expvar.Var = counters
+	require.Equal(t, `{"compile_cache_hits":1,"compile_cache_misses":0,"calls":{},"traps":{"unreachable":1},"host_calls":{},"memory_grows":1,"memory_grow_pages":3}`, counters.String())
+}
@@ -0,0 +1,70 @@
+// Package pipe allows guests to stream data through pipes, such as between
+// two modules or a module and the host, without files or sockets.
+//
+// Note: This is an experimental API and may change in any release.
+package pipe
+
+import (
+	"context"
+	"os"
+
+	"github.com/tetratelabs/wazero/internal/sys"
+)
+
+// Config configures pipe ends to pre-open for a guest.
+//
+// Instantiating a module with pipe ends results in file descriptors
+// numerically after pre-opened files and sockets, in the order they were
+// added. The guest reads from or writes to them like any other file, and
+// "poll_oneoff" waits for a read end to have data, except on Windows, where
+// read ends are always considered ready.
+//
+// For example, to stream the output of one module to another:
+//
+//	pr, pw, _ := os.Pipe()
+//	producerCtx := pipe.WithConfig(ctx, pipe.NewConfig().WithWriter(pw))
+//	producer, _ := r.InstantiateModule(producerCtx, producerCompiled, config)
+//	consumerCtx := pipe.WithConfig(ctx, pipe.NewConfig().WithReader(pr))
+//	consumer, _ := r.InstantiateModule(consumerCtx, consumerCompiled, config)
+//
+// Note: The module takes ownership of the ends, closing them when it is
+// closed. So, don't use the same Config to instantiate more than one module,
+// and don't close the ends while a module uses them.
+type Config interface {
+	// WithReader pre-opens the read end of a pipe, such as returned by
+	// os.Pipe.
+	WithReader(r *os.File) Config
+
+	// WithWriter pre-opens the write end of a pipe, such as returned by
+	// os.Pipe.
+	WithWriter(w *os.File) Config
+}
+
+// NewConfig returns a Config for module instantiation.
+func NewConfig() Config {
+	return &internalPipeConfig{c: &sys.PipeConfig{}}
+}
+
+// internalPipeConfig delegates to internal/sys.PipeConfig to avoid circular
+// dependencies.
+type internalPipeConfig struct {
+	c *sys.PipeConfig
+}
+
+// WithReader implements Config.WithReader
+func (c *internalPipeConfig) WithReader(r *os.File) Config {
+	return &internalPipeConfig{c.c.WithPipeEnd(r, false)}
+}
+
+// WithWriter implements Config.WithWriter
+func (c *internalPipeConfig) WithWriter(w *os.File) Config {
+	return &internalPipeConfig{c.c.WithPipeEnd(w, true)}
+}
+
+// WithConfig registers the given Config into the given context.Context.
+func WithConfig(ctx context.Context, config Config) context.Context {
+	if config, ok := config.(*internalPipeConfig); ok && len(config.c.Ends) > 0 {
+		return context.WithValue(ctx, sys.PipeConfigKey{}, config.c)
+	}
+	return ctx
+}
@@ -0,0 +1,52 @@
+package pipe_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/tetratelabs/wazero/experimental/pipe"
+	"github.com/tetratelabs/wazero/internal/sys"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+func TestWithConfig(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	tests := []struct {
+		name     string
+		pipeCfg  pipe.Config
+		expected []sys.PipeEnd
+	}{
+		{
+			name: "returns input when pipeCfg nil",
+		},
+		{
+			name:    "returns input when pipeCfg empty",
+			pipeCfg: pipe.NewConfig(),
+		},
+		{
+			name:     "decorates with pipeCfg",
+			pipeCfg:  pipe.NewConfig().WithWriter(w).WithReader(r),
+			expected: []sys.PipeEnd{{File: w, IsWrite: true}, {File: r}},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			if decorated := pipe.WithConfig(testCtx, tc.pipeCfg); tc.expected != nil {
+				pipeCfg := decorated.Value(sys.PipeConfigKey{}).(*sys.PipeConfig)
+				require.Equal(t, tc.expected, pipeCfg.Ends)
+			} else {
+				require.Same(t, testCtx, decorated)
+			}
+		})
+	}
+}
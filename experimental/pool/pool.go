@@ -0,0 +1,172 @@
+// Package pool re-uses instances of the same wazero.CompiledModule, instead
+// of instantiating a module per request.
+//
+// All instances share the compiled code, but each has its own memory, globals
+// and tables. When an instance is returned with Put, these are reset to their
+// state after instantiation, and the start function runs again. The capacity
+// of the memory is retained, so a module which grows its memory per request
+// doesn't re-allocate it.
+//
+// Note: This is an experimental API and may change in any release.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// ErrClosed is returned by Get after Close.
+var ErrClosed = errors.New("pool closed")
+
+// ErrNotInUse is returned by Put when the module wasn't returned by Get, or
+// was already returned with Put.
+var ErrNotInUse = errors.New("module not in use from this pool")
+
+// Pool is a fixed number of instances of the same wazero.CompiledModule. It
+// is safe to use concurrently.
+//
+// For example:
+//
+//	p, _ := pool.Instantiate(ctx, r, compiled, wazero.NewModuleConfig(), 8)
+//	defer p.Close(ctx)
+//	--snip--
+//	mod, _ := p.Get(ctx)
+//	defer p.Put(ctx, mod)
+//	_, err := mod.ExportedFunction("handle").Call(ctx)
+type Pool struct {
+	r        wazero.Runtime
+	compiled wazero.CompiledModule
+	config   wazero.ModuleConfig
+
+	free chan api.Module
+	done chan struct{}
+
+	mux    sync.Mutex
+	closed bool                    // guarded by mux
+	inUse  map[api.Module]struct{} // guarded by mux; instances returned by Get
+}
+
+// Instantiate instantiates n modules from compiled into a Pool.
+//
+// When compiled has a name, instances are named after it with their index in
+// the pool, e.g. "handler-0", overriding any name in the config. Otherwise,
+// they are anonymous, so the pool doesn't conflict with other modules.
+//
+// The system context, such as arguments and open files, isn't reset between
+// uses. Each instance has its own, created from config when instantiated.
+func Instantiate(ctx context.Context, r wazero.Runtime, compiled wazero.CompiledModule, config wazero.ModuleConfig, n int) (*Pool, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid pool size: %d", n)
+	}
+	p := &Pool{
+		r:        r,
+		compiled: compiled,
+		config:   config,
+		free:     make(chan api.Module, n),
+		done:     make(chan struct{}),
+		inUse:    make(map[api.Module]struct{}, n),
+	}
+	for i := 0; i < n; i++ {
+		name := ""
+		if compiledName := compiled.Name(); compiledName != "" {
+			name = fmt.Sprintf("%s-%d", compiledName, i)
+		}
+		mod, err := r.InstantiateModule(ctx, compiled, config.WithName(name))
+		if err != nil {
+			_ = p.Close(ctx)
+			return nil, err
+		}
+		p.free <- mod
+	}
+	return p, nil
+}
+
+// Get returns a free instance, waiting for one to be returned with Put if
+// there are none. An error is returned if ctx is done first, or the pool is
+// closed.
+func (p *Pool) Get(ctx context.Context) (api.Module, error) {
+	select {
+	case <-p.done:
+		return nil, ErrClosed
+	default:
+	}
+	select {
+	case mod := <-p.free:
+		p.mux.Lock()
+		p.inUse[mod] = struct{}{}
+		p.mux.Unlock()
+		return mod, nil
+	case <-p.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put resets the instance mod returned by Get, and returns it to the pool.
+// mod must not be used after this call.
+//
+// If mod can't be reset, because it was closed (e.g. the guest called
+// "proc_exit") or its start function fails, it is replaced by a new instance.
+// If that also fails, the error is returned and the pool has one fewer
+// instance.
+//
+// ErrNotInUse is returned, and mod is left as is, if it wasn't returned by
+// Get or was already returned with Put.
+func (p *Pool) Put(ctx context.Context, mod api.Module) error {
+	p.mux.Lock()
+	_, ok := p.inUse[mod]
+	delete(p.inUse, mod)
+	p.mux.Unlock()
+	if !ok {
+		return ErrNotInUse
+	}
+
+	if err := mod.(*wasm.ModuleInstance).Reset(ctx); err != nil {
+		_ = mod.Close(ctx)
+		if mod, err = p.r.InstantiateModule(ctx, p.compiled, p.config.WithName(mod.Name())); err != nil {
+			return err
+		}
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return mod.Close(ctx)
+	}
+	// This never blocks: only instances from Get are put back, so there are
+	// never more than the capacity.
+	select {
+	case p.free <- mod:
+		return nil
+	default:
+		return mod.Close(ctx)
+	}
+}
+
+// Close closes the free instances, and makes Put close the others.
+func (p *Pool) Close(ctx context.Context) (err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	for {
+		select {
+		case mod := <-p.free:
+			if closeErr := mod.Close(ctx); closeErr != nil {
+				err = closeErr
+			}
+		default:
+			return
+		}
+	}
+}
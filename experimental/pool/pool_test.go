@@ -0,0 +1,187 @@
+package pool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/pool"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// counterWasm defines a memory of one page starting with "wazero", and a
+// mutable global starting at 10. "inc" increments the global, stores it at
+// offset 0 and grows the memory by one page.
+var counterWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Results:           []wasm.ValueType{wasm.ValueTypeI32},
+		ResultNumInUint64: 1,
+	}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection: []wasm.Code{{Body: []byte{
+		wasm.OpcodeGlobalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Add, wasm.OpcodeGlobalSet, 0,
+		wasm.OpcodeI32Const, 0, wasm.OpcodeGlobalGet, 0, wasm.OpcodeI32Store8, 0, 0,
+		wasm.OpcodeI32Const, 1, wasm.OpcodeMemoryGrow, 0, wasm.OpcodeDrop,
+		wasm.OpcodeGlobalGet, 0,
+		wasm.OpcodeEnd,
+	}}},
+	MemorySection: &wasm.Memory{Min: 1, Max: 10, IsMaxEncoded: true},
+	GlobalSection: []wasm.Global{{
+		Type: wasm.GlobalType{ValType: wasm.ValueTypeI32, Mutable: true},
+		Init: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{10}},
+	}},
+	DataSection: []wasm.DataSegment{{
+		OffsetExpression: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+		Init:             []byte("wazero"),
+	}},
+	ExportSection: []wasm.Export{
+		{Name: "inc", Type: wasm.ExternTypeFunc, Index: 0},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+	},
+	NameSection: &wasm.NameSection{ModuleName: "counter"},
+})
+
+func TestPool(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			compiled, err := r.CompileModule(testCtx, counterWasm)
+			require.NoError(t, err)
+
+			p, err := pool.Instantiate(testCtx, r, compiled, wazero.NewModuleConfig(), 2)
+			require.NoError(t, err)
+			defer p.Close(testCtx)
+
+			// Instances are named after the module.
+			require.NotNil(t, r.Module("counter-0"))
+			require.NotNil(t, r.Module("counter-1"))
+
+			requireInc := func(mod api.Module, expected uint64) {
+				results, err := mod.ExportedFunction("inc").Call(testCtx)
+				require.NoError(t, err)
+				require.Equal(t, expected, results[0])
+			}
+			requireInitialMemory := func(mod api.Module) {
+				mem := mod.ExportedMemory("memory")
+				require.Equal(t, uint32(65536), mem.Size())
+				buf, _ := mem.Read(0, 8)
+				require.Equal(t, []byte("wazero\x00\x00"), buf)
+			}
+
+			// Use both instances, so that the next Get returns a reset one.
+			mod1, err := p.Get(testCtx)
+			require.NoError(t, err)
+			mod2, err := p.Get(testCtx)
+			require.NoError(t, err)
+			requireInc(mod1, 11)
+			requireInc(mod1, 12)
+			require.Equal(t, uint32(3*65536), mod1.ExportedMemory("memory").Size())
+			require.NoError(t, p.Put(testCtx, mod1))
+
+			mod, err := p.Get(testCtx)
+			require.NoError(t, err)
+			require.Equal(t, mod1.Name(), mod.Name())
+			requireInitialMemory(mod)
+			requireInc(mod, 11)
+
+			// A closed instance is replaced.
+			require.NoError(t, mod.Close(testCtx))
+			require.NoError(t, p.Put(testCtx, mod))
+			mod, err = p.Get(testCtx)
+			require.NoError(t, err)
+			require.Equal(t, mod1.Name(), mod.Name())
+			requireInitialMemory(mod)
+			requireInc(mod, 11)
+
+			// Get waits for an instance to be returned.
+			ctx, cancel := context.WithTimeout(testCtx, 10*time.Millisecond)
+			defer cancel()
+			_, err = p.Get(ctx)
+			require.Equal(t, context.DeadlineExceeded, err)
+
+			// Closing the pool closes the instances in it, and the ones returned later.
+			require.NoError(t, p.Put(testCtx, mod))
+			require.NoError(t, p.Close(testCtx))
+			require.Nil(t, r.Module(mod.Name()))
+			require.NotNil(t, r.Module(mod2.Name()))
+			require.NoError(t, p.Put(testCtx, mod2))
+			require.Nil(t, r.Module(mod2.Name()))
+
+			_, err = p.Get(testCtx)
+			require.Equal(t, pool.ErrClosed, err)
+		})
+	}
+}
+
+func TestInstantiate_Errors(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, counterWasm)
+	require.NoError(t, err)
+
+	_, err = pool.Instantiate(testCtx, r, compiled, wazero.NewModuleConfig(), 0)
+	require.EqualError(t, err, "invalid pool size: 0")
+
+	// The names of the instances conflict with an existing module.
+	_, err = r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("counter-1"))
+	require.NoError(t, err)
+	_, err = pool.Instantiate(testCtx, r, compiled, wazero.NewModuleConfig(), 2)
+	require.EqualError(t, err, "module[counter-1] has already been instantiated")
+
+	// The instances instantiated before the error were closed.
+	require.Nil(t, r.Module("counter-0"))
+}
+
+func TestPool_PutNotInUse(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, counterWasm)
+	require.NoError(t, err)
+
+	p, err := pool.Instantiate(testCtx, r, compiled, wazero.NewModuleConfig(), 1)
+	require.NoError(t, err)
+
+	// A module not from the pool is left as is.
+	other, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("other"))
+	require.NoError(t, err)
+	_, err = other.ExportedFunction("inc").Call(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, pool.ErrNotInUse, p.Put(testCtx, other))
+	require.Equal(t, uint32(2*65536), other.ExportedMemory("memory").Size())
+
+	// A module put twice is only returned to the pool once.
+	mod, err := p.Get(testCtx)
+	require.NoError(t, err)
+	require.NoError(t, p.Put(testCtx, mod))
+	require.Equal(t, pool.ErrNotInUse, p.Put(testCtx, mod))
+
+	mod, err = p.Get(testCtx)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(testCtx, 10*time.Millisecond)
+	defer cancel()
+	_, err = p.Get(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// Neither blocks Close.
+	require.NoError(t, p.Put(testCtx, mod))
+	require.NoError(t, p.Close(testCtx))
+	require.Nil(t, r.Module(mod.Name()))
+}
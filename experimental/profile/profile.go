@@ -0,0 +1,272 @@
+// Package profile attributes the time spent in guest code to its call stacks,
+// and writes it as a profile readable by "go tool pprof".
+//
+// Compiled guest code doesn't run on goroutines the Go profiler can sample,
+// and its stack can't be read asynchronously. Instead, the Sampler listens to
+// function calls: on the first call after each period elapses, it captures the
+// call stack and attributes to it the time since the previous sample.
+//
+// This means time is only attributed at function calls, so a long-running
+// function without calls is attributed to the stack of the next call, and
+// functions are identified by their api.FunctionDefinition DebugName.
+//
+// The time is wall-clock time, and there is one clock for all calls. When
+// functions are called concurrently, a sample is attributed the time since
+// the previous sample of any call, so samples are reported as "wall" time,
+// not "cpu" time.
+//
+// Note: This is an experimental API and may change in any release. Listening
+// to function calls slows them down, so this is meant for diagnostics, not
+// production.
+package profile
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// Sampler is a experimental.FunctionListenerFactory which samples the call
+// stacks of the functions it listens to. It is safe to use concurrently.
+//
+// For example:
+//
+//	sampler := profile.NewSampler(time.Millisecond)
+//	ctx = context.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, sampler)
+//	mod, _ := r.Instantiate(ctx, wasm)
+//	--snip--
+//	sampler.WriteProfile(f)
+type Sampler struct {
+	period time.Duration
+	// now is time.Now, except in tests.
+	now func() time.Time
+
+	mux     sync.Mutex
+	last    time.Time
+	samples map[string]*sample
+}
+
+// sample is the aggregation of all the samples of the same call stack.
+type sample struct {
+	// stack holds the function names, from the top of the stack.
+	stack []string
+	count int64
+	nanos int64
+}
+
+// NewSampler returns a Sampler which captures a call stack at most once per
+// period. Zero captures the call stack at each function call.
+func NewSampler(period time.Duration) *Sampler {
+	return &Sampler{period: period, now: time.Now, samples: map[string]*sample{}}
+}
+
+// NewFunctionListener implements the same method as documented on
+// experimental.FunctionListenerFactory.
+func (s *Sampler) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return (*listener)(s)
+}
+
+// listener implements experimental.FunctionListener without exporting the
+// methods on Sampler.
+type listener Sampler
+
+// Before implements the same method as documented on
+// experimental.FunctionListener.
+func (l *listener) Before(_ context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, si experimental.StackIterator) {
+	(*Sampler)(l).sample(si)
+}
+
+// After implements the same method as documented on
+// experimental.FunctionListener.
+func (l *listener) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+// Abort implements the same method as documented on
+// experimental.FunctionListener.
+func (l *listener) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
+
+func (s *Sampler) sample(si experimental.StackIterator) {
+	now := s.now()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.last.IsZero() { // The first call starts the clock.
+		s.last = now
+		return
+	}
+	elapsed := now.Sub(s.last)
+	if elapsed < s.period {
+		return
+	}
+	s.last = now
+
+	var stack []string
+	for si.Next() {
+		stack = append(stack, si.Function().Definition().DebugName())
+	}
+	key := strings.Join(stack, "\n")
+	smp, ok := s.samples[key]
+	if !ok {
+		smp = &sample{stack: stack}
+		s.samples[key] = smp
+	}
+	smp.count++
+	smp.nanos += int64(elapsed)
+}
+
+// WriteProfile writes the samples captured so far to w, as a gzipped pprof
+// protocol buffer. The samples are retained, so calling this again includes
+// them with any captured since.
+//
+// See https://github.com/google/pprof/blob/main/proto/profile.proto
+func (s *Sampler) WriteProfile(w io.Writer) error {
+	s.mux.Lock()
+	samples := make([]*sample, 0, len(s.samples))
+	for _, smp := range s.samples {
+		samples = append(samples, &sample{stack: smp.stack, count: smp.count, nanos: smp.nanos})
+	}
+	s.mux.Unlock()
+
+	// Sort for deterministic output.
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].stack, "\n") < strings.Join(samples[j].stack, "\n")
+	})
+
+	b := encodeProfile(samples, s.period)
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Field numbers of the messages in profile.proto.
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+	profilePeriodType  = 11
+	profilePeriod      = 12
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+
+	functionID   = 1
+	functionName = 2
+)
+
+func encodeProfile(samples []*sample, period time.Duration) []byte {
+	// The first string must be empty.
+	strs := []string{""}
+	strIdx := map[string]uint64{"": 0}
+	str := func(s string) uint64 {
+		if i, ok := strIdx[s]; ok {
+			return i
+		}
+		strIdx[s] = uint64(len(strs))
+		strs = append(strs, s)
+		return strIdx[s]
+	}
+
+	var p protobuf
+	valueType := func(field int, typ, unit string) {
+		var vt protobuf
+		vt.uint64(valueTypeType, str(typ))
+		vt.uint64(valueTypeUnit, str(unit))
+		p.bytes(field, vt.buf)
+	}
+	valueType(profileSampleType, "samples", "count")
+	valueType(profileSampleType, "wall", "nanoseconds")
+
+	// Each function has a single location, with the same ID.
+	funcIDs := map[string]uint64{}
+	var funcNames []string
+	for _, smp := range samples {
+		locIDs := make([]uint64, len(smp.stack))
+		for i, name := range smp.stack {
+			id, ok := funcIDs[name]
+			if !ok {
+				funcNames = append(funcNames, name)
+				id = uint64(len(funcNames)) // IDs must be non-zero.
+				funcIDs[name] = id
+			}
+			locIDs[i] = id
+		}
+		var sp protobuf
+		sp.packed(sampleLocationID, locIDs)
+		sp.packed(sampleValue, []uint64{uint64(smp.count), uint64(smp.nanos)})
+		p.bytes(profileSample, sp.buf)
+	}
+
+	for i, name := range funcNames {
+		id := uint64(i + 1)
+		var line, loc, fn protobuf
+		line.uint64(lineFunctionID, id)
+		loc.uint64(locationID, id)
+		loc.bytes(locationLine, line.buf)
+		p.bytes(profileLocation, loc.buf)
+
+		fn.uint64(functionID, id)
+		fn.uint64(functionName, str(name))
+		p.bytes(profileFunction, fn.buf)
+	}
+
+	valueType(profilePeriodType, "wall", "nanoseconds")
+	p.uint64(profilePeriod, uint64(period))
+
+	// The string table is last, as the fields above add to it.
+	for _, s := range strs {
+		p.bytes(profileStringTable, []byte(s))
+	}
+	return p.buf
+}
+
+// protobuf encodes the subset of the protocol buffer wire format needed by
+// profile.proto.
+type protobuf struct {
+	buf []byte
+}
+
+func (p *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		p.buf = append(p.buf, byte(x)|0x80)
+		x >>= 7
+	}
+	p.buf = append(p.buf, byte(x))
+}
+
+func (p *protobuf) uint64(field int, x uint64) {
+	p.varint(uint64(field) << 3) // wire type 0: varint
+	p.varint(x)
+}
+
+func (p *protobuf) bytes(field int, b []byte) {
+	p.varint(uint64(field)<<3 | 2) // wire type 2: length-delimited
+	p.varint(uint64(len(b)))
+	p.buf = append(p.buf, b...)
+}
+
+func (p *protobuf) packed(field int, xs []uint64) {
+	var b protobuf
+	for _, x := range xs {
+		b.varint(x)
+	}
+	p.bytes(field, b.buf)
+}
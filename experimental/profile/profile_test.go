@@ -0,0 +1,105 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// callsWasm exports "main", which calls "inner" twice.
+var callsWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection:     []wasm.FunctionType{{}},
+	FunctionSection: []wasm.Index{0, 0},
+	CodeSection: []wasm.Code{
+		{Body: []byte{wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeCall, 0, wasm.OpcodeEnd}},
+	},
+	ExportSection: []wasm.Export{{Name: "main", Type: wasm.ExternTypeFunc, Index: 1}},
+	NameSection: &wasm.NameSection{
+		ModuleName:    "test",
+		FunctionNames: wasm.NameMap{{Index: 0, Name: "inner"}, {Index: 1, Name: "main"}},
+	},
+})
+
+func TestSampler(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSampler(2 * time.Millisecond)
+			// Each call advances the clock by a millisecond.
+			now := time.Unix(0, 0)
+			s.now = func() time.Time {
+				now = now.Add(time.Millisecond)
+				return now
+			}
+
+			ctx := context.WithValue(context.Background(), experimental.FunctionListenerFactoryKey{}, s)
+			r := wazero.NewRuntimeWithConfig(ctx, tc.config)
+			defer r.Close(ctx)
+
+			mod, err := r.Instantiate(ctx, callsWasm)
+			require.NoError(t, err)
+
+			main := mod.ExportedFunction("main")
+			for i := 0; i < 2; i++ {
+				_, err = main.Call(ctx)
+				require.NoError(t, err)
+			}
+
+			// There are six calls at one millisecond intervals: the first
+			// starts the clock, then every second call is sampled. These are
+			// the calls to "inner".
+			require.Equal(t, 1, len(s.samples))
+			require.Equal(t, sample{
+				stack: []string{"test.inner", "test.main"},
+				count: 2,
+				nanos: int64(4 * time.Millisecond),
+			}, *s.samples["test.inner\ntest.main"])
+
+			var buf bytes.Buffer
+			require.NoError(t, s.WriteProfile(&buf))
+			gz, err := gzip.NewReader(&buf)
+			require.NoError(t, err)
+			b, err := io.ReadAll(gz)
+			require.NoError(t, err)
+			require.Equal(t, encodeProfile([]*sample{s.samples["test.inner\ntest.main"]}, s.period), b)
+		})
+	}
+}
+
+func TestEncodeProfile(t *testing.T) {
+	b := encodeProfile([]*sample{{stack: []string{"a", "b"}, count: 1, nanos: 2}}, 3)
+	require.Equal(t, []byte{
+		0x0a, 0x04, 0x08, 0x01, 0x10, 0x02, // sample_type: samples/count
+		0x0a, 0x04, 0x08, 0x03, 0x10, 0x04, // sample_type: wall/nanoseconds
+		0x12, 0x08, 0x0a, 0x02, 0x01, 0x02, 0x12, 0x02, 0x01, 0x02, // sample: location_id=[1,2], value=[1,2]
+		0x22, 0x06, 0x08, 0x01, 0x22, 0x02, 0x08, 0x01, // location: id=1, line.function_id=1
+		0x2a, 0x04, 0x08, 0x01, 0x10, 0x05, // function: id=1, name="a"
+		0x22, 0x06, 0x08, 0x02, 0x22, 0x02, 0x08, 0x02, // location: id=2, line.function_id=2
+		0x2a, 0x04, 0x08, 0x02, 0x10, 0x06, // function: id=2, name="b"
+		0x5a, 0x04, 0x08, 0x03, 0x10, 0x04, // period_type: wall/nanoseconds
+		0x60, 0x03, // period: 3
+		0x32, 0x00, // string_table
+		0x32, 0x07, 's', 'a', 'm', 'p', 'l', 'e', 's',
+		0x32, 0x05, 'c', 'o', 'u', 'n', 't',
+		0x32, 0x04, 'w', 'a', 'l', 'l',
+		0x32, 0x0b, 'n', 'a', 'n', 'o', 's', 'e', 'c', 'o', 'n', 'd', 's',
+		0x32, 0x01, 'a',
+		0x32, 0x01, 'b',
+	}, b)
+}
@@ -0,0 +1,49 @@
+// Package signal allows handling signals which a guest raises via the WASI
+// function proc_raise.
+//
+// proc_raise was removed from WASI, but language runtimes still call it, for
+// example to implement abort via raise(SIGABRT). Without a handler, it
+// returns ENOSYS.
+package signal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// Handler is called when the guest raises the signal sig, numbered per the
+// WASI "signal" enum, e.g. 6 is SIGABRT.
+//
+// Returning nil makes proc_raise succeed, so the guest continues. Otherwise,
+// the error ends the current call from the host, as if the guest trapped.
+type Handler func(ctx context.Context, mod api.Module, sig uint8) error
+
+// WithHandler registers the given Handler into the given context.Context.
+// Use the returned context to instantiate or call the guest.
+func WithHandler(ctx context.Context, handler Handler) context.Context {
+	if handler == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, wasip1.RaiseHandlerKey{}, wasip1.RaiseHandler(handler))
+}
+
+// Trap is a Handler which ends the call with an error naming the signal.
+func Trap(_ context.Context, _ api.Module, sig uint8) error {
+	return fmt.Errorf("signal %d raised", sig)
+}
+
+// Exit returns a Handler which closes the module with the given exit code,
+// as if the guest called proc_exit.
+//
+// For example, a shell conventionally exits with 128 plus the signal number.
+func Exit(exitCode uint32) Handler {
+	return func(ctx context.Context, mod api.Module, _ uint8) error {
+		// Ensure other callers see the exit code.
+		_ = mod.CloseWithExitCode(ctx, exitCode)
+		return sys.NewExitError(exitCode)
+	}
+}
@@ -0,0 +1,93 @@
+package signal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/signal"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// raiseWasm exports "raise", which calls proc_raise and returns its errno.
+var raiseWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Params: []wasm.ValueType{wasm.ValueTypeI32}, ParamNumInUint64: 1,
+		Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1,
+	}},
+	ImportSection: []wasm.Import{{
+		Module: wasi_snapshot_preview1.ModuleName, Name: wasip1.ProcRaiseName,
+		Type: wasm.ExternTypeFunc, DescFunc: 0,
+	}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection:     []wasm.Code{{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeCall, 0, wasm.OpcodeEnd}}},
+	ExportSection:   []wasm.Export{{Name: "raise", Type: wasm.ExternTypeFunc, Index: 1}},
+})
+
+func TestWithHandler(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "compiler", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+			wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+			compiled, err := r.CompileModule(testCtx, raiseWasm)
+			require.NoError(t, err)
+
+			instantiate := func(t *testing.T) api.Module {
+				mod, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName(t.Name()))
+				require.NoError(t, err)
+				return mod
+			}
+
+			t.Run("no handler", func(t *testing.T) {
+				results, err := instantiate(t).ExportedFunction("raise").Call(testCtx, 6)
+				require.NoError(t, err)
+				require.Equal(t, []uint64{uint64(wasip1.ErrnoNosys)}, results)
+			})
+
+			t.Run("handled", func(t *testing.T) {
+				var raised []uint8
+				ctx := signal.WithHandler(testCtx, func(_ context.Context, _ api.Module, sig uint8) error {
+					raised = append(raised, sig)
+					return nil
+				})
+				results, err := instantiate(t).ExportedFunction("raise").Call(ctx, 10)
+				require.NoError(t, err)
+				require.Equal(t, []uint64{0}, results)
+				require.Equal(t, []uint8{10}, raised)
+			})
+
+			t.Run("Trap", func(t *testing.T) {
+				ctx := signal.WithHandler(testCtx, signal.Trap)
+				_, err := instantiate(t).ExportedFunction("raise").Call(ctx, 6)
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "signal 6 raised")
+			})
+
+			t.Run("Exit", func(t *testing.T) {
+				mod := instantiate(t)
+				ctx := signal.WithHandler(testCtx, signal.Exit(134))
+				_, err := mod.ExportedFunction("raise").Call(ctx, 6)
+				require.Equal(t, uint32(134), err.(*sys.ExitError).ExitCode())
+				require.Error(t, mod.(*wasm.ModuleInstance).FailIfClosed())
+			})
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Package snapshot allows saving the state of a module instance and restoring
+// it into another instance of the same module.
+//
+// A snapshot contains the linear memory, the mutable globals and the table
+// contents defined by the module. Imported memories, globals and tables are
+// owned by other modules, so they are not part of the snapshot.
+//
+// Snapshots are taken between function calls: the state of an in-flight call,
+// such as its value and call stacks, is never captured.
+//
+// Note: This is an experimental API and the encoding may change in any
+// release, so snapshots must be restored with the same version of wazero.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// magic is the header of the encoded snapshot, followed by the version byte.
+var magic = []byte("wzsnap")
+
+const version = 1
+
+// Write encodes the state of the module instance m into w.
+//
+// An error is returned if m was not instantiated by wazero, or if a table of m
+// holds a function reference which doesn't belong to m.
+func Write(w io.Writer, m api.Module) error {
+	mi, ok := m.(*wasm.ModuleInstance)
+	if !ok {
+		return fmt.Errorf("unsupported module: %T", m)
+	}
+	source := mi.Source
+
+	e := encoder{w: w}
+	e.write(magic)
+	e.write([]byte{version})
+	e.write(source.ID[:])
+
+	if source.MemorySection != nil {
+		e.u32(1)
+		e.u32(uint32(len(mi.MemoryInstance.Buffer)))
+		e.write(mi.MemoryInstance.Buffer)
+	} else {
+		e.u32(0)
+	}
+
+	globals := mi.Globals[source.ImportGlobalCount:]
+	e.u32(uint32(len(globals)))
+	for _, g := range globals {
+		e.u64(g.Val)
+		e.u64(g.ValHi)
+	}
+
+	refs := functionReferences(mi)
+	tables := mi.Tables[source.ImportTableCount:]
+	e.u32(uint32(len(tables)))
+	for i, t := range tables {
+		e.u32(uint32(len(t.References)))
+		for j, ref := range t.References {
+			if ref == 0 {
+				e.u32(0)
+				continue
+			}
+			funcIdx, ok := refs[ref]
+			if !ok {
+				return fmt.Errorf("table[%d][%d] references a function of another module", i, j)
+			}
+			// Zero is reserved for the null reference.
+			e.u32(funcIdx + 1)
+		}
+	}
+	return e.err
+}
+
+// Restore decodes the snapshot from r and applies it to the module instance m,
+// which must be instantiated from the same module as the one the snapshot was
+// taken from.
+//
+// Memories and tables are grown as needed to fit the snapshot. An error is
+// returned if they are larger than in the snapshot, as they cannot shrink.
+func Restore(r io.Reader, m api.Module) error {
+	mi, ok := m.(*wasm.ModuleInstance)
+	if !ok {
+		return fmt.Errorf("unsupported module: %T", m)
+	}
+	source := mi.Source
+
+	d := decoder{r: r}
+	header := d.read(len(magic) + 1)
+	if d.err == nil && (!bytes.Equal(header[:len(magic)], magic) || header[len(magic)] != version) {
+		return errors.New("invalid snapshot header")
+	}
+	if id := d.read(len(source.ID)); d.err == nil && !bytes.Equal(id, source.ID[:]) {
+		return errors.New("snapshot was taken from a different module")
+	}
+
+	if hasMemory := d.u32() == 1; d.err == nil && hasMemory != (source.MemorySection != nil) {
+		return errors.New("memory mismatch")
+	} else if hasMemory {
+		size := d.u32()
+		if d.err != nil {
+			return d.err
+		}
+		mem := mi.MemoryInstance
+		current := uint32(len(mem.Buffer))
+		if current > size {
+			return fmt.Errorf("memory size %d is larger than snapshot size %d", current, size)
+		} else if current < size {
+			if _, ok = mem.Grow((size - current) >> wasm.MemoryPageSizeInBits); !ok {
+				return fmt.Errorf("cannot grow memory to snapshot size %d", size)
+			}
+		}
+		d.readInto(mem.Buffer)
+	}
+
+	globals := mi.Globals[source.ImportGlobalCount:]
+	if n := d.u32(); d.err == nil && int(n) != len(globals) {
+		return fmt.Errorf("global count mismatch: %d != %d", n, len(globals))
+	}
+	for _, g := range globals {
+		val, valHi := d.u64(), d.u64()
+		if d.err == nil && g.Type.Mutable {
+			g.Val, g.ValHi = val, valHi
+		}
+	}
+
+	tables := mi.Tables[source.ImportTableCount:]
+	if n := d.u32(); d.err == nil && int(n) != len(tables) {
+		return fmt.Errorf("table count mismatch: %d != %d", n, len(tables))
+	}
+	funcCount := source.ImportFunctionCount + wasm.Index(len(source.FunctionSection))
+	for i, t := range tables {
+		size := d.u32()
+		if d.err != nil {
+			return d.err
+		}
+		if current := uint32(len(t.References)); current > size {
+			return fmt.Errorf("table[%d] size %d is larger than snapshot size %d", i, current, size)
+		} else if current < size {
+			if t.Grow(size-current, 0) != current {
+				return fmt.Errorf("cannot grow table[%d] to snapshot size %d", i, size)
+			}
+		}
+		for j := range t.References {
+			funcIdx := d.u32()
+			switch {
+			case d.err != nil:
+				return d.err
+			case funcIdx == 0:
+				t.References[j] = 0
+			case funcIdx > funcCount:
+				return fmt.Errorf("table[%d][%d] references an invalid function index %d", i, j, funcIdx-1)
+			default:
+				t.References[j] = mi.Engine.FunctionInstanceReference(funcIdx - 1)
+			}
+		}
+	}
+	return d.err
+}
+
+// functionReferences maps the references of all the functions in mi to their index.
+func functionReferences(mi *wasm.ModuleInstance) map[wasm.Reference]wasm.Index {
+	funcCount := mi.Source.ImportFunctionCount + wasm.Index(len(mi.Source.FunctionSection))
+	refs := make(map[wasm.Reference]wasm.Index, funcCount)
+	for i := wasm.Index(0); i < funcCount; i++ {
+		refs[mi.Engine.FunctionInstanceReference(i)] = i
+	}
+	return refs
+}
+
+// encoder writes little-endian values into w, retaining the first error.
+type encoder struct {
+	w   io.Writer
+	buf [8]byte
+	err error
+}
+
+func (e *encoder) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) u32(v uint32) {
+	binary.LittleEndian.PutUint32(e.buf[:4], v)
+	e.write(e.buf[:4])
+}
+
+func (e *encoder) u64(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], v)
+	e.write(e.buf[:8])
+}
+
+// decoder reads little-endian values from r, retaining the first error.
+type decoder struct {
+	r   io.Reader
+	buf [8]byte
+	err error
+}
+
+func (d *decoder) readInto(b []byte) {
+	if d.err == nil {
+		if _, d.err = io.ReadFull(d.r, b); d.err == io.EOF {
+			d.err = io.ErrUnexpectedEOF
+		}
+	}
+}
+
+func (d *decoder) read(n int) []byte {
+	b := make([]byte, n)
+	d.readInto(b)
+	return b
+}
+
+func (d *decoder) u32() uint32 {
+	d.readInto(d.buf[:4])
+	return binary.LittleEndian.Uint32(d.buf[:4])
+}
+
+func (d *decoder) u64() uint64 {
+	d.readInto(d.buf[:8])
+	return binary.LittleEndian.Uint64(d.buf[:8])
+}
@@ -0,0 +1,174 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/snapshot"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// statefulWasm exports "mutate" which grows the memory, writes into it, sets
+// the global and overwrites the first table element with the second, which
+// changes the result of "call0".
+var statefulWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{
+		{Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1},
+		{},
+	},
+	FunctionSection: []wasm.Index{0, 0, 1, 0},
+	CodeSection: []wasm.Code{
+		{Body: []byte{wasm.OpcodeI32Const, 1, wasm.OpcodeEnd}},
+		{Body: []byte{wasm.OpcodeI32Const, 2, wasm.OpcodeEnd}},
+		{Body: []byte{
+			wasm.OpcodeI32Const, 1, wasm.OpcodeMemoryGrow, 0, wasm.OpcodeDrop,
+			wasm.OpcodeI32Const, 0x80, 0x80, 0x04, // 65536
+			wasm.OpcodeI32Const, 42,
+			wasm.OpcodeI32Store8, 0, 0,
+			wasm.OpcodeI32Const, 7, wasm.OpcodeGlobalSet, 0,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Const, 1,
+			wasm.OpcodeMiscPrefix, wasm.OpcodeMiscTableCopy, 0, 0,
+			wasm.OpcodeEnd,
+		}},
+		{Body: []byte{wasm.OpcodeI32Const, 0, wasm.OpcodeCallIndirect, 0, 0, wasm.OpcodeEnd}},
+	},
+	MemorySection: &wasm.Memory{Min: 1, Max: 2, IsMaxEncoded: true},
+	GlobalSection: []wasm.Global{{
+		Type: wasm.GlobalType{ValType: wasm.ValueTypeI32, Mutable: true},
+		Init: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+	}},
+	TableSection: []wasm.Table{{Min: 2, Type: wasm.RefTypeFuncref}},
+	ElementSection: []wasm.ElementSegment{{
+		OffsetExpr: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+		Init:       []wasm.Index{0, 1},
+		Type:       wasm.RefTypeFuncref,
+		Mode:       wasm.ElementModeActive,
+	}},
+	ExportSection: []wasm.Export{
+		{Name: "mutate", Type: wasm.ExternTypeFunc, Index: 2},
+		{Name: "call0", Type: wasm.ExternTypeFunc, Index: 3},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+		{Name: "global", Type: wasm.ExternTypeGlobal, Index: 0},
+	},
+})
+
+func TestWriteRestore(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			compiled, err := r.CompileModule(testCtx, statefulWasm)
+			require.NoError(t, err)
+
+			src, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("src"))
+			require.NoError(t, err)
+			_, err = src.ExportedFunction("mutate").Call(testCtx)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, snapshot.Write(&buf, src))
+
+			dst, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("dst"))
+			require.NoError(t, err)
+			requireCall0(t, dst, 1)
+
+			require.NoError(t, snapshot.Restore(&buf, dst))
+
+			require.Equal(t, uint32(2*65536), dst.ExportedMemory("memory").Size())
+			b, ok := dst.ExportedMemory("memory").ReadByte(65536)
+			require.True(t, ok)
+			require.Equal(t, byte(42), b)
+			require.Equal(t, uint64(7), dst.ExportedGlobal("global").Get())
+			requireCall0(t, dst, 2)
+
+			// The source instance is left untouched.
+			requireCall0(t, src, 2)
+		})
+	}
+}
+
+func TestRestore_Errors(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, statefulWasm)
+	require.NoError(t, err)
+	src, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("src"))
+	require.NoError(t, err)
+	_, err = src.ExportedFunction("mutate").Call(testCtx)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.Write(&buf, src))
+	snap := buf.Bytes()
+
+	other, err := r.Instantiate(testCtx, binaryencoding.EncodeModule(&wasm.Module{}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		snapshot    []byte
+		module      api.Module
+		expectedErr string
+	}{
+		{
+			name:        "invalid header",
+			snapshot:    []byte("snapshot"),
+			module:      src,
+			expectedErr: "invalid snapshot header",
+		},
+		{
+			name:        "different module",
+			snapshot:    snap,
+			module:      other,
+			expectedErr: "snapshot was taken from a different module",
+		},
+		{
+			name:        "truncated",
+			snapshot:    snap[:len(snap)-1],
+			module:      src,
+			expectedErr: io.ErrUnexpectedEOF.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			err := snapshot.Restore(bytes.NewReader(tc.snapshot), tc.module)
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+
+	t.Run("memory larger than snapshot", func(t *testing.T) {
+		fresh, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithName("fresh"))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, snapshot.Write(&buf, fresh))
+		err = snapshot.Restore(&buf, src)
+		require.EqualError(t, err, "memory size 131072 is larger than snapshot size 65536")
+	})
+}
+
+func requireCall0(t *testing.T, m api.Module, expected uint64) {
+	results, err := m.ExportedFunction("call0").Call(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{expected}, results)
+}
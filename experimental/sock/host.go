@@ -0,0 +1,199 @@
+package sock
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"syscall"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/sock"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// ModuleName is the module name of the host functions that extend
+// wasi_snapshot_preview1 sockets, instantiated by Instantiate:
+//
+//	(import "wazero_sock" "getsockopt"
+//	  (func $getsockopt (param $fd i32) (param $option i32) (param $result.value i32) (result (;errno;) i32)))
+//	(import "wazero_sock" "setsockopt"
+//	  (func $setsockopt (param $fd i32) (param $option i32) (param $value i32) (result (;errno;) i32)))
+//	(import "wazero_sock" "getlocaladdr"
+//	  (func $getlocaladdr (param $fd i32) (param $result.addr i32) (result (;errno;) i32)))
+//	(import "wazero_sock" "getpeeraddr"
+//	  (func $getpeeraddr (param $fd i32) (param $result.addr i32) (result (;errno;) i32)))
+//
+// The file descriptor is a pre-opened listener (see Config) or a connection
+// from wasi_snapshot_preview1 sock_accept. Options are SO_REUSEADDR,
+// SO_KEEPALIVE or TCP_NODELAY, which are all boolean: getsockopt writes zero
+// or one as a little-endian uint32 to result.value, and setsockopt enables
+// the option when value is non-zero. Results are WASI errnos, e.g. 57
+// (ENOTSOCK) for a file which isn't a socket.
+//
+// getlocaladdr and getpeeraddr are like `getsockname` and `getpeername` in
+// POSIX, and write AddrLen bytes to result.addr:
+//
+//   - [0]: 4 for an IPv4 address or 6 for an IPv6 address.
+//   - [1]: zero.
+//   - [2:4]: the port, as a little-endian uint16.
+//   - [4:20]: the IP address. An IPv4 address is in the first four bytes,
+//     followed by zeros.
+//
+// A listener has no peer, so getpeeraddr returns 53 (ENOTCONN).
+//
+// Note: This is an experimental API and may change in any release.
+const ModuleName = "wazero_sock"
+
+// Options of "getsockopt" and "setsockopt". Unlike the host's constants,
+// these values are the same on all platforms.
+const (
+	// SO_REUSEADDR allows binding an address in TIME_WAIT.
+	SO_REUSEADDR = uint32(sock.SockOptReuseAddr) //nolint
+	// SO_KEEPALIVE enables keep-alive messages on a connection.
+	SO_KEEPALIVE = uint32(sock.SockOptKeepAlive) //nolint
+	// TCP_NODELAY disables Nagle's algorithm.
+	TCP_NODELAY = uint32(sock.SockOptTCPNoDelay) //nolint
+)
+
+// AddrLen is the length of the address written by "getlocaladdr" and
+// "getpeeraddr".
+const AddrLen = 20
+
+// MustInstantiate calls Instantiate or panics on error.
+func MustInstantiate(ctx context.Context, r wazero.Runtime) {
+	if _, err := Instantiate(ctx, r); err != nil {
+		panic(err)
+	}
+}
+
+// Instantiate instantiates the ModuleName module into the runtime.
+//
+// # Notes
+//
+//   - Failure cases are documented on wazero.Runtime InstantiateModule.
+//   - Closing the wazero.Runtime has the same effect as closing the result.
+func Instantiate(ctx context.Context, r wazero.Runtime) (api.Closer, error) {
+	i32 := api.ValueTypeI32
+	return r.NewHostModuleBuilder(ModuleName).
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(getsockopt), []api.ValueType{i32, i32, i32}, []api.ValueType{i32}).
+		WithParameterNames("fd", "option", "result.value").
+		WithResultNames("errno").
+		Export("getsockopt").
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(setsockopt), []api.ValueType{i32, i32, i32}, []api.ValueType{i32}).
+		WithParameterNames("fd", "option", "value").
+		WithResultNames("errno").
+		Export("setsockopt").
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(getlocaladdr), []api.ValueType{i32, i32}, []api.ValueType{i32}).
+		WithParameterNames("fd", "result.addr").
+		WithResultNames("errno").
+		Export("getlocaladdr").
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(getpeeraddr), []api.ValueType{i32, i32}, []api.ValueType{i32}).
+		WithParameterNames("fd", "result.addr").
+		WithResultNames("errno").
+		Export("getpeeraddr").
+		Instantiate(ctx)
+}
+
+// sockOpter is implemented by both sock.TCPSock and sock.TCPConn.
+type sockOpter interface {
+	GetSockOpt(opt sock.SockOpt) (int, syscall.Errno)
+	SetSockOpt(opt sock.SockOpt, value int) syscall.Errno
+}
+
+func getsockopt(_ context.Context, mod api.Module, stack []uint64) {
+	fd, option, resultValue := int32(stack[0]), uint32(stack[1]), uint32(stack[2])
+
+	var v int
+	s, errno := lookupSockOpter(mod, fd, option)
+	if errno == 0 {
+		v, errno = s.GetSockOpt(sock.SockOpt(option))
+	}
+	if errno == 0 && !mod.Memory().WriteUint32Le(resultValue, uint32(v)) {
+		errno = syscall.EFAULT
+	}
+	stack[0] = uint64(wasip1.ToErrno(errno))
+}
+
+func setsockopt(_ context.Context, mod api.Module, stack []uint64) {
+	fd, option, value := int32(stack[0]), uint32(stack[1]), uint32(stack[2])
+
+	s, errno := lookupSockOpter(mod, fd, option)
+	if errno == 0 {
+		errno = s.SetSockOpt(sock.SockOpt(option), int(value))
+	}
+	stack[0] = uint64(wasip1.ToErrno(errno))
+}
+
+func lookupSockOpter(mod api.Module, fd int32, option uint32) (sockOpter, syscall.Errno) {
+	if f, ok := mod.(*wasm.ModuleInstance).Sys.FS().LookupFile(fd); !ok {
+		return nil, syscall.EBADF // Not open
+	} else if s, ok := f.File.(sockOpter); !ok {
+		return nil, syscall.ENOTSOCK
+	} else if option > TCP_NODELAY {
+		return nil, syscall.ENOPROTOOPT
+	} else {
+		return s, 0
+	}
+}
+
+func getlocaladdr(_ context.Context, mod api.Module, stack []uint64) {
+	stack[0] = uint64(wasip1.ToErrno(writeAddr(mod, int32(stack[0]), uint32(stack[1]), false)))
+}
+
+func getpeeraddr(_ context.Context, mod api.Module, stack []uint64) {
+	stack[0] = uint64(wasip1.ToErrno(writeAddr(mod, int32(stack[0]), uint32(stack[1]), true)))
+}
+
+// writeAddr writes the local or peer address of the socket fd to resultAddr
+// in the layout documented on ModuleName.
+func writeAddr(mod api.Module, fd int32, resultAddr uint32, peer bool) syscall.Errno {
+	f, ok := mod.(*wasm.ModuleInstance).Sys.FS().LookupFile(fd)
+	if !ok {
+		return syscall.EBADF // Not open
+	}
+
+	var addr *net.TCPAddr
+	var errno syscall.Errno
+	switch s := f.File.(type) {
+	case sock.TCPConn:
+		if peer {
+			addr, errno = s.RemoteAddr()
+		} else {
+			addr, errno = s.LocalAddr()
+		}
+	case interface{ Addr() *net.TCPAddr }: // listener
+		if peer {
+			errno = syscall.ENOTCONN
+		} else {
+			addr = s.Addr()
+		}
+	default:
+		return syscall.ENOTSOCK
+	}
+	if errno != 0 {
+		return errno
+	}
+
+	buf, ok := mod.Memory().Read(resultAddr, AddrLen)
+	if !ok {
+		return syscall.EFAULT
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		buf[0] = 4
+		copy(buf[4:], ip4)
+	} else {
+		buf[0] = 6
+		copy(buf[4:], addr.IP.To16())
+	}
+	binary.LittleEndian.PutUint16(buf[2:], uint16(addr.Port))
+	return 0
+}
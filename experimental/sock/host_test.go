@@ -0,0 +1,198 @@
+package sock_test
+
+import (
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/sock"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/sys"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasip1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// guestImports are the functions sockWasm re-exports under the same name.
+// All params and results are i32.
+var guestImports = []struct {
+	module, name string
+	paramCount   int
+}{
+	{wasi_snapshot_preview1.ModuleName, wasip1.SockAcceptName, 3},
+	{sock.ModuleName, "getsockopt", 3},
+	{sock.ModuleName, "setsockopt", 3},
+	{sock.ModuleName, "getlocaladdr", 2},
+	{sock.ModuleName, "getpeeraddr", 2},
+}
+
+// sockWasm re-exports guestImports, so that tests call them from a guest.
+var sockWasm = func() []byte {
+	m := &wasm.Module{
+		MemorySection: &wasm.Memory{Min: 1},
+		ExportSection: []wasm.Export{{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0}},
+	}
+	for i, imp := range guestImports {
+		idx := wasm.Index(i)
+		params := make([]wasm.ValueType, imp.paramCount)
+		var body []byte
+		for p := range params {
+			params[p] = wasm.ValueTypeI32
+			body = append(body, wasm.OpcodeLocalGet, byte(p))
+		}
+		body = append(body, wasm.OpcodeCall, byte(idx), wasm.OpcodeEnd)
+
+		m.TypeSection = append(m.TypeSection, wasm.FunctionType{
+			Params: params, ParamNumInUint64: len(params),
+			Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1,
+		})
+		m.ImportSection = append(m.ImportSection, wasm.Import{
+			Module: imp.module, Name: imp.name, Type: wasm.ExternTypeFunc, DescFunc: idx,
+		})
+		m.FunctionSection = append(m.FunctionSection, idx)
+		m.CodeSection = append(m.CodeSection, wasm.Code{Body: body})
+		m.ExportSection = append(m.ExportSection, wasm.Export{
+			Name: imp.name, Type: wasm.ExternTypeFunc, Index: wasm.Index(len(guestImports) + i),
+		})
+	}
+	return binaryencoding.EncodeModule(m)
+}()
+
+// requireAcceptedConn instantiates sockWasm with a listener, then connects to
+// it and accepts the connection from the guest.
+func requireAcceptedConn(t *testing.T) (mod api.Module, connFd uint32, conn *net.TCPConn) {
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+	default:
+		t.Skip("sockets are not supported on " + runtime.GOOS)
+	}
+
+	ctx := sock.WithConfig(testCtx, sock.NewConfig().WithTCPListener("127.0.0.1", 0))
+	r := wazero.NewRuntime(ctx)
+	t.Cleanup(func() { r.Close(ctx) })
+
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+	sock.MustInstantiate(ctx, r)
+
+	mod, err := r.InstantiateWithConfig(ctx, sockWasm, wazero.NewModuleConfig())
+	require.NoError(t, err)
+
+	conn, err = net.DialTCP("tcp", nil, requireListenerAddr(t, mod))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.Equal(t, wasip1.ErrnoSuccess, call(t, mod, wasip1.SockAcceptName, uint64(sys.FdPreopen), 0, 0))
+	connFd, ok := mod.Memory().ReadUint32Le(0)
+	require.True(t, ok)
+	return
+}
+
+func requireListenerAddr(t *testing.T, mod api.Module) *net.TCPAddr {
+	f, ok := mod.(*wasm.ModuleInstance).Sys.FS().LookupFile(sys.FdPreopen)
+	require.True(t, ok)
+	return f.File.(interface{ Addr() *net.TCPAddr }).Addr()
+}
+
+func call(t *testing.T, mod api.Module, name string, params ...uint64) wasip1.Errno {
+	results, err := mod.ExportedFunction(name).Call(testCtx, params...)
+	require.NoError(t, err)
+	return wasip1.Errno(results[0])
+}
+
+func TestInstantiate_sockopt(t *testing.T) {
+	mod, connFd, _ := requireAcceptedConn(t)
+
+	tests := []struct {
+		name   string
+		fd     uint64
+		option uint32
+	}{
+		{name: "listener SO_REUSEADDR", fd: uint64(sys.FdPreopen), option: sock.SO_REUSEADDR},
+		{name: "conn SO_KEEPALIVE", fd: uint64(connFd), option: sock.SO_KEEPALIVE},
+		{name: "conn TCP_NODELAY", fd: uint64(connFd), option: sock.TCP_NODELAY},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			for _, value := range []uint32{1, 0, 42} {
+				require.Equal(t, wasip1.ErrnoSuccess, call(t, mod, "setsockopt", tc.fd, uint64(tc.option), uint64(value)))
+				require.Equal(t, wasip1.ErrnoSuccess, call(t, mod, "getsockopt", tc.fd, uint64(tc.option), 16))
+
+				expected := uint32(0)
+				if value != 0 {
+					expected = 1
+				}
+				actual, ok := mod.Memory().ReadUint32Le(16)
+				require.True(t, ok)
+				require.Equal(t, expected, actual)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, name := range []string{"getsockopt", "setsockopt"} {
+			require.Equal(t, wasip1.ErrnoBadf, call(t, mod, name, 100, uint64(sock.TCP_NODELAY), 16))
+			require.Equal(t, wasip1.ErrnoNotsock, call(t, mod, name, uint64(sys.FdStdin), uint64(sock.TCP_NODELAY), 16))
+			require.Equal(t, wasip1.ErrnoNoprotoopt, call(t, mod, name, uint64(connFd), uint64(sock.TCP_NODELAY+1), 16))
+		}
+		require.Equal(t, wasip1.ErrnoFault, call(t, mod, "getsockopt", uint64(connFd), uint64(sock.TCP_NODELAY), uint64(wasm.MemoryPageSize)))
+	})
+}
+
+func TestInstantiate_addr(t *testing.T) {
+	mod, connFd, conn := requireAcceptedConn(t)
+
+	tests := []struct {
+		name     string
+		function string
+		fd       uint64
+		expected *net.TCPAddr
+	}{
+		{
+			name:     "listener getlocaladdr",
+			function: "getlocaladdr",
+			fd:       uint64(sys.FdPreopen),
+			expected: requireListenerAddr(t, mod),
+		},
+		{
+			name:     "conn getlocaladdr",
+			function: "getlocaladdr",
+			fd:       uint64(connFd),
+			expected: conn.RemoteAddr().(*net.TCPAddr),
+		},
+		{
+			name:     "conn getpeeraddr",
+			function: "getpeeraddr",
+			fd:       uint64(connFd),
+			expected: conn.LocalAddr().(*net.TCPAddr),
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, wasip1.ErrnoSuccess, call(t, mod, tc.function, tc.fd, 32))
+
+			buf, ok := mod.Memory().Read(32, sock.AddrLen)
+			require.True(t, ok)
+			expected := make([]byte, sock.AddrLen)
+			expected[0] = 4
+			expected[2], expected[3] = byte(tc.expected.Port), byte(tc.expected.Port>>8)
+			copy(expected[4:], tc.expected.IP.To4())
+			require.Equal(t, expected, buf)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		require.Equal(t, wasip1.ErrnoNotconn, call(t, mod, "getpeeraddr", uint64(sys.FdPreopen), 32))
+		for _, name := range []string{"getlocaladdr", "getpeeraddr"} {
+			require.Equal(t, wasip1.ErrnoBadf, call(t, mod, name, 100, 32))
+			require.Equal(t, wasip1.ErrnoNotsock, call(t, mod, name, uint64(sys.FdStdin), 32))
+			require.Equal(t, wasip1.ErrnoFault, call(t, mod, name, uint64(connFd), uint64(wasm.MemoryPageSize-sock.AddrLen+1)))
+		}
+	})
+}
@@ -0,0 +1,69 @@
+// Package stdio controls how stdout and stderr are presented to guests, such
+// as CLI programs which color their output when it is a terminal.
+//
+// Note: This is an experimental API and may change in any release.
+package stdio
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/internal/sys"
+)
+
+// Config configures stdout and stderr, as set with wazero.ModuleConfig
+// WithStdout and WithStderr. It has no effect on writers which are nil.
+//
+// For example, to let a guest color its output, and write it line by line:
+//
+//	ctx = stdio.WithConfig(ctx, stdio.NewConfig().WithTTY(80, 24).WithLineBuffering())
+//	mod, _ := r.InstantiateModule(ctx, compiled, config.WithStdout(w))
+type Config interface {
+	// WithTTY presents stdout and stderr to the guest as terminals, so that
+	// "isatty" returns true, regardless of the host writer. In WASI, this
+	// means "fd_fdstat_get" reports a character device without seek rights.
+	//
+	// cols and rows are the size of the terminal, or zero when unknown.
+	// There is no "ioctl" in WASI, so guests can't read the size with
+	// TIOCGWINSZ. Instead, it is passed in the environment variables COLUMNS
+	// and LINES, unless they are set with wazero.ModuleConfig WithEnv.
+	WithTTY(cols, rows uint16) Config
+
+	// WithLineBuffering buffers writes until a newline, so that the host
+	// writer receives whole lines instead of each write of the guest. The
+	// buffer is also flushed when it reaches 4KiB, and when the module is
+	// closed.
+	WithLineBuffering() Config
+}
+
+// NewConfig returns a Config for module instantiation.
+func NewConfig() Config {
+	return &internalStdioConfig{c: &sys.StdioConfig{}}
+}
+
+// internalStdioConfig delegates to internal/sys.StdioConfig to avoid circular
+// dependencies.
+type internalStdioConfig struct {
+	c *sys.StdioConfig
+}
+
+// WithTTY implements Config.WithTTY
+func (c *internalStdioConfig) WithTTY(cols, rows uint16) Config {
+	ret := *c.c // copy
+	ret.TTY, ret.Cols, ret.Rows = true, cols, rows
+	return &internalStdioConfig{&ret}
+}
+
+// WithLineBuffering implements Config.WithLineBuffering
+func (c *internalStdioConfig) WithLineBuffering() Config {
+	ret := *c.c // copy
+	ret.LineBuffered = true
+	return &internalStdioConfig{&ret}
+}
+
+// WithConfig registers the given Config into the given context.Context.
+func WithConfig(ctx context.Context, config Config) context.Context {
+	if config, ok := config.(*internalStdioConfig); ok && (config.c.TTY || config.c.LineBuffered) {
+		return context.WithValue(ctx, sys.StdioConfigKey{}, config.c)
+	}
+	return ctx
+}
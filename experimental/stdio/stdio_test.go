@@ -0,0 +1,51 @@
+package stdio_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero/experimental/stdio"
+	"github.com/tetratelabs/wazero/internal/sys"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+func TestWithConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdioCfg stdio.Config
+		expected *sys.StdioConfig
+	}{
+		{
+			name: "returns input when stdioCfg nil",
+		},
+		{
+			name:     "returns input when stdioCfg empty",
+			stdioCfg: stdio.NewConfig(),
+		},
+		{
+			name:     "decorates with tty",
+			stdioCfg: stdio.NewConfig().WithTTY(80, 24),
+			expected: &sys.StdioConfig{TTY: true, Cols: 80, Rows: 24},
+		},
+		{
+			name:     "decorates with tty and line buffering",
+			stdioCfg: stdio.NewConfig().WithLineBuffering().WithTTY(0, 0),
+			expected: &sys.StdioConfig{TTY: true, LineBuffered: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			if decorated := stdio.WithConfig(testCtx, tc.stdioCfg); tc.expected != nil {
+				stdioCfg := decorated.Value(sys.StdioConfigKey{}).(*sys.StdioConfig)
+				require.Equal(t, tc.expected, stdioCfg)
+			} else {
+				require.Same(t, testCtx, decorated)
+			}
+		})
+	}
+}
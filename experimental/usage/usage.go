@@ -0,0 +1,83 @@
+// Package usage attributes the bytes of linear memory, tables and GC objects
+// to the module instance which defines them, and allows capping them per
+// instance.
+//
+// This is useful for hosts running many tenants in the same runtime: unlike
+// the maximum pages of a memory, the limit covers all memories and tables of
+// an instance, regardless of how the guest declares them.
+//
+// Note: Only the memories and tables defined by the module are accounted.
+// Imported ones are attributed to the module exporting them, and the compiled
+// code is shared by all instances of the same module, so it isn't attributed
+// to any of them.
+package usage
+
+import (
+	"context"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// ErrLimitExceeded is wrapped by the error instantiating a module whose
+// initial memory and tables are larger than the limit set by WithLimit.
+//
+// Once instantiated, growing beyond the limit fails as if the maximum size
+// were reached: "memory.grow" and "table.grow" return -1, and
+// api.Memory Grow returns false. Allocating a struct or array beyond the
+// limit traps.
+var ErrLimitExceeded = wasm.ErrUsageLimitExceeded
+
+// WithLimit returns a context which caps the total bytes of the memories,
+// tables and GC objects held by each module instantiated with it. Each
+// instance is accounted separately.
+//
+// A limit of zero doesn't cap the instances, but still accounts them, so Of
+// reports their usage.
+func WithLimit(ctx context.Context, limit uint64) context.Context {
+	return context.WithValue(ctx, wasm.UsageLimitKey{}, limit)
+}
+
+// Usage is the bytes allocated by a module instance.
+type Usage struct {
+	// Memory is the current size of the memory defined by the module.
+	Memory uint64
+	// Tables is the bytes held by the elements of the tables defined by the
+	// module.
+	Tables uint64
+	// Heap is the bytes of the objects allocated by the module with
+	// api.CoreFeatureGC instructions. This is only accounted when the module
+	// was instantiated with a context from WithLimit, and is zero otherwise.
+	Heap uint64
+	// Limit is the value set by WithLimit, or zero if unlimited.
+	Limit uint64
+}
+
+// Total returns the sum of Memory, Tables and Heap, which is what Limit
+// applies to.
+func (u Usage) Total() uint64 {
+	return u.Memory + u.Tables + u.Heap
+}
+
+// Of returns the current Usage of the module instance m.
+//
+// This works with any module instantiated by wazero, even if it wasn't
+// instantiated with a context from WithLimit.
+func Of(m api.Module) (ret Usage) {
+	mi, ok := m.(*wasm.ModuleInstance)
+	if !ok {
+		return
+	}
+	if mi.UsageLimit != nil {
+		ret.Limit = mi.UsageLimit.Limit
+		ret.Heap = mi.UsageLimit.Heap()
+	}
+	if mi.Source.MemorySection != nil && mi.MemoryInstance != nil {
+		ret.Memory = uint64(len(mi.MemoryInstance.Buffer))
+	}
+	for _, t := range mi.Tables[mi.Source.ImportTableCount:] {
+		ret.Tables += uint64(len(t.References)) * uint64(unsafe.Sizeof(wasm.Reference(0)))
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package usage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental/usage"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// growWasm defines a memory of one page and a table of two elements, and
+// exports "grow_memory" and "grow_table" which grow them by the given delta.
+var growWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Params:            []wasm.ValueType{wasm.ValueTypeI32},
+		Results:           []wasm.ValueType{wasm.ValueTypeI32},
+		ParamNumInUint64:  1,
+		ResultNumInUint64: 1,
+	}},
+	FunctionSection: []wasm.Index{0, 0},
+	CodeSection: []wasm.Code{
+		{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeMemoryGrow, 0, wasm.OpcodeEnd}},
+		{Body: []byte{
+			wasm.OpcodeRefNull, wasm.RefTypeFuncref, wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeMiscPrefix, wasm.OpcodeMiscTableGrow, 0,
+			wasm.OpcodeEnd,
+		}},
+	},
+	MemorySection: &wasm.Memory{Min: 1, Max: 10, IsMaxEncoded: true},
+	TableSection:  []wasm.Table{{Min: 2, Type: wasm.RefTypeFuncref}},
+	ExportSection: []wasm.Export{
+		{Name: "grow_memory", Type: wasm.ExternTypeFunc, Index: 0},
+		{Name: "grow_table", Type: wasm.ExternTypeFunc, Index: 1},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+	},
+})
+
+// initial is the usage of growWasm once instantiated: one page and two references.
+const initial = 65536 + 2*8
+
+func TestWithLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config wazero.RuntimeConfig
+	}{
+		{name: "interpreter", config: wazero.NewRuntimeConfigInterpreter()},
+		{name: "default", config: wazero.NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			mod, err := r.InstantiateWithConfig(usage.WithLimit(testCtx, initial+8), growWasm,
+				wazero.NewModuleConfig().WithName(""))
+			require.NoError(t, err)
+			require.Equal(t, usage.Usage{Memory: 65536, Tables: 16, Limit: initial + 8}, usage.Of(mod))
+
+			// The memory can't grow, even if its maximum is larger.
+			results, err := mod.ExportedFunction("grow_memory").Call(testCtx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0xffffffff), results[0])
+			_, ok := mod.ExportedMemory("memory").Grow(1)
+			require.False(t, ok)
+
+			// There's room for a single table element.
+			results, err = mod.ExportedFunction("grow_table").Call(testCtx, 2)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0xffffffff), results[0])
+			results, err = mod.ExportedFunction("grow_table").Call(testCtx, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), results[0])
+
+			u := usage.Of(mod)
+			require.Equal(t, uint64(initial+8), u.Total())
+		})
+	}
+}
+
+func TestWithLimit_Instantiate(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	_, err := r.Instantiate(usage.WithLimit(testCtx, initial-1), growWasm)
+	require.True(t, errors.Is(err, usage.ErrLimitExceeded))
+	require.EqualError(t, err, "memory usage limit exceeded: module[] requires 65552 bytes, but the limit is 65551 bytes")
+}
+
+func TestOf(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// Without a limit, the usage is still reported.
+	mod, err := r.Instantiate(testCtx, growWasm)
+	require.NoError(t, err)
+	results, err := mod.ExportedFunction("grow_memory").Call(testCtx, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), results[0])
+	require.Equal(t, usage.Usage{Memory: 3 * 65536, Tables: 16}, usage.Of(mod))
+}
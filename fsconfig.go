@@ -1,6 +1,7 @@
 package wazero
 
 import (
+	"io"
 	"io/fs"
 
 	"github.com/tetratelabs/wazero/internal/fsapi"
@@ -97,6 +98,20 @@ type FSConfig interface {
 	// like "../../" is still allowed.
 	WithReadOnlyDirMount(dir, guestPath string) FSConfig
 
+	// WithCaseInsensitiveDirMount assigns a directory at `dir` to any paths
+	// beginning at `guestPath`, looking up names regardless of their case.
+	//
+	// This is the same as WithDirMount except a path which doesn't exist
+	// resolves to an entry only differing in case, as it would on Windows or
+	// macOS. This helps running guests which weren't tested on case-sensitive
+	// hosts, such as Linux. When several entries differ only in case, the
+	// first in lexicographical order is used and a warning is written to
+	// `warn`, unless it is nil.
+	//
+	// Note: This lists the parent directory of each mismatched path component,
+	// so it is slower than WithDirMount when paths aren't correctly cased.
+	WithCaseInsensitiveDirMount(dir, guestPath string, warn io.Writer) FSConfig
+
 	// WithFSMount assigns a fs.FS file system for any paths beginning at
 	// `guestPath`.
 	//
@@ -161,6 +176,11 @@ func (c *fsConfig) WithReadOnlyDirMount(dir, guestPath string) FSConfig {
 	return c.withMount(sysfs.NewReadFS(sysfs.NewDirFS(dir)), guestPath)
 }
 
+// WithCaseInsensitiveDirMount implements FSConfig.WithCaseInsensitiveDirMount
+func (c *fsConfig) WithCaseInsensitiveDirMount(dir, guestPath string, warn io.Writer) FSConfig {
+	return c.withMount(sysfs.NewCaseInsensitiveFS(sysfs.NewDirFS(dir), warn), guestPath)
+}
+
 // WithFSMount implements FSConfig.WithFSMount
 func (c *fsConfig) WithFSMount(fs fs.FS, guestPath string) FSConfig {
 	return c.withMount(sysfs.Adapt(fs), guestPath)
@@ -48,6 +48,12 @@ func TestFSConfig(t *testing.T) {
 			expectedFS:         []fsapi.FS{sysfs.NewDirFS(".")},
 			expectedGuestPaths: []string{"/"},
 		},
+		{
+			name:               "WithCaseInsensitiveDirMount",
+			input:              base.WithCaseInsensitiveDirMount(".", "/", nil),
+			expectedFS:         []fsapi.FS{sysfs.NewCaseInsensitiveFS(sysfs.NewDirFS("."), nil)},
+			expectedGuestPaths: []string{"/"},
+		},
 		{
 			name:               "multiple",
 			input:              base.WithReadOnlyDirMount(".", "/").WithDirMount("/tmp", "/tmp"),
@@ -74,8 +74,8 @@ var fdAllocate = newHostFunc(
 
 func fdAllocateFn(_ context.Context, mod api.Module, params []uint64) syscall.Errno {
 	fd := int32(params[0])
-	offset := params[1]
-	length := params[2]
+	offset := int64(params[1])
+	length := int64(params[2])
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 	f, ok := fsc.LookupFile(fd)
@@ -83,21 +83,13 @@ func fdAllocateFn(_ context.Context, mod api.Module, params []uint64) syscall.Er
 		return syscall.EBADF
 	}
 
-	tail := int64(offset + length)
-	if tail < 0 {
+	if tail := offset + length; offset < 0 || tail < 0 {
 		return syscall.EINVAL
+	} else if length == 0 {
+		return 0 // There's nothing to allocate.
 	}
 
-	st, errno := f.File.Stat()
-	if errno != 0 {
-		return errno
-	}
-
-	if st.Size >= tail {
-		return 0 // We already have enough space.
-	}
-
-	return f.File.Truncate(tail)
+	return f.File.Allocate(offset, length)
 }
 
 // fdClose is the WASI function named FdCloseName which closes a file
@@ -308,21 +300,28 @@ func fdFdstatSetFlagsFn(_ context.Context, mod api.Module, params []uint64) sysc
 		return syscall.EINVAL
 	}
 
-	if f, ok := fsc.LookupFile(fd); !ok {
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
 		return syscall.EBADF
-	} else {
-		nonblock := wasip1.FD_NONBLOCK&wasiFlag != 0
-		errno := f.File.SetNonblock(nonblock)
-		if errno != 0 {
+	} else if isDir, errno := f.File.IsDir(); errno != 0 {
+		return errno
+	} else if isDir {
+		return syscall.EISDIR
+	}
+
+	// Only change the flags which differ, as files which can't change a flag,
+	// such as ones from a read-only fs.FS, must still allow clearing it.
+	if nonblock := wasip1.FD_NONBLOCK&wasiFlag != 0; nonblock != f.File.IsNonblock() {
+		if errno := f.File.SetNonblock(nonblock); errno != 0 {
 			return errno
 		}
-		if stat, err := f.File.Stat(); err == 0 && stat.Mode.IsRegular() {
-			// For normal files, proceed to apply an append flag.
-			append := wasip1.FD_APPEND&wasiFlag != 0
+	}
+	if stat, err := f.File.Stat(); err == 0 && stat.Mode.IsRegular() {
+		// For normal files, proceed to apply an append flag.
+		if append := wasip1.FD_APPEND&wasiFlag != 0; append != f.File.IsAppend() {
 			return f.File.SetAppend(append)
 		}
 	}
-
 	return 0
 }
 
@@ -465,7 +464,7 @@ var fdFilestatSetSize = newHostFunc(wasip1.FdFilestatSetSizeName, fdFilestatSetS
 
 func fdFilestatSetSizeFn(_ context.Context, mod api.Module, params []uint64) syscall.Errno {
 	fd := int32(params[0])
-	size := uint32(params[1])
+	size := int64(params[1])
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 
@@ -473,7 +472,7 @@ func fdFilestatSetSizeFn(_ context.Context, mod api.Module, params []uint64) sys
 	if f, ok := fsc.LookupFile(fd); !ok {
 		return syscall.EBADF
 	} else {
-		return f.File.Truncate(int64(size))
+		return f.File.Truncate(size)
 	}
 }
 
@@ -869,6 +868,10 @@ func fdReaddirFn(_ context.Context, mod api.Module, params []uint64) syscall.Err
 		return errno
 	}
 
+	// Read as many entries at a time as could fit in the buffer, which avoids
+	// a lot of small reads when listing a large directory.
+	dir.Hint(uint64(bufLen / wasip1.DirentSize))
+
 	// Determine how many dirents we can write, excluding a potentially
 	// truncated entry.
 	dirents, bufused, direntCount, writeTruncatedEntry := maxDirents(dir, bufLen)
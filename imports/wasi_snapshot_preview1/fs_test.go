@@ -594,6 +594,30 @@ func Test_fdFdstatSetFlags(t *testing.T) {
 	})
 }
 
+func Test_fdFdstatSetFlags_readOnlyFS(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	defer r.Close(testCtx)
+
+	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
+	fd, errno := fsc.OpenFile(fsc.RootFS(), "animals.txt", os.O_RDONLY, 0)
+	require.EqualErrno(t, 0, errno)
+
+	// Clearing flags which aren't set succeeds, e.g. fcntl(F_SETFL, 0).
+	requireErrnoResult(t, wasip1.ErrnoSuccess, mod, wasip1.FdFdstatSetFlagsName, uint64(fd), uint64(0))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+	log.Reset()
+
+	// Files of an fs.FS can't be appended to.
+	requireErrnoResult(t, wasip1.ErrnoNosys, mod, wasip1.FdFdstatSetFlagsName, uint64(fd), uint64(wasip1.FD_APPEND))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=APPEND)
+<== errno=ENOSYS
+`, "\n"+log.String())
+}
+
 // Test_fdFdstatSetRights only tests it is stubbed for GrainLang per #271
 func Test_fdFdstatSetRights(t *testing.T) {
 	log := requireErrnoNosys(t, wasip1.FdFdstatSetRightsName, 0, 0, 0)
@@ -2,10 +2,13 @@ package wasi_snapshot_preview1
 
 import (
 	"context"
+	"io/fs"
+	"runtime"
 	"syscall"
 	"time"
 
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/fsapi"
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/wasip1"
 	"github.com/tetratelabs/wazero/internal/wasm"
@@ -49,6 +52,18 @@ type event struct {
 	outOffset uint32
 }
 
+// blockingSub is a subscription to read from a file which may block, such as
+// stdin or a pipe. Its event is written once the file is ready.
+type blockingSub struct {
+	evt   *event
+	file  fsapi.File
+	ready bool
+}
+
+// pollInterval is how long to wait on one file before checking the others,
+// when subscriptions block on more than one file.
+const pollInterval = 10 * time.Millisecond
+
 func pollOneoffFn(_ context.Context, mod api.Module, params []uint64) syscall.Errno {
 	in := uint32(params[0])
 	out := uint32(params[1])
@@ -86,8 +101,9 @@ func pollOneoffFn(_ context.Context, mod api.Module, params []uint64) syscall.Er
 
 	// Extract FS context, used in the body of the for loop for FS access.
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	// Slice of events that are processed out of the loop (blocking stdin subscribers).
-	var blockingStdinSubs []*event
+	// Slice of events that are processed out of the loop (blocking stdin or
+	// pipe subscribers).
+	var blockingSubs []*blockingSub
 	// The timeout is initialized at max Duration, the loop will find the minimum.
 	var timeout time.Duration = 1<<63 - 1
 	// Count of all the clock subscribers that have been already written back to outBuf.
@@ -136,10 +152,10 @@ func pollOneoffFn(_ context.Context, mod api.Module, params []uint64) syscall.Er
 				writeEvent(outBuf, evt)
 				readySubs++
 				continue
-			} else if fd == internalsys.FdStdin && !file.File.IsNonblock() {
-				// if the fd is Stdin, and it is in non-blocking mode,
+			} else if (fd == internalsys.FdStdin || isPipe(file)) && !file.File.IsNonblock() {
+				// if the fd is Stdin or a pipe, and it is in blocking mode,
 				// do not ack yet, append to a slice for delayed evaluation.
-				blockingStdinSubs = append(blockingStdinSubs, evt)
+				blockingSubs = append(blockingSubs, &blockingSub{evt: evt, file: file.File})
 			} else {
 				writeEvent(outBuf, evt)
 				readySubs++
@@ -167,24 +183,18 @@ func pollOneoffFn(_ context.Context, mod api.Module, params []uint64) syscall.Er
 		timeout = 0
 	}
 
-	// If there are blocking stdin subscribers, check for data with given timeout.
-	if len(blockingStdinSubs) > 0 {
-		stdin, ok := fsc.LookupFile(internalsys.FdStdin)
-		if !ok {
-			return syscall.EBADF
-		}
-		// Wait for the timeout to expire, or for some data to become available on Stdin.
-		stdinReady, errno := stdin.File.PollRead(&timeout)
-		if errno != 0 {
+	// If there are blocking subscribers, check for data with given timeout.
+	if len(blockingSubs) > 0 {
+		// Wait for the timeout to expire, or for some data to become available.
+		if errno := pollRead(blockingSubs, timeout); errno != 0 {
 			return errno
 		}
-		if stdinReady {
-			// stdin has data ready to for reading, write back all the events
-			for i := range blockingStdinSubs {
+		// write back the events of files with data ready for reading.
+		for _, sub := range blockingSubs {
+			if sub.ready {
 				readySubs++
-				evt := blockingStdinSubs[i]
-				evt.errno = 0
-				writeEvent(outBuf, evt)
+				sub.evt.errno = 0
+				writeEvent(outBuf, sub.evt)
 			}
 		}
 	} else {
@@ -202,6 +212,81 @@ func pollOneoffFn(_ context.Context, mod api.Module, params []uint64) syscall.Er
 	return 0
 }
 
+// isPipe returns true if the file is a pipe which isn't a pre-open, such as
+// one configured with experimental/pipe.
+//
+// Note: This is always false on Windows, as it can only poll stdin. Pipes are
+// considered always ready there, like regular files.
+func isPipe(f *internalsys.FileEntry) bool {
+	if f.IsPreopen || runtime.GOOS == "windows" {
+		return false // stdio, directories and sockets.
+	}
+	st, errno := f.File.Stat()
+	return errno == 0 && st.Mode&fs.ModeNamedPipe != 0
+}
+
+// pollRead waits up to timeout for any file in subs to have data ready for
+// reading, and marks the subscriptions which do.
+func pollRead(subs []*blockingSub, timeout time.Duration) syscall.Errno {
+	// Multiple subscriptions may be on the same file, e.g. stdin.
+	var files []fsapi.File
+	for _, sub := range subs {
+		if !containsFile(files, sub.file) {
+			files = append(files, sub.file)
+		}
+	}
+
+	var ready []fsapi.File
+	if len(files) == 1 {
+		if ok, errno := files[0].PollRead(&timeout); errno != 0 {
+			return errno
+		} else if ok {
+			ready = files
+		}
+	} else {
+		// Files can't be waited on together, so check all of them without
+		// waiting, then wait on the first one for a short interval.
+		for remaining := timeout; ; {
+			var zero time.Duration
+			for _, f := range files[1:] {
+				if ok, errno := f.PollRead(&zero); errno != 0 {
+					return errno
+				} else if ok {
+					ready = append(ready, f)
+				}
+			}
+			wait := pollInterval
+			if len(ready) > 0 {
+				wait = 0
+			} else if remaining < wait {
+				wait = remaining
+			}
+			if ok, errno := files[0].PollRead(&wait); errno != 0 {
+				return errno
+			} else if ok {
+				ready = append(ready, files[0])
+			}
+			if remaining -= wait; len(ready) > 0 || remaining <= 0 {
+				break
+			}
+		}
+	}
+
+	for _, sub := range subs {
+		sub.ready = containsFile(ready, sub.file)
+	}
+	return 0
+}
+
+func containsFile(files []fsapi.File, f fsapi.File) bool {
+	for _, file := range files {
+		if file == f {
+			return true
+		}
+	}
+	return false
+}
+
 // processClockEvent supports only relative name events, as that's what's used
 // to implement sleep in various compilers including Rust, Zig and TinyGo.
 func processClockEvent(inBuf []byte) (time.Duration, syscall.Errno) {
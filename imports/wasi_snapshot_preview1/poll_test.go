@@ -2,6 +2,8 @@ package wasi_snapshot_preview1_test
 
 import (
 	"io/fs"
+	"os"
+	"runtime"
 	"strings"
 	"syscall"
 	"testing"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental/pipe"
 	"github.com/tetratelabs/wazero/internal/fsapi"
 	"github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/testing/require"
@@ -567,3 +570,68 @@ type pollStdinFile struct {
 func (p *pollStdinFile) PollRead(*time.Duration) (ready bool, errno syscall.Errno) {
 	return p.ready, 0
 }
+
+func Test_pollOneoff_Pipe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows can only poll stdin")
+	}
+
+	pr1, pw1, err := os.Pipe()
+	require.NoError(t, err)
+	defer pw1.Close()
+	pr2, pw2, err := os.Pipe()
+	require.NoError(t, err)
+	defer pw2.Close()
+
+	// The read ends are pre-opened as fd 3 and 4, after stdio.
+	ctx := pipe.WithConfig(testCtx, pipe.NewConfig().WithReader(pr1).WithReader(pr2))
+	mod, r, log := requireProxyModuleWithContext(ctx, t, wazero.NewModuleConfig())
+	defer r.Close(testCtx)
+
+	// Subscriptions are 48 bytes, so pad the read ones.
+	fdReadSub48 := func(fd byte) []byte {
+		return append(fdReadSubFd(fd), make([]byte, 48-20)...)
+	}
+
+	out := uint32(256)
+	resultNevents := uint32(512)
+	mod.Memory().Write(0, concat(
+		clockNsSub(20*1000*1000),
+		fdReadSub48(3),
+		fdReadSub48(4),
+	))
+	requirePoll := func(expectedNevents uint32) {
+		requireErrnoResult(t, wasip1.ErrnoSuccess, mod, wasip1.PollOneoffName, 0, uint64(out), 3, uint64(resultNevents))
+		nevents, ok := mod.Memory().ReadUint32Le(resultNevents)
+		require.True(t, ok)
+		require.Equal(t, expectedNevents, nevents)
+	}
+	requireReadEvent := func(i uint32, expected bool) {
+		eventType, ok := mod.Memory().ReadByte(out + i*32 + 10)
+		require.True(t, ok)
+		if expected {
+			require.Equal(t, byte(wasip1.EventTypeFdRead), eventType)
+		} else {
+			require.Equal(t, byte(0), eventType)
+		}
+	}
+
+	// Neither pipe has data, so only the clock event is written.
+	requirePoll(1)
+	requireReadEvent(1, false)
+	requireReadEvent(2, false)
+
+	// Once the second pipe has data, its event is written.
+	_, err = pw2.Write([]byte("wazero"))
+	require.NoError(t, err)
+	requirePoll(2)
+	requireReadEvent(1, false)
+	requireReadEvent(2, true)
+
+	require.Equal(t, `
+==> wasi_snapshot_preview1.poll_oneoff(in=0,out=256,nsubscriptions=3)
+<== (nevents=1,errno=ESUCCESS)
+==> wasi_snapshot_preview1.poll_oneoff(in=0,out=256,nsubscriptions=3)
+<== (nevents=2,errno=ESUCCESS)
+`, "\n"+log.String())
+}
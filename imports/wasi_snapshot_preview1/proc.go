@@ -2,6 +2,7 @@ package wasi_snapshot_preview1
 
 import (
 	"context"
+	"syscall"
 
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/wasip1"
@@ -38,7 +39,25 @@ func procExitFn(ctx context.Context, mod api.Module, params []uint64) {
 	panic(sys.NewExitError(exitCode))
 }
 
-// procRaise is stubbed and will never be supported, as it was removed.
+// procRaise is the WASI function named ProcRaiseName, which sends a signal to
+// the process. This was removed from WASI, so it returns ENOSYS unless the
+// host registered a handler via experimental/signal.
+//
+// # Parameters
+//
+//   - sig: the signal to raise, e.g. 6 for SIGABRT.
 //
 // See https://github.com/WebAssembly/WASI/pull/136
-var procRaise = stubFunction(wasip1.ProcRaiseName, []api.ValueType{i32}, "sig")
+var procRaise = newHostFunc(wasip1.ProcRaiseName, procRaiseFn, []api.ValueType{i32}, "sig")
+
+func procRaiseFn(ctx context.Context, mod api.Module, params []uint64) syscall.Errno {
+	handler, ok := ctx.Value(wasip1.RaiseHandlerKey{}).(wasip1.RaiseHandler)
+	if !ok {
+		return syscall.ENOSYS
+	}
+	if err := handler(ctx, mod, uint8(params[0])); err != nil {
+		// Like procExit, prevent any code from executing after this function.
+		panic(err)
+	}
+	return 0
+}
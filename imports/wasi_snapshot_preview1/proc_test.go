@@ -51,7 +51,8 @@ func Test_procExit(t *testing.T) {
 	}
 }
 
-// Test_procRaise only tests it is stubbed for GrainLang per #271
+// Test_procRaise only tests it returns ENOSYS without a handler, as the
+// handlers are tested in experimental/signal.
 func Test_procRaise(t *testing.T) {
 	log := requireErrnoNosys(t, wasip1.ProcRaiseName, 0)
 	require.Equal(t, `
@@ -1,6 +1,7 @@
 package wasi_snapshot_preview1
 
 import (
+	"net"
 	"os"
 	"syscall"
 	"testing"
@@ -29,6 +30,14 @@ func (t testSock) Accept() (sock.TCPConn, syscall.Errno) {
 	panic("no-op")
 }
 
+func (t testSock) GetSockOpt(sock.SockOpt) (int, syscall.Errno) {
+	panic("no-op")
+}
+
+func (t testSock) SetSockOpt(sock.SockOpt, int) syscall.Errno {
+	panic("no-op")
+}
+
 type testConn struct {
 	fsapi.UnimplementedFile
 }
@@ -40,3 +49,19 @@ func (t testConn) Recvfrom([]byte, int) (n int, errno syscall.Errno) {
 func (t testConn) Shutdown(int) syscall.Errno {
 	panic("no-op")
 }
+
+func (t testConn) GetSockOpt(sock.SockOpt) (int, syscall.Errno) {
+	panic("no-op")
+}
+
+func (t testConn) SetSockOpt(sock.SockOpt, int) syscall.Errno {
+	panic("no-op")
+}
+
+func (t testConn) LocalAddr() (*net.TCPAddr, syscall.Errno) {
+	panic("no-op")
+}
+
+func (t testConn) RemoteAddr() (*net.TCPAddr, syscall.Errno) {
+	panic("no-op")
+}
@@ -2466,6 +2466,17 @@ func (a *AssemblerImpl) load64bitConst(buf asm.Buffer, c int64, dstRegBits byte)
 		}
 	}
 
+	if zeros < 2 && negs < 2 {
+		// MOVZ or MOVN needs at least two MOVKs, so two instructions are
+		// shorter when the const is a bitmask immediate except one halfword.
+		// e.g. 0x1234_ffff_0000_ffff is ORR $0xffff_0000_ffff then MOVK $0x1234.
+		if i, mask, ok := bitmaskImmediateExceptHalfword(uint64(c)); ok {
+			a.loadConstViaBitMaskImmediate(buf, mask, dstRegBits, true)
+			a.movk(buf, bits[i], i, dstRegBits)
+			return
+		}
+	}
+
 	if zeros == 3 {
 		// one MOVZ instruction.
 		for i, v := range bits {
@@ -2551,6 +2562,30 @@ func (a *AssemblerImpl) load64bitConst(buf asm.Buffer, c int64, dstRegBits byte)
 	}
 }
 
+// bitmaskImmediateExceptHalfword returns a bitmask immediate which only
+// differs from c in its i-th 16-bit halfword, if any. Candidates replace that
+// halfword with zeros, ones, or a copy of another halfword of c, as bitmask
+// immediates are made of repeated elements.
+func bitmaskImmediateExceptHalfword(c uint64) (i int, mask uint64, ok bool) {
+	for i = 0; i < 4; i++ {
+		shift := uint(i * 16)
+		rest := c &^ (0xffff << shift)
+		candidates := [5]uint64{0, 0xffff}
+		for j, k := 0, 2; j < 4; j++ {
+			if j != i {
+				candidates[k] = (c >> uint(j*16)) & 0xffff
+				k++
+			}
+		}
+		for _, fill := range candidates {
+			if mask = rest | fill<<shift; isBitMaskImmediate(mask) {
+				return i, mask, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
 func (a *AssemblerImpl) load16bitAlignedConst(buf asm.Buffer, c int64, shiftNum byte, regBits byte, reverse bool, dst64bit bool) {
 	var lastByte byte
 	if reverse {
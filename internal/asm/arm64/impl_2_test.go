@@ -413,9 +413,9 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "ADD/dst=R30/0xfffffefffffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -1099511627791}, exp: []byte{0xdb, 0x1, 0x80, 0x92, 0xfb, 0xdf, 0xdf, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0xfffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 4294967281}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0xffffffff0000000f", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -4294967281}, exp: []byte{0xfb, 0x8f, 0x60, 0xb2, 0xde, 0x3, 0x1b, 0x8b}},
-		{name: "ADD/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0x7b, 0x0, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
+		{name: "ADD/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0xfb, 0x47, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0xfffffffc0000000f", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -17179869169}, exp: []byte{0xfb, 0x87, 0x5e, 0xb2, 0xde, 0x3, 0x1b, 0x8b}},
-		{name: "ADD/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xfb, 0x1f, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
+		{name: "ADD/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0xfb, 0x5f, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0xffffff000000000f", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -1099511627761}, exp: []byte{0xfb, 0x6f, 0x58, 0xb2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x7fffffffffffffff", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 9223372036854775807}, exp: []byte{0x1b, 0x0, 0xf0, 0x92, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x8000000000000001", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -9223372036854775807}, exp: []byte{0xfb, 0x7, 0x41, 0xb2, 0xde, 0x3, 0x1b, 0x8b}},
@@ -424,7 +424,7 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "ADD/dst=R30/0x40000001", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 1073741825}, exp: []byte{0x3b, 0x0, 0x80, 0xd2, 0x1b, 0x0, 0xa8, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0xffffffffbfffffff", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -1073741825}, exp: []byte{0x1b, 0x0, 0xa8, 0x92, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x7000000010000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 8070450532516364288}, exp: []byte{0x1b, 0x0, 0xa2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
-		{name: "ADD/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0x1b, 0x0, 0xbe, 0xd2, 0xfb, 0xff, 0xdf, 0xf2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
+		{name: "ADD/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0xfb, 0x4f, 0x64, 0xb2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x7000000100000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 8070450536542896128}, exp: []byte{0x3b, 0x0, 0xc0, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x8fffffff00000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: -8070450536542896128}, exp: []byte{0xfb, 0xff, 0xdf, 0xd2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
 		{name: "ADD/dst=R30/0x7000100000000000", n: &nodeImpl{instruction: ADD, dstReg: RegR30, srcConst: 8070468124433973248}, exp: []byte{0x1b, 0x0, 0xc2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0x8b}},
@@ -612,9 +612,9 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "ADDS/dst=R30/0xfffffefffffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -1099511627791}, exp: []byte{0xdb, 0x1, 0x80, 0x92, 0xfb, 0xdf, 0xdf, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0xfffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 4294967281}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0xffffffff0000000f", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -4294967281}, exp: []byte{0xfb, 0x8f, 0x60, 0xb2, 0xde, 0x3, 0x1b, 0xab}},
-		{name: "ADDS/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0x7b, 0x0, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
+		{name: "ADDS/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0xfb, 0x47, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0xfffffffc0000000f", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -17179869169}, exp: []byte{0xfb, 0x87, 0x5e, 0xb2, 0xde, 0x3, 0x1b, 0xab}},
-		{name: "ADDS/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xfb, 0x1f, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
+		{name: "ADDS/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0xfb, 0x5f, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0xffffff000000000f", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -1099511627761}, exp: []byte{0xfb, 0x6f, 0x58, 0xb2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x7fffffffffffffff", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 9223372036854775807}, exp: []byte{0x1b, 0x0, 0xf0, 0x92, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x8000000000000001", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -9223372036854775807}, exp: []byte{0xfb, 0x7, 0x41, 0xb2, 0xde, 0x3, 0x1b, 0xab}},
@@ -623,7 +623,7 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "ADDS/dst=R30/0x40000001", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 1073741825}, exp: []byte{0x3b, 0x0, 0x80, 0xd2, 0x1b, 0x0, 0xa8, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0xffffffffbfffffff", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -1073741825}, exp: []byte{0x1b, 0x0, 0xa8, 0x92, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x7000000010000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 8070450532516364288}, exp: []byte{0x1b, 0x0, 0xa2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
-		{name: "ADDS/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0x1b, 0x0, 0xbe, 0xd2, 0xfb, 0xff, 0xdf, 0xf2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
+		{name: "ADDS/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0xfb, 0x4f, 0x64, 0xb2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x7000000100000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 8070450536542896128}, exp: []byte{0x3b, 0x0, 0xc0, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x8fffffff00000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: -8070450536542896128}, exp: []byte{0xfb, 0xff, 0xdf, 0xd2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
 		{name: "ADDS/dst=R30/0x7000100000000000", n: &nodeImpl{instruction: ADDS, dstReg: RegR30, srcConst: 8070468124433973248}, exp: []byte{0x1b, 0x0, 0xc2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xab}},
@@ -811,9 +811,9 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "SUB/dst=R30/0xfffffefffffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -1099511627791}, exp: []byte{0xdb, 0x1, 0x80, 0x92, 0xfb, 0xdf, 0xdf, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0xfffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 4294967281}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0xffffffff0000000f", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -4294967281}, exp: []byte{0xfb, 0x8f, 0x60, 0xb2, 0xde, 0x3, 0x1b, 0xcb}},
-		{name: "SUB/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0x7b, 0x0, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
+		{name: "SUB/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0xfb, 0x47, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0xfffffffc0000000f", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -17179869169}, exp: []byte{0xfb, 0x87, 0x5e, 0xb2, 0xde, 0x3, 0x1b, 0xcb}},
-		{name: "SUB/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xfb, 0x1f, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
+		{name: "SUB/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0xfb, 0x5f, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0xffffff000000000f", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -1099511627761}, exp: []byte{0xfb, 0x6f, 0x58, 0xb2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x7fffffffffffffff", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 9223372036854775807}, exp: []byte{0x1b, 0x0, 0xf0, 0x92, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x8000000000000001", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -9223372036854775807}, exp: []byte{0xfb, 0x7, 0x41, 0xb2, 0xde, 0x3, 0x1b, 0xcb}},
@@ -822,7 +822,7 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "SUB/dst=R30/0x40000001", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 1073741825}, exp: []byte{0x3b, 0x0, 0x80, 0xd2, 0x1b, 0x0, 0xa8, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0xffffffffbfffffff", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -1073741825}, exp: []byte{0x1b, 0x0, 0xa8, 0x92, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x7000000010000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 8070450532516364288}, exp: []byte{0x1b, 0x0, 0xa2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
-		{name: "SUB/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0x1b, 0x0, 0xbe, 0xd2, 0xfb, 0xff, 0xdf, 0xf2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
+		{name: "SUB/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0xfb, 0x4f, 0x64, 0xb2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x7000000100000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 8070450536542896128}, exp: []byte{0x3b, 0x0, 0xc0, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x8fffffff00000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: -8070450536542896128}, exp: []byte{0xfb, 0xff, 0xdf, 0xd2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
 		{name: "SUB/dst=R30/0x7000100000000000", n: &nodeImpl{instruction: SUB, dstReg: RegR30, srcConst: 8070468124433973248}, exp: []byte{0x1b, 0x0, 0xc2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xcb}},
@@ -1010,9 +1010,9 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "SUBS/dst=R30/0xfffffefffffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -1099511627791}, exp: []byte{0xdb, 0x1, 0x80, 0x92, 0xfb, 0xdf, 0xdf, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0xfffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 4294967281}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0xffffffff0000000f", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -4294967281}, exp: []byte{0xfb, 0x8f, 0x60, 0xb2, 0xde, 0x3, 0x1b, 0xeb}},
-		{name: "SUBS/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0x7b, 0x0, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
+		{name: "SUBS/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0xfb, 0x47, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0xfffffffc0000000f", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -17179869169}, exp: []byte{0xfb, 0x87, 0x5e, 0xb2, 0xde, 0x3, 0x1b, 0xeb}},
-		{name: "SUBS/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0x3b, 0xfe, 0x9f, 0xd2, 0xfb, 0xff, 0xbf, 0xf2, 0xfb, 0x1f, 0xc0, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
+		{name: "SUBS/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0xfb, 0x5f, 0x70, 0xb2, 0x3b, 0xfe, 0x9f, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0xffffff000000000f", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -1099511627761}, exp: []byte{0xfb, 0x6f, 0x58, 0xb2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x7fffffffffffffff", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 9223372036854775807}, exp: []byte{0x1b, 0x0, 0xf0, 0x92, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x8000000000000001", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -9223372036854775807}, exp: []byte{0xfb, 0x7, 0x41, 0xb2, 0xde, 0x3, 0x1b, 0xeb}},
@@ -1021,7 +1021,7 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "SUBS/dst=R30/0x40000001", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 1073741825}, exp: []byte{0x3b, 0x0, 0x80, 0xd2, 0x1b, 0x0, 0xa8, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0xffffffffbfffffff", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -1073741825}, exp: []byte{0x1b, 0x0, 0xa8, 0x92, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x7000000010000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 8070450532516364288}, exp: []byte{0x1b, 0x0, 0xa2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
-		{name: "SUBS/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0x1b, 0x0, 0xbe, 0xd2, 0xfb, 0xff, 0xdf, 0xf2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
+		{name: "SUBS/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0xfb, 0x4f, 0x64, 0xb2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x7000000100000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 8070450536542896128}, exp: []byte{0x3b, 0x0, 0xc0, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x8fffffff00000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: -8070450536542896128}, exp: []byte{0xfb, 0xff, 0xdf, 0xd2, 0xfb, 0xff, 0xf1, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
 		{name: "SUBS/dst=R30/0x7000100000000000", n: &nodeImpl{instruction: SUBS, dstReg: RegR30, srcConst: 8070468124433973248}, exp: []byte{0x1b, 0x0, 0xc2, 0xd2, 0x1b, 0x0, 0xee, 0xf2, 0xde, 0x3, 0x1b, 0xeb}},
@@ -1336,9 +1336,9 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "MOVD/dst=R30/0xfffffefffffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -1099511627791}, exp: []byte{0xde, 0x1, 0x80, 0x92, 0xfe, 0xdf, 0xdf, 0xf2}},
 		{name: "MOVD/dst=R30/0xfffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 4294967281}, exp: []byte{0x3e, 0xfe, 0x9f, 0xd2, 0xfe, 0xff, 0xbf, 0xf2}},
 		{name: "MOVD/dst=R30/0xffffffff0000000f", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -4294967281}, exp: []byte{0xfe, 0x8f, 0x60, 0xb2}},
-		{name: "MOVD/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0x3e, 0xfe, 0x9f, 0xd2, 0xfe, 0xff, 0xbf, 0xf2, 0x7e, 0x0, 0xc0, 0xf2}},
+		{name: "MOVD/dst=R30/0x3fffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 17179869169}, exp: []byte{0xfe, 0x47, 0x70, 0xb2, 0x3e, 0xfe, 0x9f, 0xf2}},
 		{name: "MOVD/dst=R30/0xfffffffc0000000f", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -17179869169}, exp: []byte{0xfe, 0x87, 0x5e, 0xb2}},
-		{name: "MOVD/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0x3e, 0xfe, 0x9f, 0xd2, 0xfe, 0xff, 0xbf, 0xf2, 0xfe, 0x1f, 0xc0, 0xf2}},
+		{name: "MOVD/dst=R30/0xfffffffff1", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 1099511627761}, exp: []byte{0xfe, 0x5f, 0x70, 0xb2, 0x3e, 0xfe, 0x9f, 0xf2}},
 		{name: "MOVD/dst=R30/0xffffff000000000f", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -1099511627761}, exp: []byte{0xfe, 0x6f, 0x58, 0xb2}},
 		{name: "MOVD/dst=R30/0x7fffffffffffffff", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 9223372036854775807}, exp: []byte{0x1e, 0x0, 0xf0, 0x92}},
 		{name: "MOVD/dst=R30/0x8000000000000001", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -9223372036854775807}, exp: []byte{0xfe, 0x7, 0x41, 0xb2}},
@@ -1347,7 +1347,7 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		{name: "MOVD/dst=R30/0x40000001", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 1073741825}, exp: []byte{0x3e, 0x0, 0x80, 0xd2, 0x1e, 0x0, 0xa8, 0xf2}},
 		{name: "MOVD/dst=R30/0xffffffffbfffffff", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -1073741825}, exp: []byte{0x1e, 0x0, 0xa8, 0x92}},
 		{name: "MOVD/dst=R30/0x7000000010000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 8070450532516364288}, exp: []byte{0x1e, 0x0, 0xa2, 0xd2, 0x1e, 0x0, 0xee, 0xf2}},
-		{name: "MOVD/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0x1e, 0x0, 0xbe, 0xd2, 0xfe, 0xff, 0xdf, 0xf2, 0xfe, 0xff, 0xf1, 0xf2}},
+		{name: "MOVD/dst=R30/0x8ffffffff0000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -8070450532516364288}, exp: []byte{0xfe, 0x4f, 0x64, 0xb2, 0xfe, 0xff, 0xf1, 0xf2}},
 		{name: "MOVD/dst=R30/0x7000000100000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 8070450536542896128}, exp: []byte{0x3e, 0x0, 0xc0, 0xd2, 0x1e, 0x0, 0xee, 0xf2}},
 		{name: "MOVD/dst=R30/0x8fffffff00000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: -8070450536542896128}, exp: []byte{0xfe, 0xff, 0xdf, 0xd2, 0xfe, 0xff, 0xf1, 0xf2}},
 		{name: "MOVD/dst=R30/0x7000100000000000", n: &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: 8070468124433973248}, exp: []byte{0x1e, 0x0, 0xc2, 0xd2, 0x1e, 0x0, 0xee, 0xf2}},
@@ -1401,3 +1401,92 @@ func TestAssemblerImpl_EncodeConstToRegister(t *testing.T) {
 		})
 	}
 }
+
+func TestAssemblerImpl_load64bitConst(t *testing.T) {
+	// Build constants from halfwords which are commonly special-cased, so
+	// that all combinations of MOVZ, MOVN, MOVK and ORR are covered.
+	halfwords := []uint64{0, 0xffff, 0x1234, 0x00ff, 0xff00, 0x0f0f, 0x8000, 0x0001}
+	var consts []uint64
+	for _, h0 := range halfwords {
+		for _, h1 := range halfwords {
+			for _, h2 := range halfwords {
+				for _, h3 := range halfwords {
+					consts = append(consts, h0|h1<<16|h2<<32|h3<<48)
+				}
+			}
+		}
+	}
+
+	code := asm.CodeSegment{}
+	defer func() { require.NoError(t, code.Unmap()) }()
+
+	for _, c := range consts {
+		a := NewAssembler(RegR27)
+		buf := code.NextCodeSection()
+		err := a.encodeConstToRegister(buf, &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: int64(c)})
+		require.NoError(t, err)
+
+		actual := buf.Bytes()
+		require.True(t, len(actual) <= 16, "%#x: %s", c, hex.EncodeToString(actual))
+		require.Equal(t, c, evalMovConst(t, actual), "%#x: %s", c, hex.EncodeToString(actual))
+	}
+
+	// A bitmask immediate except one halfword is two instructions.
+	for _, c := range []uint64{0x1234_ffff_0000_ffff, 0xffff_ffff_1234_0000, 0x0f0f_0f0f_0f0f_1234} {
+		a := NewAssembler(RegR27)
+		buf := code.NextCodeSection()
+		err := a.encodeConstToRegister(buf, &nodeImpl{instruction: MOVD, dstReg: RegR30, srcConst: int64(c)})
+		require.NoError(t, err)
+
+		actual := buf.Bytes()
+		require.Equal(t, 8, len(actual), "%#x: %s", c, hex.EncodeToString(actual))
+		require.Equal(t, c, evalMovConst(t, actual), "%#x: %s", c, hex.EncodeToString(actual))
+	}
+}
+
+// evalMovConst returns the value of the register after executing code, which
+// is made of the 64-bit MOVZ, MOVN, MOVK and ORR (bitmask immediate) emitted
+// to load a constant.
+func evalMovConst(t *testing.T, code []byte) (reg uint64) {
+	for ; len(code) > 0; code = code[4:] {
+		inst := uint32(code[0]) | uint32(code[1])<<8 | uint32(code[2])<<16 | uint32(code[3])<<24
+		imm16, shift := uint64(inst>>5&0xffff), inst>>21&0b11*16
+		switch inst >> 23 {
+		case 0b1_10_100101: // MOVZ
+			reg = imm16 << shift
+		case 0b1_00_100101: // MOVN
+			reg = ^(imm16 << shift)
+		case 0b1_11_100101: // MOVK
+			reg = reg&^(0xffff<<shift) | imm16<<shift
+		case 0b1_01_100100: // ORR (immediate)
+			require.Equal(t, uint32(zeroRegisterBits), inst>>5&0b11111)
+			reg = decodeBitMask(inst>>22&1, inst>>10&0b111111, inst>>16&0b111111)
+		default:
+			t.Fatalf("unexpected instruction: %#x", inst)
+		}
+	}
+	return
+}
+
+// decodeBitMask returns the value of a bitmask immediate.
+//
+// See DecodeBitMasks in https://developer.arm.com/documentation/ddi0596/2021-12/Shared-Pseudocode/AArch64-Instrs
+func decodeBitMask(n, imms, immr uint32) uint64 {
+	length := 6
+	for combined := n<<6 | ^imms&0b111111; combined>>length == 0; {
+		length--
+	}
+	size := uint32(1) << length
+	levels := size - 1
+	s, r := imms&levels, immr&levels
+
+	elem := uint64(1)<<(s+1) - 1
+	if r > 0 {
+		elem = (elem>>r | elem<<(size-r)) & (uint64(1)<<size - 1)
+	}
+	var ret uint64
+	for i := uint32(0); i < 64; i += size {
+		ret |= elem << i
+	}
+	return ret
+}
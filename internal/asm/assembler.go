@@ -84,6 +84,9 @@ type StaticConstPool struct {
 	// Note: we can use map on .consts field and remove this field,
 	// but we have the separate field for deduplication in order to have deterministic assembling behavior.
 	addedConsts map[*StaticConst]struct{}
+	// addedRaws maps the content of consts in .Consts to the *StaticConst holding it, so that
+	// distinct *StaticConst with the identical content share one slot in the emitted pool.
+	addedRaws map[string]*StaticConst
 
 	Consts []*StaticConst
 	// FirstUseOffsetInBinary holds the offset of the first instruction which accesses this const pool .
@@ -93,14 +96,22 @@ type StaticConstPool struct {
 }
 
 func NewStaticConstPool() StaticConstPool {
-	return StaticConstPool{addedConsts: map[*StaticConst]struct{}{}, FirstUseOffsetInBinary: math.MaxUint64}
+	return StaticConstPool{
+		addedConsts:            map[*StaticConst]struct{}{},
+		addedRaws:              map[string]*StaticConst{},
+		FirstUseOffsetInBinary: math.MaxUint64,
+	}
 }
 
 // Reset resets the *StaticConstPool for reuse.
 func (p *StaticConstPool) Reset() {
-	for _, c := range p.Consts {
+	// Note: addedConsts also contains the consts coalesced into another one, which are not in .Consts.
+	for c := range p.addedConsts {
 		delete(p.addedConsts, c)
 	}
+	for _, c := range p.Consts {
+		delete(p.addedRaws, string(c.Raw))
+	}
 	// Reuse the slice to avoid re-allocations.
 	p.Consts = p.Consts[:0]
 	p.PoolSizeInBytes = 0
@@ -113,6 +124,9 @@ func (p *StaticConstPool) Empty() bool {
 }
 
 // AddConst adds a *StaticConst into the pool if it's not already added.
+//
+// If another *StaticConst with the same content is already in the pool, c is coalesced into it:
+// c doesn't occupy the space in the pool, and its offset is finalized to the same offset as the existing one.
 func (p *StaticConstPool) AddConst(c *StaticConst, useOffset NodeOffsetInBinary) {
 	if _, ok := p.addedConsts[c]; ok {
 		return
@@ -123,10 +137,16 @@ func (p *StaticConstPool) AddConst(c *StaticConst, useOffset NodeOffsetInBinary)
 	}
 
 	c.offsetFinalizedCallbacks = c.offsetFinalizedCallbacks[:0]
+	p.addedConsts[c] = struct{}{}
+
+	if existing, ok := p.addedRaws[string(c.Raw)]; ok {
+		existing.AddOffsetFinalizedCallback(c.SetOffsetInBinary)
+		return
+	}
 
 	p.Consts = append(p.Consts, c)
 	p.PoolSizeInBytes += len(c.Raw)
-	p.addedConsts[c] = struct{}{}
+	p.addedRaws[string(c.Raw)] = c
 }
 
 // AssemblerBase is the common interface for assemblers among multiple architectures.
@@ -9,6 +9,39 @@ import (
 func TestNewStaticConstPool(t *testing.T) {
 	p := NewStaticConstPool()
 	require.NotNil(t, p.addedConsts)
+	require.NotNil(t, p.addedRaws)
+}
+
+func TestStaticConstPool_AddConst_coalesce(t *testing.T) {
+	p := NewStaticConstPool()
+
+	c := NewStaticConst([]byte{1, 2, 3, 4})
+	p.AddConst(c, 100)
+	var cOffset uint64
+	c.AddOffsetFinalizedCallback(func(offsetOfConstInBinary uint64) { cOffset = offsetOfConstInBinary })
+
+	// Adding the distinct *StaticConst with the same content doesn't grow the pool.
+	c2 := NewStaticConst([]byte{1, 2, 3, 4})
+	p.AddConst(c2, 200)
+	var c2Offset uint64
+	c2.AddOffsetFinalizedCallback(func(offsetOfConstInBinary uint64) { c2Offset = offsetOfConstInBinary })
+	require.Equal(t, uint64(100), p.FirstUseOffsetInBinary)
+	require.Equal(t, 1, len(p.Consts))
+	require.Equal(t, 4, p.PoolSizeInBytes)
+
+	// Finalizing the offset of the existing one must finalize the coalesced one as well.
+	c.SetOffsetInBinary(1000)
+	require.Equal(t, uint64(1000), cOffset)
+	require.Equal(t, uint64(1000), c2Offset)
+	require.Equal(t, uint64(1000), c2.OffsetInBinary)
+
+	p.Reset()
+	require.Equal(t, 0, len(p.addedConsts))
+	require.Equal(t, 0, len(p.addedRaws))
+
+	// After reset, c2 can be added as a standalone const.
+	p.AddConst(c2, 300)
+	require.Equal(t, []*StaticConst{c2}, p.Consts)
 }
 
 func TestStaticConst_AddOffsetFinalizedCallback(t *testing.T) {
@@ -152,6 +152,24 @@ func (t *Table[Key, Item]) Range(f func(Key, Item) bool) {
 	}
 }
 
+// Entry is an item and the key it is mapped to in a Table.
+type Entry[Key ~int32, Item any] struct {
+	Key  Key
+	Item Item
+}
+
+// Snapshot returns the items in the table with their keys, in ascending order
+// of keys. The returned slice is a copy, so it isn't affected by later changes
+// to the table.
+func (t *Table[Key, Item]) Snapshot() []Entry[Key, Item] {
+	entries := make([]Entry[Key, Item], 0, t.Len())
+	t.Range(func(key Key, item Item) bool {
+		entries = append(entries, Entry[Key, Item]{Key: key, Item: item})
+		return true
+	})
+	return entries
+}
+
 // Reset clears the content of the table.
 func (t *Table[Key, Item]) Reset() {
 	for i := range t.masks {
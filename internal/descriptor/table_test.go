@@ -3,6 +3,7 @@ package descriptor_test
 import (
 	"testing"
 
+	"github.com/tetratelabs/wazero/internal/descriptor"
 	"github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 )
@@ -98,6 +99,30 @@ func TestFileTable(t *testing.T) {
 	}
 }
 
+func TestFileTable_Snapshot(t *testing.T) {
+	table := new(sys.FileTable)
+	require.Equal(t, 0, len(table.Snapshot()))
+
+	v0 := &sys.FileEntry{Name: "1"}
+	v1 := &sys.FileEntry{Name: "2"}
+	v2 := &sys.FileEntry{Name: "3"}
+	require.True(t, table.InsertAt(v2, 70))
+	k0, _ := table.Insert(v0)
+	k1, _ := table.Insert(v1)
+
+	snapshot := table.Snapshot()
+	require.Equal(t, []descriptor.Entry[int32, *sys.FileEntry]{
+		{Key: k0, Item: v0},
+		{Key: k1, Item: v1},
+		{Key: 70, Item: v2},
+	}, snapshot)
+
+	// Changes to the table don't affect a snapshot.
+	table.Delete(k0)
+	require.Equal(t, 3, len(snapshot))
+	require.Equal(t, 2, len(table.Snapshot()))
+}
+
 func BenchmarkFileTableInsert(b *testing.B) {
 	table := new(sys.FileTable)
 	entry := new(sys.FileEntry)
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/tetratelabs/wazero/api"
@@ -135,6 +136,14 @@ type (
 		stackIterator stackIterator
 
 		ensureTermination bool
+
+		// executionLimit is the maximum number of loop iterations in a call, or zero if unlimited.
+		// executionCount is the number of loop iterations executed in the current call.
+		// These are only used when ensureTermination is true.
+		executionLimit, executionCount uint64
+
+		// metrics receives events of the current call, or is nil.
+		metrics experimental.Metrics
 	}
 
 	// moduleContext holds the per-function call specific module information.
@@ -280,7 +289,7 @@ type (
 		stackPointerCeil uint64
 
 		index           wasm.Index
-		goFunc          interface{}
+		goFunc          *wasm.GoFuncHolder
 		listener        experimental.FunctionListener
 		parent          *compiledModule
 		sourceOffsetMap sourceOffsetMap
@@ -486,6 +495,25 @@ func (e *engine) CompiledModuleCount() uint32 {
 	return uint32(len(e.codes))
 }
 
+// CompiledCodeSizes implements the same method as documented on wasm.Engine.
+//
+// Note: Sizes include the padding which aligns the next function.
+func (e *engine) CompiledCodeSizes(module *wasm.Module) []uint64 {
+	cm, ok := e.getCompiledModuleFromMemory(module)
+	if !ok || len(cm.functions) == 0 {
+		return nil
+	}
+	// Functions are laid out in index order, so each ends where the next begins.
+	ret := make([]uint64, len(cm.functions))
+	end := cm.executable.Size()
+	for i := len(cm.functions) - 1; i >= 0; i-- {
+		offset := cm.functions[i].executableOffset
+		ret[i] = uint64(end - offset)
+		end = offset
+	}
+	return ret
+}
+
 // DeleteCompiledModule implements the same method as documented on wasm.Engine.
 func (e *engine) DeleteCompiledModule(module *wasm.Module) {
 	e.deleteCompiledModule(module)
@@ -508,12 +536,23 @@ func (e *engine) Close() (err error) {
 }
 
 // CompileModule implements the same method as documented on wasm.Engine.
-func (e *engine) CompileModule(_ context.Context, module *wasm.Module, listeners []experimental.FunctionListener, ensureTermination bool) error {
+func (e *engine) CompileModule(ctx context.Context, module *wasm.Module, listeners []experimental.FunctionListener, ensureTermination bool) (err error) {
+	metrics := wasm.MetricsFrom(ctx)
 	if _, ok, err := e.getCompiledModule(module, listeners); ok { // cache hit!
+		if metrics != nil {
+			metrics.ModuleCompiled(true)
+		}
 		return nil
 	} else if err != nil {
 		return err
 	}
+	if metrics != nil {
+		defer func() {
+			if err == nil {
+				metrics.ModuleCompiled(false)
+			}
+		}()
+	}
 
 	irCompiler, err := wazeroir.NewCompiler(e.enabledFeatures, callFrameDataSizeInUint64, module, ensureTermination)
 	if err != nil {
@@ -553,7 +592,17 @@ func (e *engine) CompileModule(_ context.Context, module *wasm.Module, listeners
 		}
 	}()
 
-	for i := range module.CodeSection {
+	if workers := runtime.GOMAXPROCS(0); workers > 1 && localFuncs >= concurrentCompilationThreshold && !hasGoFunc(module.CodeSection) {
+		if workers > localFuncs {
+			workers = localFuncs
+		}
+		if err = e.compileWasmFunctionsConcurrently(module, cm, listeners, ensureTermination, workers, &executable); err != nil {
+			return err
+		}
+		localFuncs = 0 // All the functions are compiled, so skip the sequential compilation below.
+	}
+
+	for i := range module.CodeSection[:localFuncs] {
 		typ := &module.TypeSection[module.FunctionSection[i]]
 		buf := executable.NextCodeSection()
 		funcIndex := wasm.Index(i)
@@ -572,7 +621,7 @@ func (e *engine) CompileModule(_ context.Context, module *wasm.Module, listeners
 				def := module.FunctionDefinition(compiledFn.index)
 				return fmt.Errorf("error compiling host go func[%s]: %w", def.DebugName(), err)
 			}
-			compiledFn.goFunc = codeSeg.GoFunc
+			compiledFn.goFunc = wasm.NewGoFuncHolder(codeSeg.GoFunc)
 		} else {
 			ir, err := irCompiler.Next()
 			if err != nil {
@@ -638,11 +687,19 @@ func (e *moduleEngine) ResolveImportedFunction(index, indexInImportedModule wasm
 	e.functions[index] = imported.functions[indexInImportedModule]
 }
 
+// ReplaceGoFunction implements wasm.ModuleEngine.
+func (e *moduleEngine) ReplaceGoFunction(index wasm.Index, goFunc interface{}) {
+	e.functions[index].parent.goFunc.Store(goFunc)
+}
+
 // FunctionInstanceReference implements the same method as documented on wasm.ModuleEngine.
 func (e *moduleEngine) FunctionInstanceReference(funcIndex wasm.Index) wasm.Reference {
 	return uintptr(unsafe.Pointer(&e.functions[funcIndex]))
 }
 
+// Close implements the same method as documented on wasm.ModuleEngine.
+func (e *moduleEngine) Close(context.Context) {}
+
 // NewFunction implements wasm.ModuleEngine.
 func (e *moduleEngine) NewFunction(index wasm.Index) api.Function {
 	return e.newFunction(&e.functions[index])
@@ -741,12 +798,18 @@ func (ce *callEngine) call(ctx context.Context, params, results []uint64) (_ []u
 			// If the module closed during the call, and the call didn't err for another reason, set an ExitError.
 			err = m.FailIfClosed()
 		}
+		wasm.ReportTrap(ce.metrics, err)
 	}()
 
+	if ce.metrics = wasm.MetricsFrom(ctx); ce.metrics != nil {
+		ce.metrics.FunctionCalled(ce.initialFn.definition())
+	}
+
 	ft := ce.initialFn.funcType
 	ce.initializeStack(ft, params)
 
 	if ce.ensureTermination {
+		ce.executionLimit, ce.executionCount = m.ExecutionLimit(), 0
 		done := m.CloseModuleOnCanceledOrTimeout(ctx)
 		defer done()
 	}
@@ -1025,13 +1088,11 @@ entry:
 			}
 			stack := ce.stack[base : base+stackLen]
 
-			fn := calleeHostFunction.parent.goFunc
-			switch fn := fn.(type) {
-			case api.GoModuleFunction:
-				fn.Call(ctx, ce.callerModuleInstance, stack)
-			case api.GoFunction:
-				fn.Call(ctx, stack)
+			if ce.metrics != nil {
+				ce.metrics.HostFunctionCalled(calleeHostFunction.definition())
 			}
+			wasm.CallGoFunc(ctx, ce.callerModuleInstance, calleeHostFunction.definition(),
+				calleeHostFunction.parent.goFunc.Load(), stack)
 
 			codeAddr, modAddr = ce.returnAddress, ce.moduleInstance
 			goto entry
@@ -1055,6 +1116,11 @@ entry:
 				if err := m.FailIfClosed(); err != nil {
 					panic(err)
 				}
+				if ce.executionLimit > 0 {
+					if ce.executionCount++; ce.executionCount > ce.executionLimit {
+						panic(wasmruntime.ErrRuntimeExecutionLimitExceeded)
+					}
+				}
 			}
 			if false {
 				if ce.exitContext.builtinFunctionCallIndex == builtinFunctionIndexBreakPoint {
@@ -1219,6 +1285,117 @@ func compileGoDefinedHostFunction(buf asm.Buffer, cmp compiler) error {
 	return err
 }
 
+// concurrentCompilationThreshold is the minimum number of local functions in a module for
+// CompileModule to compile them concurrently. Below this, the cost of starting the workers
+// outweighs the gain.
+const concurrentCompilationThreshold = 32
+
+// compileWasmFunctionsConcurrently compiles all the Wasm-defined functions of module with the given
+// number of workers, each of which owns its wazeroir.Compiler and compiler.
+//
+// The machine code of each function is written into a worker-local code segment first, and then
+// copied into executable in the function index order. Since the compiled code is position
+// independent, the resulting executable is identical to the one produced by the sequential compilation.
+func (e *engine) compileWasmFunctionsConcurrently(module *wasm.Module, cm *compiledModule, listeners []experimental.FunctionListener,
+	ensureTermination bool, workers int, executable *asm.CodeSegment,
+) error {
+	type result struct {
+		code             []byte
+		stackPointerCeil uint64
+		sourceOffsetMap  sourceOffsetMap
+		err              error
+	}
+
+	localFuncs, importedFuncs := len(module.CodeSection), module.ImportFunctionCount
+	results := make([]result, localFuncs)
+	irCompilers := make([]*wazeroir.Compiler, workers)
+	for w := range irCompilers {
+		irCompiler, err := wazeroir.NewCompiler(e.enabledFeatures, callFrameDataSizeInUint64, module, ensureTermination)
+		if err != nil {
+			return err
+		}
+		irCompilers[w] = irCompiler
+	}
+
+	var next uint32 // The index of the next function to compile, shared by workers.
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for _, irCompiler := range irCompilers {
+		go func(irCompiler *wazeroir.Compiler) {
+			defer wg.Done()
+			cmp, asmNodes, offsets := newCompiler(), new(asmNodes), new(offsets)
+			var scratch asm.CodeSegment
+			defer func() {
+				if err := scratch.Unmap(); err != nil {
+					panic(fmt.Errorf("compiler: failed to munmap code segment: %w", err))
+				}
+			}()
+
+			for {
+				i := atomic.AddUint32(&next, 1) - 1
+				if int(i) >= localFuncs {
+					return
+				}
+
+				r := &results[i]
+				irCompiler.SetNext(i)
+				ir, err := irCompiler.Next()
+				if err != nil {
+					r.err = fmt.Errorf("failed to lower func[%d]: %v", i, err)
+					continue
+				}
+
+				typ := &module.TypeSection[module.FunctionSection[i]]
+				withListener := int(i) < len(listeners) && listeners[i] != nil
+				cmp.Init(typ, ir, withListener)
+
+				buf := scratch.NextCodeSection()
+				r.stackPointerCeil, r.sourceOffsetMap, err = compileWasmFunction(buf, cmp, ir, asmNodes, offsets)
+				if err != nil {
+					def := module.FunctionDefinition(importedFuncs + i)
+					r.err = fmt.Errorf("error compiling wasm func[%s]: %w", def.DebugName(), err)
+					continue
+				}
+				r.code = append([]byte(nil), buf.Bytes()...)
+				buf.Reset()
+			}
+		}(irCompiler)
+	}
+	wg.Wait()
+
+	// Report the error of the function with the smallest index for determinism.
+	for i := range results {
+		if err := results[i].err; err != nil {
+			return err
+		}
+	}
+
+	for i := range results {
+		r := &results[i]
+		buf := executable.NextCodeSection()
+		compiledFn := &cm.functions[i]
+		compiledFn.executableOffset = executable.Size()
+		compiledFn.parent = cm
+		compiledFn.index = importedFuncs + wasm.Index(i)
+		if i < len(listeners) {
+			compiledFn.listener = listeners[i]
+		}
+		compiledFn.stackPointerCeil, compiledFn.sourceOffsetMap = r.stackPointerCeil, r.sourceOffsetMap
+		buf.AppendBytes(r.code)
+	}
+	return nil
+}
+
+// hasGoFunc returns true if any of codes is a Go-defined host function.
+func hasGoFunc(codes []wasm.Code) bool {
+	for i := range codes {
+		if codes[i].GoFunc != nil {
+			return true
+		}
+	}
+	return false
+}
+
 type asmNodes struct {
 	nodes []asm.Node
 }
@@ -1227,6 +1404,10 @@ type offsets struct {
 	values []uint64
 }
 
+// errGCRequiresInterpreter is returned when compiling api.CoreFeatureGC instructions, which only the interpreter
+// implements.
+var errGCRequiresInterpreter = errors.New("GC requires the interpreter: use wazero.NewRuntimeConfigInterpreter")
+
 func compileWasmFunction(buf asm.Buffer, cmp compiler, ir *wazeroir.CompilationResult, asmNodes *asmNodes, offsets *offsets) (spCeil uint64, sm sourceOffsetMap, err error) {
 	if err = cmp.compilePreamble(); err != nil {
 		err = fmt.Errorf("failed to emit preamble: %w", err)
@@ -1542,6 +1723,12 @@ func compileWasmFunction(buf asm.Buffer, cmp compiler, ir *wazeroir.CompilationR
 			err = cmp.compileV128ITruncSatFromF(op)
 		case wazeroir.OperationKindBuiltinFunctionCheckExitCode:
 			err = cmp.compileBuiltinFunctionCheckExitCode()
+		case wazeroir.OperationKindStructNew, wazeroir.OperationKindStructGet, wazeroir.OperationKindStructSet,
+			wazeroir.OperationKindArrayNew, wazeroir.OperationKindArrayGet, wazeroir.OperationKindArraySet,
+			wazeroir.OperationKindArrayLen, wazeroir.OperationKindRefTest, wazeroir.OperationKindRefCast,
+			wazeroir.OperationKindRefI31, wazeroir.OperationKindI31Get, wazeroir.OperationKindRefEq,
+			wazeroir.OperationKindRefAsNonNull:
+			err = errGCRequiresInterpreter
 		default:
 			err = errors.New("unsupported")
 		}
@@ -12,6 +12,7 @@ import (
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/experimental"
 	"github.com/tetratelabs/wazero/experimental/logging"
+	"github.com/tetratelabs/wazero/internal/asm"
 	"github.com/tetratelabs/wazero/internal/bitpack"
 	"github.com/tetratelabs/wazero/internal/platform"
 	"github.com/tetratelabs/wazero/internal/testing/enginetest"
@@ -231,6 +232,74 @@ func TestCompiler_CompileModule(t *testing.T) {
 	})
 }
 
+func TestCompiler_compileWasmFunctionsConcurrently(t *testing.T) {
+	m := &wasm.Module{
+		TypeSection: []wasm.FunctionType{
+			{},
+			{Params: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32}, Results: []wasm.ValueType{wasm.ValueTypeI32}, ParamNumInUint64: 2, ResultNumInUint64: 1},
+		},
+		ID: wasm.ModuleID{1},
+	}
+	for i := 0; i < concurrentCompilationThreshold; i++ {
+		if i%2 == 0 {
+			m.FunctionSection = append(m.FunctionSection, 0)
+			m.CodeSection = append(m.CodeSection, wasm.Code{Body: []byte{wasm.OpcodeEnd}})
+		} else {
+			m.FunctionSection = append(m.FunctionSection, 1)
+			m.CodeSection = append(m.CodeSection, wasm.Code{Body: []byte{
+				wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Add, wasm.OpcodeEnd,
+			}})
+		}
+	}
+
+	e := et.NewEngine(api.CoreFeaturesV1).(*engine)
+
+	// Compile with a single worker, which must be identical to the sequential compilation.
+	var expected asm.CodeSegment
+	defer func() { require.NoError(t, expected.Unmap()) }()
+	expectedModule := &compiledModule{functions: make([]compiledFunction, len(m.CodeSection))}
+	err := e.compileWasmFunctionsConcurrently(m, expectedModule, nil, false, 1, &expected)
+	require.NoError(t, err)
+
+	for _, workers := range []int{2, 4, concurrentCompilationThreshold} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			var actual asm.CodeSegment
+			defer func() { require.NoError(t, actual.Unmap()) }()
+			actualModule := &compiledModule{functions: make([]compiledFunction, len(m.CodeSection))}
+			err := e.compileWasmFunctionsConcurrently(m, actualModule, nil, false, workers, &actual)
+			require.NoError(t, err)
+
+			require.Equal(t, expected.Bytes()[:expected.Size()], actual.Bytes()[:actual.Size()])
+			for i := range actualModule.functions {
+				exp, act := &expectedModule.functions[i], &actualModule.functions[i]
+				require.Equal(t, exp.executableOffset, act.executableOffset)
+				require.Equal(t, exp.stackPointerCeil, act.stackPointerCeil)
+				require.Equal(t, exp.index, act.index)
+				require.Equal(t, actualModule, act.parent)
+			}
+		})
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		errModule := &wasm.Module{
+			TypeSection:     m.TypeSection,
+			FunctionSection: m.FunctionSection,
+			CodeSection:     append([]wasm.Code{}, m.CodeSection...),
+			ID:              wasm.ModuleID{2},
+		}
+		// Call instruction without immediate for call target index is invalid and should fail to compile.
+		errModule.CodeSection[3] = wasm.Code{Body: []byte{wasm.OpcodeCall}}
+		errModule.CodeSection[30] = wasm.Code{Body: []byte{wasm.OpcodeCall}}
+
+		var executable asm.CodeSegment
+		defer func() { require.NoError(t, executable.Unmap()) }()
+		cm := &compiledModule{functions: make([]compiledFunction, len(errModule.CodeSection))}
+		err := e.compileWasmFunctionsConcurrently(errModule, cm, nil, false, 4, &executable)
+		// The error must be reported for the function with the smallest index.
+		require.EqualError(t, err, "failed to lower func[3]: handling instruction: apply stack failed for call: reading immediates: EOF")
+	})
+}
+
 func TestCompiler_Releasecode_Panic(t *testing.T) {
 	captured := require.CapturePanic(func() {
 		releaseCompiledModule(&compiledModule{
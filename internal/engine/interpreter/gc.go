@@ -0,0 +1,180 @@
+package interpreter
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// maxArrayLen is the maximum number of elements of an array created by
+// api.CoreFeatureGC instructions.
+const maxArrayLen = 1 << 27
+
+// gcObjectSize is the bytes accounted for an object, excluding its fields.
+const gcObjectSize = uint64(unsafe.Sizeof(gcObject{}))
+
+// gcHeap holds the structs and arrays allocated by api.CoreFeatureGC
+// instructions in the modules of an engine, so that they can reference each
+// other's objects.
+//
+// A reference is a handle to a slot of the heap, not a pointer, so that the
+// objects are only held by slots, which the Go GC traces. Each object is
+// owned by the module instance which allocated it, and released when that
+// module is closed. Using a reference to a released object traps with a null
+// reference error, as the generation of its slot no longer matches.
+//
+// Note: There is no collector, so objects live as long as their owner, even
+// when unreachable.
+type gcHeap struct {
+	mux   sync.RWMutex
+	slots []gcSlot
+	// free are the indexes of the slots of released objects.
+	free []uint32
+}
+
+type gcSlot struct {
+	object *gcObject
+	// gen is incremented each time the slot is released, so that stale
+	// references don't resolve to the next object in it.
+	gen uint32
+}
+
+// gcObject is a struct or an array. Each field or element is a uint64,
+// including packed ones, as v128 is not supported.
+type gcObject struct {
+	// source is the module which defines the type of this object.
+	source    *wasm.Module
+	typeIndex wasm.Index
+	kind      wasm.CompositeKind
+	fields    []uint64
+}
+
+// gcRef encodes a handle to a slot, which is never zero and never has the
+// lowest bit set, to distinguish it from null and i31 references.
+func gcRef(index, gen uint32) uint64 {
+	return uint64(gen&0x7fffffff)<<33 | (uint64(index)+1)<<1
+}
+
+// gcHeapOf returns the heap shared by the modules of the engine which
+// instantiated m, and the module engine of m, which owns the objects it
+// allocates. This is only called by GC instructions, so that other calls
+// don't depend on the engine.
+func gcHeapOf(m *wasm.ModuleInstance) (*gcHeap, *moduleEngine) {
+	me := m.Engine.(*moduleEngine)
+	return &me.parentEngine.gcHeap, me
+}
+
+// alloc returns a reference to a new object owned by the module engine me.
+// This panics if the object exceeds the usage limit of the module instance.
+func (h *gcHeap) alloc(me *moduleEngine, source *wasm.Module, typeIndex wasm.Index, fields []uint64) uint64 {
+	size := gcObjectSize + uint64(len(fields))*8
+	if !me.usage.ReserveHeap(size) {
+		panic(wasmruntime.ErrRuntimeHeapLimitExceeded)
+	}
+	o := &gcObject{source: source, typeIndex: typeIndex, kind: source.CompositeTypes[typeIndex].Kind, fields: fields}
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	var index uint32
+	if n := len(h.free); n > 0 {
+		index = h.free[n-1]
+		h.free = h.free[:n-1]
+	} else {
+		index = uint32(len(h.slots))
+		h.slots = append(h.slots, gcSlot{})
+	}
+	h.slots[index].object = o
+	me.gcSlots = append(me.gcSlots, index)
+	me.gcBytes += size
+	return gcRef(index, h.slots[index].gen)
+}
+
+// object resolves a reference returned by alloc, or panics if ref is null or
+// its object was released.
+func (h *gcHeap) object(ref uint64) *gcObject {
+	if ref == 0 {
+		panic(wasmruntime.ErrRuntimeNullReference)
+	}
+	index, gen := uint32(ref>>1)-1, uint32(ref>>33)
+
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	if int(index) < len(h.slots) {
+		if s := &h.slots[index]; s.object != nil && s.gen&0x7fffffff == gen {
+			return s.object
+		}
+	}
+	panic(wasmruntime.ErrRuntimeNullReference)
+}
+
+// release releases the objects owned by the module engine me, and un-accounts
+// them from its usage limit.
+func (h *gcHeap) release(me *moduleEngine) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	for _, index := range me.gcSlots {
+		h.slots[index] = gcSlot{gen: h.slots[index].gen + 1}
+		h.free = append(h.free, index)
+	}
+	me.usage.ReleaseHeap(me.gcBytes)
+	me.gcSlots, me.gcBytes = nil, 0
+}
+
+// i31Ref encodes v as an i31 reference, which has the lowest bit set to
+// distinguish it from objects.
+func i31Ref(v uint32) uint64 {
+	return uint64(v&0x7fffffff)<<1 | 1
+}
+
+// isI31Ref returns true if ref was returned by i31Ref.
+func isI31Ref(ref uint64) bool {
+	return ref&1 == 1
+}
+
+// refMatches returns true if ref is a non-null reference of the heap type ht
+// as defined in the module m.
+func (h *gcHeap) refMatches(ref uint64, ht wasm.HeapType, m *wasm.Module, typeIDs []wasm.FunctionTypeID) bool {
+	switch ht {
+	case wasm.HeapTypeNone, wasm.HeapTypeNoFunc, wasm.HeapTypeNoExtern:
+		return false
+	case wasm.HeapTypeFunc, wasm.HeapTypeExtern:
+		return true
+	case wasm.HeapTypeAny, wasm.HeapTypeEq:
+		return true
+	case wasm.HeapTypeI31:
+		return isI31Ref(ref)
+	}
+
+	if isI31Ref(ref) {
+		return false
+	}
+	typeIndex := wasm.Index(ht)
+	switch ht {
+	case wasm.HeapTypeStruct:
+		return h.object(ref).kind == wasm.CompositeKindStruct
+	case wasm.HeapTypeArray:
+		return h.object(ref).kind == wasm.CompositeKindArray
+	}
+	if m.CompositeTypes[typeIndex].Kind == wasm.CompositeKindFunc {
+		return functionFromUintptr(uintptr(ref)).typeID == typeIDs[typeIndex]
+	}
+	o := h.object(ref)
+	return o.source.IsSubtype(o.typeIndex, m, typeIndex)
+}
+
+// readField returns the value of a field or element, extending packed values
+// to i32.
+func readField(o *gcObject, index uint64, field *wasm.FieldType, signed bool) uint64 {
+	v := o.fields[index]
+	if signed {
+		switch field.StorageType {
+		case wasm.StorageTypeI8:
+			v = uint64(uint32(int32(int8(v))))
+		case wasm.StorageTypeI16:
+			v = uint64(uint32(int32(int16(v))))
+		}
+	}
+	return v
+}
@@ -0,0 +1,51 @@
+package interpreter
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+func TestGCHeap(t *testing.T) {
+	source := &wasm.Module{CompositeTypes: []wasm.CompositeType{{Kind: wasm.CompositeKindArray}}}
+	size := gcObjectSize + 2*8
+	usage := &wasm.UsageLimit{Limit: 2 * size}
+
+	var h gcHeap
+	m1, m2 := &moduleEngine{usage: usage}, &moduleEngine{}
+
+	ref1 := h.alloc(m1, source, 0, []uint64{1, 2})
+	ref2 := h.alloc(m2, source, 0, []uint64{3})
+	require.False(t, isI31Ref(ref1))
+	require.NotEqual(t, ref1, ref2)
+	require.Equal(t, []uint64{1, 2}, h.object(ref1).fields)
+	require.Equal(t, []uint64{3}, h.object(ref2).fields)
+	require.Equal(t, size, usage.Heap())
+
+	// The second object of m1 reaches the limit, so the third exceeds it.
+	ref4 := h.alloc(m1, source, 0, []uint64{4, 5})
+	err := require.CapturePanic(func() { h.alloc(m1, source, 0, nil) })
+	require.ErrorIs(t, err, wasmruntime.ErrRuntimeHeapLimitExceeded)
+	require.Equal(t, 2*size, usage.Heap())
+
+	// Releasing m1 frees its objects, but not those of m2.
+	h.release(m1)
+	require.Zero(t, usage.Heap())
+	require.Zero(t, usage.Used())
+	require.Equal(t, []uint64{3}, h.object(ref2).fields)
+	err = require.CapturePanic(func() { h.object(ref1) })
+	require.ErrorIs(t, err, wasmruntime.ErrRuntimeNullReference)
+
+	// A stale reference doesn't resolve to the next object in its slot.
+	ref3 := h.alloc(m2, source, 0, []uint64{6})
+	require.Equal(t, ref4>>1&0xffffffff, ref3>>1&0xffffffff) // same slot
+	require.NotEqual(t, ref4, ref3)
+	require.Equal(t, []uint64{6}, h.object(ref3).fields)
+	err = require.CapturePanic(func() { h.object(ref4) })
+	require.ErrorIs(t, err, wasmruntime.ErrRuntimeNullReference)
+
+	err = require.CapturePanic(func() { h.object(0) })
+	require.ErrorIs(t, err, wasmruntime.ErrRuntimeNullReference)
+}
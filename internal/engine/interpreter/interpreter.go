@@ -34,6 +34,8 @@ type engine struct {
 	mux               sync.RWMutex
 	// labelAddressResolutionCache is the temporary cache used to map LabelKind -> FrameID -> the index to the body.
 	labelAddressResolutionCache [wazeroir.LabelKindNum][]uint64
+	// gcHeap holds the objects allocated by the modules of this engine.
+	gcHeap gcHeap
 }
 
 func NewEngine(_ context.Context, enabledFeatures api.CoreFeatures, _ filecache.Cache) wasm.Engine {
@@ -53,6 +55,11 @@ func (e *engine) CompiledModuleCount() uint32 {
 	return uint32(len(e.compiledFunctions))
 }
 
+// CompiledCodeSizes implements the same method as documented on wasm.Engine.
+func (e *engine) CompiledCodeSizes(*wasm.Module) []uint64 {
+	return nil // interpreted
+}
+
 // DeleteCompiledModule implements the same method as documented on wasm.Engine.
 func (e *engine) DeleteCompiledModule(m *wasm.Module) {
 	e.deleteCompiledFunctions(m)
@@ -85,6 +92,15 @@ type moduleEngine struct {
 
 	// parentEngine holds *engine from which this module engine is created from.
 	parentEngine *engine
+
+	// usage is the usage limit of the module instance, which accounts the
+	// objects it allocates with api.CoreFeatureGC instructions.
+	usage *wasm.UsageLimit
+	// gcSlots are the indexes of the gcHeap slots of the objects owned by this
+	// module engine, and gcBytes their accounted size. Both are guarded by
+	// the mutex of gcHeap.
+	gcSlots []uint32
+	gcBytes uint64
 }
 
 // callEngine holds context per moduleEngine.Call, and shared across all the
@@ -106,6 +122,21 @@ type callEngine struct {
 
 	// stackiterator for Listeners to walk frames and stack.
 	stackIterator stackIterator
+
+	// executionLimit is the maximum number of loop iterations in a call, or zero if unlimited.
+	// executionCount is the number of loop iterations executed in the current call.
+	// These are only used when the function is compiled with ensureTermination.
+	executionLimit, executionCount uint64
+
+	// memoryWatch reports loads and stores in the current call, or is nil.
+	memoryWatch *experimental.MemoryWatch
+
+	// canonicalizeNaN is true when float operations must push the canonical
+	// NaN instead of any NaN. See wazero.RuntimeConfig WithNaNCanonicalization.
+	canonicalizeNaN bool
+
+	// metrics receives events of the current call, or is nil.
+	metrics experimental.Metrics
 }
 
 func (e *moduleEngine) newCallEngine(compiled *function) *callEngine {
@@ -192,7 +223,7 @@ type compiledFunction struct {
 	body                []wazeroir.UnionOperation
 	listener            experimental.FunctionListener
 	offsetsInWasmBinary []uint64
-	hostFn              interface{}
+	hostFn              *wasm.GoFuncHolder
 	ensureTermination   bool
 	index               wasm.Index
 }
@@ -302,10 +333,21 @@ func (f internalFunction) SourceOffsetForPC(pc experimental.ProgramCounter) uint
 const callFrameStackSize = 0
 
 // CompileModule implements the same method as documented on wasm.Engine.
-func (e *engine) CompileModule(_ context.Context, module *wasm.Module, listeners []experimental.FunctionListener, ensureTermination bool) error {
+func (e *engine) CompileModule(ctx context.Context, module *wasm.Module, listeners []experimental.FunctionListener, ensureTermination bool) (err error) {
+	metrics := wasm.MetricsFrom(ctx)
 	if _, ok := e.getCompiledFunctions(module); ok { // cache hit!
+		if metrics != nil {
+			metrics.ModuleCompiled(true)
+		}
 		return nil
 	}
+	if metrics != nil {
+		defer func() {
+			if err == nil {
+				metrics.ModuleCompiled(false)
+			}
+		}()
+	}
 
 	funcs := make([]compiledFunction, len(module.FunctionSection))
 	irCompiler, err := wazeroir.NewCompiler(e.enabledFeatures, callFrameStackSize, module, ensureTermination)
@@ -324,7 +366,7 @@ func (e *engine) CompileModule(_ context.Context, module *wasm.Module, listeners
 		// host function in interpreter is its Go function itself as opposed to Wasm functions,
 		// which need to be compiled down to wazeroir.
 		if codeSeg := &module.CodeSection[i]; codeSeg.GoFunc != nil {
-			compiled.hostFn = codeSeg.GoFunc
+			compiled.hostFn = wasm.NewGoFuncHolder(codeSeg.GoFunc)
 		} else {
 			ir, err := irCompiler.Next()
 			if err != nil {
@@ -356,6 +398,9 @@ func (e *engine) NewModuleEngine(module *wasm.Module, instance *wasm.ModuleInsta
 	if !ok {
 		return nil, errors.New("source module must be compiled before instantiation")
 	}
+	if instance != nil {
+		me.usage = instance.UsageLimit
+	}
 
 	for i := range codes {
 		c := &codes[i]
@@ -424,9 +469,61 @@ func (e *engine) lowerIR(ir *wazeroir.CompilationResult, ret *compiledFunction)
 	for i := range e.labelAddressResolutionCache {
 		e.labelAddressResolutionCache[i] = e.labelAddressResolutionCache[i][:0]
 	}
+
+	fuseOperations(ret.body)
 	return nil
 }
 
+// Superinstructions are interpreter-only operation kinds, which fuse a
+// sequence of operations frequently emitted for Wasm, e.g. `local.get 0;
+// local.get 1; i32.add`, to save dispatches and stack traffic.
+//
+// A superinstruction replaces the first operation of its sequence and skips
+// the others, which are kept so that the indexes of the operations, which
+// resolved labels and source offsets refer to, don't change.
+//
+// The kinds follow the last one of wazeroir, as keeping them contiguous lets
+// the dispatch switch in callNativeFunc compile to a jump table.
+const (
+	// operationKindPickPickAdd is Pick(U1), Pick(U2) then Add(B1) for an integer type.
+	operationKindPickPickAdd = wazeroir.OperationKindBuiltinFunctionCheckExitCode + 1 + iota
+	// operationKindPickConstAdd is Pick(U1), Const(U2) then Add(B1) for an integer type.
+	operationKindPickConstAdd
+)
+
+// fuseOperations replaces the first operation of each sequence in body which
+// has a superinstruction, e.g. Pick, Pick then Add. The rest of the sequence
+// is kept in place for the superinstruction to skip, so no index changes.
+//
+// Only consecutive operations are fused. Jumps target Label operations, which
+// stay in body, so a sequence with a label between its operations doesn't
+// match and isn't fused: a jump never lands after a superinstruction, in the
+// middle of the sequence it replaces.
+func fuseOperations(body []wazeroir.UnionOperation) {
+	for i := 0; i+2 < len(body); i++ {
+		first, second, third := &body[i], &body[i+1], &body[i+2]
+		if first.Kind != wazeroir.OperationKindPick || first.B3 || third.Kind != wazeroir.OperationKindAdd {
+			continue
+		}
+
+		t := wazeroir.UnsignedType(third.B1)
+		if t != wazeroir.UnsignedTypeI32 && t != wazeroir.UnsignedTypeI64 {
+			continue
+		}
+
+		var kind wazeroir.OperationKind
+		switch second.Kind {
+		case wazeroir.OperationKindPick:
+			kind = operationKindPickPickAdd
+		case wazeroir.OperationKindConstI32, wazeroir.OperationKindConstI64:
+			kind = operationKindPickConstAdd
+		default:
+			continue
+		}
+		*first = wazeroir.UnionOperation{Kind: kind, B1: third.B1, U1: first.U1, U2: second.U1}
+	}
+}
+
 func (e *engine) setLabelAddress(op *uint64, label wazeroir.Label) {
 	if label.IsReturnTarget() {
 		// Jmp to the end of the possible binary.
@@ -442,11 +539,21 @@ func (e *moduleEngine) ResolveImportedFunction(index, indexInImportedModule wasm
 	e.functions[index] = imported.functions[indexInImportedModule]
 }
 
+// ReplaceGoFunction implements wasm.ModuleEngine.
+func (e *moduleEngine) ReplaceGoFunction(index wasm.Index, goFunc interface{}) {
+	e.functions[index].parent.hostFn.Store(goFunc)
+}
+
 // FunctionInstanceReference implements the same method as documented on wasm.ModuleEngine.
 func (e *moduleEngine) FunctionInstanceReference(funcIndex wasm.Index) wasm.Reference {
 	return uintptr(unsafe.Pointer(&e.functions[funcIndex]))
 }
 
+// Close implements the same method as documented on wasm.ModuleEngine.
+func (e *moduleEngine) Close(context.Context) {
+	e.parentEngine.gcHeap.release(e)
+}
+
 // NewFunction implements the same method as documented on wasm.ModuleEngine.
 func (e *moduleEngine) NewFunction(index wasm.Index) (ce api.Function) {
 	// Note: The input parameters are pre-validated, so a compiled function is only absent on close. Updates to
@@ -529,11 +636,18 @@ func (ce *callEngine) call(ctx context.Context, params, results []uint64) (_ []u
 		if v := recover(); v != nil {
 			err = ce.recoverOnCall(ctx, m, v)
 		}
+		wasm.ReportTrap(ce.metrics, err)
 	}()
 
+	if ce.metrics = wasm.MetricsFrom(ctx); ce.metrics != nil {
+		ce.metrics.FunctionCalled(ce.f.definition())
+	}
 	ce.pushValues(params)
+	ce.memoryWatch, _ = ctx.Value(experimental.MemoryWatchKey{}).(*experimental.MemoryWatch)
+	ce.canonicalizeNaN = m.CanonicalizeNaN()
 
 	if ce.f.parent.ensureTermination {
+		ce.executionLimit, ce.executionCount = m.ExecutionLimit(), 0
 		done := m.CloseModuleOnCanceledOrTimeout(ctx)
 		defer done()
 	}
@@ -612,16 +726,13 @@ func (ce *callEngine) callGoFunc(ctx context.Context, m *wasm.ModuleInstance, f
 		lsn.Before(ctx, m, f.definition(), params, &ce.stackIterator)
 		ce.stackIterator.clear()
 	}
+	if ce.metrics != nil {
+		ce.metrics.HostFunctionCalled(f.definition())
+	}
 	frame := &callFrame{f: f, base: len(ce.stack)}
 	ce.pushFrame(frame)
 
-	fn := f.parent.hostFn
-	switch fn := fn.(type) {
-	case api.GoModuleFunction:
-		fn.Call(ctx, m, stack)
-	case api.GoFunction:
-		fn.Call(ctx, stack)
-	}
+	wasm.CallGoFunc(ctx, m, f.definition(), f.parent.hostFn.Load(), stack)
 
 	ce.popFrame()
 	if lsn != nil {
@@ -654,6 +765,11 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, m *wasm.ModuleInstance
 			if err := m.FailIfClosed(); err != nil {
 				panic(err)
 			}
+			if ce.executionLimit > 0 {
+				if ce.executionCount++; ce.executionCount > ce.executionLimit {
+					panic(wasmruntime.ErrRuntimeExecutionLimitExceeded)
+				}
+			}
 			frame.pc++
 		case wazeroir.OperationKindUnreachable:
 			panic(wasmruntime.ErrRuntimeUnreachable)
@@ -716,6 +832,27 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, m *wasm.ModuleInstance
 				}
 			}
 			frame.pc++
+		case operationKindPickPickAdd:
+			top := len(ce.stack) - 1
+			v1, v2 := ce.stack[top-int(op.U1)], ce.stack[top-int(op.U1)]
+			// The second pick is relative to the stack after the first one.
+			if op.U2 != 0 {
+				v2 = ce.stack[top+1-int(op.U2)]
+			}
+			if wazeroir.UnsignedType(op.B1) == wazeroir.UnsignedTypeI32 {
+				ce.pushValue(uint64(uint32(v1) + uint32(v2)))
+			} else {
+				ce.pushValue(v1 + v2)
+			}
+			frame.pc += 3
+		case operationKindPickConstAdd:
+			v := ce.stack[len(ce.stack)-1-int(op.U1)]
+			if wazeroir.UnsignedType(op.B1) == wazeroir.UnsignedTypeI32 {
+				ce.pushValue(uint64(uint32(v) + uint32(op.U2)))
+			} else {
+				ce.pushValue(v + op.U2)
+			}
+			frame.pc += 3
 		case wazeroir.OperationKindPick:
 			index := len(ce.stack) - 1 - int(op.U1)
 			ce.pushValue(ce.stack[index])
@@ -1732,6 +1869,131 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, m *wasm.ModuleInstance
 				}
 			}
 			frame.pc++
+		case wazeroir.OperationKindStructNew:
+			source := frame.f.parent.source
+			fields := make([]uint64, len(source.CompositeTypes[op.U1].Fields))
+			if !op.B3 {
+				for i := len(fields) - 1; i >= 0; i-- {
+					fields[i] = source.CompositeTypes[op.U1].Fields[i].Wrap(ce.popValue())
+				}
+			}
+			heap, me := gcHeapOf(moduleInst)
+			ce.pushValue(heap.alloc(me, source, wasm.Index(op.U1), fields))
+			frame.pc++
+		case wazeroir.OperationKindStructGet:
+			field := &frame.f.parent.source.CompositeTypes[op.U1].Fields[op.U2]
+			heap, _ := gcHeapOf(moduleInst)
+			o := heap.object(ce.popValue())
+			ce.pushValue(readField(o, op.U2, field, op.B3))
+			frame.pc++
+		case wazeroir.OperationKindStructSet:
+			field := &frame.f.parent.source.CompositeTypes[op.U1].Fields[op.U2]
+			v := ce.popValue()
+			heap, _ := gcHeapOf(moduleInst)
+			o := heap.object(ce.popValue())
+			o.fields[op.U2] = field.Wrap(v)
+			frame.pc++
+		case wazeroir.OperationKindArrayNew:
+			source := frame.f.parent.source
+			elem := &source.CompositeTypes[op.U1].Fields[0]
+			var elems []uint64
+			switch op.B1 {
+			case wazeroir.ArrayNewModeInit, wazeroir.ArrayNewModeDefault:
+				n := uint64(uint32(ce.popValue()))
+				if n > maxArrayLen {
+					panic(wasmruntime.ErrRuntimeArrayTooLarge)
+				}
+				elems = make([]uint64, n)
+				if op.B1 == wazeroir.ArrayNewModeInit {
+					v := elem.Wrap(ce.popValue())
+					for i := range elems {
+						elems[i] = v
+					}
+				}
+			case wazeroir.ArrayNewModeFixed:
+				elems = make([]uint64, op.U2)
+				for i := len(elems) - 1; i >= 0; i-- {
+					elems[i] = elem.Wrap(ce.popValue())
+				}
+			}
+			heap, me := gcHeapOf(moduleInst)
+			ce.pushValue(heap.alloc(me, source, wasm.Index(op.U1), elems))
+			frame.pc++
+		case wazeroir.OperationKindArrayGet:
+			elem := &frame.f.parent.source.CompositeTypes[op.U1].Fields[0]
+			index := uint64(uint32(ce.popValue()))
+			heap, _ := gcHeapOf(moduleInst)
+			o := heap.object(ce.popValue())
+			if index >= uint64(len(o.fields)) {
+				panic(wasmruntime.ErrRuntimeOutOfBoundsArrayAccess)
+			}
+			ce.pushValue(readField(o, index, elem, op.B3))
+			frame.pc++
+		case wazeroir.OperationKindArraySet:
+			elem := &frame.f.parent.source.CompositeTypes[op.U1].Fields[0]
+			v := ce.popValue()
+			index := uint64(uint32(ce.popValue()))
+			heap, _ := gcHeapOf(moduleInst)
+			o := heap.object(ce.popValue())
+			if index >= uint64(len(o.fields)) {
+				panic(wasmruntime.ErrRuntimeOutOfBoundsArrayAccess)
+			}
+			o.fields[index] = elem.Wrap(v)
+			frame.pc++
+		case wazeroir.OperationKindArrayLen:
+			heap, _ := gcHeapOf(moduleInst)
+			o := heap.object(ce.popValue())
+			ce.pushValue(uint64(len(o.fields)))
+			frame.pc++
+		case wazeroir.OperationKindRefTest:
+			ref := ce.popValue()
+			b := op.B3 // null matches nullable heap types.
+			if ref != 0 {
+				heap, _ := gcHeapOf(moduleInst)
+				b = heap.refMatches(ref, wasm.HeapType(op.U1), frame.f.parent.source, typeIDs)
+			}
+			if b {
+				ce.pushValue(1)
+			} else {
+				ce.pushValue(0)
+			}
+			frame.pc++
+		case wazeroir.OperationKindRefCast:
+			ref := ce.stack[len(ce.stack)-1]
+			if ref == 0 {
+				if !op.B3 {
+					panic(wasmruntime.ErrRuntimeCastFailure)
+				}
+			} else if heap, _ := gcHeapOf(moduleInst); !heap.refMatches(ref, wasm.HeapType(op.U1), frame.f.parent.source, typeIDs) {
+				panic(wasmruntime.ErrRuntimeCastFailure)
+			}
+			frame.pc++
+		case wazeroir.OperationKindRefI31:
+			ce.pushValue(i31Ref(uint32(ce.popValue())))
+			frame.pc++
+		case wazeroir.OperationKindI31Get:
+			ref := ce.popValue()
+			if ref == 0 {
+				panic(wasmruntime.ErrRuntimeNullReference)
+			}
+			v := uint32(ref >> 1)
+			if op.B3 {
+				v = uint32(int32(v<<1) >> 1)
+			}
+			ce.pushValue(uint64(v))
+			frame.pc++
+		case wazeroir.OperationKindRefEq:
+			if ce.popValue() == ce.popValue() {
+				ce.pushValue(1)
+			} else {
+				ce.pushValue(0)
+			}
+			frame.pc++
+		case wazeroir.OperationKindRefAsNonNull:
+			if ce.stack[len(ce.stack)-1] == 0 {
+				panic(wasmruntime.ErrRuntimeNullReference)
+			}
+			frame.pc++
 		case wazeroir.OperationKindV128Const:
 			lo, hi := op.U1, op.U2
 			ce.pushValue(lo)
@@ -3899,10 +4161,96 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, m *wasm.ModuleInstance
 		default:
 			frame.pc++
 		}
+		if ce.canonicalizeNaN {
+			ce.canonicalizeNaNResult(op)
+		}
 	}
 	ce.popFrame()
 }
 
+const (
+	// canonicalNaN32 is the canonical NaN of f32, with only the most
+	// significant bit of the payload set.
+	canonicalNaN32 = 0x7fc00000
+	// canonicalNaN64 is the canonical NaN of f64, with only the most
+	// significant bit of the payload set.
+	canonicalNaN64 = 0x7ff8000000000000
+)
+
+// canonicalizeNaNResult replaces any NaN pushed by op with the canonical NaN,
+// if op is a float operation whose NaN results can differ between CPUs.
+func (ce *callEngine) canonicalizeNaNResult(op *wazeroir.UnionOperation) {
+	top := len(ce.stack) - 1
+	switch op.Kind {
+	case wazeroir.OperationKindAdd, wazeroir.OperationKindSub, wazeroir.OperationKindMul:
+		switch wazeroir.UnsignedType(op.B1) {
+		case wazeroir.UnsignedTypeF32:
+			ce.stack[top] = canonicalizeNaN32(ce.stack[top])
+		case wazeroir.UnsignedTypeF64:
+			ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+		}
+	case wazeroir.OperationKindDiv:
+		switch wazeroir.SignedType(op.B1) {
+		case wazeroir.SignedTypeFloat32:
+			ce.stack[top] = canonicalizeNaN32(ce.stack[top])
+		case wazeroir.SignedTypeFloat64:
+			ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+		}
+	case wazeroir.OperationKindCeil, wazeroir.OperationKindFloor, wazeroir.OperationKindTrunc,
+		wazeroir.OperationKindNearest, wazeroir.OperationKindSqrt, wazeroir.OperationKindMin,
+		wazeroir.OperationKindMax:
+		if op.B1 == 0 { // Float32
+			ce.stack[top] = canonicalizeNaN32(ce.stack[top])
+		} else {
+			ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+		}
+	case wazeroir.OperationKindF32DemoteFromF64:
+		ce.stack[top] = canonicalizeNaN32(ce.stack[top])
+	case wazeroir.OperationKindF64PromoteFromF32:
+		ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+	case wazeroir.OperationKindV128Add, wazeroir.OperationKindV128Sub, wazeroir.OperationKindV128Mul,
+		wazeroir.OperationKindV128Div, wazeroir.OperationKindV128Sqrt, wazeroir.OperationKindV128Min,
+		wazeroir.OperationKindV128Max, wazeroir.OperationKindV128Ceil, wazeroir.OperationKindV128Floor,
+		wazeroir.OperationKindV128Trunc, wazeroir.OperationKindV128Nearest:
+		// The high and low 64 bits are the top two values on the stack.
+		switch op.B1 {
+		case wazeroir.ShapeF32x4:
+			ce.stack[top-1] = canonicalizeNaN32x2(ce.stack[top-1])
+			ce.stack[top] = canonicalizeNaN32x2(ce.stack[top])
+		case wazeroir.ShapeF64x2:
+			ce.stack[top-1] = canonicalizeNaN64(ce.stack[top-1])
+			ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+		}
+	case wazeroir.OperationKindV128FloatPromote:
+		ce.stack[top-1] = canonicalizeNaN64(ce.stack[top-1])
+		ce.stack[top] = canonicalizeNaN64(ce.stack[top])
+	case wazeroir.OperationKindV128FloatDemote:
+		// The high 64 bits are zero.
+		ce.stack[top-1] = canonicalizeNaN32x2(ce.stack[top-1])
+	}
+}
+
+// canonicalizeNaN32 returns canonicalNaN32 if v is an f32 NaN, or v otherwise.
+func canonicalizeNaN32(v uint64) uint64 {
+	if f := math.Float32frombits(uint32(v)); f != f {
+		return canonicalNaN32
+	}
+	return v
+}
+
+// canonicalizeNaN32x2 is like canonicalizeNaN32, for both f32 lanes in v.
+func canonicalizeNaN32x2(v uint64) uint64 {
+	return canonicalizeNaN32(v&math.MaxUint32) | canonicalizeNaN32(v>>32)<<32
+}
+
+// canonicalizeNaN64 returns canonicalNaN64 if v is an f64 NaN, or v otherwise.
+func canonicalizeNaN64(v uint64) uint64 {
+	if math.IsNaN(math.Float64frombits(v)) {
+		return canonicalNaN64
+	}
+	return v
+}
+
 func WasmCompatMax32bits(v1, v2 uint32) uint64 {
 	return uint64(math.Float32bits(moremath.WasmCompatMax32(
 		math.Float32frombits(v1),
@@ -4118,9 +4466,75 @@ func (ce *callEngine) popMemoryOffset(op *wazeroir.UnionOperation) uint32 {
 	if offset > math.MaxUint32 {
 		panic(wasmruntime.ErrRuntimeOutOfBoundsMemoryAccess)
 	}
+	if ce.memoryWatch != nil {
+		ce.watchMemory(op, uint32(offset))
+	}
 	return uint32(offset)
 }
 
+// watchMemory reports the load or store op at offset, if it overlaps any range
+// of ce.memoryWatch.
+func (ce *callEngine) watchMemory(op *wazeroir.UnionOperation, offset uint32) {
+	size, write := memoryAccess(op)
+	if !ce.memoryWatch.Watches(offset, size) {
+		return
+	}
+	frame := ce.frames[len(ce.frames)-1]
+	ce.memoryWatch.Report(experimental.MemoryAccess{
+		Function: internalFunction{frame.f},
+		PC:       experimental.ProgramCounter(frame.pc),
+		Offset:   offset,
+		Size:     size,
+		Write:    write,
+	})
+}
+
+// memoryAccess returns the count of bytes op accesses, and whether it is a
+// store, for operations which use popMemoryOffset.
+func memoryAccess(op *wazeroir.UnionOperation) (size uint32, write bool) {
+	switch op.Kind {
+	case wazeroir.OperationKindLoad, wazeroir.OperationKindStore:
+		size = 8
+		switch wazeroir.UnsignedType(op.B1) {
+		case wazeroir.UnsignedTypeI32, wazeroir.UnsignedTypeF32:
+			size = 4
+		}
+		return size, op.Kind == wazeroir.OperationKindStore
+	case wazeroir.OperationKindLoad8:
+		return 1, false
+	case wazeroir.OperationKindLoad16:
+		return 2, false
+	case wazeroir.OperationKindLoad32:
+		return 4, false
+	case wazeroir.OperationKindStore8:
+		return 1, true
+	case wazeroir.OperationKindStore16:
+		return 2, true
+	case wazeroir.OperationKindStore32:
+		return 4, true
+	case wazeroir.OperationKindV128Load:
+		switch op.B1 {
+		case wazeroir.V128LoadType128:
+			return 16, false
+		case wazeroir.V128LoadType8Splat:
+			return 1, false
+		case wazeroir.V128LoadType16Splat:
+			return 2, false
+		case wazeroir.V128LoadType32Splat, wazeroir.V128LoadType32zero:
+			return 4, false
+		default: // 8x8s, 8x8u, 16x4s, 16x4u, 32x2s, 32x2u, 64Splat or 64zero
+			return 8, false
+		}
+	case wazeroir.OperationKindV128LoadLane:
+		return uint32(op.B1) / 8, false
+	case wazeroir.OperationKindV128Store:
+		return 16, true
+	case wazeroir.OperationKindV128StoreLane:
+		return uint32(op.B1) / 8, true
+	}
+	return 0, false
+}
+
 func (ce *callEngine) callGoFuncWithStack(ctx context.Context, m *wasm.ModuleInstance, f *function) {
 	typ := f.funcType
 	paramLen := typ.ParamNumInUint64
@@ -581,3 +581,94 @@ func TestCompiler_BeforeListenerStackIteratorSourceOffset(t *testing.T) {
 func TestCompiler_BeforeListenerGlobals(t *testing.T) {
 	enginetest.RunTestModuleEngineBeforeListenerGlobals(t, et)
 }
+
+func TestInterpreter_superinstructions(t *testing.T) {
+	tests := []struct {
+		name         string
+		ops          []wazeroir.UnionOperation
+		expectedKind wazeroir.OperationKind
+	}{
+		{
+			name: "pick pick add i32",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(1, false), wazeroir.NewOperationPick(1, false),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI32),
+			},
+			expectedKind: operationKindPickPickAdd,
+		},
+		{
+			name: "pick pick add i64 - same value",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(0, false), wazeroir.NewOperationPick(0, false),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI64),
+			},
+			expectedKind: operationKindPickPickAdd,
+		},
+		{
+			name: "pick const add i32",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(1, false), wazeroir.NewOperationConstI32(math.MaxUint32),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI32),
+			},
+			expectedKind: operationKindPickConstAdd,
+		},
+		{
+			name: "pick const add i64",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(0, false), wazeroir.NewOperationConstI64(1),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI64),
+			},
+			expectedKind: operationKindPickConstAdd,
+		},
+		{
+			name: "label between picks isn't fused",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(1, false),
+				wazeroir.NewOperationLabel(wazeroir.NewLabel(wazeroir.LabelKindContinuation, 0)),
+				wazeroir.NewOperationPick(1, false),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI32),
+			},
+			expectedKind: wazeroir.OperationKindPick,
+		},
+		{
+			name: "label before add isn't fused",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(1, false), wazeroir.NewOperationPick(1, false),
+				wazeroir.NewOperationLabel(wazeroir.NewLabel(wazeroir.LabelKindContinuation, 0)),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeI32),
+			},
+			expectedKind: wazeroir.OperationKindPick,
+		},
+		{
+			name: "float add isn't fused",
+			ops: []wazeroir.UnionOperation{
+				wazeroir.NewOperationPick(0, false), wazeroir.NewOperationPick(1, false),
+				wazeroir.NewOperationAdd(wazeroir.UnsignedTypeF64),
+			},
+			expectedKind: wazeroir.OperationKindPick,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			// Run the operations with and without fusion, which must leave the same stack.
+			run := func(fuse bool) []uint64 {
+				body := append(append([]wazeroir.UnionOperation{}, tc.ops...),
+					wazeroir.UnionOperation{Kind: wazeroir.OperationKindBr, U1: uint64(math.MaxUint64)})
+				if fuse {
+					fuseOperations(body)
+					require.Equal(t, tc.expectedKind, body[0].Kind)
+				}
+				ce := &callEngine{stack: []uint64{0xffff_ffff_0000_0002, 0x0000_0001_ffff_ffff}}
+				f := &function{
+					moduleInstance: &wasm.ModuleInstance{Engine: &moduleEngine{}},
+					parent:         &compiledFunction{body: body},
+				}
+				ce.callNativeFunc(testCtx, &wasm.ModuleInstance{}, f)
+				return ce.stack
+			}
+			require.Equal(t, run(false), run(true))
+		})
+	}
+}
@@ -94,3 +94,8 @@ func (DirFile) Pwrite([]byte, int64) (int, syscall.Errno) {
 func (DirFile) Truncate(int64) syscall.Errno {
 	return syscall.EISDIR
 }
+
+// Allocate implements File.Allocate
+func (DirFile) Allocate(int64, int64) syscall.Errno {
+	return syscall.EISDIR
+}
@@ -282,6 +282,26 @@ type File interface {
 	//   - Windows does not error when calling Truncate on a closed file.
 	Truncate(size int64) syscall.Errno
 
+	// Allocate ensures disk space is allocated for the range of `length`
+	// bytes starting at `offset`, extending the file if needed. Unlike
+	// Truncate, this never shrinks the file.
+	//
+	// # Errors
+	//
+	// A zero syscall.Errno is success. The below are expected otherwise:
+	//   - syscall.ENOSYS: the implementation does not support this function.
+	//   - syscall.EBADF: the file or directory was closed or not writeable.
+	//   - syscall.EINVAL: `offset` is negative or `length` is not positive.
+	//   - syscall.EISDIR: the file was a directory.
+	//
+	// # Notes
+	//
+	//   - This is like `posix_fallocate` in POSIX. See
+	//     https://pubs.opengroup.org/onlinepubs/9699919799/functions/posix_fallocate.html
+	//   - On platforms without a way to reserve space, this extends the file
+	//     with Truncate, so the new range may be sparse.
+	Allocate(offset, length int64) syscall.Errno
+
 	// Sync synchronizes changes to the file.
 	//
 	// # Errors
@@ -370,6 +390,44 @@ type File interface {
 	//     cannot use this to update timestamps on a directory (syscall.EPERM).
 	Utimens(times *[2]syscall.Timespec) syscall.Errno
 
+	// Lock applies an advisory lock to the whole file, replacing any lock
+	// already held via this file.
+	//
+	// # Parameters
+	//
+	// The `exclusive` parameter requests an exclusive (write) lock instead of
+	// a shared (read) one. When `nonblock` is true, this returns
+	// syscall.EAGAIN instead of waiting for a conflicting lock to release.
+	//
+	// # Errors
+	//
+	// A zero syscall.Errno is success. The below are expected otherwise:
+	//   - syscall.ENOSYS: the implementation does not support this function.
+	//   - syscall.EBADF: the file or directory was closed.
+	//   - syscall.EAGAIN: `nonblock` and the file is locked by another.
+	//
+	// # Notes
+	//
+	//   - This is like `flock` with LOCK_SH or LOCK_EX in BSD, and LockFileEx
+	//     on Windows. See https://man7.org/linux/man-pages/man2/flock.2.html
+	//   - Windows locks are mandatory, so conflicting reads or writes from
+	//     other processes fail until Unlock.
+	Lock(exclusive, nonblock bool) syscall.Errno
+
+	// Unlock releases any lock held via Lock.
+	//
+	// # Errors
+	//
+	// A zero syscall.Errno is success. The below are expected otherwise:
+	//   - syscall.ENOSYS: the implementation does not support this function.
+	//   - syscall.EBADF: the file or directory was closed.
+	//
+	// # Notes
+	//
+	//   - This is like `flock` with LOCK_UN in BSD, and UnlockFileEx on
+	//     Windows. Closing the file also releases its lock.
+	Unlock() syscall.Errno
+
 	// Close closes the underlying file.
 	//
 	// A zero syscall.Errno is returned if unimplemented or success.
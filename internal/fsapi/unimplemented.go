@@ -176,6 +176,11 @@ func (UnimplementedFile) Truncate(int64) syscall.Errno {
 	return syscall.ENOSYS
 }
 
+// Allocate implements File.Allocate
+func (UnimplementedFile) Allocate(int64, int64) syscall.Errno {
+	return syscall.ENOSYS
+}
+
 // Sync implements File.Sync
 func (UnimplementedFile) Sync() syscall.Errno {
 	return 0 // not syscall.ENOSYS
@@ -201,5 +206,15 @@ func (UnimplementedFile) Utimens(*[2]syscall.Timespec) syscall.Errno {
 	return syscall.ENOSYS
 }
 
+// Lock implements File.Lock
+func (UnimplementedFile) Lock(bool, bool) syscall.Errno {
+	return syscall.ENOSYS
+}
+
+// Unlock implements File.Unlock
+func (UnimplementedFile) Unlock() syscall.Errno {
+	return syscall.ENOSYS
+}
+
 // Close implements File.Close
 func (UnimplementedFile) Close() (errno syscall.Errno) { return }
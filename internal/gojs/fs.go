@@ -97,7 +97,8 @@ func (o *jsfsOpen) invoke(ctx context.Context, mod api.Module, args ...interface
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 
-	fd, errno := fsc.OpenFile(fsc.RootFS(), path, int(flags), perm)
+	fs, path := fsc.ResolvePath(path)
+	fd, errno := fsc.OpenFile(fs, path, int(flags), perm)
 
 	return callback.invoke(ctx, mod, goos.RefJsfs, maybeError(errno), fd) // note: error first
 }
@@ -121,7 +122,8 @@ func (s *jsfsStat) invoke(ctx context.Context, mod api.Module, args ...interface
 func syscallStat(mod api.Module, path string) (*jsSt, error) {
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 
-	if st, errno := fsc.RootFS().Stat(path); errno != 0 {
+	fs, path := fsc.ResolvePath(path)
+	if st, errno := fs.Stat(path); errno != 0 {
 		return nil, errno
 	} else {
 		return newJsSt(st), nil
@@ -148,7 +150,8 @@ func (l *jsfsLstat) invoke(ctx context.Context, mod api.Module, args ...interfac
 func syscallLstat(mod api.Module, path string) (*jsSt, error) {
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 
-	if st, errno := fsc.RootFS().Lstat(path); errno != 0 {
+	fs, path := fsc.ResolvePath(path)
+	if st, errno := fs.Lstat(path); errno != 0 {
 		return nil, errno
 	} else {
 		return newJsSt(st), nil
@@ -319,7 +322,8 @@ func syscallReaddir(_ context.Context, mod api.Module, name string) (*objectArra
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
 
 	// don't allocate a file descriptor
-	f, errno := fsc.RootFS().OpenFile(name, os.O_RDONLY, 0)
+	fs, name := fsc.ResolvePath(name)
+	f, errno := fs.OpenFile(name, os.O_RDONLY, 0)
 	if errno != 0 {
 		return nil, errno
 	}
@@ -349,7 +353,7 @@ func (m *jsfsMkdir) invoke(ctx context.Context, mod api.Module, args ...interfac
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	root := fsc.RootFS()
+	root, path := fsc.ResolvePath(path)
 
 	var fd int32
 	var errno syscall.Errno
@@ -376,7 +380,8 @@ func (r *jsfsRmdir) invoke(ctx context.Context, mod api.Module, args ...interfac
 	callback := args[1].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Rmdir(path)
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Rmdir(path)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -395,11 +400,22 @@ func (r *jsfsRename) invoke(ctx context.Context, mod api.Module, args ...interfa
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Rename(from, to)
+	errno := syscallRename(fsc, from, to)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
 
+// syscallRename is like syscall.Rename, except it returns syscall.EXDEV if
+// the paths are in different pre-opens.
+func syscallRename(fsc *internalsys.FSContext, from, to string) syscall.Errno {
+	fromFS, from := fsc.ResolvePath(from)
+	toFS, to := fsc.ResolvePath(to)
+	if fromFS != toFS {
+		return syscall.EXDEV
+	}
+	return fromFS.Rename(from, to)
+}
+
 // jsfsUnlink implements jsFn for the following
 //
 //	_, err := fsCall("unlink", path) // syscall.Unlink
@@ -412,7 +428,8 @@ func (u *jsfsUnlink) invoke(ctx context.Context, mod api.Module, args ...interfa
 	callback := args[1].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Unlink(path)
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Unlink(path)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -434,7 +451,8 @@ func (u *jsfsUtimes) invoke(ctx context.Context, mod api.Module, args ...interfa
 	times := [2]syscall.Timespec{
 		syscall.NsecToTimespec(atimeSec * 1e9), syscall.NsecToTimespec(mtimeSec * 1e9),
 	}
-	errno := fsc.RootFS().Utimens(path, &times, true)
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Utimens(path, &times, true)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -452,7 +470,8 @@ func (c *jsfsChmod) invoke(ctx context.Context, mod api.Module, args ...interfac
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Chmod(path, mode)
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Chmod(path, mode)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -493,7 +512,8 @@ func (c *jsfsChown) invoke(ctx context.Context, mod api.Module, args ...interfac
 	callback := args[3].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Chown(path, int(uid), int(gid))
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Chown(path, int(uid), int(gid))
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -535,7 +555,8 @@ func (l *jsfsLchown) invoke(ctx context.Context, mod api.Module, args ...interfa
 	callback := args[3].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Lchown(path, int(uid), int(gid))
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Lchown(path, int(uid), int(gid))
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -553,7 +574,8 @@ func (t *jsfsTruncate) invoke(ctx context.Context, mod api.Module, args ...inter
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Truncate(path, length)
+	fs, path := fsc.ResolvePath(path)
+	errno := fs.Truncate(path, length)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -592,7 +614,8 @@ func (r *jsfsReadlink) invoke(ctx context.Context, mod api.Module, args ...inter
 	callback := args[1].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	dst, errno := fsc.RootFS().Readlink(path)
+	fs, path := fsc.ResolvePath(path)
+	dst, errno := fs.Readlink(path)
 
 	return callback.invoke(ctx, mod, goos.RefJsfs, maybeError(errno), dst) // note: error first
 }
@@ -611,11 +634,22 @@ func (l *jsfsLink) invoke(ctx context.Context, mod api.Module, args ...interface
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Link(path, link)
+	errno := syscallLink(fsc, path, link)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
 
+// syscallLink is like syscall.Link, except it returns syscall.EXDEV if the
+// paths are in different pre-opens.
+func syscallLink(fsc *internalsys.FSContext, path, link string) syscall.Errno {
+	fs, path := fsc.ResolvePath(path)
+	linkFS, link := fsc.ResolvePath(link)
+	if fs != linkFS {
+		return syscall.EXDEV
+	}
+	return fs.Link(path, link)
+}
+
 // jsfsSymlink implements jsFn for the following
 //
 //	_, err := fsCall("symlink", path, link) // syscall.Symlink
@@ -629,7 +663,8 @@ func (s *jsfsSymlink) invoke(ctx context.Context, mod api.Module, args ...interf
 	callback := args[2].(funcWrapper)
 
 	fsc := mod.(*wasm.ModuleInstance).Sys.FS()
-	errno := fsc.RootFS().Symlink(dst, link)
+	fs, link := fsc.ResolvePath(link)
+	errno := fs.Symlink(dst, link)
 
 	return jsfsInvoke(ctx, mod, callback, errno)
 }
@@ -0,0 +1,54 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// sumWasm exports "sum", which adds the integers below its param in a loop of
+// local.get, i32.const and i32.add, the sequences fused by the interpreter.
+var sumWasm = binaryencoding.EncodeModule(&wasm.Module{
+	TypeSection: []wasm.FunctionType{{
+		Params:            []wasm.ValueType{wasm.ValueTypeI32},
+		Results:           []wasm.ValueType{wasm.ValueTypeI32},
+		ParamNumInUint64:  1,
+		ResultNumInUint64: 1,
+	}},
+	FunctionSection: []wasm.Index{0},
+	CodeSection: []wasm.Code{{
+		LocalTypes: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32}, // i, sum
+		Body: []byte{
+			wasm.OpcodeLoop, 0x40,
+			// sum = sum + i
+			wasm.OpcodeLocalGet, 2, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Add, wasm.OpcodeLocalSet, 2,
+			// i = i + 1; if i != n, continue.
+			wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Add, wasm.OpcodeLocalTee, 1,
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Ne, wasm.OpcodeBrIf, 0,
+			wasm.OpcodeEnd,
+			wasm.OpcodeLocalGet, 2,
+			wasm.OpcodeEnd,
+		},
+	}},
+	ExportSection: []wasm.Export{{Name: "sum", Type: wasm.ExternTypeFunc, Index: 0}},
+})
+
+func BenchmarkInterpreter_superinstructions(b *testing.B) {
+	r := wazero.NewRuntimeWithConfig(testCtx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(testCtx)
+
+	mod, err := r.Instantiate(testCtx, sumWasm)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sum := mod.ExportedFunction("sum")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = sum.Call(testCtx, 10000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package conformance runs the same corpus of behavioral tests on each engine,
+// reporting the outcome of all engines when any differs from the expected one.
+// This catches divergence between engines, such as when a new one is added.
+//
+// By default, all engines supported on the host run. To select engines, set
+// the environment variable WAZERO_CONFORMANCE_ENGINES to a comma-separated
+// list of their names, e.g. "interpreter".
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/platform"
+	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
+var testCtx = context.WithValue(context.Background(), struct{}{}, "arbitrary")
+
+// enginesEnv is the environment variable which selects the engines to run.
+const enginesEnv = "WAZERO_CONFORMANCE_ENGINES"
+
+type engine struct {
+	name      string
+	newConfig func() wazero.RuntimeConfig
+	supported func() bool
+}
+
+// engines are all the engines, in the order they are reported.
+var engines = []engine{
+	{name: "interpreter", newConfig: wazero.NewRuntimeConfigInterpreter, supported: func() bool { return true }},
+	{name: "compiler", newConfig: wazero.NewRuntimeConfigCompiler, supported: platform.CompilerSupported},
+}
+
+// testCase is a behavioral test of the corpus.
+type testCase struct {
+	name string
+	// module exports the function "f", which is called with params. It can
+	// import the functions of wasi_snapshot_preview1.
+	module   *wasm.Module
+	params   []uint64
+	expected outcome
+}
+
+// outcome is what an engine observably did, compared as a string so that
+// the reports of all engines line up.
+type outcome struct {
+	results []uint64
+	// err is the first line of the error, excluding the stack trace.
+	err    string
+	stdout string
+}
+
+// String implements fmt.Stringer
+func (o outcome) String() string {
+	var ret string
+	if o.err != "" {
+		ret = fmt.Sprintf("error=%q", o.err)
+	} else {
+		ret = fmt.Sprintf("results=%#x", o.results)
+	}
+	if o.stdout != "" {
+		ret += fmt.Sprintf(" stdout=%q", o.stdout)
+	}
+	return ret
+}
+
+func TestConformance(t *testing.T) {
+	selected := selectEngines(t)
+	for _, tt := range corpus {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected := tc.expected.String()
+			var report strings.Builder
+			diverged := false
+			for _, e := range selected {
+				actual := run(e.newConfig(), &tc).String()
+				diverged = diverged || actual != expected
+				fmt.Fprintf(&report, "\t%-12s %s\n", e.name+":", actual)
+			}
+			if diverged {
+				t.Errorf("unexpected outcome\n\t%-12s %s\n%s", "expected:", expected, report.String())
+			}
+		})
+	}
+}
+
+// selectEngines returns the engines named in enginesEnv, or all supported
+// engines if it is empty.
+func selectEngines(t *testing.T) (selected []engine) {
+	names := os.Getenv(enginesEnv)
+	if names == "" {
+		for _, e := range engines {
+			if e.supported() {
+				selected = append(selected, e)
+			}
+		}
+		return
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		e, ok := findEngine(strings.TrimSpace(name))
+		if !ok {
+			t.Fatalf("%s: unknown engine %q", enginesEnv, name)
+		} else if !e.supported() {
+			t.Skipf("%s: engine %q isn't supported on this host", enginesEnv, name)
+		}
+		selected = append(selected, e)
+	}
+	return
+}
+
+func findEngine(name string) (engine, bool) {
+	for _, e := range engines {
+		if e.name == name {
+			return e, true
+		}
+	}
+	return engine{}, false
+}
+
+// run instantiates tc.module in a new runtime, calls its function "f" and
+// returns the outcome.
+func run(config wazero.RuntimeConfig, tc *testCase) (o outcome) {
+	r := wazero.NewRuntimeWithConfig(testCtx, config)
+	defer r.Close(testCtx)
+	wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+	var stdout bytes.Buffer
+	defer func() { o.stdout = stdout.String() }()
+
+	mod, err := r.InstantiateWithConfig(testCtx, binaryencoding.EncodeModule(tc.module),
+		wazero.NewModuleConfig().WithStdout(&stdout))
+	if err != nil {
+		o.err = firstLine(err)
+		return
+	}
+	if o.results, err = mod.ExportedFunction("f").Call(testCtx, tc.params...); err != nil {
+		o.err = firstLine(err)
+	}
+	return
+}
+
+func firstLine(err error) string {
+	s := err.Error()
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
@@ -0,0 +1,231 @@
+package conformance
+
+import (
+	"math"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+const (
+	i32, i64, f64 = wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeF64
+	// blockTypeEmpty is the type of a block without params or results.
+	blockTypeEmpty = 0x40
+)
+
+// corpus is the behavioral tests run on each engine. Add a case here when
+// engines are found to diverge.
+var corpus = []testCase{
+	// numeric
+	{
+		name:     "i32.add wraps",
+		module:   function([]wasm.ValueType{i32, i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Add, wasm.OpcodeEnd),
+		params:   []uint64{math.MaxUint32, 2},
+		expected: outcome{results: []uint64{1}},
+	},
+	{
+		name:     "i32.div_s by zero",
+		module:   function([]wasm.ValueType{i32, i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32DivS, wasm.OpcodeEnd),
+		params:   []uint64{1, 0},
+		expected: outcome{err: "wasm error: integer divide by zero"},
+	},
+	{
+		name:     "i32.div_s overflow",
+		module:   function([]wasm.ValueType{i32, i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32DivS, wasm.OpcodeEnd),
+		params:   []uint64{0x80000000, math.MaxUint32},
+		expected: outcome{err: "wasm error: integer overflow"},
+	},
+	{
+		name:     "i32.clz of zero",
+		module:   function([]wasm.ValueType{i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Clz, wasm.OpcodeEnd),
+		params:   []uint64{0},
+		expected: outcome{results: []uint64{32}},
+	},
+	{
+		name:     "i32.popcnt",
+		module:   function([]wasm.ValueType{i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Popcnt, wasm.OpcodeEnd),
+		params:   []uint64{0xf0f0f0f1},
+		expected: outcome{results: []uint64{17}},
+	},
+	{
+		name:     "i32.rotl by more than the width",
+		module:   function([]wasm.ValueType{i32, i32}, []wasm.ValueType{i32}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Rotl, wasm.OpcodeEnd),
+		params:   []uint64{0x80000001, 33},
+		expected: outcome{results: []uint64{3}},
+	},
+	{
+		name:     "i64.mul wraps",
+		module:   function([]wasm.ValueType{i64, i64}, []wasm.ValueType{i64}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI64Mul, wasm.OpcodeEnd),
+		params:   []uint64{1 << 63, 3},
+		expected: outcome{results: []uint64{1 << 63}},
+	},
+	{
+		name:     "f64.min of zeros",
+		module:   function([]wasm.ValueType{f64, f64}, []wasm.ValueType{f64}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeF64Min, wasm.OpcodeEnd),
+		params:   []uint64{0, math.Float64bits(math.Copysign(0, -1))},
+		expected: outcome{results: []uint64{1 << 63}},
+	},
+	{
+		name:     "i64.trunc_f64_s of NaN",
+		module:   function([]wasm.ValueType{f64}, []wasm.ValueType{i64}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeI64TruncF64S, wasm.OpcodeEnd),
+		params:   []uint64{math.Float64bits(math.NaN())},
+		expected: outcome{err: "wasm error: invalid conversion to integer"},
+	},
+	{
+		name:     "i64.trunc_f64_s overflow",
+		module:   function([]wasm.ValueType{f64}, []wasm.ValueType{i64}, nil, wasm.OpcodeLocalGet, 0, wasm.OpcodeI64TruncF64S, wasm.OpcodeEnd),
+		params:   []uint64{math.Float64bits(1e300)},
+		expected: outcome{err: "wasm error: integer overflow"},
+	},
+
+	// control flow
+	{
+		name:     "loop sums down to zero",
+		module:   sumModule(),
+		params:   []uint64{100},
+		expected: outcome{results: []uint64{5050}},
+	},
+	{
+		name:     "br_table in range",
+		module:   brTableModule(),
+		params:   []uint64{1},
+		expected: outcome{results: []uint64{20}},
+	},
+	{
+		name:     "br_table out of range uses the default",
+		module:   brTableModule(),
+		params:   []uint64{math.MaxUint32},
+		expected: outcome{results: []uint64{30}},
+	},
+	{
+		name:     "unreachable",
+		module:   function(nil, nil, nil, wasm.OpcodeUnreachable, wasm.OpcodeEnd),
+		expected: outcome{err: "wasm error: unreachable"},
+	},
+	{
+		name:     "unbounded recursion",
+		module:   function(nil, nil, nil, wasm.OpcodeCall, 0, wasm.OpcodeEnd),
+		expected: outcome{err: "wasm error: stack overflow"},
+	},
+
+	// memory
+	{
+		name: "i32.load8_u is little-endian",
+		module: withMemory(function([]wasm.ValueType{i32}, []wasm.ValueType{i32}, nil,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Store, 2, 8,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeI32Load8U, 0, 9,
+			wasm.OpcodeEnd)),
+		params:   []uint64{0x01020304},
+		expected: outcome{results: []uint64{3}},
+	},
+	{
+		name: "i64.load out of bounds",
+		module: withMemory(function([]wasm.ValueType{i32}, []wasm.ValueType{i64}, nil,
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeI64Load, 3, 0, wasm.OpcodeEnd)),
+		params:   []uint64{uint64(wasm.MemoryPageSize - 7)},
+		expected: outcome{err: "wasm error: out of bounds memory access"},
+	},
+	{
+		name: "memory.grow returns the previous size",
+		module: withMemory(function(nil, []wasm.ValueType{i32, i32, i32}, nil,
+			wasm.OpcodeI32Const, 1, wasm.OpcodeMemoryGrow, 0,
+			wasm.OpcodeI32Const, 1, wasm.OpcodeMemoryGrow, 0, // exceeds the max
+			wasm.OpcodeMemorySize, 0,
+			wasm.OpcodeEnd)),
+		expected: outcome{results: []uint64{1, math.MaxUint32, 2}},
+	},
+
+	// WASI
+	{
+		name: "fd_write to stdout",
+		module: wasiModule(
+			wasm.OpcodeI32Const, 1, // fd
+			wasm.OpcodeI32Const, 0, // iovs
+			wasm.OpcodeI32Const, 1, // iovs_len
+			wasm.OpcodeI32Const, 8, // result.nwritten
+			wasm.OpcodeCall, 0, wasm.OpcodeDrop, wasm.OpcodeEnd),
+		expected: outcome{stdout: "hello\n"},
+	},
+	{
+		name:     "proc_exit",
+		module:   wasiModule(wasm.OpcodeI32Const, 3, wasm.OpcodeCall, 1, wasm.OpcodeEnd),
+		expected: outcome{err: "module closed with exit_code(3)"},
+	},
+}
+
+// function returns a module which exports the function "f", with the given
+// signature, locals and body.
+func function(params, results, locals []wasm.ValueType, body ...byte) *wasm.Module {
+	return &wasm.Module{
+		TypeSection:     []wasm.FunctionType{{Params: params, Results: results}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection:     []wasm.Code{{LocalTypes: locals, Body: body}},
+		ExportSection:   []wasm.Export{{Name: "f", Type: wasm.ExternTypeFunc, Index: 0}},
+	}
+}
+
+// withMemory adds a memory of one page, which can grow to two, to m.
+func withMemory(m *wasm.Module) *wasm.Module {
+	m.MemorySection = &wasm.Memory{Min: 1, Cap: 1, Max: 2, IsMaxEncoded: true}
+	return m
+}
+
+// sumModule returns the sum of 1 to its param, counting it down in a loop.
+func sumModule() *wasm.Module {
+	return function([]wasm.ValueType{i32}, []wasm.ValueType{i32}, []wasm.ValueType{i32},
+		wasm.OpcodeBlock, blockTypeEmpty,
+		wasm.OpcodeLoop, blockTypeEmpty,
+		wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Eqz, wasm.OpcodeBrIf, 1,
+		wasm.OpcodeLocalGet, 1, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Add, wasm.OpcodeLocalSet, 1,
+		wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Sub, wasm.OpcodeLocalSet, 0,
+		wasm.OpcodeBr, 0,
+		wasm.OpcodeEnd,
+		wasm.OpcodeEnd,
+		wasm.OpcodeLocalGet, 1,
+		wasm.OpcodeEnd)
+}
+
+// brTableModule returns 10, 20 or 30 for its param 0, 1 or otherwise.
+func brTableModule() *wasm.Module {
+	return function([]wasm.ValueType{i32}, []wasm.ValueType{i32}, nil,
+		wasm.OpcodeBlock, blockTypeEmpty,
+		wasm.OpcodeBlock, blockTypeEmpty,
+		wasm.OpcodeBlock, blockTypeEmpty,
+		wasm.OpcodeLocalGet, 0, wasm.OpcodeBrTable, 2, 0, 1, 2,
+		wasm.OpcodeEnd,
+		wasm.OpcodeI32Const, 10, wasm.OpcodeReturn,
+		wasm.OpcodeEnd,
+		wasm.OpcodeI32Const, 20, wasm.OpcodeReturn,
+		wasm.OpcodeEnd,
+		wasm.OpcodeI32Const, 30,
+		wasm.OpcodeEnd)
+}
+
+// wasiModule returns a module which imports "fd_write" and "proc_exit" as
+// functions 0 and 1, and exports the function "f" with the given body. Its
+// memory begins with an iovec of "hello\n" for "fd_write".
+func wasiModule(body ...byte) *wasm.Module {
+	return &wasm.Module{
+		TypeSection: []wasm.FunctionType{
+			{Params: []wasm.ValueType{i32, i32, i32, i32}, Results: []wasm.ValueType{i32}},
+			{Params: []wasm.ValueType{i32}},
+			{},
+		},
+		ImportSection: []wasm.Import{
+			{Module: "wasi_snapshot_preview1", Name: "fd_write", Type: wasm.ExternTypeFunc, DescFunc: 0},
+			{Module: "wasi_snapshot_preview1", Name: "proc_exit", Type: wasm.ExternTypeFunc, DescFunc: 1},
+		},
+		FunctionSection: []wasm.Index{2},
+		CodeSection:     []wasm.Code{{Body: body}},
+		MemorySection:   &wasm.Memory{Min: 1, Cap: 1, Max: 1},
+		ExportSection: []wasm.Export{
+			{Name: "f", Type: wasm.ExternTypeFunc, Index: 2},
+			{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+		},
+		DataSection: []wasm.DataSegment{{
+			OffsetExpression: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: leb128.EncodeInt32(0)},
+			// iovec{buf: 16, len: 6}, then nwritten at 8, then the buffer.
+			Init: append([]byte{16, 0, 0, 0, 6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, "hello\n"...),
+		}},
+	}
+}
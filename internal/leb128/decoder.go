@@ -0,0 +1,83 @@
+package leb128
+
+import "io"
+
+// Decoder decodes consecutive LEB128 values from a byte slice.
+//
+// Unlike LoadUint32 and friends, this tracks the offset of the next value, so
+// callers decoding several immediates in a row don't need to re-slice the
+// buffer for each of them. The zero value is an empty Decoder, and Reset
+// re-uses it for another buffer without allocating.
+type Decoder struct {
+	buf    []byte
+	offset uint64
+}
+
+// Reset makes the Decoder read buf starting at offset.
+func (d *Decoder) Reset(buf []byte, offset uint64) {
+	d.buf, d.offset = buf, offset
+}
+
+// Offset returns the offset in the buffer of the next value to decode.
+func (d *Decoder) Offset() uint64 {
+	return d.offset
+}
+
+// Uint32 decodes an unsigned 32-bit integer. On error, the offset is unchanged.
+func (d *Decoder) Uint32() (ret uint32, err error) {
+	// Derived from https://github.com/golang/go/blob/go1.20/src/encoding/binary/varint.go
+	// with the modification on the overflow handling tailored for 32-bits.
+	var s uint32
+	for i := d.offset; i < d.offset+maxVarintLen32; i++ {
+		if i >= uint64(len(d.buf)) {
+			return 0, io.EOF
+		}
+		b := d.buf[i]
+		if b < 0x80 {
+			// Unused bits must be all zero.
+			if i == d.offset+maxVarintLen32-1 && (b&0xf0) > 0 {
+				return 0, errOverflow32
+			}
+			d.offset = i + 1
+			return ret | uint32(b)<<s, nil
+		}
+		ret |= (uint32(b) & 0x7f) << s
+		s += 7
+	}
+	return 0, errOverflow32
+}
+
+// Int33 decodes a signed 33-bit integer, such as wasm.BlockType. On error, the
+// offset is unchanged.
+//
+// See DecodeInt33AsInt64
+func (d *Decoder) Int33() (ret int64, err error) {
+	var num uint64
+	if ret, num, err = decodeInt33(d.nextByte); err == nil {
+		d.offset += num
+	}
+	return
+}
+
+// Skip advances past the next value, regardless of its type. On error, the
+// offset is unchanged.
+func (d *Decoder) Skip() error {
+	for i := d.offset; i < d.offset+maxVarintLen64; i++ {
+		if i >= uint64(len(d.buf)) {
+			return io.EOF
+		}
+		if d.buf[i] < 0x80 {
+			d.offset = i + 1
+			return nil
+		}
+	}
+	return errOverflow64
+}
+
+// nextByte implements nextByte relative to the current offset.
+func (d *Decoder) nextByte(i int) (byte, error) {
+	if pos := d.offset + uint64(i); pos < uint64(len(d.buf)) {
+		return d.buf[pos], nil
+	}
+	return 0, io.EOF
+}
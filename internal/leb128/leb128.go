@@ -203,11 +203,25 @@ func decodeInt32(next nextByte) (ret int32, bytesRead uint64, err error) {
 //
 // See https://webassembly.github.io/spec/core/binary/instructions.html#control-instructions
 func DecodeInt33AsInt64(r io.ByteReader) (ret int64, bytesRead uint64, err error) {
+	return decodeInt33(func(_ int) (byte, error) { return r.ReadByte() })
+}
+
+// LoadInt33AsInt64 is like DecodeInt33AsInt64, except it reads from the start of buf.
+func LoadInt33AsInt64(buf []byte) (ret int64, bytesRead uint64, err error) {
+	return decodeInt33(func(i int) (byte, error) {
+		if i >= len(buf) {
+			return 0, io.EOF
+		}
+		return buf[i], nil
+	})
+}
+
+func decodeInt33(next nextByte) (ret int64, bytesRead uint64, err error) {
 	var shift int
 	var b int64
 	var rb byte
 	for shift < 35 {
-		rb, err = r.ReadByte()
+		rb, err = next(int(bytesRead))
 		if err != nil {
 			return 0, 0, fmt.Errorf("readByte failed: %w", err)
 		}
@@ -37,6 +37,10 @@ func TestLeb128NoAlloc(t *testing.T) {
 		result := testing.Benchmark(BenchmarkDecodeInt64)
 		require.Zero(t, result.AllocsPerOp())
 	})
+	t.Run("Decoder", func(t *testing.T) {
+		result := testing.Benchmark(BenchmarkDecoder)
+		require.Zero(t, result.AllocsPerOp())
+	})
 }
 
 func BenchmarkLoadUint32(b *testing.B) {
@@ -117,3 +121,19 @@ func BenchmarkDecodeInt64(b *testing.B) {
 		r.Reset(data)
 	}
 }
+
+func BenchmarkDecoder(b *testing.B) {
+	b.ReportAllocs()
+	// Two consecutive immediates, like the alignment and offset of a load.
+	data := []byte{0x80, 0x80, 0x80, 0x4f, 0x80, 0x80, 0x80, 0x4f}
+	var d Decoder
+	for i := 0; i < b.N; i++ {
+		d.Reset(data, 0)
+		if _, err := d.Uint32(); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := d.Uint32(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -202,6 +202,11 @@ func TestDecodeInt33AsInt64(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, c.exp, actual)
 		require.Equal(t, uint64(len(c.bytes)), num)
+
+		actual, num, err = LoadInt33AsInt64(c.bytes)
+		require.NoError(t, err)
+		require.Equal(t, c.exp, actual)
+		require.Equal(t, uint64(len(c.bytes)), num)
 	}
 }
 
@@ -228,3 +233,74 @@ func TestDecodeInt64(t *testing.T) {
 		require.Equal(t, uint64(len(c.bytes)), num)
 	}
 }
+
+func TestDecoder_Uint32(t *testing.T) {
+	for _, c := range []struct {
+		bytes  []byte
+		exp    uint32
+		expErr bool
+	}{
+		{bytes: []byte{0x00}, exp: 0},
+		{bytes: []byte{0x80, 0x7f}, exp: 16256},
+		{bytes: []byte{0xe5, 0x8e, 0x26}, exp: 624485},
+		{bytes: []byte{0xff, 0xff, 0xff, 0xff, 0xf}, exp: math.MaxUint32},
+		{bytes: []byte{0x80, 0x80}, expErr: true},
+		{bytes: []byte{0x82, 0x80, 0x80, 0x80, 0x70}, expErr: true},
+		{bytes: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, expErr: true},
+	} {
+		// Prefix the value with a byte to ensure the offset is honored.
+		var d Decoder
+		d.Reset(append([]byte{0xff}, c.bytes...), 1)
+		actual, err := d.Uint32()
+		if c.expErr {
+			require.Error(t, err)
+			require.Equal(t, uint64(1), d.Offset())
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, c.exp, actual)
+			require.Equal(t, uint64(len(c.bytes))+1, d.Offset())
+		}
+	}
+}
+
+func TestDecoder_Int33(t *testing.T) {
+	for _, c := range []struct {
+		bytes  []byte
+		exp    int64
+		expErr bool
+	}{
+		{bytes: []byte{0x40}, exp: -64},
+		{bytes: []byte{0x7f}, exp: -1},
+		{bytes: []byte{0x80, 0x7f}, exp: -128},
+		{bytes: []byte{0x80, 0x80, 0x80, 0x80, 0x50}, expErr: true},
+		{bytes: []byte{0x80}, expErr: true},
+	} {
+		var d Decoder
+		d.Reset(c.bytes, 0)
+		actual, err := d.Int33()
+		if c.expErr {
+			require.Error(t, err)
+			require.Zero(t, d.Offset())
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, c.exp, actual)
+			require.Equal(t, uint64(len(c.bytes)), d.Offset())
+		}
+	}
+}
+
+func TestDecoder_Skip(t *testing.T) {
+	var d Decoder
+	d.Reset([]byte{0x04, 0x80, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x80}, 0)
+	require.NoError(t, d.Skip())
+	require.Equal(t, uint64(1), d.Offset())
+	require.NoError(t, d.Skip())
+	require.Equal(t, uint64(3), d.Offset())
+	require.NoError(t, d.Skip()) // The longest, 64-bit value.
+	require.Equal(t, uint64(13), d.Offset())
+	require.Error(t, d.Skip()) // Truncated.
+	require.Equal(t, uint64(13), d.Offset())
+
+	d.Reset(bytes.Repeat([]byte{0x80}, 11), 0)
+	require.EqualError(t, d.Skip(), errOverflow64.Error())
+}
@@ -24,6 +24,7 @@ const (
 	ValueTypeF64                 = api.ValueTypeF64
 	ValueTypeV128      ValueType = 0x7b // same as wasm.ValueTypeV128
 	ValueTypeFuncref   ValueType = 0x70 // same as wasm.ValueTypeFuncref
+	ValueTypeAnyref    ValueType = 0x6e // same as wasm.ValueTypeAnyref
 	ValueTypeExternref           = api.ValueTypeExternref
 
 	// ValueTypeMemI32 is a non-standard type which writes ValueTypeI32 from the memory offset.
@@ -204,7 +205,7 @@ func ValWriterForType(vt ValueType) ValWriter {
 		return writeF64
 	case ValueTypeV128:
 		return writeV128
-	case ValueTypeExternref, ValueTypeFuncref:
+	case ValueTypeExternref, ValueTypeFuncref, ValueTypeAnyref:
 		return writeRef
 	case ValueTypeMemI32:
 		return writeMemI32
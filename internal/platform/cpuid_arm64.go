@@ -0,0 +1,34 @@
+package platform
+
+// The values match the Linux HWCAP bits of the same features, so that they can
+// be queried from the auxiliary vector directly.
+const (
+	// CpuFeatureArm64PMULL is the flag to query CpuFeatureFlags.Has for the
+	// polynomial multiply long instructions (PMULL and PMULL2) of FEAT_PMULL.
+	CpuFeatureArm64PMULL = uint64(1) << 4
+	// CpuFeatureArm64Atomic is the flag to query CpuFeatureFlags.Has for the
+	// Large System Extensions (LSE) atomic instructions of ARMv8.1, e.g. CAS and LDADD.
+	CpuFeatureArm64Atomic = uint64(1) << 8
+	// CpuFeatureArm64FlagM is the flag to query CpuFeatureFlags.Has for the
+	// flag manipulation instructions of ARMv8.4 (FEAT_FlagM), e.g. CFINV and RMIF.
+	CpuFeatureArm64FlagM = uint64(1) << 27
+)
+
+// CpuFeatures exposes the capabilities for this CPU, queried via the Has method.
+var CpuFeatures CpuFeatureFlags = &cpuFeatureFlags{flags: loadCpuFeatureFlags()}
+
+// cpuFeatureFlags implements CpuFeatureFlags interface
+type cpuFeatureFlags struct {
+	flags uint64
+}
+
+// Has implements the same method on the CpuFeatureFlags interface
+func (f *cpuFeatureFlags) Has(cpuFeature uint64) bool {
+	return (f.flags & cpuFeature) != 0
+}
+
+// HasExtra implements the same method on the CpuFeatureFlags interface.
+// There are no extra flags on arm64, so this always returns false.
+func (f *cpuFeatureFlags) HasExtra(uint64) bool {
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build arm64 && !linux && !darwin
+
+package platform
+
+// loadCpuFeatureFlags returns zero, as there is no portable way to query
+// optional features on this platform. Only ARMv8.0 instructions can be used.
+func loadCpuFeatureFlags() uint64 {
+	return 0
+}
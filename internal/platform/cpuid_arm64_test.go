@@ -0,0 +1,14 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestArm64CpuId_cpuHasFeature(t *testing.T) {
+	flags := cpuFeatureFlags{flags: CpuFeatureArm64Atomic}
+	require.True(t, flags.Has(CpuFeatureArm64Atomic))
+	require.False(t, flags.Has(CpuFeatureArm64PMULL))
+	require.False(t, flags.HasExtra(CpuFeatureArm64Atomic))
+}
@@ -0,0 +1,27 @@
+package platform
+
+import "syscall"
+
+// loadCpuFeatureFlags queries the "hw.optional" sysctl entries of each
+// feature. Apple silicon supports all of them, but older kernels may not
+// report them.
+func loadCpuFeatureFlags() (flags uint64) {
+	for name, flag := range map[string]uint64{
+		"hw.optional.arm.FEAT_PMULL":  CpuFeatureArm64PMULL,
+		"hw.optional.armv8_1_atomics": CpuFeatureArm64Atomic,
+		"hw.optional.arm.FEAT_FlagM":  CpuFeatureArm64FlagM,
+	} {
+		if sysctlEnabled(name) {
+			flags |= flag
+		}
+	}
+	return
+}
+
+// sysctlEnabled returns true if the integer sysctl entry is non-zero.
+func sysctlEnabled(name string) bool {
+	// syscall.Sysctl returns the raw bytes of the value, with trailing zeros
+	// trimmed, so any remaining byte means the integer isn't zero.
+	v, err := syscall.Sysctl(name)
+	return err == nil && len(v) > 0
+}
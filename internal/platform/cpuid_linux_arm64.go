@@ -0,0 +1,35 @@
+package platform
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+// atHWCap is the AT_HWCAP key of the auxiliary vector.
+const atHWCap = 16
+
+// loadCpuFeatureFlags reads AT_HWCAP from the auxiliary vector of this
+// process, or returns zero if it cannot be read.
+func loadCpuFeatureFlags() uint64 {
+	auxv, err := os.ReadFile("/proc/self/auxv")
+	if err != nil {
+		return 0
+	}
+	return hwcapFromAuxv(auxv)
+}
+
+// hwcapFromAuxv returns the AT_HWCAP value of a 64-bit auxiliary vector,
+// which is a sequence of key and value pairs, ending with a zero key.
+func hwcapFromAuxv(auxv []byte) uint64 {
+	for len(auxv) >= 16 {
+		key, val := binary.LittleEndian.Uint64(auxv), binary.LittleEndian.Uint64(auxv[8:])
+		switch key {
+		case 0:
+			return 0
+		case atHWCap:
+			return val
+		}
+		auxv = auxv[16:]
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package platform
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func Test_hwcapFromAuxv(t *testing.T) {
+	auxv := func(pairs ...uint64) []byte {
+		ret := make([]byte, 8*len(pairs))
+		for i, v := range pairs {
+			binary.LittleEndian.PutUint64(ret[8*i:], v)
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name     string
+		auxv     []byte
+		expected uint64
+	}{
+		{name: "empty", auxv: nil, expected: 0},
+		{
+			name:     "found",
+			auxv:     auxv(6 /* AT_PAGESZ */, 4096, atHWCap, CpuFeatureArm64Atomic|CpuFeatureArm64PMULL, 0, 0),
+			expected: CpuFeatureArm64Atomic | CpuFeatureArm64PMULL,
+		},
+		{name: "after end", auxv: auxv(0, 0, atHWCap, CpuFeatureArm64Atomic), expected: 0},
+		{name: "truncated", auxv: auxv(atHWCap)[:8], expected: 0},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, hwcapFromAuxv(tc.auxv))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package platform
 
 import (
+	"bufio"
+	crand "crypto/rand"
 	"io"
 	"math/rand"
 )
@@ -13,5 +15,24 @@ const seed = int64(42)
 
 // NewFakeRandSource returns a deterministic source of random values.
 func NewFakeRandSource() io.Reader {
+	return NewSeededRandSource(seed)
+}
+
+// NewSeededRandSource returns a deterministic source of random values, which
+// always returns the same bytes for the same seed.
+func NewSeededRandSource(seed int64) io.Reader {
 	return rand.New(rand.NewSource(seed))
 }
+
+// randBufferSize is the size of the buffer of NewSysRandSource. This is
+// large enough for many small reads, such as seeding a hash map, while
+// larger reads bypass it.
+const randBufferSize = 512
+
+// NewSysRandSource returns crypto/rand.Reader buffered, so that small reads
+// don't each need a system call.
+//
+// Note: The result is not safe for concurrent use.
+func NewSysRandSource() io.Reader {
+	return bufio.NewReaderSize(crand.Reader, randBufferSize)
+}
@@ -0,0 +1,17 @@
+package platform
+
+// Decommit zeroes b. Where supported, whole pages of it are returned to the
+// operating system instead of being written, so that they no longer count
+// towards the resident memory of the process until touched again.
+func Decommit(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	decommit(b)
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,26 @@
+package platform
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// decommit uses MADV_DONTNEED, which makes private anonymous pages read back
+// as zero, for the pages within b. Bytes of b outside those are zeroed.
+func decommit(b []byte) {
+	pageSize := uintptr(os.Getpagesize())
+	addr := uintptr(unsafe.Pointer(&b[0]))
+	start := (addr+pageSize-1)&^(pageSize-1) - addr
+	end := (addr+uintptr(len(b)))&^(pageSize-1) - addr
+	if start >= end || end > uintptr(len(b)) {
+		zero(b) // b doesn't span a whole page.
+		return
+	}
+
+	zero(b[:start])
+	zero(b[end:])
+	if err := syscall.Madvise(b[start:end], syscall.MADV_DONTNEED); err != nil {
+		zero(b[start:end])
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package platform
+
+// decommit zeroes b, as pages can't be decommitted portably. For example,
+// MADV_DONTNEED on darwin doesn't guarantee pages read back as zero.
+func decommit(b []byte) {
+	zero(b)
+}
@@ -0,0 +1,39 @@
+package platform
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestDecommit(t *testing.T) {
+	pageSize := os.Getpagesize()
+
+	tests := []struct {
+		name       string
+		start, end int
+	}{
+		{name: "empty", start: 1, end: 1},
+		{name: "less than a page", start: 1, end: 3},
+		{name: "unaligned", start: 1, end: 3*pageSize - 1},
+		{name: "whole buffer", start: 0, end: 4 * pageSize},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Repeat([]byte{0xff}, 4*pageSize)
+			Decommit(buf[tc.start:tc.end])
+
+			for i, b := range buf {
+				if i >= tc.start && i < tc.end {
+					require.Equal(t, byte(0), b, "byte %d", i)
+				} else {
+					require.Equal(t, byte(0xff), b, "byte %d", i)
+				}
+			}
+		})
+	}
+}
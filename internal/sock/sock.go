@@ -8,11 +8,34 @@ import (
 	"github.com/tetratelabs/wazero/internal/fsapi"
 )
 
+// SockOpt is a socket option which can be read or written by
+// TCPSock.GetSockOpt, TCPSock.SetSockOpt and the same on TCPConn.
+//
+// These are portable identifiers, not the numeric values of any platform.
+type SockOpt int
+
+const (
+	// SockOptReuseAddr is SO_REUSEADDR at level SOL_SOCKET.
+	SockOptReuseAddr SockOpt = iota
+	// SockOptKeepAlive is SO_KEEPALIVE at level SOL_SOCKET.
+	SockOptKeepAlive
+	// SockOptTCPNoDelay is TCP_NODELAY at level IPPROTO_TCP.
+	SockOptTCPNoDelay
+)
+
 // TCPSock is a pseudo-file representing a TCP socket.
 type TCPSock interface {
 	fsapi.File
 
 	Accept() (TCPConn, syscall.Errno)
+
+	// GetSockOpt is like `getsockopt` in POSIX. All options are boolean,
+	// so the result is zero when disabled or one when enabled.
+	GetSockOpt(opt SockOpt) (int, syscall.Errno)
+
+	// SetSockOpt is like `setsockopt` in POSIX. All options are boolean,
+	// so any non-zero value enables the option.
+	SetSockOpt(opt SockOpt, value int) syscall.Errno
 }
 
 // TCPConn is a pseudo-file representing a TCP connection.
@@ -23,6 +46,33 @@ type TCPConn interface {
 	Recvfrom(p []byte, flags int) (n int, errno syscall.Errno)
 
 	Shutdown(how int) syscall.Errno
+
+	// GetSockOpt is the same as TCPSock.GetSockOpt.
+	GetSockOpt(opt SockOpt) (int, syscall.Errno)
+
+	// SetSockOpt is the same as TCPSock.SetSockOpt.
+	SetSockOpt(opt SockOpt, value int) syscall.Errno
+
+	// LocalAddr is like `getsockname` in POSIX: it returns the address this
+	// end of the connection is bound to.
+	LocalAddr() (*net.TCPAddr, syscall.Errno)
+
+	// RemoteAddr is like `getpeername` in POSIX: it returns the address of
+	// the peer, or syscall.ENOTCONN if there is none.
+	RemoteAddr() (*net.TCPAddr, syscall.Errno)
+}
+
+// String implements fmt.Stringer
+func (o SockOpt) String() string {
+	switch o {
+	case SockOptReuseAddr:
+		return "SO_REUSEADDR"
+	case SockOptKeepAlive:
+		return "SO_KEEPALIVE"
+	case SockOptTCPNoDelay:
+		return "TCP_NODELAY"
+	}
+	return fmt.Sprintf("SockOpt(%d)", int(o))
 }
 
 // ConfigKey is a context.Context Value key. Its associated value should be a Config.
@@ -0,0 +1,156 @@
+package sys
+
+import (
+	"io"
+	"syscall"
+	"time"
+)
+
+// asyncReadBufSize is the maximum count of bytes an asyncReader reads ahead.
+const asyncReadBufSize = 4096
+
+// asyncReader reads from an io.Reader which may block and has no way to set a
+// deadline, such as a host reader used for stdin. Reads which must not block
+// are serviced ahead by a worker goroutine, so that PollRead can honor its
+// timeout and ReadNonblock can return syscall.EAGAIN.
+//
+// Each asyncReader has at most one read ahead in progress, so there is at most
+// one worker per reader.
+//
+// Note: This isn't safe for concurrent use, just like the file which uses it.
+type asyncReader struct {
+	r io.Reader
+
+	// closed is closed by Close, to release callers waiting for a read ahead.
+	// This is lazily initialized by readAhead.
+	closed chan struct{}
+
+	// pending is non-nil while a worker reads ahead, and is closed once it
+	// has set buf and err.
+	pending chan struct{}
+
+	// buf holds the bytes read ahead, which weren't yet returned by Read.
+	buf []byte
+
+	// err is the error of the read ahead, which wasn't yet returned by Read.
+	err error
+}
+
+// lener is implemented by in-memory readers, such as bytes.Buffer and
+// strings.Reader, which never block.
+type lener interface {
+	Len() int
+}
+
+// Read returns any bytes read ahead, otherwise reads directly. This blocks
+// until a pending read ahead completes.
+func (a *asyncReader) Read(p []byte) (int, error) {
+	if a.pending != nil {
+		select {
+		case <-a.pending:
+			a.pending = nil
+		case <-a.closed:
+			return 0, io.EOF
+		}
+	}
+	if len(a.buf) > 0 {
+		n := copy(p, a.buf)
+		a.buf = a.buf[n:]
+		return n, nil
+	} else if err := a.err; err != nil {
+		a.err = nil
+		return 0, err
+	}
+	return a.r.Read(p)
+}
+
+// ReadNonblock is like Read, except it returns syscall.EAGAIN instead of
+// blocking. In that case, a read ahead is pending.
+func (a *asyncReader) ReadNonblock(p []byte) (int, error) {
+	if _, ok := a.r.(lener); ok {
+		return a.r.Read(p)
+	}
+	if a.pending == nil && len(a.buf) == 0 && a.err == nil {
+		a.readAhead()
+	}
+	if a.pending != nil {
+		select {
+		case <-a.pending:
+			a.pending = nil
+		default:
+			return 0, syscall.EAGAIN
+		}
+		if len(a.buf) == 0 && a.err == nil {
+			return 0, syscall.EAGAIN // nothing was read, so don't block.
+		}
+	}
+	return a.Read(p)
+}
+
+// PollRead returns true once Read wouldn't block, waiting up to timeout for a
+// read ahead to complete. A nil timeout waits forever.
+func (a *asyncReader) PollRead(timeout *time.Duration) bool {
+	if _, ok := a.r.(lener); ok {
+		return true
+	}
+	if a.pending == nil {
+		if len(a.buf) > 0 || a.err != nil {
+			return true
+		}
+		a.readAhead()
+	}
+
+	// Once closed, Read returns io.EOF without blocking, so it is ready.
+	if timeout == nil {
+		select {
+		case <-a.pending:
+		case <-a.closed:
+		}
+		return true
+	} else if *timeout <= 0 {
+		select {
+		case <-a.pending:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t := time.NewTimer(*timeout)
+	defer t.Stop()
+	select {
+	case <-a.pending:
+		return true
+	case <-a.closed:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// Close releases callers waiting for a read ahead. The worker itself can't be
+// interrupted, so it exits once the read of the underlying reader returns.
+func (a *asyncReader) Close() {
+	if a.closed != nil {
+		select {
+		case <-a.closed: // already closed
+		default:
+			close(a.closed)
+		}
+	}
+}
+
+// readAhead offloads a read to a worker, closing pending when complete.
+func (a *asyncReader) readAhead() {
+	if a.closed == nil {
+		a.closed = make(chan struct{})
+	}
+	pending := make(chan struct{})
+	a.pending = pending
+	go func() {
+		buf := make([]byte, asyncReadBufSize)
+		n, err := a.r.Read(buf)
+		a.buf, a.err = buf[:n], err
+		close(pending)
+	}()
+}
@@ -0,0 +1,115 @@
+package sys
+
+import (
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestStdinFile_PollRead(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	f := &StdinFile{Reader: r}
+
+	// A reader without deadlines isn't ready until data is written.
+	timeout := 10 * time.Millisecond
+	ready, errno := f.PollRead(&timeout)
+	require.EqualErrno(t, 0, errno)
+	require.False(t, ready)
+
+	go func() { _, _ = w.Write([]byte("wazero")) }()
+
+	ready, errno = f.PollRead(nil)
+	require.EqualErrno(t, 0, errno)
+	require.True(t, ready)
+
+	// The data read ahead is returned by Read.
+	buf := make([]byte, 3)
+	n, errno := f.Read(buf)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, "waz", string(buf[:n]))
+	n, errno = f.Read(buf)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, "ero", string(buf[:n]))
+
+	// In-memory readers are always ready.
+	f = &StdinFile{Reader: strings.NewReader("")}
+	var zero time.Duration
+	ready, errno = f.PollRead(&zero)
+	require.EqualErrno(t, 0, errno)
+	require.True(t, ready)
+}
+
+func TestStdinFile_ReadNonblock(t *testing.T) {
+	r, w := io.Pipe()
+	f := &StdinFile{Reader: r}
+	require.False(t, f.IsNonblock())
+	require.EqualErrno(t, 0, f.SetNonblock(true))
+	require.True(t, f.IsNonblock())
+
+	buf := make([]byte, 8)
+	_, errno := f.Read(buf)
+	require.EqualErrno(t, syscall.EAGAIN, errno)
+
+	_, err := w.Write([]byte("wazero"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// Wait for the pending read to complete.
+	require.True(t, f.asyncReader().PollRead(nil))
+	n, errno := f.Read(buf)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, "wazero", string(buf[:n]))
+
+	// EOF isn't an error.
+	require.True(t, f.asyncReader().PollRead(nil))
+	n, errno = f.Read(buf)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, 0, n)
+}
+
+func TestStdinFile_Close(t *testing.T) {
+	// Readers which never return don't starve other readers.
+	for i := 0; i < 8; i++ {
+		r, w := io.Pipe()
+		defer w.Close()
+		f := &StdinFile{Reader: r}
+		var zero time.Duration
+		ready, errno := f.PollRead(&zero)
+		require.EqualErrno(t, 0, errno)
+		require.False(t, ready)
+		defer f.Close()
+	}
+
+	r, w := io.Pipe()
+	defer w.Close()
+	f := &StdinFile{Reader: r}
+	go func() { _, _ = w.Write([]byte("wazero")) }()
+	ready, errno := f.PollRead(nil)
+	require.EqualErrno(t, 0, errno)
+	require.True(t, ready)
+
+	// Close releases a caller waiting for a read ahead.
+	_, _ = f.Read(make([]byte, 8))
+	ready, errno = f.PollRead(new(time.Duration))
+	require.EqualErrno(t, 0, errno)
+	require.False(t, ready)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, errno := f.Read(make([]byte, 8))
+		require.EqualErrno(t, 0, errno)
+		require.Zero(t, n) // EOF
+	}()
+	require.EqualErrno(t, 0, f.Close())
+	<-done
+
+	ready, errno = f.PollRead(nil)
+	require.EqualErrno(t, 0, errno)
+	require.True(t, ready)
+}
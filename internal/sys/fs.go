@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/fs"
 	"net"
+	"strings"
 	"syscall"
 
 	"github.com/tetratelabs/wazero/internal/descriptor"
@@ -30,7 +31,10 @@ const (
 	FdPreopen
 )
 
-const modeDevice = fs.ModeDevice | 0o640
+const (
+	modeDevice     = fs.ModeDevice | 0o640
+	modeCharDevice = fs.ModeDevice | fs.ModeCharDevice | 0o640
+)
 
 // FileEntry maps a path to an open file in a file system.
 type FileEntry struct {
@@ -83,11 +87,23 @@ func (f *FileEntry) OpenDir(addDotEntries bool) (dir *Readdir, errno syscall.Err
 	}
 }
 
-const direntBufSize = 16
+const (
+	// direntBufSize is the default count of entries read from the directory
+	// at a time.
+	direntBufSize = 16
+
+	// maxDirentBufSize bounds the count of entries read at a time when the
+	// caller hints it needs more, e.g. due to a large guest buffer.
+	//
+	// This only bounds a single read: a larger buffer is still filled by
+	// reading more batches. The cap keeps the batch held per open directory
+	// small, while already making reads rare compared to direntBufSize.
+	maxDirentBufSize = 1024
+)
 
 // Readdir is the status of a prior fs.ReadDirFile call.
 type Readdir struct {
-	// cursor is the current position in the buffer.
+	// cursor is the current position in dirents.
 	cursor uint64
 
 	// countRead is the total count of files read including Dirents.
@@ -103,9 +119,9 @@ type Readdir struct {
 	//   countRead uint64
 	countRead uint64
 
-	// dirents is a fixed buffer of size direntBufSize. Notably,
-	// directory listing are not rewindable, so we keep entries around in case
-	// the caller mis-estimated their buffer and needs a few still cached.
+	// dirents is the last batch read from the directory. Notably, directory
+	// listing are not rewindable, so we keep entries around in case the
+	// caller mis-estimated their buffer and needs a few still cached.
 	//
 	// Note: This is wasi-specific and needs to be refactored.
 	// In wasi preview1, dot and dot-dot entries are required to exist, but the
@@ -113,11 +129,15 @@ type Readdir struct {
 	// stateful dir-entry-streams per file.
 	dirents []fsapi.Dirent
 
+	// batchSize is the count of entries to read in the next batch. This is
+	// between direntBufSize and maxDirentBufSize, and adjusted by Hint.
+	batchSize uint64
+
 	// dirInit seeks and reset the provider for dirents to the beginning
 	// and returns an initial batch (e.g. dot directories).
 	dirInit func() ([]fsapi.Dirent, syscall.Errno)
 
-	// dirReader fetches a new batch of direntBufSize elements.
+	// dirReader fetches a new batch of up to n elements.
 	dirReader func(n uint64) ([]fsapi.Dirent, syscall.Errno)
 }
 
@@ -126,7 +146,7 @@ func NewReaddir(
 	dirInit func() ([]fsapi.Dirent, syscall.Errno),
 	dirReader func(n uint64) ([]fsapi.Dirent, syscall.Errno),
 ) (*Readdir, syscall.Errno) {
-	d := &Readdir{dirReader: dirReader, dirInit: dirInit}
+	d := &Readdir{dirReader: dirReader, dirInit: dirInit, batchSize: direntBufSize}
 	return d, d.init()
 }
 
@@ -148,12 +168,12 @@ func (d *Readdir) init() syscall.Errno {
 	}
 	d.dirents = initialDirents
 	// Fill the buffer with more data.
-	count := direntBufSize - len(initialDirents)
+	count := d.batchSize - uint64(len(initialDirents))
 	if count == 0 {
 		// No need to fill up the buffer further.
 		return 0
 	}
-	dirents, errno := d.dirReader(uint64(count))
+	dirents, errno := d.dirReader(count)
 	if errno != 0 {
 		return errno
 	}
@@ -195,6 +215,22 @@ func synthesizeDotEntries(f *FileEntry) (result []fsapi.Dirent, errno syscall.Er
 	return result, 0
 }
 
+// Hint sets the count of entries the caller expects to read next, e.g. based
+// on the size of its buffer. This is used as the size of the following
+// batches read from the directory, so that listing a large directory into a
+// large buffer doesn't need as many reads.
+//
+// Note: The count is bounded by direntBufSize and maxDirentBufSize.
+func (d *Readdir) Hint(n uint64) {
+	switch {
+	case n < direntBufSize:
+		n = direntBufSize
+	case n > maxDirentBufSize:
+		n = maxDirentBufSize
+	}
+	d.batchSize = n
+}
+
 // Reset seeks the internal cursor to 0 and refills the buffer.
 func (d *Readdir) Reset() syscall.Errno {
 	if d.countRead == 0 {
@@ -235,13 +271,14 @@ func (d *Readdir) Rewind(cookie int64) syscall.Errno {
 		// https://github.com/WebAssembly/wasi-libc/blob/659ff414560721b1660a19685110e484a081c3d4/libc-bottom-half/cloudlibc/src/libc/dirent/rewinddir.c#L10-L12
 		return d.Reset()
 	case unsignedCookie < d.countRead:
-		if cookie/direntBufSize != int64(d.countRead)/direntBufSize {
+		back := d.countRead - unsignedCookie
+		if back > d.cursor {
 			// The cookie is not 0, but it points into a window before the current one.
 			return syscall.ENOSYS
 		}
 		// We are allowed to rewind back to a previous offset within the current window.
 		d.countRead = unsignedCookie
-		d.cursor = d.countRead % direntBufSize
+		d.cursor -= back
 		return 0
 	default:
 		// The cookie is valid.
@@ -255,13 +292,16 @@ func (d *Readdir) Peek() (*fsapi.Dirent, syscall.Errno) {
 	switch {
 	case d.cursor == uint64(len(d.dirents)):
 		// We're past the buf size, fill it up again.
-		dirents, errno := d.dirReader(direntBufSize)
+		dirents, errno := d.dirReader(d.batchSize)
 		if errno != 0 {
 			return nil, errno
 		}
-		d.dirents = append(d.dirents, dirents...)
+		if len(dirents) > 0 {
+			d.dirents = dirents
+			d.cursor = 0
+		}
 		fallthrough
-	default: // d.cursor < direntBufSize FIXME
+	default:
 		if d.cursor == uint64(len(d.dirents)) {
 			return nil, syscall.ENOENT
 		}
@@ -299,8 +339,8 @@ type FileTable = descriptor.Table[int32, *FileEntry]
 // RootFS returns a possibly unimplemented root filesystem. Any files that
 // should be added to the table should be inserted via InsertFile.
 //
-// TODO: This is only used by GOOS=js and tests: Remove when we remove GOOS=js
-// (after Go 1.22 is released).
+// Note: This is only used by tests. Host functions should use ResolvePath,
+// so that paths under other pre-opens are visible.
 func (c *FSContext) RootFS() fsapi.FS {
 	if rootFS := c.rootFS; rootFS == nil {
 		return fsapi.UnimplementedFS{}
@@ -309,11 +349,55 @@ func (c *FSContext) RootFS() fsapi.FS {
 	}
 }
 
+// ResolvePath returns the filesystem of the pre-open whose name is the
+// longest prefix of the absolute path, and the path relative to it. This is
+// like resolving the path with `openat` from that pre-open's descriptor.
+//
+// When no pre-open matches, the result is a possibly unimplemented
+// filesystem and the path unchanged, like RootFS.
+//
+// Note: This is used by GOOS=js, which has no descriptors to resolve paths
+// from. WASI guests resolve paths themselves.
+func (c *FSContext) ResolvePath(path string) (fsapi.FS, string) {
+	p := StripPrefixesAndTrailingSlash(path)
+
+	var match *FileEntry
+	var matchLen int
+	c.openedFiles.Range(func(_ int32, entry *FileEntry) bool {
+		if !entry.IsPreopen || entry.FS == nil {
+			return true // stdio or a socket
+		}
+		name := StripPrefixesAndTrailingSlash(entry.Name)
+		if name != "" && p != name && !strings.HasPrefix(p, name+"/") {
+			return true
+		}
+		if match == nil || len(name) > matchLen {
+			match, matchLen = entry, len(name)
+		}
+		return true
+	})
+
+	if match == nil {
+		return c.RootFS(), path
+	} else if matchLen == len(p) {
+		return match.FS, "/"
+	} else if matchLen == 0 {
+		return match.FS, "/" + p
+	}
+	return match.FS, p[matchLen:] // includes the leading slash
+}
+
 // LookupFile returns a file if it is in the table.
 func (c *FSContext) LookupFile(fd int32) (*FileEntry, bool) {
 	return c.openedFiles.Lookup(fd)
 }
 
+// Snapshot returns the open files with their file descriptors, in ascending
+// order of file descriptor.
+func (c *FSContext) Snapshot() []descriptor.Entry[int32, *FileEntry] {
+	return c.openedFiles.Snapshot()
+}
+
 // OpenFile opens the file into the table and returns its file descriptor.
 // The result must be closed by CloseFile or Close.
 func (c *FSContext) OpenFile(fs fsapi.FS, path string, flag int, perm fs.FileMode) (int32, syscall.Errno) {
@@ -419,12 +503,13 @@ func (c *FSContext) Close() (err error) {
 }
 
 // InitFSContext initializes a FSContext with stdio streams and optional
-// pre-opened filesystems and TCP listeners.
+// pre-opened filesystems, TCP listeners and pipes.
 func (c *Context) InitFSContext(
 	stdin io.Reader,
 	stdout, stderr io.Writer,
 	fs []fsapi.FS, guestPaths []string,
 	tcpListeners []*net.TCPListener,
+	pipes []PipeEnd,
 ) (err error) {
 	inFile, err := stdinFileEntry(stdin)
 	if err != nil {
@@ -459,6 +544,11 @@ func (c *Context) InitFSContext(
 	for _, tl := range tcpListeners {
 		c.fsc.openedFiles.Insert(&FileEntry{IsPreopen: true, File: sysfs.NewTCPListenerFile(tl)})
 	}
+
+	// Pipes aren't marked IsPreopen, so that the guest can renumber them.
+	for _, p := range pipes {
+		c.fsc.openedFiles.Insert(&FileEntry{Name: "pipe", File: sysfs.NewPipeFile(p.File, p.IsWrite)})
+	}
 	return nil
 }
 
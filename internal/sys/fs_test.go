@@ -54,7 +54,7 @@ func TestNewFSContext(t *testing.T) {
 
 		t.Run(tc.name, func(t *testing.T) {
 			c := Context{}
-			err := c.InitFSContext(nil, nil, nil, []fsapi.FS{tc.fs}, []string{"/"}, nil)
+			err := c.InitFSContext(nil, nil, nil, []fsapi.FS{tc.fs}, []string{"/"}, nil, nil)
 			require.NoError(t, err)
 			fsc := c.fsc
 			defer fsc.Close()
@@ -92,7 +92,7 @@ func TestFSContext_CloseFile(t *testing.T) {
 	testFS := sysfs.Adapt(embedFS)
 
 	c := Context{}
-	err = c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil)
+	err = c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil, nil)
 	require.NoError(t, err)
 	fsc := c.fsc
 	defer fsc.Close()
@@ -124,7 +124,7 @@ func TestFSContext_CloseFile(t *testing.T) {
 
 func TestFSContext_noPreopens(t *testing.T) {
 	c := Context{}
-	err := c.InitFSContext(nil, nil, nil, nil, nil, nil)
+	err := c.InitFSContext(nil, nil, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 	testFS := &c.fsc
 	require.NoError(t, err)
@@ -146,11 +146,59 @@ func TestFSContext_noPreopens(t *testing.T) {
 	})
 }
 
+func TestFSContext_ResolvePath(t *testing.T) {
+	// Use different contents, so that filesystems don't compare equal.
+	rootFS := sysfs.Adapt(fstest.MapFS{"root": &fstest.MapFile{}})
+	tmpFS := sysfs.Adapt(fstest.MapFS{"tmp": &fstest.MapFile{}})
+	tmpCacheFS := sysfs.Adapt(fstest.MapFS{"cache": &fstest.MapFile{}})
+
+	c := Context{}
+	err := c.InitFSContext(nil, nil, nil,
+		[]fsapi.FS{rootFS, tmpFS, tmpCacheFS}, []string{"/", "/tmp", "tmp/cache/"}, nil, nil)
+	require.NoError(t, err)
+	defer c.fsc.Close()
+
+	tests := []struct {
+		path, expectedPath string
+		expectedFS         fsapi.FS
+	}{
+		{path: "/", expectedFS: rootFS, expectedPath: "/"},
+		{path: "/animals.txt", expectedFS: rootFS, expectedPath: "/animals.txt"},
+		{path: "/tmpfile", expectedFS: rootFS, expectedPath: "/tmpfile"},
+		{path: "/tmp", expectedFS: tmpFS, expectedPath: "/"},
+		{path: "/tmp/", expectedFS: tmpFS, expectedPath: "/"},
+		{path: "/tmp/animals.txt", expectedFS: tmpFS, expectedPath: "/animals.txt"},
+		{path: "/tmp/cache", expectedFS: tmpCacheFS, expectedPath: "/"},
+		{path: "/tmp/cache/a/b", expectedFS: tmpCacheFS, expectedPath: "/a/b"},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.path, func(t *testing.T) {
+			fs, path := c.fsc.ResolvePath(tc.path)
+			require.Equal(t, tc.expectedFS, fs)
+			require.Equal(t, tc.expectedPath, path)
+		})
+	}
+
+	t.Run("no root", func(t *testing.T) {
+		c := Context{}
+		err := c.InitFSContext(nil, nil, nil, []fsapi.FS{tmpFS}, []string{"/tmp"}, nil, nil)
+		require.NoError(t, err)
+		defer c.fsc.Close()
+
+		fs, path := c.fsc.ResolvePath("/animals.txt")
+		require.Equal(t, fsapi.UnimplementedFS{}, fs)
+		require.Equal(t, "/animals.txt", path)
+	})
+}
+
 func TestContext_Close(t *testing.T) {
 	testFS := sysfs.Adapt(testfs.FS{"foo": &testfs.File{}})
 
 	c := Context{}
-	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil)
+	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil, nil)
 	require.NoError(t, err)
 	fsc := c.fsc
 
@@ -177,7 +225,7 @@ func TestContext_Close_Error(t *testing.T) {
 	testFS := sysfs.Adapt(testfs.FS{"foo": file})
 
 	c := Context{}
-	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil)
+	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{testFS}, []string{"/"}, nil, nil)
 	require.NoError(t, err)
 	fsc := c.fsc
 
@@ -201,7 +249,7 @@ func TestFSContext_Renumber(t *testing.T) {
 	require.EqualErrno(t, 0, errno)
 
 	c := Context{}
-	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{dirFS}, []string{"/"}, nil)
+	err := c.InitFSContext(nil, nil, nil, []fsapi.FS{dirFS}, []string{"/"}, nil, nil)
 	require.NoError(t, err)
 	fsc := c.fsc
 
@@ -248,7 +296,7 @@ func TestReaddDir_Rewind(t *testing.T) {
 		name           string
 		f              *Readdir
 		cookie         int64
-		expectedCookie int64
+		expectedCursor uint64
 		expectedErrno  syscall.Errno
 	}{
 		{
@@ -279,24 +327,38 @@ func TestReaddDir_Rewind(t *testing.T) {
 			name: "cookie is last pos",
 			f: &Readdir{
 				countRead: 3,
+				cursor:    3,
 			},
-			cookie: 3,
+			cookie:         3,
+			expectedCursor: 3,
 		},
 		{
 			name: "cookie is one before last pos",
 			f: &Readdir{
 				countRead: 3,
+				cursor:    3,
 			},
-			cookie: 2,
+			cookie:         2,
+			expectedCursor: 2,
 		},
 		{
 			name: "cookie is before current entries",
 			f: &Readdir{
 				countRead: direntBufSize + 2,
+				cursor:    2,
 			},
 			cookie:        1,
 			expectedErrno: syscall.ENOSYS, // not implemented
 		},
+		{
+			name: "cookie is within current entries",
+			f: &Readdir{
+				countRead: direntBufSize + 2,
+				cursor:    2,
+			},
+			cookie:         direntBufSize + 1,
+			expectedCursor: 1,
+		},
 		{
 			name: "read from the beginning (cookie=0)",
 			f: &Readdir{
@@ -323,10 +385,50 @@ func TestReaddDir_Rewind(t *testing.T) {
 
 			errno := f.Rewind(tc.cookie)
 			require.EqualErrno(t, tc.expectedErrno, errno)
+			if errno == 0 && tc.cookie != 0 {
+				require.Equal(t, uint64(tc.cookie), f.Cookie())
+				require.Equal(t, tc.expectedCursor, f.cursor)
+			}
 		})
 	}
 }
 
+func TestReaddir_Hint(t *testing.T) {
+	var requested []uint64
+	dirInit := func() ([]fsapi.Dirent, syscall.Errno) {
+		return []fsapi.Dirent{{Name: "."}, {Name: ".."}}, 0
+	}
+	dirReader := func(n uint64) ([]fsapi.Dirent, syscall.Errno) {
+		requested = append(requested, n)
+		return make([]fsapi.Dirent, n), 0
+	}
+	d, errno := NewReaddir(dirInit, dirReader)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, []uint64{direntBufSize - 2}, requested)
+
+	next := func() {
+		d.Skip(uint64(len(d.dirents)) - d.cursor)
+		_, errno := d.Peek()
+		require.EqualErrno(t, 0, errno)
+	}
+
+	// Hints grow the batches.
+	d.Hint(100)
+	next()
+	require.Equal(t, uint64(100), requested[len(requested)-1])
+
+	// The batch size is bounded.
+	d.Hint(1)
+	next()
+	require.Equal(t, uint64(direntBufSize), requested[len(requested)-1])
+	d.Hint(maxDirentBufSize + 1)
+	next()
+	require.Equal(t, uint64(maxDirentBufSize), requested[len(requested)-1])
+
+	// The cookie keeps counting across batches.
+	require.Equal(t, uint64(direntBufSize+100+direntBufSize), d.Cookie())
+}
+
 func TestStripPrefixesAndTrailingSlash(t *testing.T) {
 	tests := []struct {
 		path, expected string
@@ -109,6 +109,24 @@ func (r *lazyDir) Utimens(times *[2]syscall.Timespec) syscall.Errno {
 	}
 }
 
+// Lock implements the same method as documented on internalapi.File
+func (r *lazyDir) Lock(exclusive, nonblock bool) syscall.Errno {
+	if f, ok := r.file(); !ok {
+		return syscall.EBADF
+	} else {
+		return f.Lock(exclusive, nonblock)
+	}
+}
+
+// Unlock implements the same method as documented on internalapi.File
+func (r *lazyDir) Unlock() syscall.Errno {
+	if f, ok := r.file(); !ok {
+		return syscall.EBADF
+	} else {
+		return f.Unlock()
+	}
+}
+
 // file returns the underlying file or false if it doesn't exist.
 func (r *lazyDir) file() (fsapi.File, bool) {
 	if f := r.f; r.f != nil {
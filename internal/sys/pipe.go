@@ -0,0 +1,38 @@
+package sys
+
+import (
+	"os"
+)
+
+// PipeConfigKey is a context.Context Value key. Its associated value should
+// be a *PipeConfig.
+type PipeConfigKey struct{}
+
+// PipeConfig is an internal struct meant to implement the interface in
+// experimental/pipe/Config.
+type PipeConfig struct {
+	// Ends are the pipe ends to pre-open, in order.
+	Ends []PipeEnd
+}
+
+// PipeEnd is one end of a pipe, such as returned by os.Pipe.
+type PipeEnd struct {
+	// File is the end of the pipe.
+	File *os.File
+	// IsWrite is true for the write end of the pipe.
+	IsWrite bool
+}
+
+// WithPipeEnd implements the WithReader and WithWriter methods in
+// experimental/pipe/Config.
+//
+// However, to avoid cyclic dependencies, this is returning the *PipeConfig
+// in this scope. The interface is implemented in experimental/pipe/Config
+// via delegation.
+func (c *PipeConfig) WithPipeEnd(f *os.File, isWrite bool) *PipeConfig {
+	ret := *c
+	ret.Ends = make([]PipeEnd, 0, len(c.Ends)+1)
+	ret.Ends = append(ret.Ends, c.Ends...)
+	ret.Ends = append(ret.Ends, PipeEnd{File: f, IsWrite: isWrite})
+	return &ret
+}
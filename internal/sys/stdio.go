@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"bytes"
 	"io"
 	"os"
 	"syscall"
@@ -14,21 +15,80 @@ import (
 // StdinFile is a fs.ModeDevice file for use implementing FdStdin.
 // This is safer than reading from os.DevNull as it can never overrun
 // operating system file descriptors.
+//
+// Reads which would otherwise block the caller, such as PollRead with a
+// timeout, or Read in non-blocking mode, are offloaded to a worker.
 type StdinFile struct {
 	noopStdinFile
 	io.Reader
+
+	// async is lazily initialized with Reader on first use.
+	async    asyncReader
+	nonblock bool
+}
+
+// asyncReader returns the reader which offloads blocking reads of Reader.
+func (f *StdinFile) asyncReader() *asyncReader {
+	if f.async.r == nil {
+		f.async.r = f.Reader
+	}
+	return &f.async
+}
+
+// IsNonblock implements the same method as documented on internalapi.File
+func (f *StdinFile) IsNonblock() bool {
+	return f.nonblock
+}
+
+// SetNonblock implements the same method as documented on internalapi.File
+func (f *StdinFile) SetNonblock(enable bool) syscall.Errno {
+	f.nonblock = enable
+	return 0
 }
 
 // Read implements the same method as documented on internalapi.File
-func (f *StdinFile) Read(buf []byte) (int, syscall.Errno) {
-	n, err := f.Reader.Read(buf)
+func (f *StdinFile) Read(buf []byte) (n int, errno syscall.Errno) {
+	if len(buf) == 0 {
+		return 0, 0 // Short-circuit 0-len reads.
+	}
+	var err error
+	if f.nonblock {
+		n, err = f.asyncReader().ReadNonblock(buf)
+	} else {
+		n, err = f.asyncReader().Read(buf)
+	}
 	return n, platform.UnwrapOSError(err)
 }
 
+// Close implements the same method as documented on internalapi.File
+//
+// Note: This doesn't close Reader, which is owned by the host. A read ahead
+// in progress continues until Reader returns.
+func (f *StdinFile) Close() syscall.Errno {
+	f.async.Close()
+	return 0
+}
+
+// PollRead implements the same method as documented on internalapi.File
+func (f *StdinFile) PollRead(timeout *time.Duration) (ready bool, errno syscall.Errno) {
+	return f.asyncReader().PollRead(timeout), 0
+}
+
 type writerFile struct {
 	noopStdoutFile
 
 	w io.Writer
+
+	// tty is true when the file is presented to the guest as a terminal.
+	tty bool
+}
+
+// Stat implements the same method as documented on internalapi.File
+func (f *writerFile) Stat() (fsapi.Stat_t, syscall.Errno) {
+	if f.tty {
+		return fsapi.Stat_t{Mode: modeCharDevice, Nlink: 1}, 0
+	}
+	return f.noopStdoutFile.Stat()
 }
 
 // Write implements the same method as documented on internalapi.File
@@ -37,6 +97,56 @@ func (f *writerFile) Write(buf []byte) (int, syscall.Errno) {
 	return n, platform.UnwrapOSError(err)
 }
 
+// maxLineBufSize is the size at which a lineBufferedFile flushes, even if
+// there is no newline.
+const maxLineBufSize = 4096
+
+// lineBufferedFile buffers the writes to File until a newline. Other methods,
+// such as Stat, are delegated, so the guest sees the same file.
+type lineBufferedFile struct {
+	fsapi.File
+
+	buf []byte
+}
+
+// Write implements the same method as documented on internalapi.File
+func (f *lineBufferedFile) Write(buf []byte) (int, syscall.Errno) {
+	i := bytes.LastIndexByte(buf, '\n')
+	if i < 0 {
+		f.buf = append(f.buf, buf...)
+		if len(f.buf) < maxLineBufSize {
+			return len(buf), 0
+		}
+		return len(buf), f.flush()
+	}
+	f.buf = append(f.buf, buf[:i+1]...)
+	if errno := f.flush(); errno != 0 {
+		return 0, errno
+	}
+	f.buf = append(f.buf, buf[i+1:]...)
+	return len(buf), 0
+}
+
+// flush writes and clears buf, even on error, so that a failing writer
+// doesn't grow it.
+func (f *lineBufferedFile) flush() syscall.Errno {
+	if len(f.buf) == 0 {
+		return 0
+	}
+	_, errno := f.File.Write(f.buf)
+	f.buf = f.buf[:0]
+	return errno
+}
+
+// Close implements the same method as documented on internalapi.File
+func (f *lineBufferedFile) Close() syscall.Errno {
+	errno := f.flush()
+	if e := f.File.Close(); errno == 0 {
+		errno = e
+	}
+	return errno
+}
+
 // noopStdinFile is a fs.ModeDevice file for use implementing FdStdin. This is
 // safer than reading from os.DevNull as it can never overrun operating system
 // file descriptors.
@@ -106,9 +216,22 @@ func stdinFileEntry(r io.Reader) (*FileEntry, error) {
 	}
 }
 
-func stdioWriterFileEntry(name string, w io.Writer) (*FileEntry, error) {
+func stdioWriterFileEntry(name string, w io.Writer) (_ *FileEntry, err error) {
 	if w == nil {
 		return &FileEntry{Name: name, IsPreopen: true, File: &noopStdoutFile{}}, nil
+	} else if sw, ok := w.(*StdioWriter); ok {
+		var e *FileEntry
+		if sw.TTY {
+			e = &FileEntry{Name: name, IsPreopen: true, File: &writerFile{w: sw.Writer, tty: true}}
+		} else if e, err = stdioWriterFileEntry(name, sw.Writer); err != nil {
+			return nil, err
+		}
+		// Buffering wraps the file, so that an *os.File keeps its type, e.g.
+		// a terminal.
+		if sw.LineBuffered {
+			e.File = &lineBufferedFile{File: e.File}
+		}
+		return e, nil
 	} else if f, ok := w.(*os.File); ok {
 		if f, err := sysfs.NewStdioFile(false, f); err != nil {
 			return nil, err
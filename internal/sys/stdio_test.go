@@ -1,6 +1,7 @@
 package sys
 
 import (
+	"bytes"
 	"io/fs"
 	"os"
 	"testing"
@@ -136,3 +137,73 @@ func TestStdio(t *testing.T) {
 		}
 	}
 }
+
+func TestStdioWriter(t *testing.T) {
+	var out bytes.Buffer
+
+	t.Run("tty", func(t *testing.T) {
+		e, err := stdioWriterFileEntry("stdout", &StdioWriter{Writer: &out, TTY: true})
+		require.NoError(t, err)
+
+		st, errno := e.File.Stat()
+		require.EqualErrno(t, 0, errno)
+		require.Equal(t, fs.ModeDevice|fs.ModeCharDevice, st.Mode&fs.ModeType)
+
+		n, errno := e.File.Write([]byte("no newline"))
+		require.EqualErrno(t, 0, errno)
+		require.Equal(t, 10, n)
+		require.Equal(t, "no newline", out.String()) // not buffered
+	})
+
+	out.Reset()
+
+	t.Run("line buffered", func(t *testing.T) {
+		e, err := stdioWriterFileEntry("stdout", &StdioWriter{Writer: &out, LineBuffered: true})
+		require.NoError(t, err)
+
+		st, errno := e.File.Stat()
+		require.EqualErrno(t, 0, errno)
+		require.Equal(t, fs.ModeDevice, st.Mode&fs.ModeType)
+
+		for _, s := range []string{"wa", "ze", "ro\nfo", "o\nbar\nb"} {
+			n, errno := e.File.Write([]byte(s))
+			require.EqualErrno(t, 0, errno)
+			require.Equal(t, len(s), n)
+		}
+		require.Equal(t, "wazero\nfoo\nbar\n", out.String())
+
+		// A long line is flushed without a newline.
+		_, errno = e.File.Write(make([]byte, maxLineBufSize))
+		require.EqualErrno(t, 0, errno)
+		require.Equal(t, len("wazero\nfoo\nbar\nb")+maxLineBufSize, out.Len())
+
+		_, errno = e.File.Write([]byte("az"))
+		require.EqualErrno(t, 0, errno)
+		require.EqualErrno(t, 0, e.File.Close())
+		require.Equal(t, len("wazero\nfoo\nbar\nbaz")+maxLineBufSize, out.Len())
+	})
+
+	t.Run("line buffered file", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "stdout")
+		require.NoError(t, err)
+		defer f.Close()
+
+		e, err := stdioWriterFileEntry("stdout", &StdioWriter{Writer: f, LineBuffered: true})
+		require.NoError(t, err)
+
+		// The file isn't replaced, so a terminal would still be one.
+		st, errno := e.File.Stat()
+		require.EqualErrno(t, 0, errno)
+		require.Equal(t, fs.FileMode(0), st.Mode&fs.ModeType) // regular file
+
+		_, errno = e.File.Write([]byte("wazero"))
+		require.EqualErrno(t, 0, errno)
+		st, _ = e.File.Stat()
+		require.Zero(t, st.Size)
+
+		require.EqualErrno(t, 0, e.File.Close())
+		b, err := os.ReadFile(f.Name())
+		require.NoError(t, err)
+		require.Equal(t, "wazero", string(b))
+	})
+}
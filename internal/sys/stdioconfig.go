@@ -0,0 +1,55 @@
+package sys
+
+import (
+	"io"
+	"strconv"
+)
+
+// StdioConfigKey is a context.Context Value key. Its associated value should
+// be a *StdioConfig.
+type StdioConfigKey struct{}
+
+// StdioConfig is an internal struct meant to implement the interface in
+// experimental/stdio/Config.
+type StdioConfig struct {
+	// TTY presents stdout and stderr to the guest as terminals.
+	TTY bool
+	// Cols and Rows are the size of the terminal, or zero when unknown.
+	Cols, Rows uint16
+	// LineBuffered buffers writes to stdout and stderr until a newline.
+	LineBuffered bool
+}
+
+// StdioWriter is the writer of stdout or stderr with the options of a
+// StdioConfig, which InitFSContext applies to the file the guest writes.
+type StdioWriter struct {
+	io.Writer
+	TTY, LineBuffered bool
+}
+
+// WrapWriter returns w as a *StdioWriter, or w itself when it is nil or there
+// are no options to apply.
+func (c *StdioConfig) WrapWriter(w io.Writer) io.Writer {
+	if w == nil || (!c.TTY && !c.LineBuffered) {
+		return w
+	}
+	return &StdioWriter{Writer: w, TTY: c.TTY, LineBuffered: c.LineBuffered}
+}
+
+// Environ returns the environment variables which pass the terminal size,
+// as pairs of key and value. This is empty unless the size is known.
+//
+// Note: There is no "ioctl" in WASI, so programs can't read the size with
+// TIOCGWINSZ. Instead, this uses the same variables as shells export.
+func (c *StdioConfig) Environ() (pairs [][]byte) {
+	if !c.TTY {
+		return
+	}
+	if c.Cols > 0 {
+		pairs = append(pairs, []byte("COLUMNS"), []byte(strconv.Itoa(int(c.Cols))))
+	}
+	if c.Rows > 0 {
+		pairs = append(pairs, []byte("LINES"), []byte(strconv.Itoa(int(c.Rows))))
+	}
+	return
+}
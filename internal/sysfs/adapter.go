@@ -64,6 +64,15 @@ func (a *adapter) Lstat(path string) (fsapi.Stat_t, syscall.Errno) {
 	return a.Stat(path)
 }
 
+// Utimens implements the same method as documented on api.FS. fs.FS is read
+// only, so this returns syscall.EROFS for any path which exists.
+func (a *adapter) Utimens(path string, _ *[2]syscall.Timespec, _ bool) syscall.Errno {
+	if _, errno := a.Stat(path); errno != 0 {
+		return errno
+	}
+	return syscall.EROFS
+}
+
 func cleanPath(name string) string {
 	if len(name) == 0 {
 		return name
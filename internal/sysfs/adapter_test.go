@@ -92,7 +92,16 @@ func TestAdapt_UtimesNano(t *testing.T) {
 	require.NoError(t, os.WriteFile(realPath, []byte{}, 0o600))
 
 	err := testFS.Utimens(path, nil, true)
-	require.EqualErrno(t, syscall.ENOSYS, err)
+	require.EqualErrno(t, syscall.EROFS, err)
+
+	err = testFS.Utimens("nope", nil, true)
+	require.EqualErrno(t, syscall.ENOENT, err)
+
+	f, errno := testFS.OpenFile(path, syscall.O_RDONLY, 0)
+	require.EqualErrno(t, 0, errno)
+	require.EqualErrno(t, syscall.EROFS, f.Utimens(nil))
+	require.EqualErrno(t, 0, f.Close())
+	require.EqualErrno(t, syscall.EBADF, f.Utimens(nil))
 }
 
 func TestAdapt_Open_Read(t *testing.T) {
@@ -0,0 +1,27 @@
+package sysfs
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/tetratelabs/wazero/internal/platform"
+)
+
+// allocateByTruncate extends the file to offset+length if it is smaller. The
+// new range is sparse on filesystems that support it, so a later write may
+// still fail with syscall.ENOSPC.
+func allocateByTruncate(f *os.File, offset, length int64) syscall.Errno {
+	tail := offset + length
+	if tail < 0 { // overflow
+		return syscall.EFBIG
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		return platform.UnwrapOSError(err)
+	}
+	if st.Size() >= tail {
+		return 0 // We already have enough space.
+	}
+	return platform.UnwrapOSError(f.Truncate(tail))
+}
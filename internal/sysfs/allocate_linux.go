@@ -0,0 +1,21 @@
+//go:build linux
+
+package sysfs
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/tetratelabs/wazero/internal/platform"
+)
+
+func allocate(f *os.File, offset, length int64) syscall.Errno {
+	// A zero mode is like posix_fallocate: the file size is extended if
+	// needed, and the range is zero-filled.
+	errno := platform.UnwrapOSError(syscall.Fallocate(int(f.Fd()), 0, offset, length))
+	if errno == syscall.EOPNOTSUPP {
+		// The filesystem doesn't support fallocate (e.g. tmpfs on old kernels).
+		return allocateByTruncate(f, offset, length)
+	}
+	return errno
+}
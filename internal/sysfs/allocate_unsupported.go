@@ -0,0 +1,12 @@
+//go:build !linux
+
+package sysfs
+
+import (
+	"os"
+	"syscall"
+)
+
+func allocate(f *os.File, offset, length int64) syscall.Errno {
+	return allocateByTruncate(f, offset, length)
+}
@@ -0,0 +1,178 @@
+package sysfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/tetratelabs/wazero/internal/fsapi"
+)
+
+// NewCaseInsensitiveFS wraps fs so that paths resolve regardless of case, as
+// they do on Windows or macOS by default. Notably, this allows guests which
+// were only tested on those hosts to open "Config.json" when the file is
+// named "config.json".
+//
+// A path is used as is when it exists. Otherwise, each missing component is
+// replaced by the entry of its parent directory which only differs in case.
+// When several entries match, the first in lexicographical order is used,
+// and a warning is written to warn, if not nil.
+func NewCaseInsensitiveFS(fs fsapi.FS, warn io.Writer) fsapi.FS {
+	if _, ok := fs.(fsapi.UnimplementedFS); ok {
+		return fs // there is nothing to resolve
+	}
+	return &caseInsensitiveFS{fs: fs, warn: warn}
+}
+
+type caseInsensitiveFS struct {
+	fs   fsapi.FS
+	warn io.Writer
+}
+
+// resolve returns path with each component which doesn't exist replaced by
+// an entry of the same name, ignoring case. Components which don't match any
+// entry are left as is, so that the error is the same as with the wrapped fs.
+func (c *caseInsensitiveFS) resolve(path string) string {
+	if _, errno := c.fs.Lstat(path); errno == 0 {
+		return path // fast path for correctly cased paths
+	}
+
+	components := strings.Split(path, "/")
+	for i, name := range components {
+		switch name {
+		case "", ".", "..":
+			continue
+		}
+		if _, errno := c.fs.Lstat(strings.Join(components[:i+1], "/")); errno == 0 {
+			continue
+		}
+		match, ok := c.match(strings.Join(components[:i], "/"), name)
+		if !ok {
+			// The parent doesn't exist or has no such entry, so there's
+			// nothing to resolve in the rest of the path.
+			break
+		}
+		components[i] = match
+	}
+	return strings.Join(components, "/")
+}
+
+// match returns the entry of the directory dir which equals name ignoring
+// case.
+func (c *caseInsensitiveFS) match(dir, name string) (string, bool) {
+	if dir == "" {
+		dir = "."
+	}
+	f, errno := c.fs.OpenFile(dir, os.O_RDONLY|fsapi.O_DIRECTORY, 0)
+	if errno != 0 {
+		return "", false
+	}
+	defer f.Close()
+
+	dirents, errno := f.Readdir(-1)
+	if errno != 0 {
+		return "", false
+	}
+	var matches []string
+	for i := range dirents {
+		if strings.EqualFold(dirents[i].Name, name) {
+			matches = append(matches, dirents[i].Name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", false
+	case 1:
+		return matches[0], true
+	}
+	sort.Strings(matches)
+	if c.warn != nil {
+		fmt.Fprintf(c.warn, "wazero: %q in %q matches %q, using %q\n", name, dir, matches, matches[0])
+	}
+	return matches[0], true
+}
+
+// String implements fmt.Stringer
+func (c *caseInsensitiveFS) String() string {
+	return c.fs.String()
+}
+
+// OpenFile implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) OpenFile(path string, flag int, perm fs.FileMode) (fsapi.File, syscall.Errno) {
+	return c.fs.OpenFile(c.resolve(path), flag, perm)
+}
+
+// Lstat implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Lstat(path string) (fsapi.Stat_t, syscall.Errno) {
+	return c.fs.Lstat(c.resolve(path))
+}
+
+// Stat implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Stat(path string) (fsapi.Stat_t, syscall.Errno) {
+	return c.fs.Stat(c.resolve(path))
+}
+
+// Mkdir implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Mkdir(path string, perm fs.FileMode) syscall.Errno {
+	return c.fs.Mkdir(c.resolve(path), perm)
+}
+
+// Chmod implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Chmod(path string, perm fs.FileMode) syscall.Errno {
+	return c.fs.Chmod(c.resolve(path), perm)
+}
+
+// Chown implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Chown(path string, uid, gid int) syscall.Errno {
+	return c.fs.Chown(c.resolve(path), uid, gid)
+}
+
+// Lchown implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Lchown(path string, uid, gid int) syscall.Errno {
+	return c.fs.Lchown(c.resolve(path), uid, gid)
+}
+
+// Rename implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Rename(from, to string) syscall.Errno {
+	return c.fs.Rename(c.resolve(from), c.resolve(to))
+}
+
+// Rmdir implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Rmdir(path string) syscall.Errno {
+	return c.fs.Rmdir(c.resolve(path))
+}
+
+// Unlink implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Unlink(path string) syscall.Errno {
+	return c.fs.Unlink(c.resolve(path))
+}
+
+// Link implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Link(oldPath, newPath string) syscall.Errno {
+	return c.fs.Link(c.resolve(oldPath), c.resolve(newPath))
+}
+
+// Symlink implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Symlink(oldPath, linkName string) syscall.Errno {
+	// oldPath is the content of the link, which is resolved when followed.
+	return c.fs.Symlink(oldPath, c.resolve(linkName))
+}
+
+// Readlink implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Readlink(path string) (string, syscall.Errno) {
+	return c.fs.Readlink(c.resolve(path))
+}
+
+// Truncate implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Truncate(path string, size int64) syscall.Errno {
+	return c.fs.Truncate(c.resolve(path), size)
+}
+
+// Utimens implements the same method as documented on api.FS
+func (c *caseInsensitiveFS) Utimens(path string, times *[2]syscall.Timespec, symlinkFollow bool) syscall.Errno {
+	return c.fs.Utimens(c.resolve(path), times, symlinkFollow)
+}
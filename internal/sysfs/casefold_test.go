@@ -0,0 +1,91 @@
+package sysfs
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/fsapi"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewCaseInsensitiveFS(t *testing.T) {
+	// Doesn't wrap file systems that have nothing to resolve
+	require.Equal(t, fsapi.UnimplementedFS{}, NewCaseInsensitiveFS(fsapi.UnimplementedFS{}, nil))
+
+	dirFS := NewDirFS("/tmp")
+	testFS := NewCaseInsensitiveFS(dirFS, nil)
+	require.NotEqual(t, dirFS, testFS)
+	require.Equal(t, "/tmp", testFS.String())
+}
+
+func TestCaseInsensitiveFS_OpenFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(tmpDir, "Assets", "img"), 0o700))
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "Assets", "img", "Logo.PNG"), []byte("logo"), 0o600))
+
+	testFS := NewCaseInsensitiveFS(NewDirFS(tmpDir), nil)
+
+	for _, p := range []string{"Assets/img/Logo.PNG", "assets/IMG/logo.png", "/ASSETS/img/logo.png"} {
+		t.Run(p, func(t *testing.T) {
+			f, errno := testFS.OpenFile(p, os.O_RDONLY, 0)
+			require.EqualErrno(t, 0, errno)
+			defer f.Close()
+
+			buf := make([]byte, 4)
+			requireRead(t, f, buf)
+			require.Equal(t, "logo", string(buf))
+		})
+	}
+
+	t.Run("stat directory", func(t *testing.T) {
+		st, errno := testFS.Stat("assets/Img")
+		require.EqualErrno(t, 0, errno)
+		require.True(t, st.Mode.IsDir())
+	})
+
+	t.Run("creates in resolved directory", func(t *testing.T) {
+		f, errno := testFS.OpenFile("ASSETS/New.txt", os.O_RDWR|os.O_CREATE, 0o600)
+		require.EqualErrno(t, 0, errno)
+		require.EqualErrno(t, 0, f.Close())
+
+		_, err := os.Stat(path.Join(tmpDir, "Assets", "New.txt"))
+		require.NoError(t, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, errno := testFS.OpenFile("assets/missing/logo.png", os.O_RDONLY, 0)
+		require.EqualErrno(t, syscall.ENOENT, errno)
+	})
+}
+
+func TestCaseInsensitiveFS_collision(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "a.txt"), []byte("lower"), 0o600))
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "A.TXT"), []byte("upper"), 0o600))
+	if _, err := os.Stat(path.Join(tmpDir, "A.txt")); err == nil {
+		t.Skip("host file system is case-insensitive")
+	}
+
+	var warn bytes.Buffer
+	testFS := NewCaseInsensitiveFS(NewDirFS(tmpDir), &warn)
+
+	// Exact matches are used as is, without a warning.
+	f, errno := testFS.OpenFile("a.txt", os.O_RDONLY, 0)
+	require.EqualErrno(t, 0, errno)
+	defer f.Close()
+	buf := make([]byte, 5)
+	requireRead(t, f, buf)
+	require.Equal(t, "lower", string(buf))
+	require.Equal(t, "", warn.String())
+
+	// Otherwise, the first match in lexicographical order wins.
+	f, errno = testFS.OpenFile("A.txt", os.O_RDONLY, 0)
+	require.EqualErrno(t, 0, errno)
+	defer f.Close()
+	requireRead(t, f, buf)
+	require.Equal(t, "upper", string(buf))
+	require.Equal(t, "wazero: \"A.txt\" in \".\" matches [\"A.TXT\" \"a.txt\"], using \"A.TXT\"\n", warn.String())
+}
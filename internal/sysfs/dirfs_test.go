@@ -532,11 +532,7 @@ func TestDirFS_Utimesns(t *testing.T) {
 		err := testFS.Utimens("nope", nil, true)
 		require.EqualErrno(t, syscall.ENOENT, err)
 		err = testFS.Utimens("nope", nil, false)
-		if SupportsSymlinkNoFollow {
-			require.EqualErrno(t, syscall.ENOENT, err)
-		} else {
-			require.EqualErrno(t, syscall.ENOSYS, err)
-		}
+		require.EqualErrno(t, syscall.ENOENT, err)
 	})
 
 	// Note: This sets microsecond granularity because Windows doesn't support
@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"sync"
 	"syscall"
 
 	"github.com/tetratelabs/wazero/internal/fsapi"
@@ -36,6 +37,17 @@ func NewStdioFile(stdin bool, f fs.File) (fsapi.File, error) {
 	return &stdioFile{File: file, st: fsapi.Stat_t{Mode: mode, Nlink: 1}}, nil
 }
 
+// NewPipeFile returns a file for one end of a pipe, such as returned by
+// os.Pipe. Closing it closes f.
+func NewPipeFile(f *os.File, isWrite bool) fsapi.File {
+	flag := syscall.O_RDONLY
+	if isWrite {
+		flag = syscall.O_WRONLY
+	}
+	// This is ok because functions that need path aren't used by pipes.
+	return newOsFile("", flag, 0, f)
+}
+
 func OpenFile(path string, flag int, perm fs.FileMode) (*os.File, syscall.Errno) {
 	if flag&fsapi.O_DIRECTORY != 0 && flag&(syscall.O_WRONLY|syscall.O_RDWR) != 0 {
 		return nil, syscall.EISDIR // invalid to open a directory writeable
@@ -122,6 +134,10 @@ type fsFile struct {
 
 	// cachedStat includes fields that won't change while a file is open.
 	cachedSt *cachedStat
+
+	// offsetMux serializes changes to the file offset, so that Pread and
+	// Pwrite emulated with Seek aren't interleaved with Read, Write or Seek.
+	offsetMux sync.Mutex
 }
 
 type cachedStat struct {
@@ -203,6 +219,9 @@ func (f *fsFile) cacheStat(st fsapi.Stat_t) (fsapi.Stat_t, syscall.Errno) {
 
 // Read implements the same method as documented on fsapi.File
 func (f *fsFile) Read(buf []byte) (n int, errno syscall.Errno) {
+	f.offsetMux.Lock()
+	defer f.offsetMux.Unlock()
+
 	if n, errno = read(f.file, buf); errno != 0 {
 		// Defer validation overhead until we've already had an error.
 		errno = fileError(f, f.closed, errno)
@@ -222,29 +241,45 @@ func (f *fsFile) Pread(buf []byte, off int64) (n int, errno syscall.Errno) {
 
 	// See /RATIONALE.md "fd_pread: io.Seeker fallback when io.ReaderAt is not supported"
 	if rs, ok := f.file.(io.ReadSeeker); ok {
-		// Determine the current position in the file, as we need to revert it.
-		currentOffset, err := rs.Seek(0, io.SeekCurrent)
-		if err != nil {
-			return 0, fileError(f, f.closed, platform.UnwrapOSError(err))
-		}
+		n, errno = f.atOffset(rs, off, func() (int, error) { return rs.Read(buf) })
+	} else {
+		errno = syscall.ENOSYS // unsupported
+	}
+	return
+}
 
-		// Put the read position back when complete.
-		defer func() { _, _ = rs.Seek(currentOffset, io.SeekStart) }()
+// atOffset calls fn with the file offset at off, then restores the offset.
+// This emulates Pread or Pwrite with io.Seeker.
+//
+// See /RATIONALE.md "fd_pread: io.Seeker fallback when io.ReaderAt is not supported"
+func (f *fsFile) atOffset(s io.Seeker, off int64, fn func() (int, error)) (n int, errno syscall.Errno) {
+	if off < 0 {
+		return 0, syscall.EINVAL
+	}
 
-		// If the current offset isn't in sync with this reader, move it.
-		if off != currentOffset {
-			if _, err = rs.Seek(off, io.SeekStart); err != nil {
-				return 0, fileError(f, f.closed, platform.UnwrapOSError(err))
-			}
-		}
+	f.offsetMux.Lock()
+	defer f.offsetMux.Unlock()
 
-		n, err = rs.Read(buf)
-		if errno = platform.UnwrapOSError(err); errno != 0 {
-			// Defer validation overhead until we've already had an error.
-			errno = fileError(f, f.closed, errno)
+	// Determine the current position in the file, as we need to revert it.
+	currentOffset, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fileError(f, f.closed, platform.UnwrapOSError(err))
+	}
+
+	// Put the position back when complete.
+	defer func() { _, _ = s.Seek(currentOffset, io.SeekStart) }()
+
+	// If the current offset isn't in sync with this file, move it.
+	if off != currentOffset {
+		if _, err = s.Seek(off, io.SeekStart); err != nil {
+			return 0, fileError(f, f.closed, platform.UnwrapOSError(err))
 		}
-	} else {
-		errno = syscall.ENOSYS // unsupported
+	}
+
+	n, err = fn()
+	if errno = platform.UnwrapOSError(err); errno != 0 {
+		// Defer validation overhead until we've already had an error.
+		errno = fileError(f, f.closed, errno)
 	}
 	return
 }
@@ -262,6 +297,9 @@ func (f *fsFile) Seek(offset int64, whence int) (newOffset int64, errno syscall.
 	}
 
 	if s, ok := f.file.(io.Seeker); ok {
+		f.offsetMux.Lock()
+		defer f.offsetMux.Unlock()
+
 		if newOffset, errno = seek(s, offset, whence); errno != 0 {
 			// Defer validation overhead until we've already had an error.
 			errno = fileError(f, f.closed, errno)
@@ -321,6 +359,9 @@ func (f *fsFile) Readdir(n int) (dirents []fsapi.Dirent, errno syscall.Errno) {
 // Write implements the same method as documented on fsapi.File.
 func (f *fsFile) Write(buf []byte) (n int, errno syscall.Errno) {
 	if w, ok := f.file.(io.Writer); ok {
+		f.offsetMux.Lock()
+		defer f.offsetMux.Unlock()
+
 		if n, errno = write(w, buf); errno != 0 {
 			// Defer validation overhead until we've already had an error.
 			errno = fileError(f, f.closed, errno)
@@ -338,12 +379,27 @@ func (f *fsFile) Pwrite(buf []byte, off int64) (n int, errno syscall.Errno) {
 			// Defer validation overhead until we've already had an error.
 			errno = fileError(f, f.closed, errno)
 		}
+	} else if ws, ok := f.file.(io.WriteSeeker); ok {
+		// Like Pread, fall back to io.Seeker when io.WriterAt isn't supported.
+		n, errno = f.atOffset(ws, off, func() (int, error) { return ws.Write(buf) })
 	} else {
 		errno = syscall.ENOSYS // unsupported
 	}
 	return
 }
 
+// Utimens implements the same method as documented on fsapi.File. fs.FS is
+// read only, so this returns syscall.EROFS unless the file wasn't opened from
+// one, such as stdio.
+func (f *fsFile) Utimens(*[2]syscall.Timespec) syscall.Errno {
+	if f.closed {
+		return syscall.EBADF
+	} else if f.fs == nil {
+		return syscall.ENOSYS
+	}
+	return syscall.EROFS
+}
+
 // Close implements the same method as documented on fsapi.File.
 func (f *fsFile) Close() syscall.Errno {
 	if f.closed {
@@ -639,6 +639,54 @@ func TestFileWriteAndPwrite(t *testing.T) {
 	require.Equal(t, "wazerowazeroero", string(b))
 }
 
+// seekerFS opens files which implement io.Writer and io.Seeker, but not
+// io.ReaderAt or io.WriterAt.
+type seekerFS struct {
+	dir string
+}
+
+func (s *seekerFS) Open(name string) (fs.File, error) {
+	f, err := os.OpenFile(path.Join(s.dir, name), os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		fs.File
+		io.Writer
+		io.Seeker
+	}{f, f, f}, nil
+}
+
+func TestFilePreadAndPwrite_Seeker(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, wazeroFile), []byte("wazero"), 0o600))
+
+	f, errno := OpenFSFile(&seekerFS{tmpDir}, wazeroFile, syscall.O_RDWR, 0)
+	require.EqualErrno(t, 0, errno)
+	defer f.Close()
+
+	buf := make([]byte, 3)
+	requireRead(t, f, buf)
+	require.Equal(t, "waz", string(buf))
+
+	// Positioned I/O is emulated with Seek, restoring the offset after.
+	requirePwrite(t, f, []byte("ERO"), 3)
+	requirePread(t, f, buf, 3)
+	require.Equal(t, "ERO", string(buf))
+	requireRead(t, f, buf)
+	require.Equal(t, "ERO", string(buf))
+
+	// Pwrite can extend the file.
+	requirePwrite(t, f, []byte("!"), 6)
+
+	b, err := os.ReadFile(path.Join(tmpDir, wazeroFile))
+	require.NoError(t, err)
+	require.Equal(t, "wazERO!", string(b))
+
+	_, errno = f.Pwrite(buf, -1)
+	require.EqualErrno(t, syscall.EINVAL, errno)
+}
+
 func requireWrite(t *testing.T, f fsapi.File, buf []byte) {
 	n, errno := f.Write(buf)
 	require.EqualErrno(t, 0, errno)
@@ -927,6 +975,70 @@ func TestFileTruncate(t *testing.T) {
 	})
 }
 
+func TestFileAllocate(t *testing.T) {
+	content := []byte("123456")
+
+	tests := []struct {
+		name            string
+		offset, length  int64
+		expectedContent []byte
+	}{
+		{
+			name:            "within",
+			offset:          1,
+			length:          5,
+			expectedContent: content,
+		},
+		{
+			name:            "larger",
+			offset:          6,
+			length:          100,
+			expectedContent: append(content, make([]byte, 100)...),
+		},
+		{
+			name:            "overlapping",
+			offset:          3,
+			length:          5,
+			expectedContent: append(content, 0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			fPath := path.Join(tmpDir, tc.name)
+			f := openForWrite(t, fPath, content)
+			defer f.Close()
+
+			errno := f.Allocate(tc.offset, tc.length)
+			require.EqualErrno(t, 0, errno)
+
+			actual, err := os.ReadFile(fPath)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedContent, actual)
+		})
+	}
+
+	allocate := func(f fsapi.File) syscall.Errno {
+		return f.Allocate(0, 1)
+	}
+
+	testEBADFIfFileClosed(t, allocate)
+	testEISDIR(t, allocate)
+
+	t.Run("invalid", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		f := openForWrite(t, path.Join(tmpDir, "allocate"), content)
+		defer f.Close()
+
+		require.EqualErrno(t, syscall.EINVAL, f.Allocate(-1, 1))
+		require.EqualErrno(t, syscall.EINVAL, f.Allocate(0, 0))
+	})
+}
+
 func TestFileUtimens(t *testing.T) {
 	switch runtime.GOOS {
 	case "linux", "darwin": // supported
@@ -949,6 +1061,55 @@ func TestFileUtimens(t *testing.T) {
 	})
 }
 
+func TestFileLock(t *testing.T) {
+	if runtime.GOOS == "js" || runtime.GOOS == "illumos" || runtime.GOOS == "solaris" {
+		t.Skip("file locking is not supported on " + runtime.GOOS)
+	}
+
+	fPath := path.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(fPath, []byte("0123456789"), 0o600))
+
+	// Locks conflict between separate opens of the same file.
+	f1, errno := OpenOSFile(fPath, os.O_RDWR, 0o600)
+	require.EqualErrno(t, 0, errno)
+	defer f1.Close()
+	f2, errno := OpenOSFile(fPath, os.O_RDWR, 0o600)
+	require.EqualErrno(t, 0, errno)
+	defer f2.Close()
+
+	require.EqualErrno(t, 0, f1.Lock(true, true))
+	require.EqualErrno(t, syscall.EAGAIN, f2.Lock(false, true))
+
+	// Shared locks don't conflict with each other.
+	require.EqualErrno(t, 0, f1.Unlock())
+	require.EqualErrno(t, 0, f2.Lock(false, true))
+	require.EqualErrno(t, 0, f1.Lock(false, true))
+	require.EqualErrno(t, syscall.EAGAIN, f1.Lock(true, true))
+
+	// Unlocking a file which isn't locked succeeds.
+	require.EqualErrno(t, 0, f2.Unlock())
+	require.EqualErrno(t, 0, f2.Unlock())
+
+	require.EqualErrno(t, 0, f1.Close())
+	require.EqualErrno(t, syscall.EBADF, f1.Lock(true, true))
+	require.EqualErrno(t, syscall.EBADF, f1.Unlock())
+
+	// Closing released the lock.
+	require.EqualErrno(t, 0, f2.Lock(true, true))
+}
+
+func TestFileLock_Unsupported(t *testing.T) {
+	embedFS, err := fs.Sub(testdata, "testdata")
+	require.NoError(t, err)
+
+	f, errno := OpenFSFile(embedFS, wazeroFile, syscall.O_RDONLY, 0)
+	require.EqualErrno(t, 0, errno)
+	defer f.Close()
+
+	require.EqualErrno(t, syscall.ENOSYS, f.Lock(false, true))
+	require.EqualErrno(t, syscall.ENOSYS, f.Unlock())
+}
+
 func TestNewStdioFile(t *testing.T) {
 	// simulate regular file attached to stdin
 	f, err := os.CreateTemp(t.TempDir(), "somefile")
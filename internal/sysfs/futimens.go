@@ -1,6 +1,7 @@
 package sysfs
 
 import (
+	"io/fs"
 	"syscall"
 	"time"
 	"unsafe"
@@ -64,7 +65,13 @@ func timesToPtr(times *[2]syscall.Timespec) unsafe.Pointer { //nolint:unused
 
 func utimensPortable(path string, times *[2]syscall.Timespec, symlinkFollow bool) error { //nolint:unused
 	if !symlinkFollow {
-		return syscall.ENOSYS
+		// Without utimensat, we can't avoid following a symbolic link. However,
+		// not following a path which isn't a link is the same as following it.
+		if st, errno := lstat(path); errno != 0 {
+			return errno
+		} else if st.Mode&fs.ModeSymlink != 0 {
+			return syscall.ENOSYS
+		}
 	}
 
 	// Handle when both inputs are current system time.
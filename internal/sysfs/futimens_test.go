@@ -18,11 +18,7 @@ func TestUtimens(t *testing.T) {
 		require.EqualErrno(t, syscall.ENOENT, err)
 
 		err = Utimens("nope", nil, false)
-		if SupportsSymlinkNoFollow {
-			require.EqualErrno(t, syscall.ENOENT, err)
-		} else {
-			require.EqualErrno(t, syscall.ENOSYS, err)
-		}
+		require.EqualErrno(t, syscall.ENOENT, err)
 	})
 	testUtimens(t, false)
 }
@@ -0,0 +1,28 @@
+//go:build !windows && !js && !illumos && !solaris
+
+package sysfs
+
+import (
+	"syscall"
+
+	"github.com/tetratelabs/wazero/internal/platform"
+)
+
+func lock(fd uintptr, exclusive, nonblock bool) syscall.Errno {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if nonblock {
+		how |= syscall.LOCK_NB
+	}
+	errno := platform.UnwrapOSError(syscall.Flock(int(fd), how))
+	if errno == syscall.EWOULDBLOCK {
+		errno = syscall.EAGAIN // same on Linux, but not all platforms.
+	}
+	return errno
+}
+
+func unlock(fd uintptr) syscall.Errno {
+	return platform.UnwrapOSError(syscall.Flock(int(fd), syscall.LOCK_UN))
+}
@@ -0,0 +1,14 @@
+//go:build js || illumos || solaris
+
+package sysfs
+
+import "syscall"
+
+// lock is not supported as there is no syscall.Flock on these platforms.
+func lock(fd uintptr, exclusive, nonblock bool) syscall.Errno {
+	return syscall.ENOSYS
+}
+
+func unlock(fd uintptr) syscall.Errno {
+	return syscall.ENOSYS
+}
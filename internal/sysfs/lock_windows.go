@@ -0,0 +1,58 @@
+package sysfs
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tetratelabs/wazero/internal/platform"
+)
+
+const (
+	_LOCKFILE_FAIL_IMMEDIATELY = 0x1
+	_LOCKFILE_EXCLUSIVE_LOCK   = 0x2
+
+	// _ERROR_LOCK_VIOLATION is returned by LockFileEx when
+	// _LOCKFILE_FAIL_IMMEDIATELY and the file is locked by another.
+	_ERROR_LOCK_VIOLATION = syscall.Errno(33)
+
+	// _ERROR_NOT_LOCKED is returned by UnlockFileEx when there is no lock.
+	_ERROR_NOT_LOCKED = syscall.Errno(158)
+)
+
+var (
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lock locks the maximum range of bytes, which is the whole file, like flock.
+func lock(fd uintptr, exclusive, nonblock bool) syscall.Errno {
+	var flags uintptr
+	if exclusive {
+		flags |= _LOCKFILE_EXCLUSIVE_LOCK
+	}
+	if nonblock {
+		flags |= _LOCKFILE_FAIL_IMMEDIATELY
+	}
+	// Like flock, replace any existing lock instead of stacking them.
+	_ = unlock(fd)
+
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(fd, flags, 0, 0xffffffff, 0xffffffff, uintptr(unsafe.Pointer(&ol)))
+	if r != 0 {
+		return 0
+	} else if err == _ERROR_LOCK_VIOLATION {
+		return syscall.EAGAIN
+	}
+	return platform.UnwrapOSError(err)
+}
+
+func unlock(fd uintptr) syscall.Errno {
+	var ol syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(fd, 0, 0xffffffff, 0xffffffff, uintptr(unsafe.Pointer(&ol)))
+	if r != 0 {
+		return 0
+	} else if err == _ERROR_NOT_LOCKED {
+		return 0 // like flock, unlocking an unlocked file succeeds.
+	}
+	return platform.UnwrapOSError(err)
+}
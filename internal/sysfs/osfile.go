@@ -238,6 +238,18 @@ func (f *osFile) Truncate(size int64) (errno syscall.Errno) {
 	return
 }
 
+// Allocate implements the same method as documented on fsapi.File
+func (f *osFile) Allocate(offset, length int64) (errno syscall.Errno) {
+	if offset < 0 || length <= 0 {
+		return syscall.EINVAL
+	}
+	if errno = allocate(f.file, offset, length); errno != 0 {
+		// Defer validation overhead until we've already had an error.
+		errno = fileError(f, f.closed, errno)
+	}
+	return
+}
+
 // Sync implements the same method as documented on fsapi.File
 func (f *osFile) Sync() syscall.Errno {
 	return fsync(f.file)
@@ -276,6 +288,24 @@ func (f *osFile) Utimens(times *[2]syscall.Timespec) syscall.Errno {
 	return platform.UnwrapOSError(err)
 }
 
+// Lock implements the same method as documented on fsapi.File
+func (f *osFile) Lock(exclusive, nonblock bool) syscall.Errno {
+	if f.closed {
+		return syscall.EBADF
+	}
+
+	return lock(f.fd, exclusive, nonblock)
+}
+
+// Unlock implements the same method as documented on fsapi.File
+func (f *osFile) Unlock() syscall.Errno {
+	if f.closed {
+		return syscall.EBADF
+	}
+
+	return unlock(f.fd)
+}
+
 // Close implements the same method as documented on fsapi.File
 func (f *osFile) Close() syscall.Errno {
 	if f.closed {
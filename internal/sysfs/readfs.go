@@ -145,6 +145,11 @@ func (r *readFile) Truncate(int64) syscall.Errno {
 	return r.writeErr()
 }
 
+// Allocate implements the same method as documented on fsapi.File.
+func (r *readFile) Allocate(int64, int64) syscall.Errno {
+	return r.writeErr()
+}
+
 // Sync implements the same method as documented on fsapi.File.
 func (r *readFile) Sync() syscall.Errno {
 	return syscall.EBADF
@@ -170,6 +175,16 @@ func (r *readFile) Utimens(*[2]syscall.Timespec) syscall.Errno {
 	return syscall.EBADF
 }
 
+// Lock implements the same method as documented on fsapi.File.
+func (r *readFile) Lock(exclusive, nonblock bool) syscall.Errno {
+	return r.f.Lock(exclusive, nonblock)
+}
+
+// Unlock implements the same method as documented on fsapi.File.
+func (r *readFile) Unlock() syscall.Errno {
+	return r.f.Unlock()
+}
+
 func (r *readFile) writeErr() syscall.Errno {
 	if isDir, errno := r.IsDir(); errno != 0 {
 		return errno
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	socketapi "github.com/tetratelabs/wazero/internal/sock"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 )
 
@@ -103,3 +104,85 @@ func TestTcpConnFile_Stat(t *testing.T) {
 	_, errno := file.Stat()
 	require.Zero(t, errno, "Stat should not fail")
 }
+
+func TestTcpConnFile_SockOpt(t *testing.T) {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listen.Close()
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", listen.Addr().String())
+	require.NoError(t, err)
+	tcp, err := net.DialTCP("tcp", nil, tcpAddr)
+	require.NoError(t, err)
+	defer tcp.Close() //nolint
+
+	file := newTcpConn(tcp)
+	for _, opt := range []socketapi.SockOpt{socketapi.SockOptKeepAlive, socketapi.SockOptTCPNoDelay} {
+		t.Run(opt.String(), func(t *testing.T) {
+			require.EqualErrno(t, 0, file.SetSockOpt(opt, 1))
+			v, errno := file.GetSockOpt(opt)
+			require.EqualErrno(t, 0, errno)
+			require.Equal(t, 1, v)
+
+			require.EqualErrno(t, 0, file.SetSockOpt(opt, 0))
+			v, errno = file.GetSockOpt(opt)
+			require.EqualErrno(t, 0, errno)
+			require.Equal(t, 0, v)
+		})
+	}
+
+	_, errno := file.GetSockOpt(socketapi.SockOpt(-1))
+	require.EqualErrno(t, syscall.ENOPROTOOPT, errno)
+	require.EqualErrno(t, syscall.ENOPROTOOPT, file.SetSockOpt(socketapi.SockOpt(-1), 1))
+
+	require.EqualErrno(t, 0, file.Close())
+	_, errno = file.GetSockOpt(socketapi.SockOptTCPNoDelay)
+	require.EqualErrno(t, syscall.EBADF, errno)
+	require.EqualErrno(t, syscall.EBADF, file.SetSockOpt(socketapi.SockOptTCPNoDelay, 1))
+}
+
+func TestTcpListenerFile_SockOpt(t *testing.T) {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listen.Close()
+
+	file := newTCPListenerFile(listen.(*net.TCPListener))
+	defer file.Close()
+
+	require.EqualErrno(t, 0, file.SetSockOpt(socketapi.SockOptReuseAddr, 1))
+	v, errno := file.GetSockOpt(socketapi.SockOptReuseAddr)
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, 1, v)
+}
+
+func TestTcpConnFile_Addr(t *testing.T) {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listen.Close()
+
+	tcpAddr, err := net.ResolveTCPAddr("tcp", listen.Addr().String())
+	require.NoError(t, err)
+	tcp, err := net.DialTCP("tcp", nil, tcpAddr)
+	require.NoError(t, err)
+	defer tcp.Close() //nolint
+
+	conn, err := listen.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	file := newTcpConn(conn.(*net.TCPConn))
+
+	local, errno := file.LocalAddr()
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, tcpAddr.String(), local.String())
+
+	remote, errno := file.RemoteAddr()
+	require.EqualErrno(t, 0, errno)
+	require.Equal(t, tcp.LocalAddr().String(), remote.String())
+
+	require.EqualErrno(t, 0, file.Close())
+	_, errno = file.LocalAddr()
+	require.EqualErrno(t, syscall.EBADF, errno)
+	_, errno = file.RemoteAddr()
+	require.EqualErrno(t, syscall.EBADF, errno)
+}
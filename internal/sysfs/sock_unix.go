@@ -55,6 +55,16 @@ func (f *tcpListenerFile) Accept() (socketapi.TCPConn, syscall.Errno) {
 	return &tcpConnFile{fd: uintptr(nfd)}, 0
 }
 
+// GetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *tcpListenerFile) GetSockOpt(opt socketapi.SockOpt) (int, syscall.Errno) {
+	return getSockOpt(f.fd, opt)
+}
+
+// SetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *tcpListenerFile) SetSockOpt(opt socketapi.SockOpt, value int) syscall.Errno {
+	return setSockOpt(f.fd, opt, value)
+}
+
 // SetNonblock implements the same method as documented on fsapi.File
 func (f *tcpListenerFile) SetNonblock(enabled bool) syscall.Errno {
 	return platform.UnwrapOSError(setNonblock(f.fd, enabled))
@@ -127,6 +137,64 @@ func (f *tcpConnFile) Recvfrom(p []byte, flags int) (n int, errno syscall.Errno)
 	return n, errno
 }
 
+// GetSockOpt implements the same method as documented on socketapi.TCPConn
+func (f *tcpConnFile) GetSockOpt(opt socketapi.SockOpt) (int, syscall.Errno) {
+	if f.closed {
+		return 0, syscall.EBADF
+	}
+	return getSockOpt(f.fd, opt)
+}
+
+// SetSockOpt implements the same method as documented on socketapi.TCPConn
+func (f *tcpConnFile) SetSockOpt(opt socketapi.SockOpt, value int) syscall.Errno {
+	if f.closed {
+		return syscall.EBADF
+	}
+	return setSockOpt(f.fd, opt, value)
+}
+
+// LocalAddr implements the same method as documented on socketapi.TCPConn
+func (f *tcpConnFile) LocalAddr() (*net.TCPAddr, syscall.Errno) {
+	if f.closed {
+		return nil, syscall.EBADF
+	}
+	sa, err := syscall.Getsockname(int(f.fd))
+	if err != nil {
+		return nil, platform.UnwrapOSError(err)
+	}
+	return sockaddrToTCPAddr(sa)
+}
+
+// RemoteAddr implements the same method as documented on socketapi.TCPConn
+func (f *tcpConnFile) RemoteAddr() (*net.TCPAddr, syscall.Errno) {
+	if f.closed {
+		return nil, syscall.EBADF
+	}
+	sa, err := syscall.Getpeername(int(f.fd))
+	if err != nil {
+		return nil, platform.UnwrapOSError(err)
+	}
+	return sockaddrToTCPAddr(sa)
+}
+
+// sockaddrToTCPAddr converts the result of syscall.Getsockname or
+// syscall.Getpeername to a *net.TCPAddr.
+func sockaddrToTCPAddr(sa syscall.Sockaddr) (*net.TCPAddr, syscall.Errno) {
+	switch sa := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return &net.TCPAddr{IP: append(net.IP{}, sa.Addr[:]...), Port: sa.Port}, 0
+	case *syscall.SockaddrInet6:
+		addr := &net.TCPAddr{IP: append(net.IP{}, sa.Addr[:]...), Port: sa.Port}
+		if sa.ZoneId != 0 {
+			if ifi, err := net.InterfaceByIndex(int(sa.ZoneId)); err == nil {
+				addr.Zone = ifi.Name
+			}
+		}
+		return addr, 0
+	}
+	return nil, syscall.EAFNOSUPPORT
+}
+
 // Shutdown implements the same method as documented on fsapi.Conn
 func (f *tcpConnFile) Shutdown(how int) syscall.Errno {
 	var err error
@@ -153,3 +221,12 @@ func (f *tcpConnFile) close() syscall.Errno {
 	f.closed = true
 	return platform.UnwrapOSError(syscall.Shutdown(int(f.fd), syscall.SHUT_RDWR))
 }
+
+func getsockoptInt(fd uintptr, level, name int) (int, syscall.Errno) {
+	v, err := syscall.GetsockoptInt(int(fd), level, name)
+	return v, platform.UnwrapOSError(err)
+}
+
+func setsockoptInt(fd uintptr, level, name, value int) syscall.Errno {
+	return platform.UnwrapOSError(syscall.SetsockoptInt(int(fd), level, name, value))
+}
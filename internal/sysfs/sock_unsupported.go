@@ -24,3 +24,13 @@ type unsupportedSockFile struct {
 func (f *unsupportedSockFile) Accept() (socketapi.TCPConn, syscall.Errno) {
 	return nil, syscall.ENOSYS
 }
+
+// GetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *unsupportedSockFile) GetSockOpt(socketapi.SockOpt) (int, syscall.Errno) {
+	return 0, syscall.ENOSYS
+}
+
+// SetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *unsupportedSockFile) SetSockOpt(socketapi.SockOpt, int) syscall.Errno {
+	return syscall.ENOSYS
+}
@@ -73,6 +73,22 @@ func (f *winTcpListenerFile) Accept() (socketapi.TCPConn, syscall.Errno) {
 	return &winTcpConnFile{tc: conn.(*net.TCPConn)}, 0
 }
 
+// GetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *winTcpListenerFile) GetSockOpt(opt socketapi.SockOpt) (v int, errno syscall.Errno) {
+	errno = control(f.tl, func(fd uintptr) (errno syscall.Errno) {
+		v, errno = getSockOpt(fd, opt)
+		return
+	})
+	return
+}
+
+// SetSockOpt implements the same method as documented on socketapi.TCPSock
+func (f *winTcpListenerFile) SetSockOpt(opt socketapi.SockOpt, value int) syscall.Errno {
+	return control(f.tl, func(fd uintptr) syscall.Errno {
+		return setSockOpt(fd, opt, value)
+	})
+}
+
 // SetNonblock implements the same method as documented on fsapi.File
 func (f *winTcpListenerFile) SetNonblock(enabled bool) syscall.Errno {
 	return 0 // setNonblock() is a no-op on Windows
@@ -105,18 +121,9 @@ func newTcpConn(tc *net.TCPConn) socketapi.TCPConn {
 
 // SetNonblock implements the same method as documented on fsapi.File
 func (f *winTcpConnFile) SetNonblock(enabled bool) (errno syscall.Errno) {
-	syscallConn, err := f.tc.SyscallConn()
-	if err != nil {
-		return platform.UnwrapOSError(err)
-	}
-
-	// Prioritize the error from setNonblock over Control
-	if controlErr := syscallConn.Control(func(fd uintptr) {
-		errno = platform.UnwrapOSError(setNonblock(fd, enabled))
-	}); errno == 0 {
-		errno = platform.UnwrapOSError(controlErr)
-	}
-	return
+	return control(f.tc, func(fd uintptr) syscall.Errno {
+		return platform.UnwrapOSError(setNonblock(fd, enabled))
+	})
 }
 
 // Read implements the same method as documented on fsapi.File
@@ -161,6 +168,50 @@ func (f *winTcpConnFile) Recvfrom(p []byte, flags int) (n int, errno syscall.Err
 	return
 }
 
+// GetSockOpt implements the same method as documented on socketapi.TCPConn
+func (f *winTcpConnFile) GetSockOpt(opt socketapi.SockOpt) (v int, errno syscall.Errno) {
+	if f.closed {
+		return 0, syscall.EBADF
+	}
+	errno = control(f.tc, func(fd uintptr) (errno syscall.Errno) {
+		v, errno = getSockOpt(fd, opt)
+		return
+	})
+	return
+}
+
+// SetSockOpt implements the same method as documented on socketapi.TCPConn
+func (f *winTcpConnFile) SetSockOpt(opt socketapi.SockOpt, value int) syscall.Errno {
+	if f.closed {
+		return syscall.EBADF
+	}
+	return control(f.tc, func(fd uintptr) syscall.Errno {
+		return setSockOpt(fd, opt, value)
+	})
+}
+
+// LocalAddr implements the same method as documented on socketapi.TCPConn
+func (f *winTcpConnFile) LocalAddr() (*net.TCPAddr, syscall.Errno) {
+	if f.closed {
+		return nil, syscall.EBADF
+	}
+	if addr, ok := f.tc.LocalAddr().(*net.TCPAddr); ok && addr != nil {
+		return addr, 0
+	}
+	return nil, syscall.ENOTSUP
+}
+
+// RemoteAddr implements the same method as documented on socketapi.TCPConn
+func (f *winTcpConnFile) RemoteAddr() (*net.TCPAddr, syscall.Errno) {
+	if f.closed {
+		return nil, syscall.EBADF
+	}
+	if addr, ok := f.tc.RemoteAddr().(*net.TCPAddr); ok && addr != nil {
+		return addr, 0
+	}
+	return nil, syscall.ENOTCONN
+}
+
 // Shutdown implements the same method as documented on fsapi.Conn
 func (f *winTcpConnFile) Shutdown(how int) syscall.Errno {
 	// FIXME: can userland shutdown listeners?
@@ -190,3 +241,27 @@ func (f *winTcpConnFile) close() syscall.Errno {
 	f.closed = true
 	return f.Shutdown(syscall.SHUT_RDWR)
 }
+
+// control invokes fn with the handle of the connection, prioritizing its
+// error over the one from Control.
+func control(c syscall.Conn, fn func(fd uintptr) syscall.Errno) (errno syscall.Errno) {
+	syscallConn, err := c.SyscallConn()
+	if err != nil {
+		return platform.UnwrapOSError(err)
+	}
+	if controlErr := syscallConn.Control(func(fd uintptr) {
+		errno = fn(fd)
+	}); errno == 0 {
+		errno = platform.UnwrapOSError(controlErr)
+	}
+	return
+}
+
+func getsockoptInt(fd uintptr, level, name int) (int, syscall.Errno) {
+	v, err := syscall.GetsockoptInt(syscall.Handle(fd), level, name)
+	return v, platform.UnwrapOSError(err)
+}
+
+func setsockoptInt(fd uintptr, level, name, value int) syscall.Errno {
+	return platform.UnwrapOSError(syscall.SetsockoptInt(syscall.Handle(fd), level, name, value))
+}
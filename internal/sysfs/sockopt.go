@@ -0,0 +1,51 @@
+//go:build linux || darwin || windows
+
+package sysfs
+
+import (
+	"syscall"
+
+	socketapi "github.com/tetratelabs/wazero/internal/sock"
+)
+
+// sockOptName returns the level and name of the option for
+// syscall.GetsockoptInt and syscall.SetsockoptInt.
+func sockOptName(opt socketapi.SockOpt) (level, name int, errno syscall.Errno) {
+	switch opt {
+	case socketapi.SockOptReuseAddr:
+		return syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 0
+	case socketapi.SockOptKeepAlive:
+		return syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 0
+	case socketapi.SockOptTCPNoDelay:
+		return syscall.IPPROTO_TCP, syscall.TCP_NODELAY, 0
+	}
+	return 0, 0, syscall.ENOPROTOOPT
+}
+
+// getSockOpt reads the boolean option via getsockoptInt, normalizing the
+// result to zero or one as some platforms return the option's bit instead.
+func getSockOpt(fd uintptr, opt socketapi.SockOpt) (int, syscall.Errno) {
+	level, name, errno := sockOptName(opt)
+	if errno != 0 {
+		return 0, errno
+	}
+	v, errno := getsockoptInt(fd, level, name)
+	if errno != 0 {
+		return 0, errno
+	} else if v != 0 {
+		v = 1
+	}
+	return v, 0
+}
+
+// setSockOpt writes the boolean option via setsockoptInt.
+func setSockOpt(fd uintptr, opt socketapi.SockOpt, value int) syscall.Errno {
+	level, name, errno := sockOptName(opt)
+	if errno != 0 {
+		return errno
+	}
+	if value != 0 {
+		value = 1
+	}
+	return setsockoptInt(fd, level, name, value)
+}
@@ -5,6 +5,11 @@ import (
 )
 
 func encodeConstantExpression(expr wasm.ConstantExpression) (ret []byte) {
+	if wasm.IsExtendedConstOpcode(expr.Opcode) { // Data is the whole expression.
+		ret = append(ret, expr.Data...)
+		ret = append(ret, wasm.OpcodeEnd)
+		return
+	}
 	ret = append(ret, expr.Opcode)
 	ret = append(ret, expr.Data...)
 	ret = append(ret, wasm.OpcodeEnd)
@@ -32,6 +32,28 @@ func TestModule_Encode(t *testing.T) {
 				0x06, // the Module name simple is 6 bytes long
 				's', 'i', 'm', 'p', 'l', 'e'),
 		},
+		{
+			name: "name and custom sections",
+			input: &wasm.Module{
+				NameSection: &wasm.NameSection{ModuleName: "simple"},
+				CustomSections: []*wasm.CustomSection{
+					{Name: "producers", Data: []byte{0x00}},
+					{Name: "meta", Data: []byte("v1")},
+				},
+			},
+			expected: append(append(Magic, version...),
+				wasm.SectionIDCustom, 0x0e, // 14 bytes in this section
+				0x04, 'n', 'a', 'm', 'e',
+				subsectionIDModuleName, 0x07, // 7 bytes in this subsection
+				0x06, // the Module name simple is 6 bytes long
+				's', 'i', 'm', 'p', 'l', 'e',
+				wasm.SectionIDCustom, 0x0b, // 11 bytes in this section
+				0x09, 'p', 'r', 'o', 'd', 'u', 'c', 'e', 'r', 's',
+				0x00,
+				wasm.SectionIDCustom, 0x07, // 7 bytes in this section
+				0x04, 'm', 'e', 't', 'a',
+				'v', '1'),
+		},
 		{
 			name: "type section",
 			input: &wasm.Module{
@@ -149,3 +149,12 @@ func encodeDataSection(datum []wasm.DataSegment) []byte {
 	}
 	return encodeSection(wasm.SectionIDData, contents)
 }
+
+// encodeCustomSection encodes a wasm.SectionIDCustom other than the "name"
+// section, which is encoded by EncodeNameSectionData.
+//
+// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#custom-section%E2%91%A0
+func encodeCustomSection(c *wasm.CustomSection) []byte {
+	contents := append(encodeSizePrefixed([]byte(c.Name)), c.Data...)
+	return encodeSection(wasm.SectionIDCustom, contents)
+}
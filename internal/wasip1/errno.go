@@ -291,8 +291,12 @@ func ToErrno(errno syscall.Errno) Errno {
 		return ErrnoNametoolong
 	case syscall.ENOENT:
 		return ErrnoNoent
+	case syscall.ENOPROTOOPT:
+		return ErrnoNoprotoopt
 	case syscall.ENOSYS:
 		return ErrnoNosys
+	case syscall.ENOTCONN:
+		return ErrnoNotconn
 	case syscall.ENOTDIR:
 		return ErrnoNotdir
 	case syscall.ENOTEMPTY:
@@ -75,11 +75,21 @@ func TestToErrno(t *testing.T) {
 			input:    syscall.ENOENT,
 			expected: ErrnoNoent,
 		},
+		{
+			name:     "syscall.ENOPROTOOPT",
+			input:    syscall.ENOPROTOOPT,
+			expected: ErrnoNoprotoopt,
+		},
 		{
 			name:     "syscall.ENOSYS",
 			input:    syscall.ENOSYS,
 			expected: ErrnoNosys,
 		},
+		{
+			name:     "syscall.ENOTCONN",
+			input:    syscall.ENOTCONN,
+			expected: ErrnoNotconn,
+		},
 		{
 			name:     "syscall.ENOTDIR",
 			input:    syscall.ENOTDIR,
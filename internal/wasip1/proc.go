@@ -1,6 +1,20 @@
 package wasip1
 
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
 const (
 	ProcExitName  = "proc_exit"
 	ProcRaiseName = "proc_raise"
 )
+
+// RaiseHandlerKey is a context.Context Value key. Its associated value should
+// be a RaiseHandler.
+type RaiseHandlerKey struct{}
+
+// RaiseHandler handles a signal raised via ProcRaiseName. A non-nil error
+// is panicked, so that it ends the call to the guest.
+type RaiseHandler func(ctx context.Context, mod api.Module, sig uint8) error
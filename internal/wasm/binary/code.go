@@ -10,7 +10,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/wasm"
 )
 
-func decodeCode(r *bytes.Reader, codeSectionStart uint64, ret *wasm.Code) (err error) {
+func decodeCode(r *bytes.Reader, codeSectionStart uint64, gc *wasm.Module, ret *wasm.Code) (err error) {
 	ss, _, err := leb128.DecodeUint32(r)
 	if err != nil {
 		return fmt.Errorf("get the size of code: %w", err)
@@ -39,18 +39,12 @@ func decodeCode(r *bytes.Reader, codeSectionStart uint64, ret *wasm.Code) (err e
 
 		sum += uint64(num)
 
-		b, err := r.ReadByte()
+		_, tn, err := decodeLocalType(r, gc)
 		if err != nil {
-			return fmt.Errorf("read type of local: %v", err)
+			return err
 		}
 
-		bytesRead += n + 1
-		switch vt := b; vt {
-		case wasm.ValueTypeI32, wasm.ValueTypeF32, wasm.ValueTypeI64, wasm.ValueTypeF64,
-			wasm.ValueTypeFuncref, wasm.ValueTypeExternref, wasm.ValueTypeV128:
-		default:
-			return fmt.Errorf("invalid local type: 0x%x", vt)
-		}
+		bytesRead += n + tn
 	}
 
 	if sum > math.MaxUint32 {
@@ -66,16 +60,17 @@ func decodeCode(r *bytes.Reader, codeSectionStart uint64, ret *wasm.Code) (err e
 	localTypes := make([]wasm.ValueType, 0, sum)
 	for i := uint32(0); i < ls; i++ {
 		num, bytesRead, err := leb128.DecodeUint32(r)
-		remaining -= int64(bytesRead) + 1 // +1 for the subsequent ReadByte
 		if err != nil {
 			return fmt.Errorf("read n of locals: %v", err)
-		} else if remaining < 0 {
-			return io.EOF
 		}
 
-		b, err := r.ReadByte()
+		b, tn, err := decodeLocalType(r, gc)
 		if err != nil {
-			return fmt.Errorf("read type of local: %v", err)
+			return err
+		}
+		remaining -= int64(bytesRead) + int64(tn)
+		if remaining < 0 {
+			return io.EOF
 		}
 
 		for j := uint32(0); j < num; j++ {
@@ -98,3 +93,28 @@ func decodeCode(r *bytes.Reader, codeSectionStart uint64, ret *wasm.Code) (err e
 	ret.Body = body
 	return nil
 }
+
+// decodeLocalType decodes the type of a local, returning it with the count of
+// bytes read. gc is the module when api.CoreFeatureGC is enabled, or nil.
+func decodeLocalType(r *bytes.Reader, gc *wasm.Module) (wasm.ValueType, uint64, error) {
+	if gc != nil {
+		before := r.Len()
+		vt, err := decodeValueTypeWithModule(r, gc)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid local type: %v", err)
+		}
+		return vt, uint64(before - r.Len()), nil
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, fmt.Errorf("read type of local: %v", err)
+	}
+	switch vt := b; vt {
+	case wasm.ValueTypeI32, wasm.ValueTypeF32, wasm.ValueTypeI64, wasm.ValueTypeF64,
+		wasm.ValueTypeFuncref, wasm.ValueTypeExternref, wasm.ValueTypeV128:
+	default:
+		return 0, 0, fmt.Errorf("invalid local type: 0x%x", vt)
+	}
+	return b, 1, nil
+}
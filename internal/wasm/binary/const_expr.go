@@ -93,7 +93,20 @@ func decodeConstantExpression(r *bytes.Reader, enabledFeatures api.CoreFeatures,
 	}
 
 	if b != wasm.OpcodeEnd {
-		return fmt.Errorf("constant expression has been not terminated")
+		switch opcode {
+		case wasm.OpcodeI32Const, wasm.OpcodeI64Const, wasm.OpcodeGlobalGet:
+		default:
+			return fmt.Errorf("constant expression has been not terminated")
+		}
+		if !enabledFeatures.IsEnabled(api.CoreFeatureExtendedConst) {
+			return fmt.Errorf("constant expression has been not terminated")
+		}
+		// The data of an extended expression includes the first opcode.
+		offsetAtData--
+		remainingBeforeData++
+		if opcode, err = decodeExtendedConstExpression(r, b); err != nil {
+			return err
+		}
 	}
 
 	ret.Data = make([]byte, remainingBeforeData-int64(r.Len())-1)
@@ -103,3 +116,36 @@ func decodeConstantExpression(r *bytes.Reader, enabledFeatures api.CoreFeatures,
 	ret.Opcode = opcode
 	return nil
 }
+
+// decodeExtendedConstExpression reads the instructions of a constant
+// expression from the second one, b, until the end opcode. This returns the
+// last instruction, which must be an arithmetic instruction allowed by
+// api.CoreFeatureExtendedConst.
+func decodeExtendedConstExpression(r *bytes.Reader, b byte) (opcode wasm.Opcode, err error) {
+	for b != wasm.OpcodeEnd {
+		opcode = b
+		switch opcode {
+		case wasm.OpcodeI32Const:
+			_, _, err = leb128.DecodeInt32(r)
+		case wasm.OpcodeI64Const:
+			_, _, err = leb128.DecodeInt64(r)
+		case wasm.OpcodeGlobalGet:
+			_, _, err = leb128.DecodeUint32(r)
+		case wasm.OpcodeI32Add, wasm.OpcodeI32Sub, wasm.OpcodeI32Mul,
+			wasm.OpcodeI64Add, wasm.OpcodeI64Sub, wasm.OpcodeI64Mul:
+		default:
+			return 0, fmt.Errorf("%v for const expression opt code: %#x", ErrInvalidByte, b)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read value: %v", err)
+		}
+		if b, err = r.ReadByte(); err != nil {
+			return 0, fmt.Errorf("look for end opcode: %v", err)
+		}
+	}
+	if !wasm.IsExtendedConstOpcode(opcode) {
+		return 0, fmt.Errorf("constant expression must end with an arithmetic instruction, but was %s",
+			wasm.InstructionName(opcode))
+	}
+	return opcode, nil
+}
@@ -104,7 +104,7 @@ func DecodeModule(
 				m.NameSection, err = decodeNameSection(r, uint64(limit))
 			}
 		case wasm.SectionIDType:
-			m.TypeSection, err = decodeTypeSection(enabledFeatures, r)
+			m.TypeSection, m.CompositeTypes, err = decodeTypeSection(enabledFeatures, r)
 		case wasm.SectionIDImport:
 			m.ImportSection, m.ImportPerModule, m.ImportFunctionCount, m.ImportGlobalCount, m.ImportMemoryCount, m.ImportTableCount, err = decodeImportSection(r, memSizer, memoryLimitPages, enabledFeatures)
 			if err != nil {
@@ -130,7 +130,11 @@ func DecodeModule(
 		case wasm.SectionIDElement:
 			m.ElementSection, err = decodeElementSection(r, enabledFeatures)
 		case wasm.SectionIDCode:
-			m.CodeSection, err = decodeCodeSection(r)
+			var gc *wasm.Module
+			if enabledFeatures.IsEnabled(api.CoreFeatureGC) {
+				gc = m
+			}
+			m.CodeSection, err = decodeCodeSection(r, gc)
 		case wasm.SectionIDData:
 			m.DataSection, err = decodeDataSection(r, enabledFeatures)
 		case wasm.SectionIDDataCount:
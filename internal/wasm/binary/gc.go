@@ -0,0 +1,245 @@
+package binary
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// typeSectionFixup is a value type in the type section which references a
+// type by index. The kind of that type may not be known until the end of the
+// section, as types in a recursive group can reference each other.
+type typeSectionFixup struct {
+	vt        *wasm.ValueType
+	typeIndex int64
+}
+
+// decodeTypeSectionGC decodes a type section of vs recursive type groups, as
+// defined by api.CoreFeatureGC. A struct or array type is added to both results,
+// with an empty wasm.FunctionType.
+//
+// See https://github.com/WebAssembly/gc/blob/main/proposals/gc/MVP.md#type-definitions-1
+func decodeTypeSectionGC(enabledFeatures api.CoreFeatures, r *bytes.Reader, vs uint32) ([]wasm.FunctionType, []wasm.CompositeType, error) {
+	var types []wasm.FunctionType
+	var composites []wasm.CompositeType
+	var fixups []typeSectionFixup
+	for i := uint32(0); i < vs; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read %d-th type: %v", i, err)
+		}
+		count := uint32(1)
+		if b == 0x4e { // rec
+			if count, _, err = leb128.DecodeUint32(r); err != nil {
+				return nil, nil, fmt.Errorf("read %d-th type: could not read recursive type count: %v", i, err)
+			}
+		} else if err = r.UnreadByte(); err != nil {
+			return nil, nil, err
+		}
+		for j := uint32(0); j < count; j++ {
+			var ft wasm.FunctionType
+			var ct wasm.CompositeType
+			if fixups, err = decodeSubType(enabledFeatures, r, &ft, &ct, fixups); err != nil {
+				return nil, nil, fmt.Errorf("read %d-th type: %v", len(types), err)
+			}
+			types = append(types, ft)
+			composites = append(composites, ct)
+		}
+	}
+
+	for _, f := range fixups {
+		if f.typeIndex >= int64(len(types)) {
+			return nil, nil, fmt.Errorf("unknown type index: %d", f.typeIndex)
+		}
+		if composites[f.typeIndex].Kind == wasm.CompositeKindFunc {
+			*f.vt = wasm.ValueTypeFuncref
+		} else {
+			*f.vt = wasm.ValueTypeAnyref
+		}
+	}
+	for i := range types {
+		_ = types[i].String() // cache the key for the function type
+	}
+	return types, composites, nil
+}
+
+func decodeSubType(
+	enabledFeatures api.CoreFeatures,
+	r *bytes.Reader,
+	ft *wasm.FunctionType,
+	ct *wasm.CompositeType,
+	fixups []typeSectionFixup,
+) ([]typeSectionFixup, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("read leading byte: %w", err)
+	}
+
+	ct.Final = true
+	if b == 0x50 || b == 0x4f { // sub or sub final
+		ct.Final = b == 0x4f
+		count, _, err := leb128.DecodeUint32(r)
+		if err != nil {
+			return nil, fmt.Errorf("could not read supertype count: %w", err)
+		} else if count > 1 {
+			return nil, fmt.Errorf("too many supertypes: %d", count)
+		}
+		for i := uint32(0); i < count; i++ {
+			index, _, err := leb128.DecodeUint32(r)
+			if err != nil {
+				return nil, fmt.Errorf("could not read supertype: %w", err)
+			}
+			ct.SuperTypes = append(ct.SuperTypes, index)
+		}
+		if b, err = r.ReadByte(); err != nil {
+			return nil, fmt.Errorf("read composite type: %w", err)
+		}
+	}
+
+	switch b {
+	case 0x60: // func
+		ct.Kind = wasm.CompositeKindFunc
+		if ft.Params, fixups, err = decodeValueTypesGC(r, "parameter", fixups); err != nil {
+			return nil, err
+		}
+		if ft.Results, fixups, err = decodeValueTypesGC(r, "result", fixups); err != nil {
+			return nil, err
+		}
+		if len(ft.Results) > 1 {
+			if err = enabledFeatures.RequireEnabled(api.CoreFeatureMultiValue); err != nil {
+				return nil, fmt.Errorf("multiple result types invalid as %v", err)
+			}
+		}
+	case 0x5f: // struct
+		ct.Kind = wasm.CompositeKindStruct
+		count, _, err := leb128.DecodeUint32(r)
+		if err != nil {
+			return nil, fmt.Errorf("could not read field count: %w", err)
+		}
+		ct.Fields = make([]wasm.FieldType, count)
+		for i := range ct.Fields {
+			if fixups, err = decodeFieldType(r, &ct.Fields[i], fixups); err != nil {
+				return nil, fmt.Errorf("could not read field %d: %w", i, err)
+			}
+		}
+	case 0x5e: // array
+		ct.Kind = wasm.CompositeKindArray
+		ct.Fields = make([]wasm.FieldType, 1)
+		if fixups, err = decodeFieldType(r, &ct.Fields[0], fixups); err != nil {
+			return nil, fmt.Errorf("could not read element type: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid composite type %#x", ErrInvalidByte, b)
+	}
+	return fixups, nil
+}
+
+func decodeValueTypesGC(r *bytes.Reader, kind string, fixups []typeSectionFixup) ([]wasm.ValueType, []typeSectionFixup, error) {
+	count, _, err := leb128.DecodeUint32(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read %s count: %w", kind, err)
+	} else if count == 0 {
+		return nil, fixups, nil
+	}
+	ret := make([]wasm.ValueType, count)
+	for i := range ret {
+		vt, typeIndex, err := decodeValueTypeGC(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read %s types: %w", kind, err)
+		}
+		ret[i] = vt
+		if typeIndex >= 0 {
+			fixups = append(fixups, typeSectionFixup{vt: &ret[i], typeIndex: typeIndex})
+		}
+	}
+	return ret, fixups, nil
+}
+
+func decodeFieldType(r *bytes.Reader, ret *wasm.FieldType, fixups []typeSectionFixup) ([]typeSectionFixup, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b == wasm.StorageTypeI8 || b == wasm.StorageTypeI16 {
+		ret.StorageType = b
+	} else if err = r.UnreadByte(); err != nil {
+		return nil, err
+	} else {
+		vt, typeIndex, err := decodeValueTypeGC(r)
+		if err != nil {
+			return nil, err
+		} else if vt == wasm.ValueTypeV128 {
+			return nil, fmt.Errorf("v128 fields are not supported")
+		}
+		ret.StorageType = vt
+		if typeIndex >= 0 {
+			fixups = append(fixups, typeSectionFixup{vt: &ret.StorageType, typeIndex: typeIndex})
+		}
+	}
+
+	switch mut, err := r.ReadByte(); {
+	case err != nil:
+		return nil, fmt.Errorf("read mutability: %w", err)
+	case mut == 0x00:
+	case mut == 0x01:
+		ret.Mutable = true
+	default:
+		return nil, fmt.Errorf("invalid mutability: 0x%x", mut)
+	}
+	return fixups, nil
+}
+
+// decodeValueTypeGC decodes a value type, including the reference types of
+// api.CoreFeatureGC. A reference to a type index returns that index instead of
+// the value type, for the caller to resolve. Otherwise, typeIndex is -1.
+func decodeValueTypeGC(r *bytes.Reader) (vt wasm.ValueType, typeIndex int64, err error) {
+	if vt, err = r.ReadByte(); err != nil {
+		return 0, 0, err
+	}
+	switch vt {
+	case wasm.ValueTypeI32, wasm.ValueTypeF32, wasm.ValueTypeI64, wasm.ValueTypeF64,
+		wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeV128:
+		return vt, -1, nil
+	case 0x63, 0x64: // (ref null ht) and (ref ht)
+		ht, err := decodeHeapType(r)
+		if err != nil {
+			return 0, 0, err
+		}
+		if vt, ok := ht.ValueType(); ok {
+			return vt, -1, nil
+		}
+		return 0, int64(ht), nil
+	}
+	// Otherwise, this is the shorthand of a nullable abstract heap type, e.g. eqref.
+	if vt, ok := wasm.HeapType(int8(vt<<1) >> 1).ValueType(); ok {
+		return vt, -1, nil
+	}
+	return 0, 0, fmt.Errorf("invalid value type: %d", vt)
+}
+
+// decodeHeapType decodes a heap type, which is a type index or an abstract
+// heap type encoded as a negative number.
+func decodeHeapType(r *bytes.Reader) (wasm.HeapType, error) {
+	raw, _, err := leb128.DecodeInt33AsInt64(r)
+	if err != nil {
+		return 0, fmt.Errorf("read heap type: %w", err)
+	}
+	ht := wasm.HeapType(raw)
+	if _, ok := ht.ValueType(); !ok && ht < 0 {
+		return 0, fmt.Errorf("invalid heap type: %d", raw)
+	}
+	return ht, nil
+}
+
+// decodeValueTypeWithModule is like decodeValueTypeGC, except type indexes are
+// resolved against the already decoded type section of m.
+func decodeValueTypeWithModule(r *bytes.Reader, m *wasm.Module) (wasm.ValueType, error) {
+	vt, typeIndex, err := decodeValueTypeGC(r)
+	if err != nil || typeIndex < 0 {
+		return vt, err
+	}
+	return m.HeapTypeValueType(wasm.HeapType(typeIndex))
+}
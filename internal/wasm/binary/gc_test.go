@@ -0,0 +1,123 @@
+package binary
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+func TestDecodeTypeSectionGC(t *testing.T) {
+	features := api.CoreFeaturesV2 | api.CoreFeatureGC
+	i32, funcRef, anyRef := wasm.ValueTypeI32, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref
+
+	tests := []struct {
+		name               string
+		input              []byte
+		count              uint32
+		expectedTypes      []wasm.FunctionType
+		expectedComposites []wasm.CompositeType
+	}{
+		{
+			name:               "func",
+			input:              []byte{0x60, 1, i32, 1, i32},
+			count:              1,
+			expectedTypes:      []wasm.FunctionType{{Params: []wasm.ValueType{i32}, Results: []wasm.ValueType{i32}}},
+			expectedComposites: []wasm.CompositeType{{Kind: wasm.CompositeKindFunc, Final: true}},
+		},
+		{
+			name: "recursive group",
+			input: []byte{
+				0x4e, 2,
+				0x5f, 1, 0x63, 1, 0, // (struct (field (ref null 1)))
+				0x60, 1, 0x64, 0, 0, // (func (param (ref 0)))
+			},
+			count:         1,
+			expectedTypes: []wasm.FunctionType{{}, {Params: []wasm.ValueType{anyRef}}},
+			expectedComposites: []wasm.CompositeType{
+				{Kind: wasm.CompositeKindStruct, Fields: []wasm.FieldType{{StorageType: funcRef}}, Final: true},
+				{Kind: wasm.CompositeKindFunc, Final: true},
+			},
+		},
+		{
+			name: "sub types",
+			input: []byte{
+				0x50, 0, 0x5e, wasm.StorageTypeI16, 1, // (sub (array (mut i16)))
+				0x4f, 1, 0, 0x5e, wasm.StorageTypeI16, 1, // (sub final 0 (array (mut i16)))
+				0x5f, 2, 0x6d, 0, 0x6a, 0, // (struct (field eqref) (field i31ref))
+			},
+			count:         3,
+			expectedTypes: []wasm.FunctionType{{}, {}, {}},
+			expectedComposites: []wasm.CompositeType{
+				{Kind: wasm.CompositeKindArray, Fields: []wasm.FieldType{{StorageType: wasm.StorageTypeI16, Mutable: true}}},
+				{
+					Kind:       wasm.CompositeKindArray,
+					Fields:     []wasm.FieldType{{StorageType: wasm.StorageTypeI16, Mutable: true}},
+					SuperTypes: []wasm.Index{0},
+					Final:      true,
+				},
+				{Kind: wasm.CompositeKindStruct, Fields: []wasm.FieldType{{StorageType: anyRef}, {StorageType: anyRef}}, Final: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			types, composites, err := decodeTypeSectionGC(features, bytes.NewReader(tc.input), tc.count)
+			require.NoError(t, err)
+			require.Equal(t, len(tc.expectedTypes), len(types))
+			for i := range types {
+				require.Equal(t, tc.expectedTypes[i].Params, types[i].Params)
+				require.Equal(t, tc.expectedTypes[i].Results, types[i].Results)
+			}
+			require.Equal(t, tc.expectedComposites, composites)
+		})
+	}
+}
+
+func TestDecodeTypeSectionGC_Errors(t *testing.T) {
+	features := api.CoreFeaturesV2 | api.CoreFeatureGC
+
+	tests := []struct {
+		name        string
+		input       []byte
+		expectedErr string
+	}{
+		{
+			name:        "invalid composite type",
+			input:       []byte{0x40},
+			expectedErr: "read 0-th type: invalid byte: invalid composite type 0x40",
+		},
+		{
+			name:        "too many supertypes",
+			input:       []byte{0x50, 2, 0, 0, 0x5f, 0},
+			expectedErr: "read 0-th type: too many supertypes: 2",
+		},
+		{
+			name:        "v128 field",
+			input:       []byte{0x5f, 1, wasm.ValueTypeV128, 0},
+			expectedErr: "read 0-th type: could not read field 0: v128 fields are not supported",
+		},
+		{
+			name:        "invalid mutability",
+			input:       []byte{0x5e, wasm.ValueTypeI32, 2},
+			expectedErr: "read 0-th type: could not read element type: invalid mutability: 0x2",
+		},
+		{
+			name:        "unknown type index",
+			input:       []byte{0x5e, 0x63, 1, 0},
+			expectedErr: "unknown type index: 1",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := decodeTypeSectionGC(features, bytes.NewReader(tc.input), 1)
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
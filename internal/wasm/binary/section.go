@@ -10,19 +10,23 @@ import (
 	"github.com/tetratelabs/wazero/internal/wasm"
 )
 
-func decodeTypeSection(enabledFeatures api.CoreFeatures, r *bytes.Reader) ([]wasm.FunctionType, error) {
+func decodeTypeSection(enabledFeatures api.CoreFeatures, r *bytes.Reader) ([]wasm.FunctionType, []wasm.CompositeType, error) {
 	vs, _, err := leb128.DecodeUint32(r)
 	if err != nil {
-		return nil, fmt.Errorf("get size of vector: %w", err)
+		return nil, nil, fmt.Errorf("get size of vector: %w", err)
+	}
+
+	if enabledFeatures.IsEnabled(api.CoreFeatureGC) {
+		return decodeTypeSectionGC(enabledFeatures, r, vs)
 	}
 
 	result := make([]wasm.FunctionType, vs)
 	for i := uint32(0); i < vs; i++ {
 		if err = decodeFunctionType(enabledFeatures, r, &result[i]); err != nil {
-			return nil, fmt.Errorf("read %d-th type: %v", i, err)
+			return nil, nil, fmt.Errorf("read %d-th type: %v", i, err)
 		}
 	}
-	return result, nil
+	return result, nil, nil
 }
 
 // decodeImportSection decodes the decoded import segments plus the count per wasm.ExternType.
@@ -183,7 +187,10 @@ func decodeElementSection(r *bytes.Reader, enabledFeatures api.CoreFeatures) ([]
 	return result, nil
 }
 
-func decodeCodeSection(r *bytes.Reader) ([]wasm.Code, error) {
+// decodeCodeSection decodes the code section. When api.CoreFeatureGC is
+// enabled, gc is the module with its type section already decoded, or nil
+// otherwise.
+func decodeCodeSection(r *bytes.Reader, gc *wasm.Module) ([]wasm.Code, error) {
 	codeSectionStart := uint64(r.Len())
 	vs, _, err := leb128.DecodeUint32(r)
 	if err != nil {
@@ -192,7 +199,7 @@ func decodeCodeSection(r *bytes.Reader) ([]wasm.Code, error) {
 
 	result := make([]wasm.Code, vs)
 	for i := uint32(0); i < vs; i++ {
-		err = decodeCode(r, codeSectionStart, &result[i])
+		err = decodeCode(r, codeSectionStart, gc, &result[i])
 		if err != nil {
 			return nil, fmt.Errorf("read %d-th code segment: %v", i, err)
 		}
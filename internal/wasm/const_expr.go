@@ -0,0 +1,115 @@
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+)
+
+// IsExtendedConstOpcode returns true if the opcode is an arithmetic
+// instruction allowed in a ConstantExpression by api.CoreFeatureExtendedConst.
+func IsExtendedConstOpcode(opcode Opcode) bool {
+	switch opcode {
+	case OpcodeI32Add, OpcodeI32Sub, OpcodeI32Mul, OpcodeI64Add, OpcodeI64Sub, OpcodeI64Mul:
+		return true
+	}
+	return false
+}
+
+// validateExtendedConstExpression type-checks the instructions of an extended
+// ConstantExpression, returning the type of its only result.
+func validateExtendedConstExpression(globals []GlobalType, data []byte) (ValueType, error) {
+	var stack []ValueType
+	for pc := uint64(0); pc < uint64(len(data)); {
+		opcode := data[pc]
+		pc++
+		switch opcode {
+		case OpcodeI32Const:
+			// Treat constants as signed as their interpretation is not yet known per /RATIONALE.md
+			_, n, err := leb128.LoadInt32(data[pc:])
+			if err != nil {
+				return 0, fmt.Errorf("read i32: %w", err)
+			}
+			pc += n
+			stack = append(stack, ValueTypeI32)
+		case OpcodeI64Const:
+			// Treat constants as signed as their interpretation is not yet known per /RATIONALE.md
+			_, n, err := leb128.LoadInt64(data[pc:])
+			if err != nil {
+				return 0, fmt.Errorf("read i64: %w", err)
+			}
+			pc += n
+			stack = append(stack, ValueTypeI64)
+		case OpcodeGlobalGet:
+			id, n, err := leb128.LoadUint32(data[pc:])
+			if err != nil {
+				return 0, fmt.Errorf("read index of global: %w", err)
+			}
+			if uint32(len(globals)) <= id {
+				return 0, fmt.Errorf("global index out of range")
+			}
+			pc += n
+			stack = append(stack, globals[id].ValType)
+		case OpcodeI32Add, OpcodeI32Sub, OpcodeI32Mul, OpcodeI64Add, OpcodeI64Sub, OpcodeI64Mul:
+			vt := ValueTypeI32
+			if opcode >= OpcodeI64Add {
+				vt = ValueTypeI64
+			}
+			if l := len(stack); l < 2 || stack[l-1] != vt || stack[l-2] != vt {
+				return 0, fmt.Errorf("cannot use %s in const expression: operands must be two %s values",
+					InstructionName(opcode), ValueTypeName(vt))
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			return 0, fmt.Errorf("invalid opcode for const expression: 0x%x", opcode)
+		}
+	}
+	if len(stack) != 1 {
+		return 0, fmt.Errorf("const expression must result in one value, but had %d", len(stack))
+	}
+	return stack[0], nil
+}
+
+// executeExtendedConstExpression returns the result of an extended
+// ConstantExpression, whose validity is ensured by validateConstExpression.
+func executeExtendedConstExpression(importedGlobals []*GlobalInstance, data []byte) uint64 {
+	var stack []uint64
+	for pc := uint64(0); pc < uint64(len(data)); {
+		opcode := data[pc]
+		pc++
+		switch opcode {
+		case OpcodeI32Const:
+			v, n, _ := leb128.LoadInt32(data[pc:])
+			pc += n
+			stack = append(stack, uint64(uint32(v)))
+		case OpcodeI64Const:
+			v, n, _ := leb128.LoadInt64(data[pc:])
+			pc += n
+			stack = append(stack, uint64(v))
+		case OpcodeGlobalGet:
+			id, n, _ := leb128.LoadUint32(data[pc:])
+			pc += n
+			stack = append(stack, importedGlobals[id].Val)
+		default:
+			x, y := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			var v uint64
+			switch opcode {
+			case OpcodeI32Add:
+				v = uint64(uint32(x) + uint32(y))
+			case OpcodeI32Sub:
+				v = uint64(uint32(x) - uint32(y))
+			case OpcodeI32Mul:
+				v = uint64(uint32(x) * uint32(y))
+			case OpcodeI64Add:
+				v = x + y
+			case OpcodeI64Sub:
+				v = x - y
+			case OpcodeI64Mul:
+				v = x * y
+			}
+			stack = append(stack, v)
+		}
+	}
+	return stack[0]
+}
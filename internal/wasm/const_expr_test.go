@@ -0,0 +1,154 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestExtendedConstExpression(t *testing.T) {
+	globals := []GlobalType{{ValType: ValueTypeI32}, {ValType: ValueTypeI64}}
+	globalInstances := []*GlobalInstance{
+		{Type: globals[0], Val: uint64(uint32(10))},
+		{Type: globals[1], Val: 1 << 40},
+	}
+
+	tests := []struct {
+		name     string
+		expr     ConstantExpression
+		expected uint64
+		vt       ValueType
+	}{
+		{
+			name: "i32.add",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeGlobalGet, 0, OpcodeI32Const, 5, OpcodeI32Add,
+			}},
+			expected: 15,
+			vt:       ValueTypeI32,
+		},
+		{
+			name: "i32.sub wraps",
+			expr: ConstantExpression{Opcode: OpcodeI32Sub, Data: []byte{
+				OpcodeI32Const, 0, OpcodeI32Const, 1, OpcodeI32Sub,
+			}},
+			expected: 0xffffffff,
+			vt:       ValueTypeI32,
+		},
+		{
+			name: "i32.mul",
+			expr: ConstantExpression{Opcode: OpcodeI32Mul, Data: []byte{
+				OpcodeGlobalGet, 0, OpcodeGlobalGet, 0, OpcodeI32Mul,
+			}},
+			expected: 100,
+			vt:       ValueTypeI32,
+		},
+		{
+			name: "i64.sub of i64.mul",
+			expr: ConstantExpression{Opcode: OpcodeI64Sub, Data: []byte{
+				OpcodeGlobalGet, 1, OpcodeI64Const, 2, OpcodeI64Const, 3, OpcodeI64Mul, OpcodeI64Sub,
+			}},
+			expected: 1<<40 - 6,
+			vt:       ValueTypeI64,
+		},
+		{
+			name: "i64.add negative",
+			expr: ConstantExpression{Opcode: OpcodeI64Add, Data: []byte{
+				OpcodeI64Const, 0x7f, OpcodeI64Const, 0x7f, OpcodeI64Add, // -1 + -1
+			}},
+			expected: 0xfffffffffffffffe,
+			vt:       ValueTypeI64,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, validateConstExpression(globals, 0, &tc.expr, tc.vt))
+
+			g := &GlobalInstance{Type: GlobalType{ValType: tc.vt}}
+			g.initialize(globalInstances, &tc.expr, nil)
+			require.Equal(t, tc.expected, g.Val)
+
+			if tc.vt == ValueTypeI32 {
+				require.Equal(t, uint32(tc.expected), uint32(executeConstExpressionI32(globalInstances, &tc.expr)))
+			}
+		})
+	}
+}
+
+func TestExtendedConstExpression_validate_errors(t *testing.T) {
+	globals := []GlobalType{{ValType: ValueTypeI32}, {ValType: ValueTypeF32}}
+
+	tests := []struct {
+		name        string
+		expr        ConstantExpression
+		vt          ValueType
+		expectedErr string
+	}{
+		{
+			name: "result type mismatch",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeI32Const, 1, OpcodeI32Const, 2, OpcodeI32Add,
+			}},
+			vt:          ValueTypeI64,
+			expectedErr: "const expression type mismatch expected i64 but got i32",
+		},
+		{
+			name: "operand type mismatch",
+			expr: ConstantExpression{Opcode: OpcodeI64Add, Data: []byte{
+				OpcodeI32Const, 1, OpcodeI64Const, 2, OpcodeI64Add,
+			}},
+			vt:          ValueTypeI64,
+			expectedErr: "cannot use i64.add in const expression: operands must be two i64 values",
+		},
+		{
+			name: "global operand type mismatch",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeGlobalGet, 1, OpcodeI32Const, 2, OpcodeI32Add,
+			}},
+			vt:          ValueTypeI32,
+			expectedErr: "cannot use i32.add in const expression: operands must be two i32 values",
+		},
+		{
+			name: "missing operand",
+			expr: ConstantExpression{Opcode: OpcodeI32Mul, Data: []byte{
+				OpcodeI32Const, 1, OpcodeI32Mul,
+			}},
+			vt:          ValueTypeI32,
+			expectedErr: "cannot use i32.mul in const expression: operands must be two i32 values",
+		},
+		{
+			name: "too many results",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeI32Const, 1, OpcodeI32Const, 2, OpcodeI32Const, 3, OpcodeI32Add,
+			}},
+			vt:          ValueTypeI32,
+			expectedErr: "const expression must result in one value, but had 2",
+		},
+		{
+			name: "global index out of range",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeGlobalGet, 2, OpcodeI32Const, 2, OpcodeI32Add,
+			}},
+			vt:          ValueTypeI32,
+			expectedErr: "global index out of range",
+		},
+		{
+			name: "invalid opcode",
+			expr: ConstantExpression{Opcode: OpcodeI32Add, Data: []byte{
+				OpcodeI32Const, 1, OpcodeI32Const, 2, OpcodeI32DivS, OpcodeI32Add,
+			}},
+			vt:          ValueTypeI32,
+			expectedErr: "invalid opcode for const expression: 0x6d",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateConstExpression(globals, 0, &tc.expr, tc.vt)
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
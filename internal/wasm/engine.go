@@ -24,6 +24,10 @@ type Engine interface {
 	// module instances have outstanding calls.
 	DeleteCompiledModule(module *Module)
 
+	// CompiledCodeSizes returns the size in bytes of the native code of each function defined in module, in index
+	// order, or nil if the engine doesn't compile to native code or module isn't compiled.
+	CompiledCodeSizes(module *Module) []uint64
+
 	// NewModuleEngine compiles down the function instances in a module, and returns ModuleEngine for the module.
 	//
 	// * module is the source module from which moduleFunctions are instantiated. This is used for caching.
@@ -45,10 +49,22 @@ type ModuleEngine interface {
 	//	- `importedModuleEngine` is the ModuleEngine for the imported ModuleInstance.
 	ResolveImportedFunction(index, indexInImportedModule Index, importedModuleEngine ModuleEngine)
 
+	// ReplaceGoFunction replaces the Go implementation of the host function
+	// at `index`, which must be defined by this module with Code.GoFunc. This
+	// is safe to call while the function is called.
+	//
+	// Note: Instances of the same compiled module share the implementation.
+	ReplaceGoFunction(index Index, goFunc interface{})
+
 	// LookupFunction returns the api.Function created from the function in the function table at the given offset.
 	LookupFunction(t *TableInstance, typeId FunctionTypeID, tableOffset Index) (api.Function, error)
 
 	// FunctionInstanceReference returns Reference for the given Index for a FunctionInstance. The returned values are used by
 	// the initialization via ElementSegment.
 	FunctionInstanceReference(funcIndex Index) Reference
+
+	// Close releases the resources held for the module instance, such as the
+	// objects it allocated with api.CoreFeatureGC instructions. This is
+	// called once, when the module instance is closed.
+	Close(ctx context.Context)
 }
@@ -554,7 +554,7 @@ func (m *Module) validateFunctionWithMaxStackValues(
 			}
 			pc += num
 
-			if int(typeIndex) >= len(m.TypeSection) {
+			if int(typeIndex) >= len(m.TypeSection) || !m.IsFunctionType(typeIndex) {
 				return fmt.Errorf("invalid type index at %s: %d", OpcodeCallIndirectName, typeIndex)
 			}
 
@@ -810,6 +810,19 @@ func (m *Module) validateFunctionWithMaxStackValues(
 			switch op {
 			case OpcodeRefNull:
 				pc++
+				if enabledFeatures.IsEnabled(api.CoreFeatureGC) {
+					ht, num, err := LoadHeapType(body[pc:])
+					if err != nil {
+						return fmt.Errorf("failed to read heap type for ref.null: %v", err)
+					}
+					vt, err := m.HeapTypeValueType(ht)
+					if err != nil {
+						return fmt.Errorf("unknown type for ref.null: %v", err)
+					}
+					pc += num - 1
+					valueTypeStack.push(vt)
+					break
+				}
 				switch reftype := body[pc]; reftype {
 				case ValueTypeExternref:
 					valueTypeStack.push(ValueTypeExternref)
@@ -866,6 +879,34 @@ func (m *Module) validateFunctionWithMaxStackValues(
 				}
 			}
 			pc += num - 1
+		} else if op == OpcodeRefEq || op == OpcodeRefAsNonNull {
+			if err := enabledFeatures.RequireEnabled(api.CoreFeatureGC); err != nil {
+				return fmt.Errorf("%s invalid as %v", InstructionName(op), err)
+			}
+			if op == OpcodeRefEq {
+				for i := 0; i < 2; i++ {
+					if err := valueTypeStack.popAndVerifyType(ValueTypeAnyref); err != nil {
+						return fmt.Errorf("cannot pop the operand for %s: %v", OpcodeRefEqName, err)
+					}
+				}
+				valueTypeStack.push(ValueTypeI32)
+			} else {
+				tp, err := valueTypeStack.pop()
+				if err != nil {
+					return fmt.Errorf("cannot pop the operand for %s: %v", OpcodeRefAsNonNullName, err)
+				} else if !isReferenceValueType(tp) && tp != valueTypeUnknown {
+					return fmt.Errorf("type mismatch: expected reference type but was %s", ValueTypeName(tp))
+				}
+				valueTypeStack.push(tp)
+			}
+		} else if op == OpcodeGCPrefix {
+			if err := enabledFeatures.RequireEnabled(api.CoreFeatureGC); err != nil {
+				return fmt.Errorf("%s invalid as %v", InstructionName(op), err)
+			}
+			var err error
+			if pc, err = m.validateGCInstruction(valueTypeStack, body, pc); err != nil {
+				return err
+			}
 		} else if op == OpcodeMiscPrefix {
 			pc++
 			// A misc opcode is encoded as an unsigned variable 32-bit integer.
@@ -1390,7 +1431,7 @@ func (m *Module) validateFunctionWithMaxStackValues(
 			}
 		} else if op == OpcodeBlock {
 			br.Reset(body[pc+1:])
-			bt, num, err := DecodeBlockType(m.TypeSection, br, enabledFeatures)
+			bt, num, err := DecodeBlockType(m.TypeSection, m.CompositeTypes, br, enabledFeatures)
 			if err != nil {
 				return fmt.Errorf("read block: %w", err)
 			}
@@ -1406,7 +1447,7 @@ func (m *Module) validateFunctionWithMaxStackValues(
 			pc += num
 		} else if op == OpcodeLoop {
 			br.Reset(body[pc+1:])
-			bt, num, err := DecodeBlockType(m.TypeSection, br, enabledFeatures)
+			bt, num, err := DecodeBlockType(m.TypeSection, m.CompositeTypes, br, enabledFeatures)
 			if err != nil {
 				return fmt.Errorf("read block: %w", err)
 			}
@@ -1422,7 +1463,7 @@ func (m *Module) validateFunctionWithMaxStackValues(
 			pc += num
 		} else if op == OpcodeIf {
 			br.Reset(body[pc+1:])
-			bt, num, err := DecodeBlockType(m.TypeSection, br, enabledFeatures)
+			bt, num, err := DecodeBlockType(m.TypeSection, m.CompositeTypes, br, enabledFeatures)
 			if err != nil {
 				return fmt.Errorf("read block: %w", err)
 			}
@@ -1533,7 +1574,8 @@ func (m *Module) validateFunctionWithMaxStackValues(
 				pc++
 				tp := body[pc]
 				if tp != ValueTypeI32 && tp != ValueTypeI64 && tp != ValueTypeF32 && tp != ValueTypeF64 &&
-					tp != api.ValueTypeExternref && tp != ValueTypeFuncref && tp != ValueTypeV128 {
+					tp != api.ValueTypeExternref && tp != ValueTypeFuncref && tp != ValueTypeV128 &&
+					(tp != ValueTypeAnyref || !enabledFeatures.IsEnabled(api.CoreFeatureGC)) {
 					return fmt.Errorf("invalid type %s for %s", ValueTypeName(tp), OpcodeTypedSelectName)
 				}
 			} else if isReferenceValueType(v1) || isReferenceValueType(v2) {
@@ -1886,9 +1928,12 @@ type controlBlock struct {
 // WebAssembly 1.0 (20191205) compatible result type. Positive numbers are decoded when `enabledFeatures` include
 // CoreFeatureMultiValue and include an index in the Module.TypeSection.
 //
+// When `enabledFeatures` include CoreFeatureGC, the result type can also be a reference type of that proposal, and
+// `composites` are the Module.CompositeTypes used to resolve a type index in it.
+//
 // See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#binary-blocktype
 // See https://github.com/WebAssembly/spec/blob/wg-2.0.draft1/proposals/multi-value/Overview.md
-func DecodeBlockType(types []FunctionType, r *bytes.Reader, enabledFeatures api.CoreFeatures) (*FunctionType, uint64, error) {
+func DecodeBlockType(types []FunctionType, composites []CompositeType, r *bytes.Reader, enabledFeatures api.CoreFeatures) (*FunctionType, uint64, error) {
 	raw, num, err := leb128.DecodeInt33AsInt64(r)
 	if err != nil {
 		return nil, 0, fmt.Errorf("decode int33: %w", err)
@@ -1913,17 +1958,48 @@ func DecodeBlockType(types []FunctionType, r *bytes.Reader, enabledFeatures api.
 	case -17: // 0x6f in original byte = externref
 		ret = blockType_v_externref
 	default:
+		if raw < 0 && enabledFeatures.IsEnabled(api.CoreFeatureGC) {
+			return decodeBlockTypeGC(types, composites, r, raw, num)
+		}
 		if err = enabledFeatures.RequireEnabled(api.CoreFeatureMultiValue); err != nil {
 			return nil, num, fmt.Errorf("block with function type return invalid as %v", err)
 		}
 		if raw < 0 || (raw >= int64(len(types))) {
 			return nil, 0, fmt.Errorf("type index out of range: %d", raw)
 		}
+		if composites != nil && composites[raw].Kind != CompositeKindFunc {
+			return nil, 0, fmt.Errorf("type index %d is not a function type", raw)
+		}
 		ret = &types[raw]
 	}
 	return ret, num, err
 }
 
+// decodeBlockTypeGC decodes the result type of a block which is a reference type of CoreFeatureGC, given the negative
+// number `raw` already read from `r` in `num` bytes.
+func decodeBlockTypeGC(types []FunctionType, composites []CompositeType, r *bytes.Reader, raw int64, num uint64) (*FunctionType, uint64, error) {
+	ht := HeapType(raw)
+	if raw == -0x1c || raw == -0x1d { // 0x64 (ref ht) or 0x63 (ref null ht)
+		v, n, err := leb128.DecodeInt33AsInt64(r)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decode heap type: %w", err)
+		}
+		ht, num = HeapType(v), num+n
+	}
+	m := &Module{TypeSection: types, CompositeTypes: composites}
+	vt, err := m.HeapTypeValueType(ht)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid block type: %w", err)
+	}
+	switch vt {
+	case ValueTypeFuncref:
+		return blockType_v_funcref, num, nil
+	case ValueTypeExternref:
+		return blockType_v_externref, num, nil
+	}
+	return blockType_v_anyref, num, nil
+}
+
 // These block types are defined as globals in order to avoid allocations in DecodeBlockType.
 var (
 	blockType_v_v         = &FunctionType{}
@@ -1934,6 +2010,7 @@ var (
 	blockType_v_v128      = &FunctionType{Results: []ValueType{ValueTypeV128}, ResultNumInUint64: 2}
 	blockType_v_funcref   = &FunctionType{Results: []ValueType{ValueTypeFuncref}, ResultNumInUint64: 1}
 	blockType_v_externref = &FunctionType{Results: []ValueType{ValueTypeExternref}, ResultNumInUint64: 1}
+	blockType_v_anyref    = &FunctionType{Results: []ValueType{ValueTypeAnyref}, ResultNumInUint64: 1}
 )
 
 // SplitCallStack returns the input stack resliced to the count of params and
@@ -3348,7 +3348,7 @@ func TestDecodeBlockType(t *testing.T) {
 		} {
 			tc := tc
 			t.Run(tc.name, func(t *testing.T) {
-				actual, read, err := DecodeBlockType(nil, bytes.NewReader([]byte{tc.in}), api.CoreFeaturesV2)
+				actual, read, err := DecodeBlockType(nil, nil, bytes.NewReader([]byte{tc.in}), api.CoreFeaturesV2)
 				require.NoError(t, err)
 				require.Equal(t, uint64(1), read)
 				require.Equal(t, 0, len(actual.Params))
@@ -3373,7 +3373,7 @@ func TestDecodeBlockType(t *testing.T) {
 		}
 		for index := range types {
 			expected := &types[index]
-			actual, read, err := DecodeBlockType(types, bytes.NewReader([]byte{byte(index)}), api.CoreFeatureMultiValue)
+			actual, read, err := DecodeBlockType(types, nil, bytes.NewReader([]byte{byte(index)}), api.CoreFeatureMultiValue)
 			require.NoError(t, err)
 			require.Equal(t, uint64(1), read)
 			require.Equal(t, expected, actual)
@@ -0,0 +1,392 @@
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+)
+
+// CompositeKind is the kind of a CompositeType.
+type CompositeKind byte
+
+const (
+	// CompositeKindFunc is a function type, defined in Module.TypeSection.
+	CompositeKindFunc CompositeKind = iota
+	// CompositeKindStruct is a struct type with zero or more fields.
+	CompositeKindStruct
+	// CompositeKindArray is an array type whose elements are its only field.
+	CompositeKindArray
+)
+
+// StorageType is the type of a struct field or an array element. This is
+// either a ValueType or a packed integer type.
+type StorageType = byte
+
+const (
+	// StorageTypeI8 is a packed 8-bit integer, read as i32.
+	StorageTypeI8 StorageType = 0x78
+	// StorageTypeI16 is a packed 16-bit integer, read as i32.
+	StorageTypeI16 StorageType = 0x77
+)
+
+// FieldType is the type of a struct field or an array element.
+type FieldType struct {
+	StorageType StorageType
+	Mutable     bool
+}
+
+// IsPacked returns true if the field must be read with a sign or zero
+// extension, e.g. `struct.get_s`.
+func (f FieldType) IsPacked() bool {
+	return f.StorageType == StorageTypeI8 || f.StorageType == StorageTypeI16
+}
+
+// ValueType returns the type used to read or write the field on the stack.
+func (f FieldType) ValueType() ValueType {
+	if f.IsPacked() {
+		return ValueTypeI32
+	}
+	return f.StorageType
+}
+
+// Wrap truncates the value v to the size of a packed field.
+func (f FieldType) Wrap(v uint64) uint64 {
+	switch f.StorageType {
+	case StorageTypeI8:
+		return v & 0xff
+	case StorageTypeI16:
+		return v & 0xffff
+	}
+	return v
+}
+
+// CompositeType is a type definition of CoreFeatureGC.
+//
+// See https://github.com/WebAssembly/gc/blob/main/proposals/gc/MVP.md#type-definitions
+type CompositeType struct {
+	Kind CompositeKind
+	// Fields are the fields of a struct, or the single element type of an array.
+	Fields []FieldType
+	// SuperTypes are the indexes of the declared supertypes, at most one.
+	SuperTypes []Index
+	// Final is true unless the type was declared with `sub` without `final`.
+	Final bool
+}
+
+// IsFunctionType returns true if the type at the index in Module.TypeSection
+// is a function type, and not a struct or an array type.
+func (m *Module) IsFunctionType(typeIndex Index) bool {
+	return m.CompositeTypes == nil || m.CompositeTypes[typeIndex].Kind == CompositeKindFunc
+}
+
+// HeapType is a heap type of CoreFeatureGC: a type index when not negative,
+// or one of the abstract heap types below.
+type HeapType int64
+
+// Abstract heap types are encoded as negative signed 33-bit integers.
+const (
+	HeapTypeNoFunc   HeapType = -0x0d // 0x73
+	HeapTypeNoExtern HeapType = -0x0e // 0x72
+	HeapTypeNone     HeapType = -0x0f // 0x71
+	HeapTypeFunc     HeapType = -0x10 // 0x70
+	HeapTypeExtern   HeapType = -0x11 // 0x6f
+	HeapTypeAny      HeapType = -0x12 // 0x6e
+	HeapTypeEq       HeapType = -0x13 // 0x6d
+	HeapTypeI31      HeapType = -0x14 // 0x6c
+	HeapTypeStruct   HeapType = -0x15 // 0x6b
+	HeapTypeArray    HeapType = -0x16 // 0x6a
+)
+
+// ValueType returns the value type of references to an abstract heap type,
+// or false if ht is a type index or invalid. References to the "any"
+// hierarchy are all ValueTypeAnyref.
+func (ht HeapType) ValueType() (ValueType, bool) {
+	switch ht {
+	case HeapTypeFunc, HeapTypeNoFunc:
+		return ValueTypeFuncref, true
+	case HeapTypeExtern, HeapTypeNoExtern:
+		return ValueTypeExternref, true
+	case HeapTypeAny, HeapTypeEq, HeapTypeI31, HeapTypeStruct, HeapTypeArray, HeapTypeNone:
+		return ValueTypeAnyref, true
+	}
+	return 0, false
+}
+
+// LoadHeapType reads the heap type immediate of an instruction, such as
+// `ref.null`, from the start of buf.
+func LoadHeapType(buf []byte) (HeapType, uint64, error) {
+	raw, num, err := leb128.LoadInt33AsInt64(buf)
+	if err != nil {
+		return 0, 0, fmt.Errorf("read heap type: %w", err)
+	}
+	ht := HeapType(raw)
+	if _, ok := ht.ValueType(); !ok && ht < 0 {
+		return 0, 0, fmt.Errorf("invalid heap type: %d", raw)
+	}
+	return ht, num, nil
+}
+
+// HeapTypeValueType is like HeapType.ValueType, except type indexes are
+// resolved against the types of this module.
+func (m *Module) HeapTypeValueType(ht HeapType) (ValueType, error) {
+	if vt, ok := ht.ValueType(); ok {
+		return vt, nil
+	}
+	if ht < 0 || ht >= HeapType(len(m.TypeSection)) {
+		return 0, fmt.Errorf("unknown heap type: %d", ht)
+	}
+	if m.IsFunctionType(Index(ht)) {
+		return ValueTypeFuncref, nil
+	}
+	return ValueTypeAnyref, nil
+}
+
+// validateCompositeTypes ensures each declared supertype is an earlier,
+// non-final type, which the subtype extends without changing its fields.
+func (m *Module) validateCompositeTypes() error {
+	for i := range m.CompositeTypes {
+		sub := &m.CompositeTypes[i]
+		if len(sub.SuperTypes) == 0 {
+			continue
+		} else if len(sub.SuperTypes) > 1 {
+			return fmt.Errorf("type[%d] has more than one supertype", i)
+		}
+		superIndex := sub.SuperTypes[0]
+		if superIndex >= Index(i) {
+			return fmt.Errorf("type[%d] has supertype %d which is not declared before it", i, superIndex)
+		}
+		super := &m.CompositeTypes[superIndex]
+		if super.Final {
+			return fmt.Errorf("type[%d] has final supertype %d", i, superIndex)
+		} else if !sub.matches(super) {
+			return fmt.Errorf("type[%d] does not match its supertype %d", i, superIndex)
+		}
+		if sub.Kind == CompositeKindFunc && !m.TypeSection[i].EqualsSignature(
+			m.TypeSection[superIndex].Params, m.TypeSection[superIndex].Results) {
+			return fmt.Errorf("type[%d] does not match its supertype %d", i, superIndex)
+		}
+	}
+	return nil
+}
+
+// matches returns true if c has the same kind as super, and starts with the
+// same fields.
+func (c *CompositeType) matches(super *CompositeType) bool {
+	if c.Kind != super.Kind || len(c.Fields) < len(super.Fields) {
+		return false
+	} else if c.Kind == CompositeKindArray && len(c.Fields) != len(super.Fields) {
+		return false
+	}
+	for i := range super.Fields {
+		if c.Fields[i] != super.Fields[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubtype returns true if the type at typeIndex in m is the type at
+// superIndex in super, or declares it as a direct or indirect supertype.
+//
+// Types of different modules match when they have the same structure, as
+// references to other types are all ValueTypeAnyref or ValueTypeFuncref.
+func (m *Module) IsSubtype(typeIndex Index, super *Module, superIndex Index) bool {
+	want := &super.CompositeTypes[superIndex]
+	for {
+		if m == super {
+			if typeIndex == superIndex {
+				return true
+			}
+		} else if have := &m.CompositeTypes[typeIndex]; have.Kind == want.Kind && len(have.Fields) == len(want.Fields) &&
+			have.matches(want) && have.Final == want.Final {
+			return true
+		}
+		superTypes := m.CompositeTypes[typeIndex].SuperTypes
+		if len(superTypes) == 0 {
+			return false
+		}
+		typeIndex = superTypes[0]
+	}
+}
+
+// validateGCInstruction validates the instruction prefixed by OpcodeGCPrefix
+// at body[pc], returning the position of its last byte.
+func (m *Module) validateGCInstruction(valueTypeStack *valueTypeStack, body []byte, pc uint64) (uint64, error) {
+	pc++
+	gcOp32, num, err := leb128.LoadUint32(body[pc:])
+	if err != nil {
+		return 0, fmt.Errorf("failed to read gc opcode: %v", err)
+	} else if uint32(byte(gcOp32)) != gcOp32 {
+		return 0, fmt.Errorf("invalid gc opcode: %#x", gcOp32)
+	}
+	pc += num - 1
+	gcOpcode := byte(gcOp32)
+	name := GCInstructionName(gcOpcode)
+
+	switch gcOpcode {
+	case OpcodeGCStructNew, OpcodeGCStructNewDefault:
+		ct, read, err := m.loadCompositeTypeImmediate(body[pc+1:], CompositeKindStruct, name)
+		if err != nil {
+			return 0, err
+		}
+		pc += read
+		if gcOpcode == OpcodeGCStructNew {
+			for i := len(ct.Fields) - 1; i >= 0; i-- {
+				if err = valueTypeStack.popAndVerifyType(ct.Fields[i].ValueType()); err != nil {
+					return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+				}
+			}
+		}
+		valueTypeStack.push(ValueTypeAnyref)
+	case OpcodeGCStructGet, OpcodeGCStructGetS, OpcodeGCStructGetU, OpcodeGCStructSet:
+		ct, read, err := m.loadCompositeTypeImmediate(body[pc+1:], CompositeKindStruct, name)
+		if err != nil {
+			return 0, err
+		}
+		pc += read
+		fieldIndex, read, err := leb128.LoadUint32(body[pc+1:])
+		if err != nil {
+			return 0, fmt.Errorf("read field index for %s: %v", name, err)
+		} else if fieldIndex >= uint32(len(ct.Fields)) {
+			return 0, fmt.Errorf("unknown field %d for %s", fieldIndex, name)
+		}
+		pc += read
+		field := ct.Fields[fieldIndex]
+		if err = validateFieldAccess(gcOpcode, field, name); err != nil {
+			return 0, err
+		}
+		if gcOpcode == OpcodeGCStructSet {
+			if err = valueTypeStack.popAndVerifyType(field.ValueType()); err != nil {
+				return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+			}
+		}
+		if err = valueTypeStack.popAndVerifyType(ValueTypeAnyref); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		if gcOpcode != OpcodeGCStructSet {
+			valueTypeStack.push(field.ValueType())
+		}
+	case OpcodeGCArrayNew, OpcodeGCArrayNewDefault, OpcodeGCArrayNewFixed:
+		ct, read, err := m.loadCompositeTypeImmediate(body[pc+1:], CompositeKindArray, name)
+		if err != nil {
+			return 0, err
+		}
+		pc += read
+		elem := ct.Fields[0].ValueType()
+		switch gcOpcode {
+		case OpcodeGCArrayNew:
+			if err = valueTypeStack.popAndVerifyType(ValueTypeI32); err != nil {
+				return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+			}
+			if err = valueTypeStack.popAndVerifyType(elem); err != nil {
+				return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+			}
+		case OpcodeGCArrayNewDefault:
+			if err = valueTypeStack.popAndVerifyType(ValueTypeI32); err != nil {
+				return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+			}
+		case OpcodeGCArrayNewFixed:
+			n, read, err := leb128.LoadUint32(body[pc+1:])
+			if err != nil {
+				return 0, fmt.Errorf("read length for %s: %v", name, err)
+			}
+			pc += read
+			for i := uint32(0); i < n; i++ {
+				if err = valueTypeStack.popAndVerifyType(elem); err != nil {
+					return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+				}
+			}
+		}
+		valueTypeStack.push(ValueTypeAnyref)
+	case OpcodeGCArrayGet, OpcodeGCArrayGetS, OpcodeGCArrayGetU, OpcodeGCArraySet:
+		ct, read, err := m.loadCompositeTypeImmediate(body[pc+1:], CompositeKindArray, name)
+		if err != nil {
+			return 0, err
+		}
+		pc += read
+		elem := ct.Fields[0]
+		if err = validateFieldAccess(gcOpcode, elem, name); err != nil {
+			return 0, err
+		}
+		if gcOpcode == OpcodeGCArraySet {
+			if err = valueTypeStack.popAndVerifyType(elem.ValueType()); err != nil {
+				return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+			}
+		}
+		if err = valueTypeStack.popAndVerifyType(ValueTypeI32); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		if err = valueTypeStack.popAndVerifyType(ValueTypeAnyref); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		if gcOpcode != OpcodeGCArraySet {
+			valueTypeStack.push(elem.ValueType())
+		}
+	case OpcodeGCArrayLen, OpcodeGCI31GetS, OpcodeGCI31GetU:
+		if err = valueTypeStack.popAndVerifyType(ValueTypeAnyref); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		valueTypeStack.push(ValueTypeI32)
+	case OpcodeGCRefI31:
+		if err = valueTypeStack.popAndVerifyType(ValueTypeI32); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		valueTypeStack.push(ValueTypeAnyref)
+	case OpcodeGCRefTest, OpcodeGCRefTestNull, OpcodeGCRefCast, OpcodeGCRefCastNull:
+		ht, read, err := LoadHeapType(body[pc+1:])
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", name, err)
+		}
+		pc += read
+		vt, err := m.HeapTypeValueType(ht)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", name, err)
+		}
+		if err = valueTypeStack.popAndVerifyType(vt); err != nil {
+			return 0, fmt.Errorf("cannot pop the operand for %s: %v", name, err)
+		}
+		if gcOpcode == OpcodeGCRefTest || gcOpcode == OpcodeGCRefTestNull {
+			valueTypeStack.push(ValueTypeI32)
+		} else {
+			valueTypeStack.push(vt)
+		}
+	default:
+		if name == "" {
+			return 0, fmt.Errorf("invalid gc opcode: %#x", gcOpcode)
+		}
+		return 0, fmt.Errorf("%s is not supported", name)
+	}
+	return pc, nil
+}
+
+// loadCompositeTypeImmediate reads the type index immediate at the start of
+// buf, which must be a type of the given kind.
+func (m *Module) loadCompositeTypeImmediate(buf []byte, kind CompositeKind, name string) (*CompositeType, uint64, error) {
+	typeIndex, read, err := leb128.LoadUint32(buf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("read type index for %s: %v", name, err)
+	} else if typeIndex >= uint32(len(m.CompositeTypes)) || m.CompositeTypes[typeIndex].Kind != kind {
+		return nil, 0, fmt.Errorf("invalid type index for %s: %d", name, typeIndex)
+	}
+	return &m.CompositeTypes[typeIndex], read, nil
+}
+
+// validateFieldAccess ensures packed fields are only read with a sign or zero
+// extension, and only mutable fields are written.
+func validateFieldAccess(gcOpcode OpcodeGC, field FieldType, name string) error {
+	switch gcOpcode {
+	case OpcodeGCStructGet, OpcodeGCArrayGet:
+		if field.IsPacked() {
+			return fmt.Errorf("%s cannot read a packed field", name)
+		}
+	case OpcodeGCStructGetS, OpcodeGCStructGetU, OpcodeGCArrayGetS, OpcodeGCArrayGetU:
+		if !field.IsPacked() {
+			return fmt.Errorf("%s can only read a packed field", name)
+		}
+	case OpcodeGCStructSet, OpcodeGCArraySet:
+		if !field.Mutable {
+			return fmt.Errorf("%s cannot write an immutable field", name)
+		}
+	}
+	return nil
+}
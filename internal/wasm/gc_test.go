@@ -0,0 +1,197 @@
+package wasm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestModule_validateCompositeTypes(t *testing.T) {
+	i32Field := FieldType{StorageType: ValueTypeI32}
+	tests := []struct {
+		name        string
+		types       []CompositeType
+		expectedErr string
+	}{
+		{
+			name: "struct extends struct",
+			types: []CompositeType{
+				{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+				{Kind: CompositeKindStruct, Fields: []FieldType{i32Field, i32Field}, SuperTypes: []Index{0}},
+			},
+		},
+		{
+			name: "supertype declared later",
+			types: []CompositeType{
+				{Kind: CompositeKindStruct, SuperTypes: []Index{1}},
+				{Kind: CompositeKindStruct},
+			},
+			expectedErr: "type[0] has supertype 1 which is not declared before it",
+		},
+		{
+			name: "final supertype",
+			types: []CompositeType{
+				{Kind: CompositeKindStruct, Final: true},
+				{Kind: CompositeKindStruct, SuperTypes: []Index{0}},
+			},
+			expectedErr: "type[1] has final supertype 0",
+		},
+		{
+			name: "different fields",
+			types: []CompositeType{
+				{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+				{Kind: CompositeKindStruct, Fields: []FieldType{{StorageType: ValueTypeI64}}, SuperTypes: []Index{0}},
+			},
+			expectedErr: "type[1] does not match its supertype 0",
+		},
+		{
+			name: "array extends struct",
+			types: []CompositeType{
+				{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+				{Kind: CompositeKindArray, Fields: []FieldType{i32Field}, SuperTypes: []Index{0}},
+			},
+			expectedErr: "type[1] does not match its supertype 0",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Module{TypeSection: make([]FunctionType, len(tc.types)), CompositeTypes: tc.types}
+			err := m.validateCompositeTypes()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestModule_IsSubtype(t *testing.T) {
+	i32Field := FieldType{StorageType: ValueTypeI32}
+	m := &Module{CompositeTypes: []CompositeType{
+		{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+		{Kind: CompositeKindStruct, Fields: []FieldType{i32Field, i32Field}, SuperTypes: []Index{0}},
+		{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+	}}
+	require.True(t, m.IsSubtype(1, m, 1))
+	require.True(t, m.IsSubtype(1, m, 0))
+	require.False(t, m.IsSubtype(0, m, 1))
+	// Identical types in the same module are distinct.
+	require.False(t, m.IsSubtype(2, m, 0))
+
+	// Types of different modules match structurally.
+	other := &Module{CompositeTypes: []CompositeType{
+		{Kind: CompositeKindStruct, Fields: []FieldType{i32Field}},
+	}}
+	require.True(t, m.IsSubtype(1, other, 0))
+	require.True(t, other.IsSubtype(0, m, 2))
+	require.False(t, other.IsSubtype(0, m, 1))
+}
+
+func TestModule_validateGCInstruction(t *testing.T) {
+	types := []CompositeType{
+		{Kind: CompositeKindStruct, Fields: []FieldType{{StorageType: StorageTypeI8}, {StorageType: ValueTypeI64, Mutable: true}}},
+		{Kind: CompositeKindArray, Fields: []FieldType{{StorageType: ValueTypeI32}}},
+		{Kind: CompositeKindFunc, Final: true},
+	}
+	i32_i32 := FunctionType{Params: []ValueType{ValueTypeI32}, Results: []ValueType{ValueTypeI32}}
+
+	tests := []struct {
+		name        string
+		body        []byte
+		expectedErr string
+	}{
+		{
+			name: "struct.get_s",
+			body: []byte{
+				OpcodeLocalGet, 0, OpcodeI64Const, 1, OpcodeGCPrefix, OpcodeGCStructNew, 0,
+				OpcodeGCPrefix, OpcodeGCStructGetS, 0, 0, OpcodeEnd,
+			},
+		},
+		{
+			name: "struct.get on packed field",
+			body: []byte{
+				OpcodeGCPrefix, OpcodeGCStructNewDefault, 0,
+				OpcodeGCPrefix, OpcodeGCStructGet, 0, 0, OpcodeEnd,
+			},
+			expectedErr: "struct.get cannot read a packed field",
+		},
+		{
+			name: "struct.set on immutable field",
+			body: []byte{
+				OpcodeGCPrefix, OpcodeGCStructNewDefault, 0, OpcodeLocalGet, 0,
+				OpcodeGCPrefix, OpcodeGCStructSet, 0, 0, OpcodeLocalGet, 0, OpcodeEnd,
+			},
+			expectedErr: "struct.set cannot write an immutable field",
+		},
+		{
+			name: "struct.new of an array type",
+			body: []byte{
+				OpcodeGCPrefix, OpcodeGCStructNewDefault, 1, OpcodeDrop, OpcodeLocalGet, 0, OpcodeEnd,
+			},
+			expectedErr: "invalid type index for struct.new_default: 1",
+		},
+		{
+			name: "array.len",
+			body: []byte{
+				OpcodeLocalGet, 0, OpcodeLocalGet, 0, OpcodeGCPrefix, OpcodeGCArrayNew, 1,
+				OpcodeGCPrefix, OpcodeGCArrayLen, OpcodeEnd,
+			},
+		},
+		{
+			name: "array.new_fixed with too few operands",
+			body: []byte{
+				OpcodeLocalGet, 0, OpcodeGCPrefix, OpcodeGCArrayNewFixed, 1, 2,
+				OpcodeGCPrefix, OpcodeGCArrayLen, OpcodeEnd,
+			},
+			expectedErr: "cannot pop the operand for array.new_fixed: i32 missing",
+		},
+		{
+			name: "ref.test",
+			body: []byte{
+				OpcodeLocalGet, 0, OpcodeGCPrefix, OpcodeGCRefI31, OpcodeGCPrefix, OpcodeGCRefTest, 0, OpcodeEnd,
+			},
+		},
+		{
+			name: "ref.eq",
+			body: []byte{
+				OpcodeRefNull, 0x6d, OpcodeRefNull, 0x6c, OpcodeRefEq, OpcodeEnd,
+			},
+		},
+		{
+			name: "array.copy",
+			body: []byte{
+				OpcodeLocalGet, 0, OpcodeGCPrefix, OpcodeGCArrayCopy, 1, 1, OpcodeEnd,
+			},
+			expectedErr: "array.copy is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Module{
+				TypeSection:     []FunctionType{{}, {}, i32_i32},
+				CompositeTypes:  types,
+				FunctionSection: []Index{2},
+				CodeSection:     []Code{{Body: tc.body}},
+			}
+			err := m.validateFunction(&stacks{}, api.CoreFeaturesV2|api.CoreFeatureGC,
+				0, []Index{0}, nil, nil, nil, nil, bytes.NewReader(nil))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.expectedErr)
+			}
+
+			// GC instructions are invalid unless the feature is enabled.
+			err = m.validateFunction(&stacks{}, api.CoreFeaturesV2,
+				0, []Index{0}, nil, nil, nil, nil, bytes.NewReader(nil))
+			require.Error(t, err)
+		})
+	}
+}
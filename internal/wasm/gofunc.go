@@ -9,6 +9,7 @@ import (
 	"reflect"
 
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 )
 
 type paramsKind byte
@@ -22,9 +23,10 @@ const (
 // Below are reflection code to get the interface type used to parse functions and set values.
 
 var (
-	moduleType    = reflect.TypeOf((*api.Module)(nil)).Elem()
-	goContextType = reflect.TypeOf((*context.Context)(nil)).Elem()
-	errorType     = reflect.TypeOf((*error)(nil)).Elem()
+	moduleType     = reflect.TypeOf((*api.Module)(nil)).Elem()
+	goContextType  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	memoryViewType = reflect.TypeOf(api.MemoryView(nil))
 )
 
 // compile-time check to ensure reflectGoModuleFunction implements
@@ -101,6 +103,11 @@ func callGoFunc(ctx context.Context, mod api.Module, fn *reflect.Value, stack []
 
 		for j := 0; i < pLen; i++ {
 			next := tp.In(i)
+			if next == memoryViewType {
+				in[i] = newMemoryViewVal(mod, stack[j], stack[j+1])
+				j += 2
+				continue
+			}
 			val := reflect.New(next).Elem()
 			k := next.Kind()
 			raw := stack[j]
@@ -151,6 +158,20 @@ func newModuleVal(m api.Module) reflect.Value {
 	return val
 }
 
+// newMemoryViewVal returns the api.MemoryView of m's memory at the offset and
+// byteCount params, or traps if it is out of range.
+func newMemoryViewVal(m api.Module, offset, byteCount uint64) reflect.Value {
+	var buf []byte
+	ok := false
+	if mem := m.Memory(); mem != nil {
+		buf, ok = mem.Read(uint32(offset), uint32(byteCount))
+	}
+	if !ok {
+		panic(wasmruntime.ErrRuntimeOutOfBoundsMemoryAccess)
+	}
+	return reflect.ValueOf(api.MemoryView(buf))
+}
+
 // MustParseGoReflectFuncCode parses Code from the go function or panics.
 //
 // Exposing this simplifies FunctionDefinition of host functions in built-in host
@@ -189,12 +210,20 @@ func parseGoReflectFunc(fn interface{}) (params, results []ValueType, code Code,
 
 	pCount := p.NumIn() - pOffset
 	if pCount > 0 {
-		params = make([]ValueType, pCount)
+		params = make([]ValueType, 0, pCount)
 	}
-	for i := 0; i < len(params); i++ {
+	for i := 0; i < pCount; i++ {
 		pI := p.In(i + pOffset)
-		if t, ok := getTypeOf(pI.Kind()); ok {
-			params[i] = t
+		if pI == memoryViewType {
+			if pk != paramsKindContextModule {
+				err = fmt.Errorf("param[%d] is an api.MemoryView, which requires api.Module as param[1]", i+pOffset)
+				return
+			}
+			// The view is decoded from its offset and byte count.
+			params = append(params, ValueTypeI32, ValueTypeI32)
+			continue
+		} else if t, ok := getTypeOf(pI.Kind()); ok {
+			params = append(params, t)
 			continue
 		}
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 )
 
 // testCtx is an arbitrary, non-default context. Non-nil also prevents linter errors.
@@ -52,6 +53,12 @@ func Test_parseGoFunc(t *testing.T) {
 			expectNeedsModule: true,
 			expectedType:      &FunctionType{Params: []ValueType{i32, i64, f32, f64, externref}, Results: []ValueType{i32}},
 		},
+		{
+			name:              "api.MemoryView param - (ctx, mod)",
+			input:             func(context.Context, api.Module, api.MemoryView, uint64) uint32 { return 0 },
+			expectNeedsModule: true,
+			expectedType:      &FunctionType{Params: []ValueType{i32, i32, i64}, Results: []ValueType{i32}},
+		},
 	}
 	for _, tt := range tests {
 		tc := tt
@@ -107,6 +114,11 @@ func Test_parseGoFunc_Errors(t *testing.T) {
 			input:       func(context.Context, uint64, context.Context) error { return nil },
 			expectedErr: "param[2] is a context.Context, which may be defined only once as param[0]",
 		},
+		{
+			name:        "api.MemoryView without api.Module",
+			input:       func(context.Context, api.MemoryView) {},
+			expectedErr: "param[1] is an api.MemoryView, which requires api.Module as param[1]",
+		},
 		{
 			name:        "multiple wasm.Module",
 			input:       func(context.Context, api.Module, uint64, api.Module) error { return nil },
@@ -126,7 +138,7 @@ func Test_parseGoFunc_Errors(t *testing.T) {
 
 func Test_callGoFunc(t *testing.T) {
 	tPtr := uintptr(unsafe.Pointer(t))
-	inst := &ModuleInstance{}
+	inst := &ModuleInstance{MemoryInstance: &MemoryInstance{Buffer: []byte("wazero"), Min: 1}}
 
 	tests := []struct {
 		name                         string
@@ -210,6 +222,16 @@ func Test_callGoFunc(t *testing.T) {
 			},
 			expectedResults: []uint64{100},
 		},
+		{
+			name: "api.MemoryView param - (ctx, mod)",
+			input: func(ctx context.Context, m api.Module, v api.MemoryView, w uint32) uint32 {
+				require.Equal(t, api.MemoryView("zero"), v)
+				v[0] = 'Z' // The view isn't a copy.
+				return w
+			},
+			inputParams:     []uint64{2, 4, 100},
+			expectedResults: []uint64{100},
+		},
 	}
 	for _, tt := range tests {
 		tc := tt
@@ -243,3 +265,16 @@ func Test_callGoFunc(t *testing.T) {
 		})
 	}
 }
+
+func Test_callGoFunc_MemoryView(t *testing.T) {
+	inst := &ModuleInstance{MemoryInstance: &MemoryInstance{Buffer: []byte("wazero"), Min: 1}}
+	_, _, code, err := parseGoReflectFunc(func(context.Context, api.Module, api.MemoryView) {})
+	require.NoError(t, err)
+	fn := code.GoFunc.(api.GoModuleFunction)
+
+	fn.Call(testCtx, inst, []uint64{1, 5})
+	err = require.CapturePanic(func() { fn.Call(testCtx, inst, []uint64{2, 5}) })
+	require.Equal(t, wasmruntime.ErrRuntimeOutOfBoundsMemoryAccess, err)
+	err = require.CapturePanic(func() { fn.Call(testCtx, &ModuleInstance{}, []uint64{0, 0}) })
+	require.Equal(t, wasmruntime.ErrRuntimeOutOfBoundsMemoryAccess, err)
+}
@@ -1,11 +1,16 @@
 package wasm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
+	"sync/atomic"
 
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/wasmdebug"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 type HostFuncExporter interface {
@@ -177,3 +182,64 @@ func (m *Module) maybeAddType(params, results []ValueType, enabledFeatures api.C
 	m.TypeSection = append(m.TypeSection, FunctionType{Params: params, Results: results})
 	return result, nil
 }
+
+// GoFuncHolder holds the Go implementation of a host function, which is an
+// api.GoModuleFunction or an api.GoFunction. Engines load it on each call, so
+// that Store.ReplaceHostModule can replace it while the function is called.
+type GoFuncHolder struct {
+	v atomic.Value // of goFunc
+}
+
+// goFunc wraps the implementation, as atomic.Value requires all values to
+// have the same concrete type.
+type goFunc struct{ fn interface{} }
+
+// NewGoFuncHolder returns a GoFuncHolder initialized to fn.
+func NewGoFuncHolder(fn interface{}) *GoFuncHolder {
+	h := &GoFuncHolder{}
+	h.Store(fn)
+	return h
+}
+
+// Load returns the current implementation.
+func (h *GoFuncHolder) Load() interface{} {
+	return h.v.Load().(goFunc).fn
+}
+
+// Store replaces the implementation.
+func (h *GoFuncHolder) Store(fn interface{}) {
+	h.v.Store(goFunc{fn})
+}
+
+// CallGoFunc calls fn, which is an api.GoModuleFunction or an api.GoFunction,
+// with the caller m and the host function definition def.
+//
+// When TrapHostPanics is enabled in the store of m, a panic of fn is raised
+// again as a *sys.HostFunctionError, which captures the Go stack trace.
+func CallGoFunc(ctx context.Context, m *ModuleInstance, def api.FunctionDefinition, fn interface{}, stack []uint64) {
+	if m != nil && m.s != nil && m.s.TrapHostPanics {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				panic(hostFunctionError(def, recovered))
+			}
+		}()
+	}
+	switch fn := fn.(type) {
+	case api.GoModuleFunction:
+		fn.Call(ctx, m, stack)
+	case api.GoFunction:
+		fn.Call(ctx, stack)
+	}
+}
+
+// hostFunctionError returns the value to panic with, given the value a host
+// function panicked with.
+func hostFunctionError(def api.FunctionDefinition, recovered interface{}) interface{} {
+	switch recovered.(type) {
+	case *sys.ExitError, *sys.HostFunctionError, *wasmruntime.Error:
+		// Exits and traps already have their meaning, including those from a
+		// guest function called by the host function.
+		return recovered
+	}
+	return &sys.HostFunctionError{Function: def.DebugName(), Recovered: recovered, Stack: debug.Stack()}
+}
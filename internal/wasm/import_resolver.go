@@ -0,0 +1,44 @@
+package wasm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetratelabs/wazero/experimental"
+)
+
+// resolvingKey is a context.Context Value key. Its associated value is the
+// *resolving chain of modules being resolved, to detect import cycles.
+type resolvingKey struct{}
+
+// resolving is a module name being resolved by an experimental.ImportResolver, and the
+// one which imports it, if it was also being resolved.
+type resolving struct {
+	moduleName string
+	parent     *resolving
+}
+
+// resolveModule calls the experimental.ImportResolver in ctx, if any, to instantiate the
+// module named moduleName. notFound is returned if there is none.
+func (s *Store) resolveModule(ctx context.Context, moduleName string, notFound error) (*ModuleInstance, error) {
+	if ctx == nil {
+		return nil, notFound
+	}
+	resolver, ok := ctx.Value(experimental.ImportResolverKey{}).(experimental.ImportResolver)
+	if !ok || resolver == nil {
+		return nil, notFound
+	}
+
+	parent, _ := ctx.Value(resolvingKey{}).(*resolving)
+	for r := parent; r != nil; r = r.parent {
+		if r.moduleName == moduleName {
+			return nil, fmt.Errorf("module[%s] has a cyclic import", moduleName)
+		}
+	}
+	ctx = context.WithValue(ctx, resolvingKey{}, &resolving{moduleName: moduleName, parent: parent})
+
+	if err := resolver(ctx, moduleName); err != nil {
+		return nil, fmt.Errorf("module[%s] not resolved: %w", moduleName, err)
+	}
+	return s.module(moduleName)
+}
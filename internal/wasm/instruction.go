@@ -251,6 +251,13 @@ const (
 	// Currently, this is only supported in the constant expression in element segments.
 	OpcodeRefFunc = 0xd2
 
+	// Below are toggled with CoreFeatureGC
+
+	// OpcodeRefEq pops two references and pushes 1 if they are the same, 0 otherwise.
+	OpcodeRefEq Opcode = 0xd3
+	// OpcodeRefAsNonNull traps if the reference on the top of the stack is null.
+	OpcodeRefAsNonNull Opcode = 0xd4
+
 	// Below are toggled with CoreFeatureSignExtensionOps
 
 	// OpcodeI32Extend8S extends a signed 8-bit integer to a 32-bit integer.
@@ -281,6 +288,51 @@ const (
 	// OpcodeVecPrefix is the prefix of all vector isntructions introduced in
 	// CoreFeatureSIMD.
 	OpcodeVecPrefix Opcode = 0xfd
+
+	// OpcodeGCPrefix is the prefix of the struct, array, cast and i31
+	// instructions introduced in CoreFeatureGC.
+	OpcodeGCPrefix Opcode = 0xfb
+)
+
+// OpcodeGC represents an opcode of the instructions which have multi-byte
+// encoding and are prefixed by OpcodeGCPrefix.
+//
+// These opcodes are toggled with CoreFeatureGC.
+// See https://github.com/WebAssembly/gc/blob/main/proposals/gc/MVP.md#instructions
+type OpcodeGC = byte
+
+const (
+	OpcodeGCStructNew        OpcodeGC = 0x00
+	OpcodeGCStructNewDefault OpcodeGC = 0x01
+	OpcodeGCStructGet        OpcodeGC = 0x02
+	OpcodeGCStructGetS       OpcodeGC = 0x03
+	OpcodeGCStructGetU       OpcodeGC = 0x04
+	OpcodeGCStructSet        OpcodeGC = 0x05
+	OpcodeGCArrayNew         OpcodeGC = 0x06
+	OpcodeGCArrayNewDefault  OpcodeGC = 0x07
+	OpcodeGCArrayNewFixed    OpcodeGC = 0x08
+	OpcodeGCArrayNewData     OpcodeGC = 0x09
+	OpcodeGCArrayNewElem     OpcodeGC = 0x0a
+	OpcodeGCArrayGet         OpcodeGC = 0x0b
+	OpcodeGCArrayGetS        OpcodeGC = 0x0c
+	OpcodeGCArrayGetU        OpcodeGC = 0x0d
+	OpcodeGCArraySet         OpcodeGC = 0x0e
+	OpcodeGCArrayLen         OpcodeGC = 0x0f
+	OpcodeGCArrayFill        OpcodeGC = 0x10
+	OpcodeGCArrayCopy        OpcodeGC = 0x11
+	OpcodeGCArrayInitData    OpcodeGC = 0x12
+	OpcodeGCArrayInitElem    OpcodeGC = 0x13
+	OpcodeGCRefTest          OpcodeGC = 0x14
+	OpcodeGCRefTestNull      OpcodeGC = 0x15
+	OpcodeGCRefCast          OpcodeGC = 0x16
+	OpcodeGCRefCastNull      OpcodeGC = 0x17
+	OpcodeGCBrOnCast         OpcodeGC = 0x18
+	OpcodeGCBrOnCastFail     OpcodeGC = 0x19
+	OpcodeGCAnyConvertExtern OpcodeGC = 0x1a
+	OpcodeGCExternConvertAny OpcodeGC = 0x1b
+	OpcodeGCRefI31           OpcodeGC = 0x1c
+	OpcodeGCI31GetS          OpcodeGC = 0x1d
+	OpcodeGCI31GetU          OpcodeGC = 0x1e
 )
 
 // OpcodeMisc represents opcodes of the miscellaneous operations.
@@ -786,7 +838,7 @@ const (
 	OpcodeF32ConvertI32SName    = "f32.convert_i32_s"
 	OpcodeF32ConvertI32UName    = "f32.convert_i32_u"
 	OpcodeF32ConvertI64SName    = "f32.convert_i64_s"
-	OpcodeF32ConvertI64UName    = "f32.convert_i64u"
+	OpcodeF32ConvertI64UName    = "f32.convert_i64_u"
 	OpcodeF32DemoteF64Name      = "f32.demote_f64"
 	OpcodeF64ConvertI32SName    = "f64.convert_i32_s"
 	OpcodeF64ConvertI32UName    = "f64.convert_i32_u"
@@ -802,6 +854,9 @@ const (
 	OpcodeRefIsNullName = "ref.is_null"
 	OpcodeRefFuncName   = "ref.func"
 
+	OpcodeRefEqName        = "ref.eq"
+	OpcodeRefAsNonNullName = "ref.as_non_null"
+
 	OpcodeTableGetName = "table.get"
 	OpcodeTableSetName = "table.set"
 
@@ -815,6 +870,7 @@ const (
 
 	OpcodeMiscPrefixName = "misc_prefix"
 	OpcodeVecPrefixName  = "vector_prefix"
+	OpcodeGCPrefixName   = "gc_prefix"
 )
 
 var instructionNames = [256]string{
@@ -996,6 +1052,9 @@ var instructionNames = [256]string{
 	OpcodeRefIsNull: OpcodeRefIsNullName,
 	OpcodeRefFunc:   OpcodeRefFuncName,
 
+	OpcodeRefEq:        OpcodeRefEqName,
+	OpcodeRefAsNonNull: OpcodeRefAsNonNullName,
+
 	OpcodeTableGet: OpcodeTableGetName,
 	OpcodeTableSet: OpcodeTableSetName,
 
@@ -1009,6 +1068,7 @@ var instructionNames = [256]string{
 
 	OpcodeMiscPrefix: OpcodeMiscPrefixName,
 	OpcodeVecPrefix:  OpcodeVecPrefixName,
+	OpcodeGCPrefix:   OpcodeGCPrefixName,
 }
 
 // InstructionName returns the instruction corresponding to this binary Opcode.
@@ -1066,6 +1126,79 @@ func MiscInstructionName(oc OpcodeMisc) string {
 	return miscInstructionNames[oc]
 }
 
+const (
+	OpcodeStructNewName        = "struct.new"
+	OpcodeStructNewDefaultName = "struct.new_default"
+	OpcodeStructGetName        = "struct.get"
+	OpcodeStructGetSName       = "struct.get_s"
+	OpcodeStructGetUName       = "struct.get_u"
+	OpcodeStructSetName        = "struct.set"
+	OpcodeArrayNewName         = "array.new"
+	OpcodeArrayNewDefaultName  = "array.new_default"
+	OpcodeArrayNewFixedName    = "array.new_fixed"
+	OpcodeArrayNewDataName     = "array.new_data"
+	OpcodeArrayNewElemName     = "array.new_elem"
+	OpcodeArrayGetName         = "array.get"
+	OpcodeArrayGetSName        = "array.get_s"
+	OpcodeArrayGetUName        = "array.get_u"
+	OpcodeArraySetName         = "array.set"
+	OpcodeArrayLenName         = "array.len"
+	OpcodeArrayFillName        = "array.fill"
+	OpcodeArrayCopyName        = "array.copy"
+	OpcodeArrayInitDataName    = "array.init_data"
+	OpcodeArrayInitElemName    = "array.init_elem"
+	OpcodeRefTestName          = "ref.test"
+	OpcodeRefTestNullName      = "ref.test_null"
+	OpcodeRefCastName          = "ref.cast"
+	OpcodeRefCastNullName      = "ref.cast_null"
+	OpcodeBrOnCastName         = "br_on_cast"
+	OpcodeBrOnCastFailName     = "br_on_cast_fail"
+	OpcodeAnyConvertExternName = "any.convert_extern"
+	OpcodeExternConvertAnyName = "extern.convert_any"
+	OpcodeRefI31Name           = "ref.i31"
+	OpcodeI31GetSName          = "i31.get_s"
+	OpcodeI31GetUName          = "i31.get_u"
+)
+
+var gcInstructionNames = [256]string{
+	OpcodeGCStructNew:        OpcodeStructNewName,
+	OpcodeGCStructNewDefault: OpcodeStructNewDefaultName,
+	OpcodeGCStructGet:        OpcodeStructGetName,
+	OpcodeGCStructGetS:       OpcodeStructGetSName,
+	OpcodeGCStructGetU:       OpcodeStructGetUName,
+	OpcodeGCStructSet:        OpcodeStructSetName,
+	OpcodeGCArrayNew:         OpcodeArrayNewName,
+	OpcodeGCArrayNewDefault:  OpcodeArrayNewDefaultName,
+	OpcodeGCArrayNewFixed:    OpcodeArrayNewFixedName,
+	OpcodeGCArrayNewData:     OpcodeArrayNewDataName,
+	OpcodeGCArrayNewElem:     OpcodeArrayNewElemName,
+	OpcodeGCArrayGet:         OpcodeArrayGetName,
+	OpcodeGCArrayGetS:        OpcodeArrayGetSName,
+	OpcodeGCArrayGetU:        OpcodeArrayGetUName,
+	OpcodeGCArraySet:         OpcodeArraySetName,
+	OpcodeGCArrayLen:         OpcodeArrayLenName,
+	OpcodeGCArrayFill:        OpcodeArrayFillName,
+	OpcodeGCArrayCopy:        OpcodeArrayCopyName,
+	OpcodeGCArrayInitData:    OpcodeArrayInitDataName,
+	OpcodeGCArrayInitElem:    OpcodeArrayInitElemName,
+	OpcodeGCRefTest:          OpcodeRefTestName,
+	OpcodeGCRefTestNull:      OpcodeRefTestNullName,
+	OpcodeGCRefCast:          OpcodeRefCastName,
+	OpcodeGCRefCastNull:      OpcodeRefCastNullName,
+	OpcodeGCBrOnCast:         OpcodeBrOnCastName,
+	OpcodeGCBrOnCastFail:     OpcodeBrOnCastFailName,
+	OpcodeGCAnyConvertExtern: OpcodeAnyConvertExternName,
+	OpcodeGCExternConvertAny: OpcodeExternConvertAnyName,
+	OpcodeGCRefI31:           OpcodeRefI31Name,
+	OpcodeGCI31GetS:          OpcodeI31GetSName,
+	OpcodeGCI31GetU:          OpcodeI31GetUName,
+}
+
+// GCInstructionName returns the instruction corresponding to this GC Opcode.
+func GCInstructionName(oc OpcodeGC) string {
+	return gcInstructionNames[oc]
+}
+
 const (
 	OpcodeVecV128LoadName                  = "v128.load"
 	OpcodeVecV128Load8x8SName              = "v128.load8x8_s"
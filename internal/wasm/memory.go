@@ -9,7 +9,9 @@ import (
 	"unsafe"
 
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 	"github.com/tetratelabs/wazero/internal/internalapi"
+	"github.com/tetratelabs/wazero/internal/platform"
 )
 
 const (
@@ -41,6 +43,16 @@ type MemoryInstance struct {
 	mux sync.RWMutex
 	// definition is known at compile time.
 	definition api.MemoryDefinition
+	// usage is non-nil when growing this memory is accounted by the module which defines it.
+	usage *UsageLimit
+	// generation is incremented when Buffer is re-allocated or shrunk, which
+	// makes slices of it held by the host stale. Guarded by mux.
+	generation uint64
+	// pins is the count of Pin calls not yet followed by Unpin. While
+	// positive, Grow fails instead of re-allocating Buffer. Guarded by mux.
+	pins uint32
+	// metrics is non-nil when growing this memory is reported.
+	metrics experimental.Metrics
 }
 
 // NewMemoryInstance creates a new instance based on the parameters in the SectionIDMemory.
@@ -180,6 +192,15 @@ func MemoryPagesToBytesNum(pages uint32) (bytesNum uint64) {
 
 // Grow implements the same method as documented on api.Memory.
 func (m *MemoryInstance) Grow(delta uint32) (result uint32, ok bool) {
+	if m.metrics != nil && delta != 0 {
+		// Deferred first, so that this is called after unlocking.
+		defer func() {
+			if ok {
+				m.metrics.MemoryGrown(delta)
+			}
+		}()
+	}
+
 	// We take write-lock here as the following might result in a new slice
 	m.mux.Lock()
 	defer m.mux.Unlock()
@@ -190,12 +211,15 @@ func (m *MemoryInstance) Grow(delta uint32) (result uint32, ok bool) {
 	}
 
 	// If exceeds the max of memory size, we push -1 according to the spec.
+	// A pinned memory can only grow within its capacity, as re-allocating
+	// would move it.
 	newPages := currentPages + delta
-	if newPages > m.Max {
+	if newPages > m.Max || (newPages > m.Cap && m.pins > 0) || !m.usage.reserve(MemoryPagesToBytesNum(delta)) {
 		return 0, false
 	} else if newPages > m.Cap { // grow the memory.
 		m.Buffer = append(m.Buffer, make([]byte, MemoryPagesToBytesNum(delta))...)
 		m.Cap = newPages
+		m.generation++
 		return currentPages, true
 	} else { // We already have the capacity we need.
 		sp := (*reflect.SliceHeader)(unsafe.Pointer(&m.Buffer))
@@ -204,6 +228,57 @@ func (m *MemoryInstance) Grow(delta uint32) (result uint32, ok bool) {
 	}
 }
 
+// View returns the bytes at offset like Read, and the generation of Buffer
+// they alias. The bytes are stale once Generation returns a different value.
+func (m *MemoryInstance) View(offset, byteCount uint32) (buf []byte, generation uint64, ok bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	if buf, ok = m.Read(offset, byteCount); ok {
+		generation = m.generation
+	}
+	return
+}
+
+// Generation returns the current generation of Buffer. See View.
+func (m *MemoryInstance) Generation() uint64 {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	return m.generation
+}
+
+// Pin prevents Buffer from being re-allocated until Unpin is called. Grow
+// fails if it needs more than the current capacity meanwhile.
+func (m *MemoryInstance) Pin() {
+	m.mux.Lock()
+	m.pins++
+	m.mux.Unlock()
+}
+
+// Unpin reverses a prior call to Pin.
+func (m *MemoryInstance) Unpin() {
+	m.mux.Lock()
+	if m.pins > 0 {
+		m.pins--
+	}
+	m.mux.Unlock()
+}
+
+// Discard zeroes countPages pages starting at offsetPages, returning them to
+// the operating system where supported. This returns false if the range is
+// out of bounds.
+func (m *MemoryInstance) Discard(offsetPages, countPages uint32) bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	offset := uint64(offsetPages) << MemoryPageSizeInBits
+	byteCount := uint64(countPages) << MemoryPageSizeInBits
+	if offset+byteCount > uint64(len(m.Buffer)) {
+		return false
+	}
+	platform.Decommit(m.Buffer[offset : offset+byteCount])
+	return true
+}
+
 // PageSize returns the current memory buffer size in pages.
 func (m *MemoryInstance) PageSize() (result uint32) {
 	return memoryBytesNumToPages(uint64(len(m.Buffer)))
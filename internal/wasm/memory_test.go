@@ -465,6 +465,26 @@ func TestMemoryInstance_Read(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestMemoryInstance_Discard(t *testing.T) {
+	mem := &MemoryInstance{Buffer: make([]byte, MemoryPagesToBytesNum(3)), Min: 3}
+	for i := range mem.Buffer {
+		mem.Buffer[i] = 0xff
+	}
+
+	require.True(t, mem.Discard(1, 1))
+	for i, b := range mem.Buffer {
+		if page := uint32(i) / MemoryPageSize; page == 1 {
+			require.Equal(t, byte(0), b)
+		} else {
+			require.Equal(t, byte(0xff), b)
+		}
+	}
+
+	require.True(t, mem.Discard(3, 0))
+	require.False(t, mem.Discard(2, 2))
+	require.False(t, mem.Discard(math.MaxUint32, 1))
+}
+
 func TestMemoryInstance_WriteUint16Le(t *testing.T) {
 	memory := &MemoryInstance{Buffer: make([]byte, 100)}
 
@@ -0,0 +1,31 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// MetricsFrom returns the metrics registered with experimental.WithMetrics,
+// or nil.
+func MetricsFrom(ctx context.Context) experimental.Metrics {
+	if ctx == nil {
+		return nil
+	}
+	metrics, _ := ctx.Value(experimental.MetricsKey{}).(experimental.Metrics)
+	return metrics
+}
+
+// ReportTrap calls experimental.Metrics Trapped if err is a trap. This is
+// safe to call with nil metrics.
+func ReportTrap(metrics experimental.Metrics, err error) {
+	if metrics == nil || err == nil {
+		return
+	}
+	var trap *wasmruntime.Error
+	if errors.As(err, &trap) {
+		metrics.Trapped(trap.Error())
+	}
+}
@@ -34,6 +34,11 @@ type Module struct {
 	// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#types%E2%91%A0%E2%91%A0
 	TypeSection []FunctionType
 
+	// CompositeTypes is index-correlated with TypeSection when the module uses
+	// types of CoreFeatureGC, or nil otherwise. For a struct or array type, the
+	// FunctionType at the same index in TypeSection is empty and unused.
+	CompositeTypes []CompositeType
+
 	// ImportSection contains imported functions, tables, memories or globals required for instantiation
 	// (Store.Instantiate).
 	//
@@ -254,6 +259,10 @@ func (m *Module) Validate(enabledFeatures api.CoreFeatures) error {
 		tp.CacheNumInUint64()
 	}
 
+	if err := m.validateCompositeTypes(); err != nil {
+		return err
+	}
+
 	if err := m.validateStartSection(); err != nil {
 		return err
 	}
@@ -357,6 +366,8 @@ func (m *Module) validateFunctions(enabledFeatures api.CoreFeatures, functions [
 	for idx, typeIndex := range m.FunctionSection {
 		if typeIndex >= typeCount {
 			return fmt.Errorf("invalid %s: type section index %d out of range", m.funcDesc(SectionIDFunction, Index(idx)), typeIndex)
+		} else if !m.IsFunctionType(typeIndex) {
+			return fmt.Errorf("invalid %s: type section index %d is not a function type", m.funcDesc(SectionIDFunction, Index(idx)), typeIndex)
 		}
 		c := &m.CodeSection[idx]
 		if c.GoFunc != nil {
@@ -476,6 +487,8 @@ func (m *Module) validateImports(enabledFeatures api.CoreFeatures) error {
 		case ExternTypeFunc:
 			if int(imp.DescFunc) >= len(m.TypeSection) {
 				return fmt.Errorf("invalid import[%q.%q] function: type index out of range", imp.Module, imp.Name)
+			} else if !m.IsFunctionType(imp.DescFunc) {
+				return fmt.Errorf("invalid import[%q.%q] function: type index %d is not a function type", imp.Module, imp.Name, imp.DescFunc)
 			}
 		case ExternTypeGlobal:
 			if !imp.DescGlobal.Mutable {
@@ -524,6 +537,10 @@ func (m *Module) validateExports(enabledFeatures api.CoreFeatures, functions []I
 func validateConstExpression(globals []GlobalType, numFuncs uint32, expr *ConstantExpression, expectedType ValueType) (err error) {
 	var actualType ValueType
 	switch expr.Opcode {
+	case OpcodeI32Add, OpcodeI32Sub, OpcodeI32Mul, OpcodeI64Add, OpcodeI64Sub, OpcodeI64Mul:
+		if actualType, err = validateExtendedConstExpression(globals, expr.Data); err != nil {
+			return err
+		}
 	case OpcodeI32Const:
 		// Treat constants as signed as their interpretation is not yet known per /RATIONALE.md
 		_, _, err = leb128.LoadInt32(expr.Data)
@@ -636,9 +653,21 @@ func (m *ModuleInstance) buildMemory(module *Module) {
 	if memSec != nil {
 		m.MemoryInstance = NewMemoryInstance(memSec)
 		m.MemoryInstance.definition = &module.MemoryDefinitionSection[0]
+		m.MemoryInstance.usage = m.UsageLimit
 	}
 }
 
+// initialUsage returns the bytes of the memory and tables defined by the module when instantiated.
+func (m *Module) initialUsage() (ret uint64) {
+	if m.MemorySection != nil {
+		ret += MemoryPagesToBytesNum(m.MemorySection.Min)
+	}
+	for i := range m.TableSection {
+		ret += uint64(m.TableSection[i].Min) * referenceSize
+	}
+	return
+}
+
 // Index is the offset in an index, not necessarily an absolute position in a Module section. This is because
 // indexs are often preceded by a corresponding type in the Module.ImportSection.
 //
@@ -785,6 +814,13 @@ type Global struct {
 	Init ConstantExpression
 }
 
+// ConstantExpression is an expression evaluated at instantiation, such as a
+// global initializer or an active segment offset.
+//
+// When Opcode is an arithmetic instruction allowed by
+// api.CoreFeatureExtendedConst (see IsExtendedConstOpcode), Data is the whole
+// expression, including Opcode as its last instruction but not the end
+// opcode. Otherwise, Data is the immediate of the single instruction Opcode.
 type ConstantExpression struct {
 	Opcode Opcode
 	Data   []byte
@@ -1024,6 +1060,9 @@ const (
 	// TODO: ValueTypeFuncref is not exposed in the api pkg yet.
 	ValueTypeFuncref   ValueType = 0x70
 	ValueTypeExternref           = api.ValueTypeExternref
+	// ValueTypeAnyref is any reference of the "any" hierarchy in CoreFeatureGC,
+	// such as `eqref` or `(ref null $t)` where $t is a struct or array type.
+	ValueTypeAnyref ValueType = 0x6e
 )
 
 // ValueTypeName is an alias of api.ValueTypeName defined to simplify imports.
@@ -1032,12 +1071,14 @@ func ValueTypeName(t ValueType) string {
 		return "funcref"
 	} else if t == ValueTypeV128 {
 		return "v128"
+	} else if t == ValueTypeAnyref {
+		return "anyref"
 	}
 	return api.ValueTypeName(t)
 }
 
 func isReferenceValueType(vt ValueType) bool {
-	return vt == ValueTypeExternref || vt == ValueTypeFuncref
+	return vt == ValueTypeExternref || vt == ValueTypeFuncref || vt == ValueTypeAnyref
 }
 
 // ExternType is an alias of api.ExternType defined to simplify imports.
@@ -148,6 +148,10 @@ func (m *ModuleInstance) ensureResourcesClosed(ctx context.Context) (err error)
 		m.Sys = nil
 	}
 
+	if m.Engine != nil {
+		m.Engine.Close(ctx)
+	}
+
 	if m.CodeCloser == nil {
 		return
 	}
@@ -158,8 +162,85 @@ func (m *ModuleInstance) ensureResourcesClosed(ctx context.Context) (err error)
 	return
 }
 
+// ExecutionLimit returns the maximum number of loop iterations a function call can execute, or zero if unlimited.
+//
+// Note: the limit is only enforced when the functions were compiled with the termination checks.
+// See wazero.RuntimeConfig WithExecutionLimit.
+func (m *ModuleInstance) ExecutionLimit() uint64 {
+	if m.s == nil {
+		return 0
+	}
+	return m.s.ExecutionLimit
+}
+
+// CanonicalizeNaN returns true if floating-point operations must return the canonical NaN instead of any NaN.
+//
+// See wazero.RuntimeConfig WithNaNCanonicalization.
+func (m *ModuleInstance) CanonicalizeNaN() bool {
+	return m.s != nil && m.s.CanonicalizeNaN
+}
+
+// Reset restores the memory, globals and tables defined by this module to
+// their state after instantiation, then executes the start function again.
+//
+// The capacity of the memory is retained, so that it isn't re-allocated when
+// the module grows it again. Imported memories, globals and tables are not
+// reset, as they are owned by the module which defines them. Neither is the
+// system context, so files opened by the module remain open.
+//
+// Note: This must not be called concurrently with a function call.
+func (m *ModuleInstance) Reset(ctx context.Context) error {
+	if err := m.FailIfClosed(); err != nil {
+		return err
+	}
+	module := m.Source
+
+	if module.MemorySection != nil {
+		mem := m.MemoryInstance
+		mem.mux.Lock()
+		// Zero the whole length, as Grow assumes the capacity beyond it is.
+		for i := range mem.Buffer {
+			mem.Buffer[i] = 0
+		}
+		min := MemoryPagesToBytesNum(mem.Min)
+		if uint64(len(mem.Buffer)) != min {
+			mem.usage.release(uint64(len(mem.Buffer)) - min)
+			mem.Buffer = mem.Buffer[:min]
+			mem.generation++
+		}
+		mem.mux.Unlock()
+	}
+
+	importedGlobals := m.Globals[:module.ImportGlobalCount]
+	for i := range module.GlobalSection {
+		g := m.Globals[module.ImportGlobalCount+Index(i)]
+		g.Val, g.ValHi = 0, 0
+		g.initialize(importedGlobals, &module.GlobalSection[i].Init, m.Engine.FunctionInstanceReference)
+	}
+
+	for _, t := range m.Tables[module.ImportTableCount:] {
+		t.mux.Lock()
+		t.usage.release(uint64(len(t.References)-int(t.Min)) * referenceSize)
+		t.References = t.References[:t.Min]
+		for i := range t.References {
+			t.References[i] = 0
+		}
+		t.mux.Unlock()
+	}
+
+	m.buildElementInstances(module.ElementSection)
+	if err := m.applyData(module.DataSection); err != nil {
+		return err
+	}
+	m.applyElements(module.ElementSection)
+	return m.callStart(ctx)
+}
+
 // Memory implements the same method as documented on api.Module.
 func (m *ModuleInstance) Memory() api.Memory {
+	if m.MemoryInstance == nil {
+		return nil // Avoid a non-nil interface holding a nil pointer.
+	}
 	return m.MemoryInstance
 }
 
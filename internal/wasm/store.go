@@ -48,6 +48,18 @@ type (
 		// Engine is a global context for a Store which is in responsible for compilation and execution of Wasm modules.
 		Engine Engine
 
+		// ExecutionLimit is the maximum number of loop iterations a function call can execute, or zero if unlimited.
+		// This is read-only after the Store is created.
+		ExecutionLimit uint64
+
+		// CanonicalizeNaN is true when floating-point operations must return the canonical NaN instead of any NaN.
+		// This is read-only after the Store is created.
+		CanonicalizeNaN bool
+
+		// TrapHostPanics is true when a panic in a host function must be raised as a *sys.HostFunctionError.
+		// This is read-only after the Store is created.
+		TrapHostPanics bool
+
 		// typeIDs maps each FunctionType.String() to a unique FunctionTypeID. This is used at runtime to
 		// do type-checks on indirect function calls.
 		typeIDs map[string]FunctionTypeID
@@ -118,6 +130,9 @@ type (
 		// CodeCloser is non-nil when the code should be closed after this module.
 		CodeCloser api.Closer
 
+		// UsageLimit is non-nil when the bytes of the memory and tables defined by this module are accounted.
+		UsageLimit *UsageLimit
+
 		// s is the Store on which this module is instantiated.
 		s *Store
 		// prev and next hold the nodes in the linked list of ModuleInstance held by Store.
@@ -183,17 +198,7 @@ func (m *ModuleInstance) applyElements(elems []ElementSegment) {
 			len(elem.Init) == 0 {
 			continue
 		}
-		var offset uint32
-		if elem.OffsetExpr.Opcode == OpcodeGlobalGet {
-			// Ignore error as it's already validated.
-			globalIdx, _, _ := leb128.LoadUint32(elem.OffsetExpr.Data)
-			global := m.Globals[globalIdx]
-			offset = uint32(global.Val)
-		} else {
-			// Ignore error as it's already validated.
-			o, _, _ := leb128.LoadInt32(elem.OffsetExpr.Data)
-			offset = uint32(o)
-		}
+		offset := uint32(executeConstExpressionI32(m.Globals, &elem.OffsetExpr))
 
 		table := m.Tables[elem.TableIndex]
 		references := table.References
@@ -318,6 +323,63 @@ func (s *Store) Instantiate(
 	return m, nil
 }
 
+// ReplaceHostModule replaces the Go functions of the host module
+// instantiated with the same name as `module` by the ones of `module`. The
+// modules importing these functions call the replacements without being
+// re-instantiated, including calls already in progress.
+//
+// `module` must be a host module exporting the same functions with the same
+// signatures. Otherwise, an error is returned and nothing is replaced.
+//
+// Note: Function definitions and listeners of the instantiated module are
+// not replaced.
+func (s *Store) ReplaceHostModule(module *Module) error {
+	name := module.NameSection.ModuleName
+	m, err := s.module(name)
+	if err != nil {
+		return err
+	}
+	source := m.Source
+	if !source.IsHostModule || !module.IsHostModule {
+		return fmt.Errorf("module[%s] is not a host module", name)
+	}
+
+	if len(module.ExportSection) != len(source.ExportSection) {
+		return fmt.Errorf("module[%s] exports %d functions, but the replacement exports %d",
+			name, len(source.ExportSection), len(module.ExportSection))
+	}
+	replacements := make([]interface{}, len(source.FunctionSection))
+	for i := range source.ExportSection {
+		exp := &source.ExportSection[i]
+		if exp.Type != ExternTypeFunc {
+			return fmt.Errorf("module[%s] exports %s[%s], which can't be replaced", name, ExternTypeName(exp.Type), exp.Name)
+		}
+		replacement, ok := module.Exports[exp.Name]
+		if !ok || replacement.Type != ExternTypeFunc {
+			return fmt.Errorf("module[%s] replacement doesn't export function[%s]", name, exp.Name)
+		}
+
+		typ := &source.TypeSection[source.FunctionSection[exp.Index]]
+		replacementType := &module.TypeSection[module.FunctionSection[replacement.Index]]
+		if !replacementType.EqualsSignature(typ.Params, typ.Results) {
+			return fmt.Errorf("function[%s] signature mismatch: %s != %s", exp.Name, typ, replacementType)
+		}
+
+		goFunc := module.CodeSection[replacement.Index].GoFunc
+		if source.CodeSection[exp.Index].GoFunc == nil || goFunc == nil {
+			return fmt.Errorf("function[%s] is not implemented in Go", exp.Name)
+		}
+		replacements[exp.Index] = goFunc
+	}
+
+	for i, goFunc := range replacements {
+		if goFunc != nil {
+			m.Engine.ReplaceGoFunction(Index(i), goFunc)
+		}
+	}
+	return nil
+}
+
 func (s *Store) instantiate(
 	ctx context.Context,
 	module *Module,
@@ -327,6 +389,14 @@ func (s *Store) instantiate(
 ) (m *ModuleInstance, err error) {
 	m = &ModuleInstance{ModuleName: name, TypeIDs: typeIDs, Sys: sysCtx, s: s, Source: module}
 
+	if limit, ok := usageLimit(ctx); ok {
+		m.UsageLimit = &UsageLimit{Limit: limit}
+		if initial := module.initialUsage(); !m.UsageLimit.reserve(initial) {
+			return nil, fmt.Errorf("%w: module[%s] requires %d bytes, but the limit is %d bytes",
+				ErrUsageLimitExceeded, name, initial, limit)
+		}
+	}
+
 	m.Tables = make([]*TableInstance, int(module.ImportTableCount)+len(module.TableSection))
 	m.Globals = make([]*GlobalInstance, int(module.ImportGlobalCount)+len(module.GlobalSection))
 	m.Engine, err = s.Engine.NewModuleEngine(module, m)
@@ -334,7 +404,7 @@ func (s *Store) instantiate(
 		return nil, err
 	}
 
-	if err = m.resolveImports(module); err != nil {
+	if err = m.resolveImports(ctx, module); err != nil {
 		return nil, err
 	}
 
@@ -347,6 +417,9 @@ func (s *Store) instantiate(
 
 	m.buildGlobals(module, m.Engine.FunctionInstanceReference)
 	m.buildMemory(module)
+	if module.MemorySection != nil {
+		m.MemoryInstance.metrics = MetricsFrom(ctx)
+	}
 	m.Exports = module.Exports
 
 	// As of reference types proposal, data segment validation must happen after instantiation,
@@ -368,26 +441,37 @@ func (s *Store) instantiate(
 
 	m.applyElements(module.ElementSection)
 
-	// Execute the start function.
-	if module.StartSection != nil {
-		funcIdx := *module.StartSection
-		ce := m.Engine.NewFunction(funcIdx)
-		_, err = ce.Call(ctx)
-		if exitErr, ok := err.(*sys.ExitError); ok { // Don't wrap an exit error!
-			return nil, exitErr
-		} else if err != nil {
-			return nil, fmt.Errorf("start %s failed: %w", module.funcDesc(SectionIDFunction, funcIdx), err)
-		}
+	if err = m.callStart(ctx); err != nil {
+		return nil, err
 	}
 	return
 }
 
-func (m *ModuleInstance) resolveImports(module *Module) (err error) {
+// callStart executes the start function, if any.
+func (m *ModuleInstance) callStart(ctx context.Context) error {
+	module := m.Source
+	if module.StartSection == nil {
+		return nil
+	}
+	funcIdx := *module.StartSection
+	ce := m.Engine.NewFunction(funcIdx)
+	_, err := ce.Call(ctx)
+	if exitErr, ok := err.(*sys.ExitError); ok { // Don't wrap an exit error!
+		return exitErr
+	} else if err != nil {
+		return fmt.Errorf("start %s failed: %w", module.funcDesc(SectionIDFunction, funcIdx), err)
+	}
+	return nil
+}
+
+func (m *ModuleInstance) resolveImports(ctx context.Context, module *Module) (err error) {
 	for moduleName, imports := range module.ImportPerModule {
 		var importedModule *ModuleInstance
 		importedModule, err = m.s.module(moduleName)
 		if err != nil {
-			return err
+			if importedModule, err = m.s.resolveModule(ctx, moduleName, err); err != nil {
+				return err
+			}
 		}
 
 		for _, i := range imports {
@@ -496,6 +580,8 @@ func executeConstExpressionI32(importedGlobals []*GlobalInstance, expr *Constant
 		id, _, _ := leb128.LoadUint32(expr.Data)
 		g := importedGlobals[id]
 		ret = int32(g.Val)
+	case OpcodeI32Add, OpcodeI32Sub, OpcodeI32Mul:
+		ret = int32(executeExtendedConstExpression(importedGlobals, expr.Data))
 	}
 	return
 }
@@ -546,6 +632,8 @@ func (g *GlobalInstance) initialize(importedGlobals []*GlobalInstance, expr *Con
 		g.Val = uint64(funcRefResolver(v))
 	case OpcodeVecV128Const:
 		g.Val, g.ValHi = binary.LittleEndian.Uint64(expr.Data[0:8]), binary.LittleEndian.Uint64(expr.Data[8:16])
+	case OpcodeI32Add, OpcodeI32Sub, OpcodeI32Mul, OpcodeI64Add, OpcodeI64Sub, OpcodeI64Mul:
+		g.Val = executeExtendedConstExpression(importedGlobals, expr.Data)
 	}
 }
 
@@ -29,7 +29,7 @@ func Test_resolveImports_table(t *testing.T) {
 			ModuleName: moduleName,
 		}
 		m := &ModuleInstance{Tables: make([]*TableInstance, 1), s: s}
-		err := m.resolveImports(&Module{
+		err := m.resolveImports(testCtx, &Module{
 			ImportPerModule: map[string][]*Import{
 				moduleName: {{Module: moduleName, Name: name, Type: ExternTypeTable, DescTable: Table{Max: &max}}},
 			},
@@ -46,7 +46,7 @@ func Test_resolveImports_table(t *testing.T) {
 			ModuleName: moduleName,
 		}
 		m := &ModuleInstance{Tables: make([]*TableInstance, 1), s: s}
-		err := m.resolveImports(&Module{
+		err := m.resolveImports(testCtx, &Module{
 			ImportPerModule: map[string][]*Import{
 				moduleName: {{Module: moduleName, Name: name, Type: ExternTypeTable, DescTable: importTableType}},
 			},
@@ -63,7 +63,7 @@ func Test_resolveImports_table(t *testing.T) {
 			ModuleName: moduleName,
 		}
 		m := &ModuleInstance{Tables: make([]*TableInstance, 1), s: s}
-		err := m.resolveImports(&Module{
+		err := m.resolveImports(testCtx, &Module{
 			ImportPerModule: map[string][]*Import{
 				moduleName: {{Module: moduleName, Name: name, Type: ExternTypeTable, DescTable: importTableType}},
 			},
@@ -78,7 +78,7 @@ func Test_resolveImports_table(t *testing.T) {
 			ModuleName: moduleName,
 		}
 		m := &ModuleInstance{Tables: make([]*TableInstance, 1), s: s}
-		err := m.resolveImports(&Module{
+		err := m.resolveImports(testCtx, &Module{
 			ImportPerModule: map[string][]*Import{
 				moduleName: {{Module: moduleName, Name: name, Type: ExternTypeTable, DescTable: Table{Type: RefTypeExternref}}},
 			},
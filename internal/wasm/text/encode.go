@@ -0,0 +1,655 @@
+package text
+
+import (
+	"sort"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+var (
+	magic   = []byte{0x00, 0x61, 0x73, 0x6D}
+	version = []byte{0x01, 0x00, 0x00, 0x00}
+)
+
+// encode compiles the declared fields, now that all indexes are known, and
+// returns the module in the binary format.
+func (b *moduleBuilder) encode() ([]byte, error) {
+	var importSection, funcSection, tableSection, memorySection, globalSection [][]byte
+	var exportSection, elemSection, codeSection, dataSection [][]byte
+
+	for _, imp := range b.imports {
+		desc, err := b.importDesc(imp)
+		if err != nil {
+			return nil, err
+		}
+		entry := append(encodeName(imp.module), encodeName(imp.name)...)
+		entry = append(entry, imp.kind)
+		importSection = append(importSection, append(entry, desc...))
+	}
+
+	for _, f := range b.funcFields {
+		typeIdx, code, err := b.function(f)
+		if err != nil {
+			return nil, err
+		}
+		funcSection = append(funcSection, leb128.EncodeUint32(typeIdx))
+		codeSection = append(codeSection, append(leb128.EncodeUint32(uint32(len(code))), code...))
+	}
+
+	for _, f := range b.tableFields {
+		t, err := b.table(f)
+		if err != nil {
+			return nil, err
+		}
+		tableSection = append(tableSection, t)
+	}
+
+	for _, f := range b.memoryFields {
+		m, err := b.memory(f)
+		if err != nil {
+			return nil, err
+		}
+		memorySection = append(memorySection, m)
+	}
+
+	for _, f := range b.globalFields {
+		gt, rest, err := b.globalType(f.rest, f.n)
+		if err != nil {
+			return nil, err
+		}
+		init, err := b.constExpr(rest)
+		if err != nil {
+			return nil, err
+		}
+		globalSection = append(globalSection, append(gt, init...))
+	}
+
+	for _, e := range b.exports {
+		if e.inline {
+			entry := append(encodeName(e.n.list[1].text), e.kind)
+			exportSection = append(exportSection, append(entry, leb128.EncodeUint32(e.idx)...))
+			continue
+		}
+		entry, err := b.export(e.n)
+		if err != nil {
+			return nil, err
+		}
+		exportSection = append(exportSection, entry)
+	}
+
+	var startSection []byte
+	if b.start != nil {
+		if len(b.start.list) != 2 {
+			return nil, b.start.errorf("expected (start funcidx)")
+		}
+		idx, err := b.funcs.resolve(b.start.list[1])
+		if err != nil {
+			return nil, err
+		}
+		startSection = leb128.EncodeUint32(idx)
+	}
+
+	for _, f := range b.elemFields {
+		if f.encoded != nil {
+			elemSection = append(elemSection, f.encoded)
+			continue
+		}
+		e, err := b.elem(f)
+		if err != nil {
+			return nil, err
+		}
+		elemSection = append(elemSection, e)
+	}
+
+	for _, f := range b.dataFields {
+		if f.encoded != nil {
+			dataSection = append(dataSection, f.encoded)
+			continue
+		}
+		d, err := b.data(f)
+		if err != nil {
+			return nil, err
+		}
+		dataSection = append(dataSection, d)
+	}
+
+	ret := append(append([]byte{}, magic...), version...)
+	ret = appendSection(ret, wasm.SectionIDType, b.types)
+	ret = appendSection(ret, wasm.SectionIDImport, importSection)
+	ret = appendSection(ret, wasm.SectionIDFunction, funcSection)
+	ret = appendSection(ret, wasm.SectionIDTable, tableSection)
+	ret = appendSection(ret, wasm.SectionIDMemory, memorySection)
+	ret = appendSection(ret, wasm.SectionIDGlobal, globalSection)
+	ret = appendSection(ret, wasm.SectionIDExport, exportSection)
+	if startSection != nil {
+		ret = append(ret, wasm.SectionIDStart)
+		ret = append(ret, leb128.EncodeUint32(uint32(len(startSection)))...)
+		ret = append(ret, startSection...)
+	}
+	ret = appendSection(ret, wasm.SectionIDElement, elemSection)
+	if b.usesDataCount {
+		count := leb128.EncodeUint32(uint32(len(dataSection)))
+		ret = append(ret, wasm.SectionIDDataCount)
+		ret = append(ret, leb128.EncodeUint32(uint32(len(count)))...)
+		ret = append(ret, count...)
+	}
+	ret = appendSection(ret, wasm.SectionIDCode, codeSection)
+	ret = appendSection(ret, wasm.SectionIDData, dataSection)
+	if names := b.nameSection(); names != nil {
+		ret = append(ret, wasm.SectionIDCustom)
+		ret = append(ret, leb128.EncodeUint32(uint32(len(names)))...)
+		ret = append(ret, names...)
+	}
+	return ret, nil
+}
+
+func (b *moduleBuilder) importDesc(imp *importField) ([]byte, error) {
+	switch imp.kind {
+	case wasm.ExternTypeFunc:
+		tu, consumed, err := b.parseTypeUse(imp.rest)
+		if err != nil {
+			return nil, err
+		} else if consumed != len(imp.rest) {
+			return nil, imp.rest[consumed].errorf("unexpected %s", describe(imp.rest[consumed]))
+		}
+		b.addParamNames(imp.idx, &tu, nil)
+		idx, err := b.typeIndex(&tu)
+		if err != nil {
+			return nil, err
+		}
+		return leb128.EncodeUint32(idx), nil
+	case wasm.ExternTypeTable:
+		return b.tableType(imp.rest, imp.n)
+	case wasm.ExternTypeMemory:
+		return limits(imp.rest, imp.n)
+	default: // wasm.ExternTypeGlobal
+		gt, rest, err := b.globalType(imp.rest, imp.n)
+		if err != nil {
+			return nil, err
+		} else if len(rest) > 0 {
+			return nil, rest[0].errorf("unexpected %s", describe(rest[0]))
+		}
+		return gt, nil
+	}
+}
+
+// function returns the type index and the encoded code of a function.
+func (b *moduleBuilder) function(f *field) (uint32, []byte, error) {
+	tu, consumed, err := b.parseTypeUse(f.rest)
+	if err != nil {
+		return 0, nil, err
+	}
+	typeIdx, err := b.typeIndex(&tu)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c := &funcCompiler{b: b, locals: map[string]uint32{}, localCount: b.paramCount(typeIdx)}
+	b.addParamNames(f.idx, &tu, c.locals)
+
+	// Locals are encoded as runs of the same type.
+	var localTypes []wasm.ValueType
+	rest := f.rest[consumed:]
+	for ; len(rest) > 0 && rest[0].head() == "local"; rest = rest[1:] {
+		l := rest[0].list[1:]
+		if len(l) > 0 && l[0].kind == tokenID {
+			if len(l) != 2 {
+				return 0, nil, rest[0].errorf("expected one type for named local")
+			}
+			if _, ok := c.locals[l[0].text]; ok {
+				return 0, nil, l[0].errorf("duplicate local %s", l[0].text)
+			}
+			c.locals[l[0].text] = c.localCount
+			b.addLocalName(f.idx, c.localCount, l[0].text)
+			l = l[1:]
+		}
+		for _, t := range l {
+			vt, err := valueType(t)
+			if err != nil {
+				return 0, nil, err
+			}
+			localTypes = append(localTypes, vt)
+			c.localCount++
+		}
+	}
+
+	var runs [][]byte
+	for i := 0; i < len(localTypes); {
+		j := i
+		for j < len(localTypes) && localTypes[j] == localTypes[i] {
+			j++
+		}
+		runs = append(runs, append(leb128.EncodeUint32(uint32(j-i)), localTypes[i]))
+		i = j
+	}
+	c.code = encodeVector(runs)
+
+	if err = c.instrs(rest); err != nil {
+		return 0, nil, err
+	} else if len(c.labels) > 0 {
+		return 0, nil, f.n.errorf("missing end of block")
+	}
+	return typeIdx, append(c.code, wasm.OpcodeEnd), nil
+}
+
+// addParamNames records names of parameters in the name section and, if
+// locals is non-nil, makes them resolvable in the function body.
+func (b *moduleBuilder) addParamNames(funcIdx uint32, tu *typeUse, locals map[string]uint32) {
+	for i, id := range tu.paramIDs {
+		if id != nil {
+			b.addLocalName(funcIdx, uint32(i), id.text)
+			if locals != nil {
+				locals[id.text] = uint32(i)
+			}
+		}
+	}
+}
+
+func (b *moduleBuilder) addLocalName(funcIdx, localIdx uint32, id string) {
+	names, ok := b.localNames[funcIdx]
+	if !ok {
+		names = map[uint32]string{}
+		b.localNames[funcIdx] = names
+	}
+	names[localIdx] = id[1:]
+}
+
+// constExpr compiles a constant expression, e.g. "(i32.const 1)", including
+// the terminating end opcode.
+func (b *moduleBuilder) constExpr(nodes []*node) ([]byte, error) {
+	c := &funcCompiler{b: b}
+	if err := c.instrs(nodes); err != nil {
+		return nil, err
+	}
+	return append(c.code, wasm.OpcodeEnd), nil
+}
+
+// limits encodes "min max?" of a table or memory type.
+func limits(nodes []*node, n *node) ([]byte, error) {
+	if len(nodes) == 0 || len(nodes) > 2 {
+		return nil, n.errorf("expected limits, e.g. \"1\" or \"1 2\"")
+	}
+	var values []uint32
+	for _, l := range nodes {
+		if l.kind != tokenNumber {
+			return nil, l.errorf("expected limit, but was %s", describe(l))
+		}
+		v, err := parseUint(l.text, 32)
+		if err != nil {
+			return nil, l.errorf("invalid limit %s", l.text)
+		}
+		values = append(values, uint32(v))
+	}
+	if len(values) == 1 {
+		return append([]byte{0x00}, leb128.EncodeUint32(values[0])...), nil
+	}
+	ret := append([]byte{0x01}, leb128.EncodeUint32(values[0])...)
+	return append(ret, leb128.EncodeUint32(values[1])...), nil
+}
+
+// tableType encodes "min max? reftype".
+func (b *moduleBuilder) tableType(nodes []*node, n *node) ([]byte, error) {
+	if len(nodes) < 2 {
+		return nil, n.errorf("expected table type, e.g. \"1 funcref\"")
+	}
+	rt, err := refType(nodes[len(nodes)-1])
+	if err != nil {
+		return nil, err
+	}
+	l, err := limits(nodes[:len(nodes)-1], n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{rt}, l...), nil
+}
+
+// table encodes a table, or the abbreviation with inline elements, e.g.
+// "(table funcref (elem $f $g))".
+func (b *moduleBuilder) table(f *field) ([]byte, error) {
+	if f.segment == nil {
+		return b.tableType(f.rest, f.n)
+	} else if len(f.rest) != 2 {
+		return nil, f.n.errorf("expected (table reftype (elem ...))")
+	}
+	rt, err := refType(f.rest[0])
+	if err != nil {
+		return nil, err
+	}
+	init, count, err := b.elemList(f.rest[1].list[1:], rt, true)
+	if err != nil {
+		return nil, err
+	}
+	offset := []byte{wasm.OpcodeI32Const, 0, wasm.OpcodeEnd}
+	// Active segment with a table index (flag 2) or expressions (flag 6).
+	seg := append([]byte{init[0]}, leb128.EncodeUint32(f.idx)...)
+	seg = append(seg, offset...)
+	f.segment.encoded = append(seg, init[1:]...)
+
+	size := leb128.EncodeUint32(count)
+	ret := append([]byte{rt, 0x01}, size...)
+	return append(ret, size...), nil
+}
+
+// memory encodes a memory, or the abbreviation with inline data, e.g.
+// "(memory (data "hello"))".
+func (b *moduleBuilder) memory(f *field) ([]byte, error) {
+	if f.segment == nil {
+		return limits(f.rest, f.n)
+	} else if len(f.rest) != 1 {
+		return nil, f.n.errorf("expected (memory (data ...))")
+	}
+	var init []byte
+	for _, s := range f.rest[0].list[1:] {
+		if s.kind != tokenString {
+			return nil, s.errorf("expected string, but was %s", describe(s))
+		}
+		init = append(init, s.text...)
+	}
+	seg := append([]byte{0x02}, leb128.EncodeUint32(f.idx)...)
+	seg = append(seg, wasm.OpcodeI32Const, 0, wasm.OpcodeEnd)
+	seg = append(seg, leb128.EncodeUint32(uint32(len(init)))...)
+	f.segment.encoded = append(seg, init...)
+
+	pages := leb128.EncodeUint32((uint32(len(init)) + wasm.MemoryPageSize - 1) / wasm.MemoryPageSize)
+	ret := append([]byte{0x01}, pages...)
+	return append(ret, pages...), nil
+}
+
+// globalType encodes "valtype" or "(mut valtype)", returning the remaining nodes.
+func (b *moduleBuilder) globalType(nodes []*node, n *node) ([]byte, []*node, error) {
+	if len(nodes) == 0 {
+		return nil, nil, n.errorf("missing global type")
+	}
+	t := nodes[0]
+	mutable := byte(0)
+	if t.head() == "mut" {
+		if len(t.list) != 2 {
+			return nil, nil, t.errorf("expected (mut valtype)")
+		}
+		t, mutable = t.list[1], 1
+	}
+	vt, err := valueType(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte{vt, mutable}, nodes[1:], nil
+}
+
+func (b *moduleBuilder) export(n *node) ([]byte, error) {
+	if len(n.list) != 3 || n.list[1].kind != tokenString || len(n.list[2].list) != 2 {
+		return nil, n.errorf("expected (export \"name\" (kind index))")
+	}
+	desc := n.list[2]
+	var kind wasm.ExternType
+	var space *indexSpace
+	switch desc.head() {
+	case "func":
+		kind, space = wasm.ExternTypeFunc, b.funcs
+	case "table":
+		kind, space = wasm.ExternTypeTable, b.tables
+	case "memory":
+		kind, space = wasm.ExternTypeMemory, b.memories
+	case "global":
+		kind, space = wasm.ExternTypeGlobal, b.globals
+	default:
+		return nil, desc.errorf("unknown export kind %s", describe(desc))
+	}
+	idx, err := space.resolve(desc.list[1])
+	if err != nil {
+		return nil, err
+	}
+	ret := append(encodeName(n.list[1].text), kind)
+	return append(ret, leb128.EncodeUint32(idx)...), nil
+}
+
+// offset parses the offset of an active segment, either "(offset instr*)"
+// or a single folded instruction, returning the encoded expression.
+func (b *moduleBuilder) offset(n *node) ([]byte, error) {
+	if n.head() == "offset" {
+		return b.constExpr(n.list[1:])
+	}
+	return b.constExpr([]*node{n})
+}
+
+// elemList encodes the element kind or type and the elements of a segment,
+// e.g. "func $f $g" or "funcref (ref.func $f) (ref.null func)". The first
+// byte returned is the flag of an active segment with a table index, which
+// is 2 for function indexes and 6 for expressions.
+func (b *moduleBuilder) elemList(nodes []*node, rt wasm.RefType, allowBareIndexes bool) ([]byte, uint32, error) {
+	if len(nodes) > 0 && nodes[0].kind == tokenKeyword && nodes[0].text == "func" {
+		nodes, allowBareIndexes = nodes[1:], true
+	} else if len(nodes) > 0 && nodes[0].kind == tokenKeyword {
+		var err error
+		if rt, err = refType(nodes[0]); err != nil {
+			return nil, 0, err
+		}
+		nodes, allowBareIndexes = nodes[1:], false
+	}
+
+	if allowBareIndexes && (len(nodes) == 0 || !nodes[0].isList()) {
+		indexes := make([][]byte, 0, len(nodes))
+		for _, i := range nodes {
+			idx, err := b.funcs.resolve(i)
+			if err != nil {
+				return nil, 0, err
+			}
+			indexes = append(indexes, leb128.EncodeUint32(idx))
+		}
+		// elemkind 0x00 is funcref.
+		return append([]byte{0x02, 0x00}, encodeVector(indexes)...), uint32(len(nodes)), nil
+	}
+
+	exprs := make([][]byte, 0, len(nodes))
+	for _, e := range nodes {
+		if e.head() == "item" {
+			expr, err := b.constExpr(e.list[1:])
+			if err != nil {
+				return nil, 0, err
+			}
+			exprs = append(exprs, expr)
+			continue
+		} else if !e.isList() {
+			return nil, 0, e.errorf("expected element expression, but was %s", describe(e))
+		}
+		expr, err := b.constExpr([]*node{e})
+		if err != nil {
+			return nil, 0, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return append([]byte{0x06, rt}, encodeVector(exprs)...), uint32(len(nodes)), nil
+}
+
+// elem encodes an element segment, which is active when it has an offset,
+// declarative if it starts with "declare" and passive otherwise.
+func (b *moduleBuilder) elem(f *field) ([]byte, error) {
+	rest := f.rest
+	if len(rest) > 0 && rest[0].kind == tokenKeyword && rest[0].text == "declare" {
+		init, _, err := b.elemList(rest[1:], wasm.RefTypeFuncref, false)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{init[0] + 1}, init[1:]...), nil // flag 3 or 7
+	}
+
+	var tableIdx *uint32
+	if len(rest) > 0 && rest[0].head() == "table" {
+		if len(rest[0].list) != 2 {
+			return nil, rest[0].errorf("expected (table index)")
+		}
+		idx, err := b.tables.resolve(rest[0].list[1])
+		if err != nil {
+			return nil, err
+		}
+		tableIdx, rest = &idx, rest[1:]
+	} else if len(rest) > 0 && isIndex(rest[0]) && len(rest) > 1 && rest[1].isList() {
+		// Legacy syntax: (elem tableidx (offset) funcidx*)
+		idx, err := b.tables.resolve(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		tableIdx, rest = &idx, rest[1:]
+	}
+
+	if len(rest) == 0 || !rest[0].isList() || rest[0].head() == "item" {
+		init, _, err := b.elemList(rest, wasm.RefTypeFuncref, false)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{init[0] - 1}, init[1:]...), nil // flag 1 or 5
+	}
+
+	offset, err := b.offset(rest[0])
+	if err != nil {
+		return nil, err
+	}
+	// Bare function indexes are allowed after an offset, e.g. (elem (i32.const 0) $f).
+	init, _, err := b.elemList(rest[1:], wasm.RefTypeFuncref, true)
+	if err != nil {
+		return nil, err
+	}
+	if tableIdx == nil && (init[0] == 0x06 && init[1] == wasm.RefTypeFuncref || init[0] == 0x02) {
+		// Table zero, with implicit elemkind or type: flag 0 or 4.
+		ret := append([]byte{init[0] - 2}, offset...)
+		return append(ret, init[2:]...), nil
+	}
+	var idx uint32
+	if tableIdx != nil {
+		idx = *tableIdx
+	}
+	ret := append([]byte{init[0]}, leb128.EncodeUint32(idx)...)
+	ret = append(ret, offset...)
+	return append(ret, init[1:]...), nil
+}
+
+// data encodes a data segment, which is active when it has an offset.
+func (b *moduleBuilder) data(f *field) ([]byte, error) {
+	rest := f.rest
+	var memIdx uint32
+	hasMemIdx := false
+	if len(rest) > 0 && rest[0].head() == "memory" {
+		if len(rest[0].list) != 2 {
+			return nil, rest[0].errorf("expected (memory index)")
+		}
+		idx, err := b.memories.resolve(rest[0].list[1])
+		if err != nil {
+			return nil, err
+		}
+		memIdx, hasMemIdx, rest = idx, true, rest[1:]
+	} else if len(rest) > 0 && isIndex(rest[0]) {
+		idx, err := b.memories.resolve(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		memIdx, hasMemIdx, rest = idx, true, rest[1:]
+	}
+
+	var ret []byte
+	if len(rest) > 0 && rest[0].isList() {
+		offset, err := b.offset(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		if memIdx == 0 {
+			ret = append([]byte{0x00}, offset...)
+		} else {
+			ret = append(append([]byte{0x02}, leb128.EncodeUint32(memIdx)...), offset...)
+		}
+		rest = rest[1:]
+	} else if hasMemIdx {
+		return nil, f.n.errorf("missing offset of active data segment")
+	} else {
+		ret = []byte{0x01}
+	}
+
+	var init []byte
+	for _, s := range rest {
+		if s.kind != tokenString {
+			return nil, s.errorf("expected string, but was %s", describe(s))
+		}
+		init = append(init, s.text...)
+	}
+	ret = append(ret, leb128.EncodeUint32(uint32(len(init)))...)
+	return append(ret, init...), nil
+}
+
+// nameSection returns the "name" custom section, or nil if there are no names.
+func (b *moduleBuilder) nameSection() []byte {
+	var subsections []byte
+	if b.name != nil {
+		subsections = appendSubsection(subsections, 0, encodeName(b.name.text[1:]))
+	}
+	if len(b.funcNames) > 0 {
+		subsections = appendSubsection(subsections, 1, encodeNameMap(b.funcNames))
+	}
+	if len(b.localNames) > 0 {
+		var entries [][]byte
+		for _, funcIdx := range sortedKeys(b.localNames) {
+			entries = append(entries, append(leb128.EncodeUint32(funcIdx), encodeNameMap(b.localNames[funcIdx])...))
+		}
+		subsections = appendSubsection(subsections, 2, encodeVector(entries))
+	}
+	if subsections == nil {
+		return nil
+	}
+	return append(encodeName("name"), subsections...)
+}
+
+func appendSubsection(ret []byte, id byte, data []byte) []byte {
+	ret = append(ret, id)
+	ret = append(ret, leb128.EncodeUint32(uint32(len(data)))...)
+	return append(ret, data...)
+}
+
+func encodeNameMap(names map[uint32]string) []byte {
+	keys := make([]uint32, 0, len(names))
+	for k := range names {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	entries := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, append(leb128.EncodeUint32(k), encodeName(names[k])...))
+	}
+	return encodeVector(entries)
+}
+
+func sortedKeys(m map[uint32]map[uint32]string) []uint32 {
+	keys := make([]uint32, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func encodeFuncType(params, results []wasm.ValueType) []byte {
+	ret := append([]byte{0x60}, leb128.EncodeUint32(uint32(len(params)))...)
+	ret = append(ret, params...)
+	ret = append(ret, leb128.EncodeUint32(uint32(len(results)))...)
+	return append(ret, results...)
+}
+
+func encodeName(name string) []byte {
+	return append(leb128.EncodeUint32(uint32(len(name))), name...)
+}
+
+func encodeVector(items [][]byte) []byte {
+	ret := leb128.EncodeUint32(uint32(len(items)))
+	for _, i := range items {
+		ret = append(ret, i...)
+	}
+	return ret
+}
+
+// appendSection appends a section with the given entries, unless empty.
+func appendSection(ret []byte, id wasm.SectionID, entries [][]byte) []byte {
+	if len(entries) == 0 {
+		return ret
+	}
+	contents := encodeVector(entries)
+	ret = append(ret, id)
+	ret = append(ret, leb128.EncodeUint32(uint32(len(contents)))...)
+	return append(ret, contents...)
+}
@@ -0,0 +1,481 @@
+package text
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"strings"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// funcCompiler compiles instructions of a function body or constant
+// expression into the binary format.
+type funcCompiler struct {
+	b *moduleBuilder
+	// locals are the indexes of named parameters and locals.
+	locals     map[string]uint32
+	localCount uint32
+	// labels are the identifiers of enclosing blocks, innermost last. Unnamed
+	// blocks are "".
+	labels []string
+	code   []byte
+}
+
+// instrs compiles a sequence of plain and folded instructions.
+func (c *funcCompiler) instrs(nodes []*node) error {
+	for i := 0; i < len(nodes); {
+		n := nodes[i]
+		i++
+		if n.isList() {
+			if err := c.folded(n); err != nil {
+				return err
+			}
+			continue
+		} else if n.kind != tokenKeyword {
+			return n.errorf("expected instruction, but was %s", describe(n))
+		}
+
+		switch n.text {
+		case "block", "loop", "if":
+			label, bt, consumed, err := c.blockType(nodes[i:])
+			if err != nil {
+				return err
+			}
+			i += consumed
+			c.code = append(c.code, instructions[n.text].opcode...)
+			c.code = append(c.code, bt...)
+			c.labels = append(c.labels, label)
+		case "else", "end":
+			if len(c.labels) == 0 {
+				return n.errorf("unexpected %s", n.text)
+			}
+			if i < len(nodes) && nodes[i].kind == tokenID {
+				if nodes[i].text != c.labels[len(c.labels)-1] {
+					return nodes[i].errorf("mismatched label %s", nodes[i].text)
+				}
+				i++
+			}
+			if n.text == "else" {
+				c.code = append(c.code, wasm.OpcodeElse)
+			} else {
+				c.code = append(c.code, wasm.OpcodeEnd)
+				c.labels = c.labels[:len(c.labels)-1]
+			}
+		default:
+			consumed, err := c.instr(n, nodes[i:])
+			if err != nil {
+				return err
+			}
+			i += consumed
+		}
+	}
+	return nil
+}
+
+// folded compiles a folded instruction, e.g. "(i32.add (local.get 0) (i32.const 1))".
+func (c *funcCompiler) folded(n *node) error {
+	if len(n.list) == 0 || n.list[0].kind != tokenKeyword {
+		return n.errorf("expected instruction, but was %s", describe(n))
+	}
+	name, rest := n.list[0], n.list[1:]
+	switch name.text {
+	case "block", "loop":
+		label, bt, consumed, err := c.blockType(rest)
+		if err != nil {
+			return err
+		}
+		c.code = append(c.code, instructions[name.text].opcode...)
+		c.code = append(c.code, bt...)
+		c.labels = append(c.labels, label)
+		if err = c.instrs(rest[consumed:]); err != nil {
+			return err
+		}
+		c.code = append(c.code, wasm.OpcodeEnd)
+		c.labels = c.labels[:len(c.labels)-1]
+		return nil
+	case "if":
+		label, bt, consumed, err := c.blockType(rest)
+		if err != nil {
+			return err
+		}
+		rest = rest[consumed:]
+		// Conditions precede the "then" clause.
+		for len(rest) > 0 && rest[0].head() != "then" {
+			if !rest[0].isList() {
+				return rest[0].errorf("expected (then), but was %s", describe(rest[0]))
+			}
+			if err = c.folded(rest[0]); err != nil {
+				return err
+			}
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return n.errorf("missing (then) in if")
+		}
+		c.code = append(c.code, wasm.OpcodeIf)
+		c.code = append(c.code, bt...)
+		c.labels = append(c.labels, label)
+		if err = c.instrs(rest[0].list[1:]); err != nil {
+			return err
+		}
+		if len(rest) > 1 {
+			if rest[1].head() != "else" || len(rest) > 2 {
+				return rest[1].errorf("expected (else), but was %s", describe(rest[1]))
+			} else if len(rest[1].list) > 1 { // An empty else is omitted.
+				c.code = append(c.code, wasm.OpcodeElse)
+				if err = c.instrs(rest[1].list[1:]); err != nil {
+					return err
+				}
+			}
+		}
+		c.code = append(c.code, wasm.OpcodeEnd)
+		c.labels = c.labels[:len(c.labels)-1]
+		return nil
+	}
+
+	// Compile the operands first, then the instruction with its immediates.
+	var operands []*node
+	code := c.code
+	c.code = nil
+	consumed, err := c.instr(name, rest)
+	if err != nil {
+		return err
+	}
+	instr := c.code
+	c.code = code
+	for _, op := range rest[consumed:] {
+		if !op.isList() {
+			return op.errorf("unexpected %s", describe(op))
+		}
+		operands = append(operands, op)
+	}
+	for _, op := range operands {
+		if err = c.folded(op); err != nil {
+			return err
+		}
+	}
+	c.code = append(c.code, instr...)
+	return nil
+}
+
+// blockType parses the optional label and block type of a block, loop or if.
+func (c *funcCompiler) blockType(nodes []*node) (label string, bt []byte, consumed int, err error) {
+	if len(nodes) > 0 && nodes[0].kind == tokenID {
+		label, nodes, consumed = nodes[0].text, nodes[1:], 1
+	}
+	tu, n, err := c.b.parseTypeUse(nodes)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	consumed += n
+	switch {
+	case tu.typeIdx == nil && len(tu.params) == 0 && len(tu.results) == 0:
+		bt = []byte{0x40}
+	case tu.typeIdx == nil && len(tu.params) == 0 && len(tu.results) == 1:
+		bt = []byte{tu.results[0]}
+	default:
+		idx, err := c.b.typeIndex(&tu)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		// Like other tools, prefer the short form when the type allows it.
+		switch t := c.b.types[idx]; {
+		case len(t) == 3 && t[1] == 0 && t[2] == 0:
+			bt = []byte{0x40}
+		case len(t) == 4 && t[1] == 0 && t[2] == 1:
+			bt = []byte{t[3]}
+		default:
+			bt = leb128.EncodeInt64(int64(idx))
+		}
+	}
+	return
+}
+
+// instr compiles a plain instruction, returning how many of the following
+// nodes were consumed as its immediates.
+func (c *funcCompiler) instr(n *node, rest []*node) (consumed int, err error) {
+	in, ok := instructions[n.text]
+	if !ok {
+		return 0, n.errorf("unknown instruction %s", n.text)
+	}
+	opcode := in.opcode
+	var imm []byte
+
+	// index consumes an optional index immediate.
+	index := func(space *indexSpace, required bool) (uint32, error) {
+		if consumed < len(rest) && isIndex(rest[consumed]) {
+			consumed++
+			return space.resolve(rest[consumed-1])
+		} else if required {
+			return 0, n.errorf("missing %s index for %s", space.kind, n.text)
+		}
+		return 0, nil
+	}
+
+	switch in.imm {
+	case immNone:
+	case immBlock:
+		// Only reachable from folded instructions without a block body.
+		return 0, n.errorf("unexpected %s", n.text)
+	case immLabel:
+		depth, err := c.label(n, rest)
+		if err != nil {
+			return 0, err
+		}
+		consumed = 1
+		imm = leb128.EncodeUint32(depth)
+	case immLabels:
+		var depths []uint32
+		for consumed < len(rest) && isIndex(rest[consumed]) {
+			depth, err := c.label(n, rest[consumed:])
+			if err != nil {
+				return 0, err
+			}
+			depths = append(depths, depth)
+			consumed++
+		}
+		if len(depths) == 0 {
+			return 0, n.errorf("missing label for %s", n.text)
+		}
+		imm = leb128.EncodeUint32(uint32(len(depths) - 1))
+		for _, d := range depths {
+			imm = append(imm, leb128.EncodeUint32(d)...)
+		}
+	case immFunc:
+		idx, err := index(c.b.funcs, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immCallIndirect:
+		tableIdx, err := index(c.b.tables, false)
+		if err != nil {
+			return 0, err
+		}
+		tu, n, err := c.b.parseTypeUse(rest[consumed:])
+		if err != nil {
+			return 0, err
+		}
+		consumed += n
+		typeIdx, err := c.b.typeIndex(&tu)
+		if err != nil {
+			return 0, err
+		}
+		imm = append(leb128.EncodeUint32(typeIdx), leb128.EncodeUint32(tableIdx)...)
+	case immLocal:
+		if len(rest) == 0 || !isIndex(rest[0]) {
+			return 0, n.errorf("missing local index for %s", n.text)
+		}
+		consumed = 1
+		idx, err := c.local(rest[0])
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immGlobal:
+		idx, err := index(c.b.globals, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immTable:
+		idx, err := index(c.b.tables, false)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immMemArg:
+		offset, align := uint64(0), uint64(1)<<in.alignment
+		for ; consumed < len(rest) && rest[consumed].kind == tokenKeyword; consumed++ {
+			arg := rest[consumed]
+			var v uint64
+			if strings.HasPrefix(arg.text, "offset=") {
+				if v, err = parseUint(arg.text[7:], 64); err != nil {
+					return 0, arg.errorf("invalid %s", arg.text)
+				}
+				offset = v
+			} else if strings.HasPrefix(arg.text, "align=") {
+				if v, err = parseUint(arg.text[6:], 32); err != nil || bits.OnesCount64(v) != 1 {
+					return 0, arg.errorf("invalid %s", arg.text)
+				}
+				align = v
+			} else {
+				break
+			}
+		}
+		imm = append(leb128.EncodeUint32(uint32(bits.TrailingZeros64(align))), leb128.EncodeUint64(offset)...)
+	case immMemory:
+		idx, err := index(c.b.memories, false)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immI32, immI64, immF32, immF64:
+		if len(rest) == 0 || rest[0].isList() {
+			return 0, n.errorf("missing constant for %s", n.text)
+		}
+		consumed = 1
+		if imm, err = constant(in.imm, rest[0]); err != nil {
+			return 0, err
+		}
+	case immSelect:
+		var types []wasm.ValueType
+		for ; consumed < len(rest) && rest[consumed].head() == "result"; consumed++ {
+			for _, t := range rest[consumed].list[1:] {
+				vt, err := valueType(t)
+				if err != nil {
+					return 0, err
+				}
+				types = append(types, vt)
+			}
+		}
+		if consumed > 0 {
+			opcode = []byte{wasm.OpcodeTypedSelect}
+			imm = append(leb128.EncodeUint32(uint32(len(types))), types...)
+		}
+	case immRefType:
+		if len(rest) == 0 {
+			return 0, n.errorf("missing reference type for %s", n.text)
+		}
+		consumed = 1
+		rt, err := refType(rest[0])
+		if err != nil {
+			return 0, err
+		}
+		imm = []byte{rt}
+	case immMemoryInit:
+		c.b.usesDataCount = true
+		var memIdx uint32
+		if len(rest) > 1 && isIndex(rest[0]) && isIndex(rest[1]) {
+			if memIdx, err = index(c.b.memories, true); err != nil {
+				return 0, err
+			}
+		}
+		dataIdx, err := index(c.b.datas, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = append(leb128.EncodeUint32(dataIdx), leb128.EncodeUint32(memIdx)...)
+	case immData:
+		c.b.usesDataCount = true
+		idx, err := index(c.b.datas, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immMemoryCopy:
+		dst, err := index(c.b.memories, false)
+		if err != nil {
+			return 0, err
+		}
+		src, err := index(c.b.memories, false)
+		if err != nil {
+			return 0, err
+		}
+		imm = append(leb128.EncodeUint32(dst), leb128.EncodeUint32(src)...)
+	case immTableInit:
+		var tableIdx uint32
+		if len(rest) > 1 && isIndex(rest[0]) && isIndex(rest[1]) {
+			if tableIdx, err = index(c.b.tables, true); err != nil {
+				return 0, err
+			}
+		}
+		elemIdx, err := index(c.b.elems, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = append(leb128.EncodeUint32(elemIdx), leb128.EncodeUint32(tableIdx)...)
+	case immElem:
+		idx, err := index(c.b.elems, true)
+		if err != nil {
+			return 0, err
+		}
+		imm = leb128.EncodeUint32(idx)
+	case immTableCopy:
+		var dst, src uint32
+		if len(rest) > 1 && isIndex(rest[0]) && isIndex(rest[1]) {
+			if dst, err = index(c.b.tables, true); err != nil {
+				return 0, err
+			}
+			if src, err = index(c.b.tables, true); err != nil {
+				return 0, err
+			}
+		}
+		imm = append(leb128.EncodeUint32(dst), leb128.EncodeUint32(src)...)
+	}
+	c.code = append(c.code, opcode...)
+	c.code = append(c.code, imm...)
+	return consumed, nil
+}
+
+// label returns the depth of the label referenced by rest[0] from inside the
+// innermost block.
+func (c *funcCompiler) label(n *node, rest []*node) (uint32, error) {
+	if len(rest) == 0 || !isIndex(rest[0]) {
+		return 0, n.errorf("missing label for %s", n.text)
+	}
+	l := rest[0]
+	if l.kind == tokenNumber {
+		v, err := parseUint(l.text, 32)
+		if err != nil {
+			return 0, l.errorf("invalid label %s", l.text)
+		}
+		return uint32(v), nil
+	}
+	for i := len(c.labels) - 1; i >= 0; i-- {
+		if c.labels[i] == l.text {
+			return uint32(len(c.labels) - 1 - i), nil
+		}
+	}
+	return 0, l.errorf("unknown label %s", l.text)
+}
+
+// local resolves the index of a parameter or local.
+func (c *funcCompiler) local(n *node) (uint32, error) {
+	if n.kind == tokenID {
+		if idx, ok := c.locals[n.text]; ok {
+			return idx, nil
+		}
+		return 0, n.errorf("unknown local %s", n.text)
+	}
+	v, err := parseUint(n.text, 32)
+	if err != nil {
+		return 0, n.errorf("invalid local index %s", n.text)
+	}
+	return uint32(v), nil
+}
+
+// constant encodes the immediate of a const instruction.
+func constant(imm immediate, n *node) ([]byte, error) {
+	switch imm {
+	case immI32:
+		v, err := parseInt(n.text, 32)
+		if err != nil {
+			return nil, n.errorf("invalid i32 constant %s", n.text)
+		}
+		return leb128.EncodeInt32(int32(uint32(v))), nil
+	case immI64:
+		v, err := parseInt(n.text, 64)
+		if err != nil {
+			return nil, n.errorf("invalid i64 constant %s", n.text)
+		}
+		return leb128.EncodeInt64(int64(v)), nil
+	case immF32:
+		v, err := parseFloat(n.text, 32)
+		if err != nil {
+			return nil, n.errorf("invalid f32 constant %s", n.text)
+		}
+		ret := make([]byte, 4)
+		binary.LittleEndian.PutUint32(ret, uint32(v))
+		return ret, nil
+	default:
+		v, err := parseFloat(n.text, 64)
+		if err != nil {
+			return nil, n.errorf("invalid f64 constant %s", n.text)
+		}
+		ret := make([]byte, 8)
+		binary.LittleEndian.PutUint64(ret, v)
+		return ret, nil
+	}
+}
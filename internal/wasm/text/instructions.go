@@ -0,0 +1,151 @@
+package text
+
+import (
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// immediate is the kind of immediates following an instruction name.
+type immediate byte
+
+const (
+	immNone immediate = iota
+	// immBlock is an optional label and block type, e.g. "block $l (result i32)".
+	immBlock
+	// immLabel is a label, e.g. "br $l" or "br 0".
+	immLabel
+	// immLabels is one or more labels, e.g. "br_table 0 1 $l".
+	immLabels
+	// immFunc is a function index, e.g. "call $f".
+	immFunc
+	// immCallIndirect is an optional table index and a type use.
+	immCallIndirect
+	// immLocal is a local index, e.g. "local.get $x".
+	immLocal
+	// immGlobal is a global index, e.g. "global.get $g".
+	immGlobal
+	// immTable is an optional table index, e.g. "table.size" or "table.size $t".
+	immTable
+	// immMemArg is an optional offset and alignment, e.g. "i32.load offset=4 align=2".
+	immMemArg
+	// immMemory is an optional memory index, which is encoded as a zero byte.
+	immMemory
+	immI32
+	immI64
+	immF32
+	immF64
+	// immSelect is an optional result type, e.g. "select (result i32)".
+	immSelect
+	// immRefType is a heap type, e.g. "ref.null func".
+	immRefType
+	// immMemoryInit is a data index, e.g. "memory.init $d".
+	immMemoryInit
+	// immData is a data index, e.g. "data.drop $d".
+	immData
+	// immMemoryCopy has two memory indexes, which are encoded as zero bytes.
+	immMemoryCopy
+	// immTableInit is an optional table index and an element index.
+	immTableInit
+	// immElem is an element index, e.g. "elem.drop $e".
+	immElem
+	// immTableCopy is optional destination and source table indexes.
+	immTableCopy
+)
+
+// instruction is how to encode an instruction in the binary format.
+type instruction struct {
+	// opcode includes any prefix, e.g. wasm.OpcodeMiscPrefix.
+	opcode []byte
+	imm    immediate
+	// alignment is the natural alignment of immMemArg, as a power of two.
+	alignment uint32
+}
+
+// instructions are all supported plain instructions, keyed by name.
+var instructions = map[string]instruction{}
+
+func init() {
+	for i := 0; i < 256; i++ {
+		op := wasm.Opcode(i)
+		name := wasm.InstructionName(op)
+		switch op {
+		case wasm.OpcodeElse, wasm.OpcodeEnd, wasm.OpcodeTypedSelect, wasm.OpcodeMiscPrefix, wasm.OpcodeVecPrefix:
+			continue // not plain instructions in the text format
+		}
+		if name == "" {
+			continue
+		}
+		in := instruction{opcode: []byte{op}}
+		switch op {
+		case wasm.OpcodeBlock, wasm.OpcodeLoop, wasm.OpcodeIf:
+			in.imm = immBlock
+		case wasm.OpcodeBr, wasm.OpcodeBrIf:
+			in.imm = immLabel
+		case wasm.OpcodeBrTable:
+			in.imm = immLabels
+		case wasm.OpcodeCall, wasm.OpcodeRefFunc:
+			in.imm = immFunc
+		case wasm.OpcodeCallIndirect:
+			in.imm = immCallIndirect
+		case wasm.OpcodeLocalGet, wasm.OpcodeLocalSet, wasm.OpcodeLocalTee:
+			in.imm = immLocal
+		case wasm.OpcodeGlobalGet, wasm.OpcodeGlobalSet:
+			in.imm = immGlobal
+		case wasm.OpcodeTableGet, wasm.OpcodeTableSet:
+			in.imm = immTable
+		case wasm.OpcodeMemorySize, wasm.OpcodeMemoryGrow:
+			in.imm = immMemory
+		case wasm.OpcodeI32Const:
+			in.imm = immI32
+		case wasm.OpcodeI64Const:
+			in.imm = immI64
+		case wasm.OpcodeF32Const:
+			in.imm = immF32
+		case wasm.OpcodeF64Const:
+			in.imm = immF64
+		case wasm.OpcodeSelect:
+			in.imm = immSelect
+		case wasm.OpcodeRefNull:
+			in.imm = immRefType
+		case wasm.OpcodeI32Load8S, wasm.OpcodeI32Load8U, wasm.OpcodeI64Load8S, wasm.OpcodeI64Load8U,
+			wasm.OpcodeI32Store8, wasm.OpcodeI64Store8:
+			in.imm, in.alignment = immMemArg, 0
+		case wasm.OpcodeI32Load16S, wasm.OpcodeI32Load16U, wasm.OpcodeI64Load16S, wasm.OpcodeI64Load16U,
+			wasm.OpcodeI32Store16, wasm.OpcodeI64Store16:
+			in.imm, in.alignment = immMemArg, 1
+		case wasm.OpcodeI32Load, wasm.OpcodeF32Load, wasm.OpcodeI64Load32S, wasm.OpcodeI64Load32U,
+			wasm.OpcodeI32Store, wasm.OpcodeF32Store, wasm.OpcodeI64Store32:
+			in.imm, in.alignment = immMemArg, 2
+		case wasm.OpcodeI64Load, wasm.OpcodeF64Load, wasm.OpcodeI64Store, wasm.OpcodeF64Store:
+			in.imm, in.alignment = immMemArg, 3
+		}
+		instructions[name] = in
+	}
+
+	for i := 0; i < 256; i++ {
+		op := wasm.OpcodeMisc(i)
+		name := wasm.MiscInstructionName(op)
+		if name == "" {
+			continue
+		}
+		in := instruction{opcode: []byte{wasm.OpcodeMiscPrefix, op}}
+		switch op {
+		case wasm.OpcodeMiscMemoryInit:
+			in.imm = immMemoryInit
+		case wasm.OpcodeMiscDataDrop:
+			in.imm = immData
+		case wasm.OpcodeMiscMemoryCopy:
+			in.imm = immMemoryCopy
+		case wasm.OpcodeMiscMemoryFill:
+			in.imm = immMemory
+		case wasm.OpcodeMiscTableInit:
+			in.imm = immTableInit
+		case wasm.OpcodeMiscElemDrop:
+			in.imm = immElem
+		case wasm.OpcodeMiscTableCopy:
+			in.imm = immTableCopy
+		case wasm.OpcodeMiscTableGrow, wasm.OpcodeMiscTableSize, wasm.OpcodeMiscTableFill:
+			in.imm = immTable
+		}
+		instructions[name] = in
+	}
+}
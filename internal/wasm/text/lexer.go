@@ -0,0 +1,254 @@
+package text
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of token, or tokenLParen for a list node.
+type tokenKind byte
+
+const (
+	// tokenLParen is a '(', which starts a list.
+	tokenLParen tokenKind = iota
+	// tokenRParen is a ')', which ends a list.
+	tokenRParen
+	// tokenKeyword starts with a lowercase letter, e.g. "module", "i32.add" or "offset=4".
+	tokenKeyword
+	// tokenID starts with a '$', e.g. "$main".
+	tokenID
+	// tokenString is a quoted string, e.g. "\"hello\"", which is decoded in node.text.
+	tokenString
+	// tokenNumber is any other token, e.g. "1", "-0x1p3" or "1_000".
+	tokenNumber
+)
+
+// node is either a list, e.g. "(i32.const 1)", or an atom, e.g. "1".
+type node struct {
+	kind      tokenKind
+	line, col int
+	// text is the source of an atom, except for tokenString, which is decoded.
+	text string
+	// list are the elements of a list.
+	list []*node
+}
+
+func (n *node) isList() bool {
+	return n.kind == tokenLParen
+}
+
+// head returns the keyword at the start of a list, or "" if there is none.
+func (n *node) head() string {
+	if n.isList() && len(n.list) > 0 && n.list[0].kind == tokenKeyword {
+		return n.list[0].text
+	}
+	return ""
+}
+
+// errorf returns an error prefixed by the position of the node.
+func (n *node) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%d:%d: %s", n.line, n.col, fmt.Sprintf(format, args...))
+}
+
+// lexer splits the source into nodes.
+type lexer struct {
+	source    []byte
+	pos       int
+	line, col int
+}
+
+// parse returns the top-level nodes of source.
+func parse(source []byte) ([]*node, error) {
+	l := &lexer{source: source, line: 1, col: 1}
+	var stack [][]*node
+	var current []*node
+	var lists []*node
+	for {
+		if err := l.skipSpace(); err != nil {
+			return nil, err
+		}
+		if l.pos == len(l.source) {
+			break
+		}
+		line, col := l.line, l.col
+		switch c := l.source[l.pos]; c {
+		case '(':
+			l.advance(1)
+			n := &node{kind: tokenLParen, line: line, col: col}
+			current = append(current, n)
+			lists = append(lists, n)
+			stack = append(stack, current)
+			current = nil
+		case ')':
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%d:%d: unexpected ')'", line, col)
+			}
+			l.advance(1)
+			n := lists[len(lists)-1]
+			lists = lists[:len(lists)-1]
+			n.list = current
+			current = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case '"':
+			s, err := l.string()
+			if err != nil {
+				return nil, err
+			}
+			current = append(current, &node{kind: tokenString, line: line, col: col, text: s})
+		default:
+			start := l.pos
+			for l.pos < len(l.source) && isIDChar(l.source[l.pos]) {
+				l.advance(1)
+			}
+			if l.pos == start {
+				r, _ := utf8.DecodeRune(l.source[l.pos:])
+				return nil, fmt.Errorf("%d:%d: unexpected character %q", line, col, r)
+			}
+			text := string(l.source[start:l.pos])
+			kind := tokenNumber
+			if c == '$' {
+				kind = tokenID
+			} else if c >= 'a' && c <= 'z' {
+				kind = tokenKeyword
+			}
+			current = append(current, &node{kind: kind, line: line, col: col, text: text})
+		}
+	}
+	if len(lists) > 0 {
+		n := lists[len(lists)-1]
+		return nil, n.errorf("unclosed '('")
+	}
+	return current, nil
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n; i++ {
+		if l.source[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+// skipSpace skips whitespace and comments, including nested block comments.
+func (l *lexer) skipSpace() error {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.advance(1)
+		case c == ';' && l.peek(1) == ';':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' {
+				l.advance(1)
+			}
+		case c == '(' && l.peek(1) == ';':
+			line, col := l.line, l.col
+			l.advance(2)
+			for depth := 1; depth > 0; {
+				if l.pos >= len(l.source) {
+					return fmt.Errorf("%d:%d: unclosed block comment", line, col)
+				}
+				if l.source[l.pos] == '(' && l.peek(1) == ';' {
+					depth++
+					l.advance(2)
+				} else if l.source[l.pos] == ';' && l.peek(1) == ')' {
+					depth--
+					l.advance(2)
+				} else {
+					l.advance(1)
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.source) {
+		return l.source[l.pos+offset]
+	}
+	return 0
+}
+
+// string decodes a quoted string, which can contain arbitrary bytes via escapes.
+func (l *lexer) string() (string, error) {
+	line, col := l.line, l.col
+	l.advance(1) // opening quote
+	var ret []byte
+	for {
+		if l.pos >= len(l.source) || l.source[l.pos] == '\n' {
+			return "", fmt.Errorf("%d:%d: unterminated string", line, col)
+		}
+		c := l.source[l.pos]
+		switch {
+		case c == '"':
+			l.advance(1)
+			return string(ret), nil
+		case c == '\\':
+			escLine, escCol := l.line, l.col
+			l.advance(1)
+			if l.pos >= len(l.source) {
+				return "", fmt.Errorf("%d:%d: unterminated string", line, col)
+			}
+			switch e := l.source[l.pos]; e {
+			case 't':
+				ret = append(ret, '\t')
+				l.advance(1)
+			case 'n':
+				ret = append(ret, '\n')
+				l.advance(1)
+			case 'r':
+				ret = append(ret, '\r')
+				l.advance(1)
+			case '"', '\'', '\\':
+				ret = append(ret, e)
+				l.advance(1)
+			case 'u':
+				end := l.pos + 2
+				for end < len(l.source) && l.source[end] != '}' {
+					end++
+				}
+				if l.peek(1) != '{' || end >= len(l.source) {
+					return "", fmt.Errorf("%d:%d: invalid unicode escape", escLine, escCol)
+				}
+				v, err := strconv.ParseUint(string(l.source[l.pos+2:end]), 16, 32)
+				if err != nil || !utf8.ValidRune(rune(v)) {
+					return "", fmt.Errorf("%d:%d: invalid unicode escape", escLine, escCol)
+				}
+				ret = utf8.AppendRune(ret, rune(v))
+				l.advance(end + 1 - l.pos)
+			default:
+				if l.pos+2 > len(l.source) {
+					return "", fmt.Errorf("%d:%d: invalid escape", escLine, escCol)
+				}
+				v, err := strconv.ParseUint(string(l.source[l.pos:l.pos+2]), 16, 8)
+				if err != nil {
+					return "", fmt.Errorf("%d:%d: invalid escape", escLine, escCol)
+				}
+				ret = append(ret, byte(v))
+				l.advance(2)
+			}
+		default:
+			ret = append(ret, c)
+			l.advance(1)
+		}
+	}
+}
+
+// isIDChar returns true if c can be in a keyword, id or number.
+func isIDChar(c byte) bool {
+	switch {
+	case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	}
+	switch c {
+	case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '/', ':', '<', '=', '>', '?', '@', '\\', '^', '_', '`', '|', '~':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,492 @@
+package text
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/wasm"
+)
+
+// indexSpace assigns indexes to functions, tables, memories, globals, element
+// or data segments, optionally named by an identifier, e.g. "$main".
+type indexSpace struct {
+	kind  string
+	names map[string]uint32
+	count uint32
+	// defined is true once a definition which isn't an import is added, as
+	// imports must precede them.
+	defined bool
+}
+
+func newIndexSpace(kind string) *indexSpace {
+	return &indexSpace{kind: kind, names: map[string]uint32{}}
+}
+
+// add returns the next index, naming it if id is a tokenID.
+func (s *indexSpace) add(id *node, isImport bool) (uint32, error) {
+	if isImport && s.defined {
+		return 0, id.errorf("import of %s after its definitions", s.kind)
+	} else if !isImport {
+		s.defined = true
+	}
+	idx := s.count
+	if id != nil && id.kind == tokenID {
+		if _, ok := s.names[id.text]; ok {
+			return 0, id.errorf("duplicate %s %s", s.kind, id.text)
+		}
+		s.names[id.text] = idx
+	}
+	s.count++
+	return idx, nil
+}
+
+// resolve returns the index n refers to, by identifier or number.
+func (s *indexSpace) resolve(n *node) (uint32, error) {
+	switch n.kind {
+	case tokenID:
+		if idx, ok := s.names[n.text]; ok {
+			return idx, nil
+		}
+		return 0, n.errorf("unknown %s %s", s.kind, n.text)
+	case tokenNumber:
+		v, err := parseUint(n.text, 32)
+		if err != nil {
+			return 0, n.errorf("invalid %s index %s", s.kind, n.text)
+		}
+		return uint32(v), nil
+	}
+	return 0, n.errorf("expected %s index, but was %s", s.kind, describe(n))
+}
+
+// isIndex returns true if n can be resolved by indexSpace.resolve.
+func isIndex(n *node) bool {
+	return n.kind == tokenID || n.kind == tokenNumber
+}
+
+// describe returns a short description of n for errors.
+func describe(n *node) string {
+	switch n.kind {
+	case tokenLParen:
+		if h := n.head(); h != "" {
+			return "(" + h + ")"
+		}
+		return "list"
+	case tokenString:
+		return fmt.Sprintf("%q", n.text)
+	}
+	return n.text
+}
+
+// typeUse is a reference to a function type by index and/or inline
+// parameters and results, e.g. "(type $t) (param $x i32) (result i32)".
+type typeUse struct {
+	n        *node
+	typeIdx  *node
+	params   []wasm.ValueType
+	results  []wasm.ValueType
+	inline   bool
+	paramIDs []*node
+}
+
+// field is a module field whose compilation is deferred until all indexes
+// are known.
+type field struct {
+	n *node
+	// rest are the elements of n after any identifier, inline exports and
+	// inline import.
+	rest []*node
+	idx  uint32
+	id   *node
+	// segment is the inline element or data segment of a table or memory,
+	// e.g. "(memory (data "hello"))".
+	segment *field
+	// encoded is the segment, if inline, once its table or memory is encoded.
+	encoded []byte
+}
+
+// export is an export field, or an inline export of a definition, e.g.
+// "(func (export "f"))", whose index is known when declared.
+type export struct {
+	n      *node
+	inline bool
+	kind   wasm.ExternType
+	idx    uint32
+}
+
+type importField struct {
+	field
+	module, name string
+	kind         wasm.ExternType
+}
+
+// moduleBuilder compiles module fields into the binary format.
+type moduleBuilder struct {
+	name *node
+
+	types     [][]byte // encoded function types
+	typeSpace *indexSpace
+
+	funcs, tables, memories, globals, elems, datas *indexSpace
+
+	imports      []*importField
+	funcFields   []*field
+	tableFields  []*field
+	memoryFields []*field
+	globalFields []*field
+	elemFields   []*field
+	dataFields   []*field
+	exports      []*export
+	start        *node
+
+	usesDataCount bool
+
+	funcNames  map[uint32]string
+	localNames map[uint32]map[uint32]string
+}
+
+// Compile returns the WebAssembly binary format of source, a module in the
+// WebAssembly text format, e.g. "(module (func (export "f")))".
+//
+// The module is not validated, except for what is necessary to encode it,
+// such as resolving identifiers.
+func Compile(source []byte) ([]byte, error) {
+	nodes, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &moduleBuilder{
+		typeSpace:  newIndexSpace("type"),
+		funcs:      newIndexSpace("func"),
+		tables:     newIndexSpace("table"),
+		memories:   newIndexSpace("memory"),
+		globals:    newIndexSpace("global"),
+		elems:      newIndexSpace("elem"),
+		datas:      newIndexSpace("data"),
+		funcNames:  map[uint32]string{},
+		localNames: map[uint32]map[uint32]string{},
+	}
+
+	fields := nodes
+	if len(nodes) == 1 && nodes[0].head() == "module" {
+		fields = nodes[0].list[1:]
+		if len(fields) > 0 && fields[0].kind == tokenID {
+			b.name, fields = fields[0], fields[1:]
+		}
+		if len(fields) > 0 && fields[0].kind == tokenKeyword {
+			return nil, fields[0].errorf("unsupported module format %s", fields[0].text)
+		}
+	}
+
+	for _, f := range fields {
+		if err = b.declare(f); err != nil {
+			return nil, err
+		}
+	}
+	return b.encode()
+}
+
+// IsText returns true if source starts with a '(', ignoring whitespace and
+// comments, which is never true for the binary format.
+func IsText(source []byte) bool {
+	l := &lexer{source: source, line: 1, col: 1}
+	if err := l.skipSpace(); err != nil {
+		return false
+	}
+	return l.pos < len(source) && source[l.pos] == '('
+}
+
+// declare assigns the index of a module field, deferring its compilation.
+func (b *moduleBuilder) declare(n *node) error {
+	switch n.head() {
+	case "type":
+		return b.declareType(n)
+	case "import":
+		return b.declareImport(n)
+	case "func":
+		return b.declareDefinition(n, wasm.ExternTypeFunc, b.funcs, &b.funcFields)
+	case "table":
+		return b.declareDefinition(n, wasm.ExternTypeTable, b.tables, &b.tableFields)
+	case "memory":
+		return b.declareDefinition(n, wasm.ExternTypeMemory, b.memories, &b.memoryFields)
+	case "global":
+		return b.declareDefinition(n, wasm.ExternTypeGlobal, b.globals, &b.globalFields)
+	case "export":
+		b.exports = append(b.exports, &export{n: n})
+		return nil
+	case "start":
+		if b.start != nil {
+			return n.errorf("multiple start functions")
+		}
+		b.start = n
+		return nil
+	case "elem":
+		return b.declareSegment(n, b.elems, &b.elemFields)
+	case "data":
+		return b.declareSegment(n, b.datas, &b.dataFields)
+	}
+	return n.errorf("unknown module field %s", describe(n))
+}
+
+func (b *moduleBuilder) declareType(n *node) error {
+	rest := n.list[1:]
+	var id *node
+	if len(rest) > 0 && rest[0].kind == tokenID {
+		id, rest = rest[0], rest[1:]
+	}
+	if len(rest) != 1 || rest[0].head() != "func" {
+		return n.errorf("expected (func) in type definition")
+	}
+	tu, consumed, err := b.parseTypeUse(rest[0].list[1:])
+	if err != nil {
+		return err
+	} else if consumed != len(rest[0].list)-1 || tu.typeIdx != nil {
+		return rest[0].errorf("invalid function type")
+	}
+	if _, err = b.typeSpace.add(id, false); err != nil {
+		return err
+	}
+	b.types = append(b.types, encodeFuncType(tu.params, tu.results))
+	return nil
+}
+
+func (b *moduleBuilder) declareImport(n *node) error {
+	if len(n.list) != 4 || n.list[1].kind != tokenString || n.list[2].kind != tokenString || !n.list[3].isList() {
+		return n.errorf("expected (import \"module\" \"name\" (desc))")
+	}
+	desc := n.list[3]
+	var kind wasm.ExternType
+	var space *indexSpace
+	switch desc.head() {
+	case "func":
+		kind, space = wasm.ExternTypeFunc, b.funcs
+	case "table":
+		kind, space = wasm.ExternTypeTable, b.tables
+	case "memory":
+		kind, space = wasm.ExternTypeMemory, b.memories
+	case "global":
+		kind, space = wasm.ExternTypeGlobal, b.globals
+	default:
+		return desc.errorf("unknown import kind %s", describe(desc))
+	}
+	rest := desc.list[1:]
+	var id *node
+	if len(rest) > 0 && rest[0].kind == tokenID {
+		id, rest = rest[0], rest[1:]
+	}
+	return b.addImport(desc, id, rest, n.list[1].text, n.list[2].text, kind, space)
+}
+
+func (b *moduleBuilder) addImport(n, id *node, rest []*node, module, name string, kind wasm.ExternType, space *indexSpace) error {
+	if id == nil {
+		id = n
+	}
+	idx, err := space.add(id, true)
+	if err != nil {
+		return err
+	}
+	if kind == wasm.ExternTypeFunc && id.kind == tokenID {
+		b.funcNames[idx] = id.text[1:]
+	}
+	b.imports = append(b.imports, &importField{
+		field:  field{n: n, rest: rest, idx: idx, id: id},
+		module: module, name: name, kind: kind,
+	})
+	return nil
+}
+
+// declareDefinition declares a function, table, memory or global, which can
+// have inline exports, or be an inline import.
+func (b *moduleBuilder) declareDefinition(n *node, kind wasm.ExternType, space *indexSpace, fields *[]*field) error {
+	rest := n.list[1:]
+	var id *node
+	if len(rest) > 0 && rest[0].kind == tokenID {
+		id, rest = rest[0], rest[1:]
+	}
+
+	var exports []*node
+	for len(rest) > 0 && rest[0].head() == "export" {
+		exports, rest = append(exports, rest[0]), rest[1:]
+	}
+
+	var idx uint32
+	var err error
+	if len(rest) > 0 && rest[0].head() == "import" {
+		imp := rest[0]
+		if len(imp.list) != 3 || imp.list[1].kind != tokenString || imp.list[2].kind != tokenString {
+			return imp.errorf("expected (import \"module\" \"name\")")
+		}
+		if err = b.addImport(n, id, rest[1:], imp.list[1].text, imp.list[2].text, kind, space); err != nil {
+			return err
+		}
+		idx = b.imports[len(b.imports)-1].idx
+	} else {
+		idOrNode := id
+		if idOrNode == nil {
+			idOrNode = n
+		}
+		if idx, err = space.add(idOrNode, false); err != nil {
+			return err
+		}
+		if kind == wasm.ExternTypeFunc && id != nil {
+			b.funcNames[idx] = id.text[1:]
+		}
+		f := &field{n: n, rest: rest, idx: idx, id: id}
+		*fields = append(*fields, f)
+
+		// Inline segments are indexed in order with the other segments.
+		if last := len(rest) - 1; last >= 0 && kind == wasm.ExternTypeTable && rest[last].head() == "elem" {
+			f.segment = &field{n: rest[last]}
+			if f.segment.idx, err = b.elems.add(rest[last], false); err != nil {
+				return err
+			}
+			b.elemFields = append(b.elemFields, f.segment)
+		} else if last >= 0 && kind == wasm.ExternTypeMemory && rest[last].head() == "data" {
+			f.segment = &field{n: rest[last]}
+			if f.segment.idx, err = b.datas.add(rest[last], false); err != nil {
+				return err
+			}
+			b.dataFields = append(b.dataFields, f.segment)
+		}
+	}
+
+	for _, e := range exports {
+		if len(e.list) != 2 || e.list[1].kind != tokenString {
+			return e.errorf("expected (export \"name\")")
+		}
+		b.exports = append(b.exports, &export{n: e, inline: true, kind: kind, idx: idx})
+	}
+	return nil
+}
+
+func (b *moduleBuilder) declareSegment(n *node, space *indexSpace, fields *[]*field) error {
+	rest := n.list[1:]
+	var id *node
+	if len(rest) > 0 && rest[0].kind == tokenID {
+		id, rest = rest[0], rest[1:]
+	}
+	idOrNode := id
+	if idOrNode == nil {
+		idOrNode = n
+	}
+	idx, err := space.add(idOrNode, false)
+	if err != nil {
+		return err
+	}
+	*fields = append(*fields, &field{n: n, rest: rest, idx: idx, id: id})
+	return nil
+}
+
+// parseTypeUse parses an optional "(type x)" followed by any "(param ...)"
+// and "(result ...)", returning how many nodes were consumed.
+func (b *moduleBuilder) parseTypeUse(nodes []*node) (tu typeUse, consumed int, err error) {
+	if len(nodes) > 0 {
+		tu.n = nodes[0]
+	}
+	if len(nodes) > 0 && nodes[0].head() == "type" {
+		if len(nodes[0].list) != 2 || !isIndex(nodes[0].list[1]) {
+			return tu, 0, nodes[0].errorf("expected (type index)")
+		}
+		tu.typeIdx = nodes[0].list[1]
+		consumed++
+	}
+	for ; consumed < len(nodes) && nodes[consumed].head() == "param"; consumed++ {
+		p := nodes[consumed].list[1:]
+		tu.inline = true
+		if len(p) > 0 && p[0].kind == tokenID {
+			if len(p) != 2 {
+				return tu, 0, nodes[consumed].errorf("expected one type for named param")
+			}
+			vt, err := valueType(p[1])
+			if err != nil {
+				return tu, 0, err
+			}
+			tu.params, tu.paramIDs = append(tu.params, vt), append(tu.paramIDs, p[0])
+			continue
+		}
+		for _, t := range p {
+			vt, err := valueType(t)
+			if err != nil {
+				return tu, 0, err
+			}
+			tu.params, tu.paramIDs = append(tu.params, vt), append(tu.paramIDs, nil)
+		}
+	}
+	for ; consumed < len(nodes) && nodes[consumed].head() == "result"; consumed++ {
+		tu.inline = true
+		for _, t := range nodes[consumed].list[1:] {
+			vt, err := valueType(t)
+			if err != nil {
+				return tu, 0, err
+			}
+			tu.results = append(tu.results, vt)
+		}
+	}
+	return
+}
+
+// typeIndex returns the index of the function type of tu, adding it to the
+// type section if it is only inline.
+func (b *moduleBuilder) typeIndex(tu *typeUse) (uint32, error) {
+	encoded := encodeFuncType(tu.params, tu.results)
+	if tu.typeIdx != nil {
+		idx, err := b.typeSpace.resolve(tu.typeIdx)
+		if err != nil {
+			return 0, err
+		} else if idx >= uint32(len(b.types)) {
+			return 0, tu.typeIdx.errorf("unknown type %s", tu.typeIdx.text)
+		} else if tu.inline && string(b.types[idx]) != string(encoded) {
+			return 0, tu.typeIdx.errorf("inline function type doesn't match type %s", tu.typeIdx.text)
+		}
+		return idx, nil
+	}
+	for i, t := range b.types {
+		if string(t) == string(encoded) {
+			return uint32(i), nil
+		}
+	}
+	b.types = append(b.types, encoded)
+	return uint32(len(b.types) - 1), nil
+}
+
+// paramCount returns the count of parameters of the function type of tu.
+func (b *moduleBuilder) paramCount(typeIdx uint32) uint32 {
+	count, _, _ := leb128.LoadUint32(b.types[typeIdx][1:])
+	return count
+}
+
+// valueType returns the value type named by n, e.g. "i32".
+func valueType(n *node) (wasm.ValueType, error) {
+	if n.kind == tokenKeyword {
+		switch n.text {
+		case "i32":
+			return wasm.ValueTypeI32, nil
+		case "i64":
+			return wasm.ValueTypeI64, nil
+		case "f32":
+			return wasm.ValueTypeF32, nil
+		case "f64":
+			return wasm.ValueTypeF64, nil
+		case "v128":
+			return wasm.ValueTypeV128, nil
+		case "funcref":
+			return wasm.ValueTypeFuncref, nil
+		case "externref":
+			return wasm.ValueTypeExternref, nil
+		}
+	}
+	return 0, n.errorf("unknown value type %s", describe(n))
+}
+
+// refType returns the reference type named by n, e.g. "funcref".
+func refType(n *node) (wasm.RefType, error) {
+	if n.kind == tokenKeyword {
+		switch n.text {
+		case "funcref", "func":
+			return wasm.RefTypeFuncref, nil
+		case "externref", "extern":
+			return wasm.RefTypeExternref, nil
+		}
+	}
+	return 0, n.errorf("unknown reference type %s", describe(n))
+}
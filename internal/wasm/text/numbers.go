@@ -0,0 +1,91 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errInvalidNumber = errors.New("invalid number")
+
+// parseUint parses an unsigned integer, in decimal or hexadecimal with a "0x"
+// prefix, optionally with '_' between digits.
+func parseUint(s string, bitSize int) (uint64, error) {
+	base := 10
+	if strings.HasPrefix(s, "0x") {
+		base, s = 16, s[2:]
+	}
+	if s == "" || s[0] == '_' || s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return 0, errInvalidNumber
+	}
+	v, err := strconv.ParseUint(strings.ReplaceAll(s, "_", ""), base, bitSize)
+	if err != nil {
+		return 0, errInvalidNumber
+	}
+	return v, nil
+}
+
+// parseInt parses a possibly signed integer whose bits fit in bitSize, e.g.
+// both -1 and 0xffffffff are valid for 32 bits.
+func parseInt(s string, bitSize int) (uint64, error) {
+	neg := false
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg, s = s[0] == '-', s[1:]
+	}
+	if !neg {
+		return parseUint(s, bitSize)
+	}
+	v, err := parseUint(s, bitSize)
+	if err != nil || v > 1<<(bitSize-1) {
+		return 0, errInvalidNumber
+	}
+	return -v & (math.MaxUint64 >> (64 - bitSize)), nil
+}
+
+// parseFloat returns the bits of a float of bitSize 32 or 64, including
+// "inf", "nan" and "nan:0x..." with an explicit payload.
+func parseFloat(s string, bitSize int) (uint64, error) {
+	var sign uint64
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		if s[0] == '-' {
+			sign = 1 << (bitSize - 1)
+		}
+		s = s[1:]
+	}
+
+	mantissaBits := 52
+	if bitSize == 32 {
+		mantissaBits = 23
+	}
+	exponentMask := (uint64(1)<<(bitSize-1) - 1) &^ (uint64(1)<<mantissaBits - 1)
+
+	switch {
+	case s == "inf":
+		return sign | exponentMask, nil
+	case s == "nan":
+		return sign | exponentMask | 1<<(mantissaBits-1), nil
+	case strings.HasPrefix(s, "nan:"):
+		payload, err := parseUint(s[4:], 64)
+		if err != nil || payload == 0 || payload >= 1<<mantissaBits || !strings.HasPrefix(s[4:], "0x") {
+			return 0, errInvalidNumber
+		}
+		return sign | exponentMask | payload, nil
+	}
+
+	if s == "" || s[0] < '0' || s[0] > '9' || s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return 0, errInvalidNumber
+	}
+	s = strings.ReplaceAll(s, "_", "")
+	if strings.HasPrefix(s, "0x") && !strings.ContainsAny(s, "pP") {
+		s += "p0" // Go requires an exponent in hexadecimal floats.
+	}
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err != nil {
+		return 0, errInvalidNumber
+	}
+	if bitSize == 32 {
+		return sign | uint64(math.Float32bits(float32(f))), nil
+	}
+	return sign | math.Float64bits(f), nil
+}
@@ -0,0 +1,247 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func decode(t *testing.T, source string) *wasm.Module {
+	bin, err := Compile([]byte(source))
+	require.NoError(t, err)
+	m, err := binary.DecodeModule(bin, api.CoreFeaturesV2, wasm.MemoryLimitPages, false, false, false)
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(api.CoreFeaturesV2))
+	return m
+}
+
+func TestCompile(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		m := decode(t, "(module)")
+		require.Equal(t, 0, len(m.FunctionSection))
+	})
+
+	t.Run("func", func(t *testing.T) {
+		m := decode(t, `(module $math
+  ;; adds two numbers
+  (func $add (export "add") (param $x i32) (param $y i32) (result i32)
+    (local $tmp i32)
+    (local.set $tmp (i32.add (local.get $x) (local.get $y)))
+    local.get $tmp)
+  (func (export "call_add") (result i32)
+    i32.const 1
+    i32.const 2
+    call $add))`)
+
+		require.Equal(t, 2, len(m.TypeSection))
+		require.Equal(t, "i32i32_i32", m.TypeSection[0].String())
+		require.Equal(t, "v_i32", m.TypeSection[1].String())
+		require.Equal(t, []wasm.Index{0, 1}, m.FunctionSection)
+		require.Equal(t, []wasm.ValueType{wasm.ValueTypeI32}, m.CodeSection[0].LocalTypes)
+		require.Equal(t, []byte{
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeI32Add, wasm.OpcodeLocalSet, 2,
+			wasm.OpcodeLocalGet, 2, wasm.OpcodeEnd,
+		}, m.CodeSection[0].Body)
+		require.Equal(t, []byte{
+			wasm.OpcodeI32Const, 1, wasm.OpcodeI32Const, 2, wasm.OpcodeCall, 0, wasm.OpcodeEnd,
+		}, m.CodeSection[1].Body)
+		require.Equal(t, []wasm.Export{
+			{Name: "add", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "call_add", Type: wasm.ExternTypeFunc, Index: 1},
+		}, m.ExportSection)
+		require.Equal(t, &wasm.NameSection{
+			ModuleName:    "math",
+			FunctionNames: wasm.NameMap{{Index: 0, Name: "add"}},
+			LocalNames: wasm.IndirectNameMap{{Index: 0, NameMap: wasm.NameMap{
+				{Index: 0, Name: "x"}, {Index: 1, Name: "y"}, {Index: 2, Name: "tmp"},
+			}}},
+		}, m.NameSection)
+	})
+
+	t.Run("control", func(t *testing.T) {
+		m := decode(t, `(module
+  (func (param i32) (result i32)
+    (block $outer
+      (block $inner
+        (br_table $inner $outer (local.get 0)))
+      (return (i32.const 1)))
+    (if (result i32) (local.get 0)
+      (then (i32.const 2))
+      (else (i32.const 3)))
+    loop $l
+      i32.const 0
+      br_if $l
+    end
+    drop
+    i32.const 4))`)
+
+		require.Equal(t, []byte{
+			wasm.OpcodeBlock, 0x40,
+			wasm.OpcodeBlock, 0x40,
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeBrTable, 1, 0, 1,
+			wasm.OpcodeEnd,
+			wasm.OpcodeI32Const, 1, wasm.OpcodeReturn,
+			wasm.OpcodeEnd,
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeIf, wasm.ValueTypeI32,
+			wasm.OpcodeI32Const, 2,
+			wasm.OpcodeElse,
+			wasm.OpcodeI32Const, 3,
+			wasm.OpcodeEnd,
+			wasm.OpcodeLoop, 0x40,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeBrIf, 0,
+			wasm.OpcodeEnd,
+			wasm.OpcodeDrop,
+			wasm.OpcodeI32Const, 4,
+			wasm.OpcodeEnd,
+		}, m.CodeSection[0].Body)
+	})
+
+	t.Run("memory and data", func(t *testing.T) {
+		m := decode(t, `(module
+  (memory (export "memory") 1 2)
+  (data (i32.const 8) "hi\n" "\00")
+  (func (result i32) (i32.load8_u offset=1 (i32.const 8))))`)
+
+		require.Equal(t, &wasm.Memory{Min: 1, Cap: 1, Max: 2, IsMaxEncoded: true}, m.MemorySection)
+		require.Equal(t, []wasm.DataSegment{
+			{
+				OffsetExpression: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{8}},
+				Init:             []byte("hi\n\x00"),
+			},
+		}, m.DataSection)
+		require.Equal(t, []byte{
+			wasm.OpcodeI32Const, 8, wasm.OpcodeI32Load8U, 0, 1, wasm.OpcodeEnd,
+		}, m.CodeSection[0].Body)
+	})
+
+	t.Run("table and elem", func(t *testing.T) {
+		m := decode(t, `(module
+  (type $v (func))
+  (table 2 funcref)
+  (elem (i32.const 0) $a $b)
+  (func $a)
+  (func $b (call_indirect (type $v) (i32.const 0))))`)
+
+		require.Equal(t, []wasm.Table{{Min: 2, Type: wasm.RefTypeFuncref}}, m.TableSection)
+		require.Equal(t, []wasm.ElementSegment{
+			{
+				OffsetExpr: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+				Init:       []wasm.Index{0, 1},
+				Type:       wasm.RefTypeFuncref,
+				Mode:       wasm.ElementModeActive,
+			},
+		}, m.ElementSection)
+		require.Equal(t, []byte{
+			wasm.OpcodeI32Const, 0, wasm.OpcodeCallIndirect, 0, 0, wasm.OpcodeEnd,
+		}, m.CodeSection[1].Body)
+	})
+
+	t.Run("inline segments", func(t *testing.T) {
+		m := decode(t, `(module
+  (table funcref (elem $f))
+  (elem $e func $f)
+  (memory (data "hi"))
+  (func $f (elem.drop $e)))`)
+
+		require.Equal(t, 2, len(m.ElementSection))
+		require.Equal(t, wasm.ElementModeActive, m.ElementSection[0].Mode)
+		require.Equal(t, wasm.ElementModePassive, m.ElementSection[1].Mode)
+		require.Equal(t, &wasm.Memory{Min: 1, Cap: 1, Max: 1, IsMaxEncoded: true}, m.MemorySection)
+		require.Equal(t, []byte("hi"), m.DataSection[0].Init)
+		require.Equal(t, []byte{
+			wasm.OpcodeMiscPrefix, wasm.OpcodeMiscElemDrop, 1, wasm.OpcodeEnd,
+		}, m.CodeSection[0].Body)
+	})
+
+	t.Run("imports and globals", func(t *testing.T) {
+		m := decode(t, `(module
+  (import "env" "log" (func $log (param i64)))
+  (global $g (import "env" "g") i32)
+  (global $counter (mut i64) (i64.const -1))
+  (func (export "inc")
+    (global.set $counter (i64.add (global.get $counter) (i64.const 1)))
+    (call $log (global.get $counter))))`)
+
+		require.Equal(t, []wasm.Import{
+			{Type: wasm.ExternTypeFunc, Module: "env", Name: "log", DescFunc: 0},
+			{Type: wasm.ExternTypeGlobal, Module: "env", Name: "g", DescGlobal: wasm.GlobalType{ValType: wasm.ValueTypeI32}},
+		}, m.ImportSection)
+		require.Equal(t, []wasm.Global{
+			{
+				Type: wasm.GlobalType{ValType: wasm.ValueTypeI64, Mutable: true},
+				Init: wasm.ConstantExpression{Opcode: wasm.OpcodeI64Const, Data: []byte{0x7f}},
+			},
+		}, m.GlobalSection)
+		require.Equal(t, []wasm.Export{{Name: "inc", Type: wasm.ExternTypeFunc, Index: 1}}, m.ExportSection)
+		require.Equal(t, []byte{
+			wasm.OpcodeGlobalGet, 1, wasm.OpcodeI64Const, 1, wasm.OpcodeI64Add, wasm.OpcodeGlobalSet, 1,
+			wasm.OpcodeGlobalGet, 1, wasm.OpcodeCall, 0,
+			wasm.OpcodeEnd,
+		}, m.CodeSection[0].Body)
+	})
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name, source, expectedErr string
+	}{
+		{
+			name:        "unbalanced",
+			source:      "(module",
+			expectedErr: "1:1: unclosed '('",
+		},
+		{
+			name:        "unknown field",
+			source:      "(module (fun))",
+			expectedErr: "1:9: unknown module field (fun)",
+		},
+		{
+			name:        "unknown instruction",
+			source:      "(module (func i32.foo))",
+			expectedErr: "1:15: unknown instruction i32.foo",
+		},
+		{
+			name:        "unknown local",
+			source:      "(module (func local.get $x))",
+			expectedErr: "1:25: unknown local $x",
+		},
+		{
+			name:        "unknown func",
+			source:      "(module (func call $f))",
+			expectedErr: "1:20: unknown func $f",
+		},
+		{
+			name:        "duplicate func",
+			source:      "(module (func $f) (func $f))",
+			expectedErr: "1:25: duplicate func $f",
+		},
+		{
+			name:        "import after definition",
+			source:      `(module (func) (import "a" "b" (func $f)))`,
+			expectedErr: "1:38: import of func after its definitions",
+		},
+		{
+			name:        "i32 overflow",
+			source:      "(module (func i32.const 0x1_0000_0000 drop))",
+			expectedErr: "1:25: invalid i32 constant 0x1_0000_0000",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile([]byte(tc.source))
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestIsText(t *testing.T) {
+	require.True(t, IsText([]byte("(module)")))
+	require.True(t, IsText([]byte(";; comment\n  (; block ;) (module)")))
+	require.False(t, IsText([]byte("\x00asm\x01\x00\x00\x00")))
+	require.False(t, IsText(nil))
+}
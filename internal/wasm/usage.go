@@ -0,0 +1,103 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// UsageLimitKey is a context.Context Value key. Its associated value should
+// be an uint64 which is the maximum bytes of linear memory, tables and GC
+// objects a module instance can hold.
+type UsageLimitKey struct{}
+
+// ErrUsageLimitExceeded is returned when a module instance cannot be
+// instantiated within its UsageLimit.
+var ErrUsageLimitExceeded = errors.New("memory usage limit exceeded")
+
+// referenceSize is the bytes held by each element of TableInstance.References.
+const referenceSize = uint64(unsafe.Sizeof(Reference(0)))
+
+// UsageLimit accounts the bytes of linear memory and tables defined by a
+// module instance, as well as the objects it allocates with
+// api.CoreFeatureGC instructions, which can't exceed Limit. Imported memories
+// and tables are accounted by the module which defines them.
+type UsageLimit struct {
+	// Limit is the maximum bytes, or zero if unlimited.
+	Limit uint64
+	used  uint64
+	// heap is the part of used which is objects allocated by
+	// api.CoreFeatureGC instructions.
+	heap uint64
+}
+
+// Used returns the bytes currently accounted.
+func (u *UsageLimit) Used() uint64 {
+	return atomic.LoadUint64(&u.used)
+}
+
+// reserve accounts n more bytes, returning false without accounting them if
+// that would exceed the limit. This is safe to call on a nil receiver, which
+// means the module isn't accounted.
+func (u *UsageLimit) reserve(n uint64) bool {
+	if u == nil {
+		return true
+	}
+	for {
+		used := atomic.LoadUint64(&u.used)
+		next := used + n
+		if next < used || (u.Limit != 0 && next > u.Limit) {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&u.used, used, next) {
+			return true
+		}
+	}
+}
+
+// release un-accounts n bytes previously reserved. This is safe to call on a
+// nil receiver.
+func (u *UsageLimit) release(n uint64) {
+	if u != nil && n != 0 {
+		atomic.AddUint64(&u.used, ^(n - 1))
+	}
+}
+
+// Heap returns the bytes of the objects currently accounted with
+// ReserveHeap, which are included in Used.
+func (u *UsageLimit) Heap() uint64 {
+	return atomic.LoadUint64(&u.heap)
+}
+
+// ReserveHeap accounts an object of n bytes allocated by an
+// api.CoreFeatureGC instruction, returning false without accounting it if
+// that would exceed the limit. This is safe to call on a nil receiver.
+func (u *UsageLimit) ReserveHeap(n uint64) bool {
+	if u == nil {
+		return true
+	}
+	if !u.reserve(n) {
+		return false
+	}
+	atomic.AddUint64(&u.heap, n)
+	return true
+}
+
+// ReleaseHeap un-accounts n bytes previously reserved with ReserveHeap. This
+// is safe to call on a nil receiver.
+func (u *UsageLimit) ReleaseHeap(n uint64) {
+	if u != nil && n != 0 {
+		atomic.AddUint64(&u.heap, ^(n - 1))
+		u.release(n)
+	}
+}
+
+// usageLimit returns the limit set with UsageLimitKey, if any.
+func usageLimit(ctx context.Context) (uint64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	limit, ok := ctx.Value(UsageLimitKey{}).(uint64)
+	return limit, ok
+}
@@ -40,12 +40,12 @@ func FuncName(moduleName, funcName string, funcIdx uint32) string {
 	return ret.String()
 }
 
-// signature returns a formatted signature similar to how it is defined in Go.
+// Signature returns a formatted signature similar to how it is defined in Go.
 //
 // * paramTypes should be from wasm.FunctionType
 // * resultTypes should be from wasm.FunctionType
 // TODO: add paramNames
-func signature(funcName string, paramTypes []api.ValueType, resultTypes []api.ValueType) string {
+func Signature(funcName string, paramTypes []api.ValueType, resultTypes []api.ValueType) string {
 	var ret strings.Builder
 	ret.WriteString(funcName)
 
@@ -112,6 +112,10 @@ type stackTrace struct {
 	frames []string
 }
 
+// StackTracePrefix is the prefix coming before the wasm stack trace included
+// in errors returned by ErrorBuilder.FromRecovered.
+const StackTracePrefix = "wasm stack trace:"
+
 // GoRuntimeErrorTracePrefix is the prefix coming before the Go runtime stack trace included in the face of runtime.Error.
 // This is exported for testing purpose.
 const GoRuntimeErrorTracePrefix = "Go runtime stack trace:"
@@ -129,27 +133,33 @@ func (s *stackTrace) FromRecovered(recovered interface{}) error {
 
 	// If the error was internal, don't mention it was recovered.
 	if wasmErr, ok := recovered.(*wasmruntime.Error); ok {
-		return fmt.Errorf("wasm error: %w\nwasm stack trace:\n\t%s", wasmErr, stack)
+		return fmt.Errorf("wasm error: %w\n"+StackTracePrefix+"\n\t%s", wasmErr, stack)
+	}
+
+	// If a host function panicked, it was converted to a trap which keeps the Go stack trace of the panic.
+	if hostErr, ok := recovered.(*sys.HostFunctionError); ok {
+		return fmt.Errorf("%w\n"+StackTracePrefix+"\n\t%s\n\n%s\n%s",
+			hostErr, stack, GoRuntimeErrorTracePrefix, hostErr.Stack)
 	}
 
 	// If we have a runtime.Error, something severe happened which should include the stack trace. This could be
 	// a nil pointer from wazero or a user-defined function from HostModuleBuilder.
 	if runtimeErr, ok := recovered.(runtime.Error); ok {
-		return fmt.Errorf("%w (recovered by wazero)\nwasm stack trace:\n\t%s\n\n%s\n%s",
+		return fmt.Errorf("%w (recovered by wazero)\n"+StackTracePrefix+"\n\t%s\n\n%s\n%s",
 			runtimeErr, stack, GoRuntimeErrorTracePrefix, debug.Stack())
 	}
 
 	// At this point we expect the error was from a function defined by HostModuleBuilder that intentionally called panic.
 	if runtimeErr, ok := recovered.(error); ok { // e.g. panic(errors.New("whoops"))
-		return fmt.Errorf("%w (recovered by wazero)\nwasm stack trace:\n\t%s", runtimeErr, stack)
+		return fmt.Errorf("%w (recovered by wazero)\n"+StackTracePrefix+"\n\t%s", runtimeErr, stack)
 	} else { // e.g. panic("whoops")
-		return fmt.Errorf("%v (recovered by wazero)\nwasm stack trace:\n\t%s", recovered, stack)
+		return fmt.Errorf("%v (recovered by wazero)\n"+StackTracePrefix+"\n\t%s", recovered, stack)
 	}
 }
 
 // AddFrame implements ErrorBuilder.AddFrame
 func (s *stackTrace) AddFrame(funcName string, paramTypes, resultTypes []api.ValueType, sources []string) {
-	sig := signature(funcName, paramTypes, resultTypes)
+	sig := Signature(funcName, paramTypes, resultTypes)
 	s.frames = append(s.frames, sig)
 	for _, source := range sources {
 		s.frames = append(s.frames, "\t"+source)
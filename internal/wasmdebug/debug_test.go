@@ -8,6 +8,7 @@ import (
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	"github.com/tetratelabs/wazero/internal/wasmruntime"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 func TestFuncName(t *testing.T) {
@@ -58,7 +59,7 @@ func TestAddSignature(t *testing.T) {
 	for _, tt := range tests {
 		tc := tt
 		t.Run(tc.name, func(t *testing.T) {
-			withSignature := signature("x.y", tc.paramTypes, tc.resultTypes)
+			withSignature := Signature("x.y", tc.paramTypes, tc.resultTypes)
 			require.Equal(t, tc.expected, withSignature)
 		})
 	}
@@ -147,6 +148,24 @@ wasm stack trace:
 	require.Contains(t, errStr, "wazero/internal/wasmdebug/debug_test.go")
 }
 
+func TestErrorBuilderHostFunctionError(t *testing.T) {
+	hostErr := &sys.HostFunctionError{Function: "env.abort", Recovered: "whoops", Stack: []byte("goroutine 1 [running]:")}
+
+	builder := NewErrorBuilder()
+	builder.AddFrame("env.abort", nil, nil, nil)
+	builder.AddFrame("x.y", nil, nil, nil)
+	withStackTrace := builder.FromRecovered(hostErr)
+
+	require.Equal(t, hostErr, errors.Unwrap(withStackTrace))
+	require.EqualError(t, withStackTrace, `host function env.abort panicked: whoops
+wasm stack trace:
+	env.abort()
+	x.y()
+
+Go runtime stack trace:
+goroutine 1 [running]:`)
+}
+
 // compile-time check to ensure testRuntimeErr implements runtime.Error.
 var _ runtime.Error = testRuntimeErr("")
 
@@ -27,6 +27,23 @@ var (
 	ErrRuntimeInvalidTableAccess = New("invalid table access")
 	// ErrRuntimeIndirectCallTypeMismatch indicates that the type check failed during call_indirect.
 	ErrRuntimeIndirectCallTypeMismatch = New("indirect call type mismatch")
+	// ErrRuntimeExecutionLimitExceeded indicates that the function call executed more loop
+	// iterations than the limit configured by the embedder.
+	ErrRuntimeExecutionLimitExceeded = New("execution limit exceeded")
+	// ErrRuntimeNullReference indicates that the program tried to access a struct or array
+	// through a null reference, or ref.as_non_null was executed on a null reference.
+	ErrRuntimeNullReference = New("null reference")
+	// ErrRuntimeCastFailure indicates that ref.cast was executed on a reference of another type.
+	ErrRuntimeCastFailure = New("cast failure")
+	// ErrRuntimeOutOfBoundsArrayAccess indicates that the program tried to access an array
+	// element out of bounds.
+	ErrRuntimeOutOfBoundsArrayAccess = New("out of bounds array access")
+	// ErrRuntimeArrayTooLarge indicates that the program tried to create an array with more
+	// elements than the runtime supports.
+	ErrRuntimeArrayTooLarge = New("array too large")
+	// ErrRuntimeHeapLimitExceeded indicates that the program tried to allocate a struct or
+	// array beyond the usage limit of its module instance.
+	ErrRuntimeHeapLimitExceeded = New("heap usage limit exceeded")
 )
 
 // Error is returned by a wasm.Engine during the execution of Wasm functions, and they indicate that the Wasm runtime
@@ -190,7 +190,9 @@ type Compiler struct {
 
 	ensureTermination bool
 	// Pre-allocated bytes.Reader to be used in various places.
-	br             *bytes.Reader
+	br *bytes.Reader
+	// dec decodes consecutive immediates of the current instruction without re-slicing body.
+	dec            leb128.Decoder
 	funcTypeToSigs funcTypeToIRSignatures
 
 	next int
@@ -299,6 +301,13 @@ func NewCompiler(enabledFeatures api.CoreFeatures, callFrameStackSizeInUint64 in
 	return c, nil
 }
 
+// SetNext sets the index of the function in the code section which is lowered by the next call to Next.
+//
+// This allows multiple Compilers for the same module to lower disjoint sets of functions concurrently.
+func (c *Compiler) SetNext(funcIndex wasm.Index) {
+	c.next = int(funcIndex)
+}
+
 // Next returns the next CompilationResult for this Compiler.
 func (c *Compiler) Next() (*CompilationResult, error) {
 	funcIndex := c.next
@@ -410,7 +419,7 @@ operatorSwitch:
 		// Nop is noop!
 	case wasm.OpcodeBlock:
 		c.br.Reset(c.body[c.pc+1:])
-		bt, num, err := wasm.DecodeBlockType(c.types, c.br, c.enabledFeatures)
+		bt, num, err := wasm.DecodeBlockType(c.types, c.module.CompositeTypes, c.br, c.enabledFeatures)
 		if err != nil {
 			return fmt.Errorf("reading block type for block instruction: %w", err)
 		}
@@ -434,7 +443,7 @@ operatorSwitch:
 
 	case wasm.OpcodeLoop:
 		c.br.Reset(c.body[c.pc+1:])
-		bt, num, err := wasm.DecodeBlockType(c.types, c.br, c.enabledFeatures)
+		bt, num, err := wasm.DecodeBlockType(c.types, c.module.CompositeTypes, c.br, c.enabledFeatures)
 		if err != nil {
 			return fmt.Errorf("reading block type for loop instruction: %w", err)
 		}
@@ -476,7 +485,7 @@ operatorSwitch:
 		}
 	case wasm.OpcodeIf:
 		c.br.Reset(c.body[c.pc+1:])
-		bt, num, err := wasm.DecodeBlockType(c.types, c.br, c.enabledFeatures)
+		bt, num, err := wasm.DecodeBlockType(c.types, c.module.CompositeTypes, c.br, c.enabledFeatures)
 		if err != nil {
 			return fmt.Errorf("reading block type for if instruction: %w", err)
 		}
@@ -1583,6 +1592,14 @@ operatorSwitch:
 		)
 	case wasm.OpcodeRefNull:
 		c.pc++ // Skip the type of reftype as every ref value is opaque pointer.
+		if c.enabledFeatures.IsEnabled(api.CoreFeatureGC) {
+			// With GC, the type is a heap type which may take multiple bytes.
+			_, num, err := wasm.LoadHeapType(c.body[c.pc:])
+			if err != nil {
+				return fmt.Errorf("failed to read heap type for ref.null: %v", err)
+			}
+			c.pc += num - 1
+		}
 		c.emit(
 			NewOperationConstI64(0),
 		)
@@ -1611,6 +1628,108 @@ operatorSwitch:
 		c.emit(
 			NewOperationTableSet(tableIndex),
 		)
+	case wasm.OpcodeRefEq:
+		// References are opaque pointers, so they are equal when their values are.
+		c.emit(
+			NewOperationRefEq(),
+		)
+	case wasm.OpcodeRefAsNonNull:
+		c.emit(
+			NewOperationRefAsNonNull(),
+		)
+	case wasm.OpcodeGCPrefix:
+		c.pc++
+		gcOp, num, err := leb128.LoadUint32(c.body[c.pc:])
+		if err != nil {
+			return fmt.Errorf("failed to read gc opcode: %v", err)
+		}
+		c.pc += num - 1
+		gcOpcode := byte(gcOp)
+		var immediates [2]uint32
+		var heapType wasm.HeapType
+		switch gcOpcode {
+		case wasm.OpcodeGCStructGet, wasm.OpcodeGCStructGetS, wasm.OpcodeGCStructGetU, wasm.OpcodeGCStructSet,
+			wasm.OpcodeGCArrayNewFixed:
+			for i := range immediates {
+				c.pc++
+				if immediates[i], num, err = leb128.LoadUint32(c.body[c.pc:]); err != nil {
+					return fmt.Errorf("failed to read immediate for %s: %v", wasm.GCInstructionName(gcOpcode), err)
+				}
+				c.pc += num - 1
+			}
+		case wasm.OpcodeGCStructNew, wasm.OpcodeGCStructNewDefault, wasm.OpcodeGCArrayNew, wasm.OpcodeGCArrayNewDefault,
+			wasm.OpcodeGCArrayGet, wasm.OpcodeGCArrayGetS, wasm.OpcodeGCArrayGetU, wasm.OpcodeGCArraySet:
+			c.pc++
+			if immediates[0], num, err = leb128.LoadUint32(c.body[c.pc:]); err != nil {
+				return fmt.Errorf("failed to read type index for %s: %v", wasm.GCInstructionName(gcOpcode), err)
+			}
+			c.pc += num - 1
+		case wasm.OpcodeGCRefTest, wasm.OpcodeGCRefTestNull, wasm.OpcodeGCRefCast, wasm.OpcodeGCRefCastNull:
+			c.pc++
+			if heapType, num, err = wasm.LoadHeapType(c.body[c.pc:]); err != nil {
+				return fmt.Errorf("failed to read heap type for %s: %v", wasm.GCInstructionName(gcOpcode), err)
+			}
+			c.pc += num - 1
+		}
+
+		switch gcOpcode {
+		case wasm.OpcodeGCStructNew, wasm.OpcodeGCStructNewDefault:
+			c.emit(
+				NewOperationStructNew(immediates[0], gcOpcode == wasm.OpcodeGCStructNewDefault),
+			)
+		case wasm.OpcodeGCStructGet, wasm.OpcodeGCStructGetS, wasm.OpcodeGCStructGetU:
+			c.emit(
+				NewOperationStructGet(immediates[0], immediates[1],
+					gcOpcode != wasm.OpcodeGCStructGet, gcOpcode == wasm.OpcodeGCStructGetS),
+			)
+		case wasm.OpcodeGCStructSet:
+			c.emit(
+				NewOperationStructSet(immediates[0], immediates[1]),
+			)
+		case wasm.OpcodeGCArrayNew:
+			c.emit(
+				NewOperationArrayNew(immediates[0], ArrayNewModeInit, 0),
+			)
+		case wasm.OpcodeGCArrayNewDefault:
+			c.emit(
+				NewOperationArrayNew(immediates[0], ArrayNewModeDefault, 0),
+			)
+		case wasm.OpcodeGCArrayNewFixed:
+			c.emit(
+				NewOperationArrayNew(immediates[0], ArrayNewModeFixed, immediates[1]),
+			)
+		case wasm.OpcodeGCArrayGet, wasm.OpcodeGCArrayGetS, wasm.OpcodeGCArrayGetU:
+			c.emit(
+				NewOperationArrayGet(immediates[0],
+					gcOpcode != wasm.OpcodeGCArrayGet, gcOpcode == wasm.OpcodeGCArrayGetS),
+			)
+		case wasm.OpcodeGCArraySet:
+			c.emit(
+				NewOperationArraySet(immediates[0]),
+			)
+		case wasm.OpcodeGCArrayLen:
+			c.emit(
+				NewOperationArrayLen(),
+			)
+		case wasm.OpcodeGCRefTest, wasm.OpcodeGCRefTestNull:
+			c.emit(
+				NewOperationRefTest(heapType, gcOpcode == wasm.OpcodeGCRefTestNull),
+			)
+		case wasm.OpcodeGCRefCast, wasm.OpcodeGCRefCastNull:
+			c.emit(
+				NewOperationRefCast(heapType, gcOpcode == wasm.OpcodeGCRefCastNull),
+			)
+		case wasm.OpcodeGCRefI31:
+			c.emit(
+				NewOperationRefI31(),
+			)
+		case wasm.OpcodeGCI31GetS, wasm.OpcodeGCI31GetU:
+			c.emit(
+				NewOperationI31Get(gcOpcode == wasm.OpcodeGCI31GetS),
+			)
+		default:
+			return fmt.Errorf("unsupported gc instruction in wazeroir: %s", wasm.GCInstructionName(gcOpcode))
+		}
 	case wasm.OpcodeMiscPrefix:
 		c.pc++
 		// A misc opcode is encoded as an unsigned variable 32-bit integer.
@@ -2876,11 +2995,12 @@ func (c *Compiler) applyToStack(opcode wasm.Opcode) (index uint32, err error) {
 		wasm.OpcodeGlobalGet,
 		wasm.OpcodeGlobalSet:
 		// Assumes that we are at the opcode now so skip it before read immediates.
-		v, num, err := leb128.LoadUint32(c.body[c.pc+1:])
+		c.dec.Reset(c.body, c.pc+1)
+		v, err := c.dec.Uint32()
 		if err != nil {
 			return 0, fmt.Errorf("reading immediates: %w", err)
 		}
-		c.pc += num
+		c.pc = c.dec.Offset() - 1
 		index = v
 	default:
 		// Note that other opcodes are free of index
@@ -2980,7 +3100,7 @@ func (c *Compiler) emitDefaultValue(t wasm.ValueType) {
 	case wasm.ValueTypeI32:
 		c.stackPush(UnsignedTypeI32)
 		c.emit(NewOperationConstI32(0))
-	case wasm.ValueTypeI64, wasm.ValueTypeExternref, wasm.ValueTypeFuncref:
+	case wasm.ValueTypeI64, wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref:
 		c.stackPush(UnsignedTypeI64)
 		c.emit(NewOperationConstI64(0))
 	case wasm.ValueTypeF32:
@@ -3054,15 +3174,15 @@ func (c *Compiler) stackLenInUint64(ceil int) (ret int) {
 
 func (c *Compiler) readMemoryArg(tag string) (MemoryArg, error) {
 	c.result.UsesMemory = true
-	alignment, num, err := leb128.LoadUint32(c.body[c.pc+1:])
+	c.dec.Reset(c.body, c.pc+1)
+	alignment, err := c.dec.Uint32()
 	if err != nil {
 		return MemoryArg{}, fmt.Errorf("reading alignment for %s: %w", tag, err)
 	}
-	c.pc += num
-	offset, num, err := leb128.LoadUint32(c.body[c.pc+1:])
+	offset, err := c.dec.Uint32()
 	if err != nil {
 		return MemoryArg{}, fmt.Errorf("reading offset for %s: %w", tag, err)
 	}
-	c.pc += num
+	c.pc = c.dec.Offset() - 1
 	return MemoryArg{Offset: offset, Alignment: alignment}, nil
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
+
+	"github.com/tetratelabs/wazero/internal/wasm"
 )
 
 // UnsignedInt represents unsigned 32-bit or 64-bit integers.
@@ -413,6 +415,32 @@ func (o OperationKind) String() (ret string) {
 		ret = "V128Narrow"
 	case OperationKindV128ITruncSatFromF:
 		ret = "V128ITruncSatFromF"
+	case OperationKindStructNew:
+		ret = "StructNew"
+	case OperationKindStructGet:
+		ret = "StructGet"
+	case OperationKindStructSet:
+		ret = "StructSet"
+	case OperationKindArrayNew:
+		ret = "ArrayNew"
+	case OperationKindArrayGet:
+		ret = "ArrayGet"
+	case OperationKindArraySet:
+		ret = "ArraySet"
+	case OperationKindArrayLen:
+		ret = "ArrayLen"
+	case OperationKindRefTest:
+		ret = "RefTest"
+	case OperationKindRefCast:
+		ret = "RefCast"
+	case OperationKindRefI31:
+		ret = "RefI31"
+	case OperationKindI31Get:
+		ret = "I31Get"
+	case OperationKindRefEq:
+		ret = "RefEq"
+	case OperationKindRefAsNonNull:
+		ret = "RefAsNonNull"
 	case OperationKindBuiltinFunctionCheckExitCode:
 		ret = "BuiltinFunctionCheckExitCode"
 	default:
@@ -702,6 +730,35 @@ const (
 	// OperationKindV128ITruncSatFromF is the Kind for NewOperationV128ITruncSatFromF.
 	OperationKindV128ITruncSatFromF
 
+	// GC related instructions, enabled by api.CoreFeatureGC.
+
+	// OperationKindStructNew is the Kind for NewOperationStructNew.
+	OperationKindStructNew
+	// OperationKindStructGet is the Kind for NewOperationStructGet.
+	OperationKindStructGet
+	// OperationKindStructSet is the Kind for NewOperationStructSet.
+	OperationKindStructSet
+	// OperationKindArrayNew is the Kind for NewOperationArrayNew.
+	OperationKindArrayNew
+	// OperationKindArrayGet is the Kind for NewOperationArrayGet.
+	OperationKindArrayGet
+	// OperationKindArraySet is the Kind for NewOperationArraySet.
+	OperationKindArraySet
+	// OperationKindArrayLen is the Kind for NewOperationArrayLen.
+	OperationKindArrayLen
+	// OperationKindRefTest is the Kind for NewOperationRefTest.
+	OperationKindRefTest
+	// OperationKindRefCast is the Kind for NewOperationRefCast.
+	OperationKindRefCast
+	// OperationKindRefI31 is the Kind for NewOperationRefI31.
+	OperationKindRefI31
+	// OperationKindI31Get is the Kind for NewOperationI31Get.
+	OperationKindI31Get
+	// OperationKindRefEq is the Kind for NewOperationRefEq.
+	OperationKindRefEq
+	// OperationKindRefAsNonNull is the Kind for NewOperationRefAsNonNull.
+	OperationKindRefAsNonNull
+
 	// OperationKindBuiltinFunctionCheckExitCode is the Kind for NewOperationBuiltinFunctionCheckExitCode.
 	OperationKindBuiltinFunctionCheckExitCode
 
@@ -828,9 +885,30 @@ func (o UnionOperation) String() string {
 		OperationKindTableSize,
 		OperationKindTableGrow,
 		OperationKindTableFill,
+		OperationKindArrayLen,
+		OperationKindRefI31,
+		OperationKindRefEq,
+		OperationKindRefAsNonNull,
 		OperationKindBuiltinFunctionCheckExitCode:
 		return o.Kind.String()
 
+	case OperationKindStructNew:
+		return fmt.Sprintf("%s %d (default=%v)", o.Kind, o.U1, o.B3)
+	case OperationKindStructGet:
+		return fmt.Sprintf("%s %d %d (packed=%v, signed=%v)", o.Kind, o.U1, o.U2, o.B1 != 0, o.B3)
+	case OperationKindStructSet:
+		return fmt.Sprintf("%s %d %d", o.Kind, o.U1, o.U2)
+	case OperationKindArrayNew:
+		return fmt.Sprintf("%s %d (mode=%d, n=%d)", o.Kind, o.U1, o.B1, o.U2)
+	case OperationKindArrayGet:
+		return fmt.Sprintf("%s %d (packed=%v, signed=%v)", o.Kind, o.U1, o.B1 != 0, o.B3)
+	case OperationKindArraySet:
+		return fmt.Sprintf("%s %d", o.Kind, o.U1)
+	case OperationKindRefTest, OperationKindRefCast:
+		return fmt.Sprintf("%s %d (nullable=%v)", o.Kind, int64(o.U1), o.B3)
+	case OperationKindI31Get:
+		return fmt.Sprintf("%s (signed=%v)", o.Kind, o.B3)
+
 	case OperationKindCall,
 		OperationKindGlobalGet,
 		OperationKindGlobalSet:
@@ -2565,3 +2643,132 @@ func NewOperationV128Narrow(originShape Shape, signed bool) UnionOperation {
 func NewOperationV128ITruncSatFromF(originShape Shape, signed bool) UnionOperation {
 	return UnionOperation{Kind: OperationKindV128ITruncSatFromF, B1: originShape, B3: signed}
 }
+
+// ArrayNewMode is how NewOperationArrayNew initializes the elements of an array.
+type ArrayNewMode = byte
+
+const (
+	// ArrayNewModeInit initializes all elements to the same operand.
+	ArrayNewModeInit ArrayNewMode = iota
+	// ArrayNewModeDefault initializes all elements to their default value.
+	ArrayNewModeDefault
+	// ArrayNewModeFixed initializes each element from its own operand.
+	ArrayNewModeFixed
+)
+
+// NewOperationStructNew is a constructor for UnionOperation with OperationKindStructNew.
+//
+// This corresponds to wasm.OpcodeStructNewName and wasm.OpcodeStructNewDefaultName.
+//
+// typeIndex is the index of the struct type in the module. When useDefault is
+// true, fields are set to their default value instead of operands.
+func NewOperationStructNew(typeIndex uint32, useDefault bool) UnionOperation {
+	return UnionOperation{Kind: OperationKindStructNew, U1: uint64(typeIndex), B3: useDefault}
+}
+
+// NewOperationStructGet is a constructor for UnionOperation with OperationKindStructGet.
+//
+// This corresponds to wasm.OpcodeStructGetName, wasm.OpcodeStructGetSName and
+// wasm.OpcodeStructGetUName.
+//
+// packed is true for the latter two, in which case signed selects the extension.
+func NewOperationStructGet(typeIndex, fieldIndex uint32, packed, signed bool) UnionOperation {
+	op := UnionOperation{Kind: OperationKindStructGet, U1: uint64(typeIndex), U2: uint64(fieldIndex), B3: signed}
+	if packed {
+		op.B1 = 1
+	}
+	return op
+}
+
+// NewOperationStructSet is a constructor for UnionOperation with OperationKindStructSet.
+//
+// This corresponds to wasm.OpcodeStructSetName.
+func NewOperationStructSet(typeIndex, fieldIndex uint32) UnionOperation {
+	return UnionOperation{Kind: OperationKindStructSet, U1: uint64(typeIndex), U2: uint64(fieldIndex)}
+}
+
+// NewOperationArrayNew is a constructor for UnionOperation with OperationKindArrayNew.
+//
+// This corresponds to wasm.OpcodeArrayNewName, wasm.OpcodeArrayNewDefaultName
+// and wasm.OpcodeArrayNewFixedName.
+//
+// n is the number of elements when mode is ArrayNewModeFixed.
+func NewOperationArrayNew(typeIndex uint32, mode ArrayNewMode, n uint32) UnionOperation {
+	return UnionOperation{Kind: OperationKindArrayNew, U1: uint64(typeIndex), B1: mode, U2: uint64(n)}
+}
+
+// NewOperationArrayGet is a constructor for UnionOperation with OperationKindArrayGet.
+//
+// This corresponds to wasm.OpcodeArrayGetName, wasm.OpcodeArrayGetSName and
+// wasm.OpcodeArrayGetUName.
+//
+// packed is true for the latter two, in which case signed selects the extension.
+func NewOperationArrayGet(typeIndex uint32, packed, signed bool) UnionOperation {
+	op := UnionOperation{Kind: OperationKindArrayGet, U1: uint64(typeIndex), B3: signed}
+	if packed {
+		op.B1 = 1
+	}
+	return op
+}
+
+// NewOperationArraySet is a constructor for UnionOperation with OperationKindArraySet.
+//
+// This corresponds to wasm.OpcodeArraySetName.
+func NewOperationArraySet(typeIndex uint32) UnionOperation {
+	return UnionOperation{Kind: OperationKindArraySet, U1: uint64(typeIndex)}
+}
+
+// NewOperationArrayLen is a constructor for UnionOperation with OperationKindArrayLen.
+//
+// This corresponds to wasm.OpcodeArrayLenName.
+func NewOperationArrayLen() UnionOperation {
+	return UnionOperation{Kind: OperationKindArrayLen}
+}
+
+// NewOperationRefTest is a constructor for UnionOperation with OperationKindRefTest.
+//
+// This corresponds to wasm.OpcodeRefTestName and wasm.OpcodeRefTestNullName.
+func NewOperationRefTest(ht wasm.HeapType, nullable bool) UnionOperation {
+	return UnionOperation{Kind: OperationKindRefTest, U1: uint64(ht), B3: nullable}
+}
+
+// NewOperationRefCast is a constructor for UnionOperation with OperationKindRefCast.
+//
+// This corresponds to wasm.OpcodeRefCastName and wasm.OpcodeRefCastNullName.
+//
+// The engines are expected to exit the execution with wasmruntime.ErrRuntimeCastFailure
+// if the reference doesn't match the heap type.
+func NewOperationRefCast(ht wasm.HeapType, nullable bool) UnionOperation {
+	return UnionOperation{Kind: OperationKindRefCast, U1: uint64(ht), B3: nullable}
+}
+
+// NewOperationRefI31 is a constructor for UnionOperation with OperationKindRefI31.
+//
+// This corresponds to wasm.OpcodeRefI31Name.
+func NewOperationRefI31() UnionOperation {
+	return UnionOperation{Kind: OperationKindRefI31}
+}
+
+// NewOperationI31Get is a constructor for UnionOperation with OperationKindI31Get.
+//
+// This corresponds to wasm.OpcodeI31GetSName and wasm.OpcodeI31GetUName.
+func NewOperationI31Get(signed bool) UnionOperation {
+	return UnionOperation{Kind: OperationKindI31Get, B3: signed}
+}
+
+// NewOperationRefEq is a constructor for UnionOperation with OperationKindRefEq.
+//
+// This corresponds to wasm.OpcodeRefEqName.
+func NewOperationRefEq() UnionOperation {
+	return UnionOperation{Kind: OperationKindRefEq}
+}
+
+// NewOperationRefAsNonNull is a constructor for UnionOperation with OperationKindRefAsNonNull.
+//
+// This corresponds to wasm.OpcodeRefAsNonNullName.
+//
+// The engines are expected to exit the execution with wasmruntime.ErrRuntimeNullReference
+// if the reference is null.
+func NewOperationRefAsNonNull() UnionOperation {
+	return UnionOperation{Kind: OperationKindRefAsNonNull}
+}
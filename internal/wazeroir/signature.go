@@ -3,6 +3,7 @@ package wazeroir
 import (
 	"fmt"
 
+	"github.com/tetratelabs/wazero/internal/leb128"
 	"github.com/tetratelabs/wazero/internal/wasm"
 )
 
@@ -452,6 +453,13 @@ func (c *Compiler) wasmOpcodeSignature(op wasm.Opcode, index uint32) (*signature
 	case wasm.OpcodeRefNull:
 		// ref.null is translated as i64.const 0.
 		return signature_None_I64, nil
+	case wasm.OpcodeRefEq:
+		// ref.eq compares two opaque references as i64 values.
+		return signature_I64I64_I32, nil
+	case wasm.OpcodeRefAsNonNull:
+		return signature_I64_I64, nil
+	case wasm.OpcodeGCPrefix:
+		return c.wasmGCOpcodeSignature()
 	case wasm.OpcodeMiscPrefix:
 		switch miscOp := c.body[c.pc+1]; miscOp {
 		case wasm.OpcodeMiscI32TruncSatF32S, wasm.OpcodeMiscI32TruncSatF32U:
@@ -640,13 +648,107 @@ func (f *funcTypeToIRSignatures) get(typeIndex wasm.Index, indirect bool) *signa
 	return sig
 }
 
+// wasmGCOpcodeSignature returns the signature of the instruction prefixed by
+// wasm.OpcodeGCPrefix at the current pc. Unlike other instructions, the
+// signature of struct and array instructions depends on the type immediate.
+// References are opaque 64-bit values, like other reference types.
+func (c *Compiler) wasmGCOpcodeSignature() (*signature, error) {
+	gcOp, num, err := leb128.LoadUint32(c.body[c.pc+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gc opcode: %v", err)
+	}
+	immediates := c.body[c.pc+1+num:]
+
+	switch gcOpcode := byte(gcOp); gcOpcode {
+	case wasm.OpcodeGCStructNewDefault, wasm.OpcodeGCArrayNewDefault:
+		if gcOpcode == wasm.OpcodeGCArrayNewDefault {
+			return signature_I32_I64, nil
+		}
+		return signature_None_I64, nil
+	case wasm.OpcodeGCStructNew:
+		ct, _, err := c.compositeTypeImmediate(immediates)
+		if err != nil {
+			return nil, err
+		}
+		s := &signature{in: make([]UnsignedType, len(ct.Fields)), out: []UnsignedType{UnsignedTypeI64}}
+		for i, f := range ct.Fields {
+			s.in[i] = wasmValueTypeToUnsignedType(f.ValueType())
+		}
+		return s, nil
+	case wasm.OpcodeGCStructGet, wasm.OpcodeGCStructGetS, wasm.OpcodeGCStructGetU, wasm.OpcodeGCStructSet:
+		ct, num, err := c.compositeTypeImmediate(immediates)
+		if err != nil {
+			return nil, err
+		}
+		fieldIndex, _, err := leb128.LoadUint32(immediates[num:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read field index: %v", err)
+		} else if fieldIndex >= uint32(len(ct.Fields)) {
+			return nil, fmt.Errorf("invalid field index: %d", fieldIndex)
+		}
+		t := wasmValueTypeToUnsignedType(ct.Fields[fieldIndex].ValueType())
+		if gcOpcode == wasm.OpcodeGCStructSet {
+			return &signature{in: []UnsignedType{UnsignedTypeI64, t}}, nil
+		}
+		return &signature{in: []UnsignedType{UnsignedTypeI64}, out: []UnsignedType{t}}, nil
+	case wasm.OpcodeGCArrayNew, wasm.OpcodeGCArrayNewFixed:
+		ct, num, err := c.compositeTypeImmediate(immediates)
+		if err != nil {
+			return nil, err
+		}
+		t := wasmValueTypeToUnsignedType(ct.Fields[0].ValueType())
+		if gcOpcode == wasm.OpcodeGCArrayNew {
+			return &signature{in: []UnsignedType{t, UnsignedTypeI32}, out: []UnsignedType{UnsignedTypeI64}}, nil
+		}
+		n, _, err := leb128.LoadUint32(immediates[num:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read array length: %v", err)
+		}
+		s := &signature{in: make([]UnsignedType, n), out: []UnsignedType{UnsignedTypeI64}}
+		for i := range s.in {
+			s.in[i] = t
+		}
+		return s, nil
+	case wasm.OpcodeGCArrayGet, wasm.OpcodeGCArrayGetS, wasm.OpcodeGCArrayGetU, wasm.OpcodeGCArraySet:
+		ct, _, err := c.compositeTypeImmediate(immediates)
+		if err != nil {
+			return nil, err
+		}
+		t := wasmValueTypeToUnsignedType(ct.Fields[0].ValueType())
+		if gcOpcode == wasm.OpcodeGCArraySet {
+			return &signature{in: []UnsignedType{UnsignedTypeI64, UnsignedTypeI32, t}}, nil
+		}
+		return &signature{in: []UnsignedType{UnsignedTypeI64, UnsignedTypeI32}, out: []UnsignedType{t}}, nil
+	case wasm.OpcodeGCArrayLen, wasm.OpcodeGCRefTest, wasm.OpcodeGCRefTestNull,
+		wasm.OpcodeGCI31GetS, wasm.OpcodeGCI31GetU:
+		return signature_I64_I32, nil
+	case wasm.OpcodeGCRefCast, wasm.OpcodeGCRefCastNull:
+		return signature_I64_I64, nil
+	case wasm.OpcodeGCRefI31:
+		return signature_I32_I64, nil
+	default:
+		return nil, fmt.Errorf("unsupported gc instruction in wazeroir: %s", wasm.GCInstructionName(gcOpcode))
+	}
+}
+
+// compositeTypeImmediate reads the type index at the start of buf.
+func (c *Compiler) compositeTypeImmediate(buf []byte) (*wasm.CompositeType, uint64, error) {
+	typeIndex, num, err := leb128.LoadUint32(buf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read type index: %v", err)
+	} else if typeIndex >= uint32(len(c.module.CompositeTypes)) {
+		return nil, 0, fmt.Errorf("invalid type index: %d", typeIndex)
+	}
+	return &c.module.CompositeTypes[typeIndex], num, nil
+}
+
 func wasmValueTypeToUnsignedType(vt wasm.ValueType) UnsignedType {
 	switch vt {
 	case wasm.ValueTypeI32:
 		return UnsignedTypeI32
 	case wasm.ValueTypeI64,
 		// From wazeroir layer, ref type values are opaque 64-bit pointers.
-		wasm.ValueTypeExternref, wasm.ValueTypeFuncref:
+		wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref:
 		return UnsignedTypeI64
 	case wasm.ValueTypeF32:
 		return UnsignedTypeF32
@@ -664,7 +766,7 @@ func wasmValueTypeToUnsignedOutSignature(vt wasm.ValueType) *signature {
 		return signature_None_I32
 	case wasm.ValueTypeI64,
 		// From wazeroir layer, ref type values are opaque 64-bit pointers.
-		wasm.ValueTypeExternref, wasm.ValueTypeFuncref:
+		wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref:
 		return signature_None_I64
 	case wasm.ValueTypeF32:
 		return signature_None_F32
@@ -682,7 +784,7 @@ func wasmValueTypeToUnsignedInSignature(vt wasm.ValueType) *signature {
 		return signature_I32_None
 	case wasm.ValueTypeI64,
 		// From wazeroir layer, ref type values are opaque 64-bit pointers.
-		wasm.ValueTypeExternref, wasm.ValueTypeFuncref:
+		wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref:
 		return signature_I64_None
 	case wasm.ValueTypeF32:
 		return signature_F32_None
@@ -700,7 +802,7 @@ func wasmValueTypeToUnsignedInOutSignature(vt wasm.ValueType) *signature {
 		return signature_I32_I32
 	case wasm.ValueTypeI64,
 		// From wazeroir layer, ref type values are opaque 64-bit pointers.
-		wasm.ValueTypeExternref, wasm.ValueTypeFuncref:
+		wasm.ValueTypeExternref, wasm.ValueTypeFuncref, wasm.ValueTypeAnyref:
 		return signature_I64_I64
 	case wasm.ValueTypeF32:
 		return signature_F32_F32
@@ -11,6 +11,7 @@ import (
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/wasm"
 	binaryformat "github.com/tetratelabs/wazero/internal/wasm/binary"
+	"github.com/tetratelabs/wazero/internal/wasm/text"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -93,6 +94,8 @@ type Runtime interface {
 	//
 	//   - The resulting module name defaults to what was binary from the custom name section.
 	//   - Any pre-compilation done after decoding the source is dependent on RuntimeConfig.
+	//   - The WebAssembly text format (%.wat) is also accepted, e.g. "(module)",
+	//     except SIMD instructions.
 	//
 	// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#name-section%E2%91%A0
 	CompileModule(ctx context.Context, binary []byte) (CompiledModule, error)
@@ -134,6 +137,35 @@ type Runtime interface {
 	// Module returns an instantiated module in this runtime or nil if there aren't any.
 	Module(moduleName string) api.Module
 
+	// ReplaceHostModule replaces the functions of the host module instantiated
+	// with the same name as the builder by the ones it defines. Modules
+	// importing these functions call the replacements without being
+	// re-instantiated.
+	//
+	// Here's an example of swapping the logging backend of a guest:
+	//	_, _ = r.NewHostModuleBuilder("env").
+	//		NewFunctionBuilder().WithFunc(logToStdout).Export("log").
+	//		Instantiate(ctx)
+	//	mod, _ := r.Instantiate(ctx, guestWasm)
+	//	--snip--
+	//	err := r.ReplaceHostModule(ctx, r.NewHostModuleBuilder("env").
+	//		NewFunctionBuilder().WithFunc(logToFile).Export("log"))
+	//
+	// # Errors
+	//
+	// Nothing is replaced when an error is returned, which happens if:
+	//   - No host module of that name was instantiated.
+	//   - The builder doesn't export the same functions, with the same
+	//     signatures, as the host module.
+	//
+	// # Notes
+	//
+	//   - This is safe to call while the functions are called. Calls in
+	//     progress complete with the previous implementation.
+	//   - The function definitions of the host module, such as returned by
+	//     api.Module ExportedFunctionDefinitions, are not replaced.
+	ReplaceHostModule(ctx context.Context, builder HostModuleBuilder) error
+
 	// Closer closes all compiled code by delegating to CloseWithExitCode with an exit code of zero.
 	api.Closer
 }
@@ -146,6 +178,9 @@ func NewRuntime(ctx context.Context) Runtime {
 // NewRuntimeWithConfig returns a runtime with the given configuration.
 func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 	config := rConfig.(*runtimeConfig)
+	if config.canonicalizeNaN && config.engineKind != engineKindInterpreter {
+		panic("WithNaNCanonicalization requires the interpreter: use NewRuntimeConfigInterpreter")
+	}
 	var engine wasm.Engine
 	var cacheImpl *cache
 	if c := config.cache; c != nil {
@@ -157,6 +192,9 @@ func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 		engine = config.newEngine(ctx, config.enabledFeatures, nil)
 	}
 	store := wasm.NewStore(config.enabledFeatures, engine)
+	store.ExecutionLimit = config.executionLimit
+	store.CanonicalizeNaN = config.canonicalizeNaN
+	store.TrapHostPanics = config.trapHostPanics
 	zero := uint64(0)
 	return &runtime{
 		cache:                 cacheImpl,
@@ -167,7 +205,9 @@ func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 		dwarfDisabled:         config.dwarfDisabled,
 		storeCustomSections:   config.storeCustomSections,
 		closed:                &zero,
-		ensureTermination:     config.ensureTermination,
+		ensureTermination:     config.ensureTermination || config.executionLimit > 0,
+		preStartHook:          config.preStartHook,
+		postStartHook:         config.postStartHook,
 	}
 }
 
@@ -190,6 +230,10 @@ type runtime struct {
 	closed *uint64
 
 	ensureTermination bool
+
+	// preStartHook and postStartHook are called around the start functions
+	// of guest modules, unless nil.
+	preStartHook, postStartHook InstantiationHook
 }
 
 // Module implements Runtime.Module.
@@ -200,12 +244,32 @@ func (r *runtime) Module(moduleName string) api.Module {
 	return r.store.Module(moduleName)
 }
 
+// ReplaceHostModule implements Runtime.ReplaceHostModule
+func (r *runtime) ReplaceHostModule(_ context.Context, builder HostModuleBuilder) error {
+	b := builder.(*hostModuleBuilder)
+	module, err := wasm.NewHostModule(b.moduleName, b.exportNames, b.nameToHostFunc, r.enabledFeatures)
+	if err != nil {
+		return err
+	} else if err = module.Validate(r.enabledFeatures); err != nil {
+		return err
+	}
+	return r.store.ReplaceHostModule(module)
+}
+
 // CompileModule implements Runtime.CompileModule
 func (r *runtime) CompileModule(ctx context.Context, binary []byte) (CompiledModule, error) {
 	if err := r.failIfClosed(); err != nil {
 		return nil, err
 	}
 
+	if text.IsText(binary) {
+		compiled, err := text.Compile(binary)
+		if err != nil {
+			return nil, err
+		}
+		binary = compiled
+	}
+
 	internal, err := binaryformat.DecodeModule(binary, r.enabledFeatures,
 		r.memoryLimitPages, r.memoryCapacityFromMax, !r.dwarfDisabled, r.storeCustomSections)
 	if err != nil {
@@ -291,12 +355,18 @@ func (r *runtime) InstantiateModule(
 	code := compiled.(*compiledModule)
 	config := mConfig.(*moduleConfig)
 
-	// Only build listeners on a guest module. A host module doesn't have
-	// memory, and a guest without memory can't use listeners anyway.
+	// Only build listeners, pipes and stdio options on a guest module. A host module doesn't
+	// have memory, and a guest without memory can't use them anyway.
 	if !code.module.IsHostModule {
 		if sockConfig, ok := ctx.Value(internalsock.ConfigKey{}).(*internalsock.Config); ok {
 			config.sockConfig = sockConfig
 		}
+		if pipeConfig, ok := ctx.Value(internalsys.PipeConfigKey{}).(*internalsys.PipeConfig); ok {
+			config.pipeConfig = pipeConfig
+		}
+		if stdioConfig, ok := ctx.Value(internalsys.StdioConfigKey{}).(*internalsys.StdioConfig); ok {
+			config.stdioConfig = stdioConfig
+		}
 	}
 
 	var sysCtx *internalsys.Context
@@ -324,6 +394,15 @@ func (r *runtime) InstantiateModule(
 		mod.(*wasm.ModuleInstance).CodeCloser = code
 	}
 
+	isGuest := !code.module.IsHostModule
+	if hook := r.preStartHook; hook != nil && isGuest {
+		if err = hook(ctx, mod); err != nil {
+			_ = mod.Close(ctx) // Don't leak the module on error.
+			err = fmt.Errorf("module[%s] pre-start hook failed: %w", name, err)
+			return
+		}
+	}
+
 	// Now, invoke any start functions, failing at first error.
 	for _, fn := range config.startFunctions {
 		start := mod.ExportedFunction(fn)
@@ -343,6 +422,13 @@ func (r *runtime) InstantiateModule(
 			return
 		}
 	}
+
+	if hook := r.postStartHook; hook != nil && isGuest {
+		if err = hook(ctx, mod); err != nil {
+			_ = mod.Close(ctx) // Don't leak the module on error.
+			err = fmt.Errorf("module[%s] post-start hook failed: %w", name, err)
+		}
+	}
 	return
 }
 
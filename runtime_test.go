@@ -16,6 +16,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/testing/binaryencoding"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -546,6 +547,564 @@ func TestRuntime_InstantiateModule_ExitError(t *testing.T) {
 	}
 }
 
+func TestRuntime_ReplaceHostModule(t *testing.T) {
+	// The guest re-exports env.get as "call", so that calls go through the import.
+	guestWasm := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection:     []wasm.FunctionType{{Results: []wasm.ValueType{wasm.ValueTypeI32}, ResultNumInUint64: 1}},
+		ImportSection:   []wasm.Import{{Module: "env", Name: "get", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection:     []wasm.Code{{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeEnd}}},
+		ExportSection:   []wasm.Export{{Name: "call", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	for _, tc := range []struct {
+		name   string
+		config RuntimeConfig
+	}{
+		{name: "interpreter", config: NewRuntimeConfigInterpreter()},
+		{name: "default", config: NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			getN := func(n uint32) func() uint32 {
+				return func() uint32 { return n }
+			}
+			_, err := r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(getN(1)).Export("get").
+				Instantiate(testCtx)
+			require.NoError(t, err)
+
+			mod, err := r.Instantiate(testCtx, guestWasm)
+			require.NoError(t, err)
+			call := mod.ExportedFunction("call")
+
+			results, err := call.Call(testCtx)
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), results[0])
+
+			require.NoError(t, r.ReplaceHostModule(testCtx, r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(getN(2)).Export("get")))
+
+			results, err = call.Call(testCtx)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), results[0])
+		})
+	}
+}
+
+func TestRuntime_ReplaceHostModule_Errors(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("get").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+	_, err = r.InstantiateWithConfig(testCtx, binaryNamedZero, NewModuleConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		builder     HostModuleBuilder
+		expectedErr string
+	}{
+		{
+			name:        "not instantiated",
+			builder:     r.NewHostModuleBuilder("foo"),
+			expectedErr: "module[foo] not instantiated",
+		},
+		{
+			name:        "not a host module",
+			builder:     r.NewHostModuleBuilder("0"),
+			expectedErr: "module[0] is not a host module",
+		},
+		{
+			name: "signature mismatch",
+			builder: r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(func() uint64 { return 1 }).Export("get"),
+			expectedErr: "function[get] signature mismatch: v_i32 != v_i64",
+		},
+		{
+			name: "missing function",
+			builder: r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("got"),
+			expectedErr: "module[env] replacement doesn't export function[get]",
+		},
+		{
+			name: "extra function",
+			builder: r.NewHostModuleBuilder("env").
+				NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("get").
+				NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("got"),
+			expectedErr: "module[env] exports 1 functions, but the replacement exports 2",
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			err := r.ReplaceHostModule(testCtx, tc.builder)
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestRuntime_ExtendedConst(t *testing.T) {
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		MemorySection: &wasm.Memory{Min: 1, Max: 1},
+		GlobalSection: []wasm.Global{{
+			Type: wasm.GlobalType{ValType: wasm.ValueTypeI64},
+			Init: wasm.ConstantExpression{Opcode: wasm.OpcodeI64Add, Data: []byte{
+				wasm.OpcodeI64Const, 5, wasm.OpcodeI64Const, 7, wasm.OpcodeI64Add,
+			}},
+		}},
+		DataSection: []wasm.DataSegment{{
+			OffsetExpression: wasm.ConstantExpression{Opcode: wasm.OpcodeI32Mul, Data: []byte{
+				wasm.OpcodeI32Const, 2, wasm.OpcodeI32Const, 3, wasm.OpcodeI32Mul,
+			}},
+			Init: []byte{0xff},
+		}},
+		ExportSection: []wasm.Export{
+			{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+			{Name: "global", Type: wasm.ExternTypeGlobal, Index: 0},
+		},
+	})
+
+	for _, tc := range []struct {
+		name   string
+		config RuntimeConfig
+	}{
+		{name: "interpreter", config: NewRuntimeConfigInterpreter()},
+		{name: "default", config: NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			// Not in CoreFeaturesV2, so must be enabled.
+			_, err := r.CompileModule(testCtx, bin)
+			require.EqualError(t, err, "global[0]: constant expression has been not terminated")
+
+			r = NewRuntimeWithConfig(testCtx, tc.config.
+				WithCoreFeatures(api.CoreFeaturesV2|api.CoreFeatureExtendedConst))
+			defer r.Close(testCtx)
+
+			m, err := r.Instantiate(testCtx, bin)
+			require.NoError(t, err)
+			require.Equal(t, uint64(12), m.ExportedGlobal("global").Get())
+
+			b, ok := m.ExportedMemory("memory").ReadByte(6)
+			require.True(t, ok)
+			require.Equal(t, byte(0xff), b)
+		})
+	}
+}
+
+func TestRuntime_GC(t *testing.T) {
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		FunctionSection: []wasm.Index{2, 2, 2, 3, 3},
+		CodeSection: []wasm.Code{
+			{ // Returns the sum of a struct's i32 field and i8 field, both set to the parameter.
+				LocalTypes: []wasm.ValueType{wasm.ValueTypeAnyref},
+				Body: []byte{
+					wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 0,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCStructNew, 0,
+					wasm.OpcodeLocalSet, 1,
+					wasm.OpcodeLocalGet, 1, wasm.OpcodeGCPrefix, wasm.OpcodeGCStructGet, 0, 0,
+					wasm.OpcodeLocalGet, 1, wasm.OpcodeGCPrefix, wasm.OpcodeGCStructGetS, 0, 1,
+					wasm.OpcodeI32Add, wasm.OpcodeEnd,
+				},
+			},
+			{ // Sets the last element of an array of the parameter's length to 42, and returns it plus the length.
+				LocalTypes: []wasm.ValueType{wasm.ValueTypeAnyref},
+				Body: []byte{
+					wasm.OpcodeLocalGet, 0, wasm.OpcodeGCPrefix, wasm.OpcodeGCArrayNewDefault, 1,
+					wasm.OpcodeLocalSet, 1,
+					wasm.OpcodeLocalGet, 1, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Sub,
+					wasm.OpcodeI32Const, 42, wasm.OpcodeGCPrefix, wasm.OpcodeGCArraySet, 1,
+					wasm.OpcodeLocalGet, 1, wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Sub,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCArrayGet, 1,
+					wasm.OpcodeLocalGet, 1, wasm.OpcodeGCPrefix, wasm.OpcodeGCArrayLen,
+					wasm.OpcodeI32Add, wasm.OpcodeEnd,
+				},
+			},
+			{ // Returns the parameter, sign extended from 31 bits by an i31 reference.
+				Body: []byte{
+					wasm.OpcodeLocalGet, 0, wasm.OpcodeGCPrefix, wasm.OpcodeGCRefI31,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCRefCast, 0x6c, // i31
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCI31GetS, wasm.OpcodeEnd,
+				},
+			},
+			{ // Casts an i31 reference to the struct type.
+				Body: []byte{
+					wasm.OpcodeI32Const, 1, wasm.OpcodeGCPrefix, wasm.OpcodeGCRefI31,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCRefCast, 0,
+					wasm.OpcodeDrop, wasm.OpcodeI32Const, 0, wasm.OpcodeEnd,
+				},
+			},
+			{ // Returns 2 if a struct is of its type, plus 1 if it is an i31.
+				Body: []byte{
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCStructNewDefault, 0,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCRefTest, 0,
+					wasm.OpcodeI32Const, 2, wasm.OpcodeI32Mul,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCStructNewDefault, 0,
+					wasm.OpcodeGCPrefix, wasm.OpcodeGCRefTest, 0x6c, // i31
+					wasm.OpcodeI32Add, wasm.OpcodeEnd,
+				},
+			},
+		},
+		ExportSection: []wasm.Export{
+			{Name: "struct", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "array", Type: wasm.ExternTypeFunc, Index: 1},
+			{Name: "i31", Type: wasm.ExternTypeFunc, Index: 2},
+			{Name: "bad_cast", Type: wasm.ExternTypeFunc, Index: 3},
+			{Name: "test", Type: wasm.ExternTypeFunc, Index: 4},
+		},
+	})
+	// Insert the type section, which can't be encoded from wasm.FunctionType.
+	typeSection := []byte{
+		wasm.SectionIDType, 0x13, 4,
+		0x5f, 2, wasm.ValueTypeI32, 1, wasm.StorageTypeI8, 1, // (struct (mut i32) (mut i8))
+		0x5e, wasm.ValueTypeI32, 1, // (array (mut i32))
+		0x60, 1, wasm.ValueTypeI32, 1, wasm.ValueTypeI32, // (func (param i32) (result i32))
+		0x60, 0, 1, wasm.ValueTypeI32, // (func (result i32))
+	}
+	bin = append(bin[:8:8], append(typeSection, bin[8:]...)...)
+
+	if platform.CompilerSupported() {
+		t.Run("compiler", func(t *testing.T) {
+			config := NewRuntimeConfigCompiler().WithCoreFeatures(api.CoreFeaturesV2 | api.CoreFeatureGC)
+			r := NewRuntimeWithConfig(testCtx, config)
+			defer r.Close(testCtx)
+
+			// Only the interpreter implements GC instructions.
+			_, err := r.CompileModule(testCtx, bin)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "GC requires the interpreter")
+		})
+	}
+
+	t.Run("interpreter", func(t *testing.T) {
+		config := NewRuntimeConfigInterpreter()
+		r := NewRuntimeWithConfig(testCtx, config)
+		defer r.Close(testCtx)
+
+		// Not in CoreFeaturesV2, so must be enabled.
+		_, err := r.CompileModule(testCtx, bin)
+		require.Error(t, err)
+
+		r = NewRuntimeWithConfig(testCtx, config.WithCoreFeatures(api.CoreFeaturesV2|api.CoreFeatureGC))
+		defer r.Close(testCtx)
+
+		m, err := r.Instantiate(testCtx, bin)
+		require.NoError(t, err)
+
+		results, err := m.ExportedFunction("struct").Call(testCtx, 0x1ff)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{0x1ff - 1}, results) // 0x1ff wraps to -1 as i8.
+
+		results, err = m.ExportedFunction("array").Call(testCtx, 3)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{45}, results)
+
+		_, err = m.ExportedFunction("array").Call(testCtx, 0)
+		require.ErrorIs(t, err, wasmruntime.ErrRuntimeOutOfBoundsArrayAccess)
+
+		results, err = m.ExportedFunction("i31").Call(testCtx, 0x7fffffff)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{0xffffffff}, results)
+
+		_, err = m.ExportedFunction("bad_cast").Call(testCtx)
+		require.ErrorIs(t, err, wasmruntime.ErrRuntimeCastFailure)
+
+		results, err = m.ExportedFunction("test").Call(testCtx)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{2}, results)
+
+		// Objects are accounted against the usage limit of the module
+		// which allocates them, until it is closed.
+		limitCtx := context.WithValue(testCtx, wasm.UsageLimitKey{}, uint64(1024))
+		m, err = r.InstantiateWithConfig(limitCtx, bin, NewModuleConfig().WithName("limited"))
+		require.NoError(t, err)
+		usage := m.(*wasm.ModuleInstance).UsageLimit
+
+		_, err = m.ExportedFunction("array").Call(testCtx, 1024)
+		require.ErrorIs(t, err, wasmruntime.ErrRuntimeHeapLimitExceeded)
+		_, err = m.ExportedFunction("array").Call(testCtx, 3)
+		require.NoError(t, err)
+		require.NotEqual(t, uint64(0), usage.Heap())
+
+		require.NoError(t, m.Close(testCtx))
+		require.Zero(t, usage.Heap())
+	})
+}
+
+func TestRuntime_CompileModule_Text(t *testing.T) {
+	wat := []byte(`(module $fac
+  (memory (export "memory") 1)
+  (data (i32.const 0) "wazero")
+  (func $fac (export "fac") (param $n i64) (result i64)
+    (if (result i64) (i64.eqz (local.get $n))
+      (then (i64.const 1))
+      (else (i64.mul (local.get $n) (call $fac (i64.sub (local.get $n) (i64.const 1))))))))`)
+
+	for _, tc := range []struct {
+		name   string
+		config RuntimeConfig
+	}{
+		{name: "interpreter", config: NewRuntimeConfigInterpreter()},
+		{name: "default", config: NewRuntimeConfig()},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, tc.config)
+			defer r.Close(testCtx)
+
+			compiled, err := r.CompileModule(testCtx, wat)
+			require.NoError(t, err)
+			require.Equal(t, "fac", compiled.Name())
+
+			m, err := r.InstantiateModule(testCtx, compiled, NewModuleConfig())
+			require.NoError(t, err)
+
+			results, err := m.ExportedFunction("fac").Call(testCtx, 5)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{120}, results)
+
+			b, ok := m.ExportedMemory("memory").Read(0, 6)
+			require.True(t, ok)
+			require.Equal(t, "wazero", string(b))
+
+			_, err = r.CompileModule(testCtx, []byte("(module (func i32.foo))"))
+			require.EqualError(t, err, "1:15: unknown instruction i32.foo")
+		})
+	}
+}
+
+func TestRuntime_WithExecutionLimit(t *testing.T) {
+	// countdown loops until its parameter is zero, which executes the loop header once per iteration.
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection:     []wasm.FunctionType{{Params: []wasm.ValueType{wasm.ValueTypeI32}, ParamNumInUint64: 1}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []wasm.Code{{Body: []byte{
+			wasm.OpcodeLoop, 0x40,
+			wasm.OpcodeLocalGet, 0, wasm.OpcodeI32Const, 1, wasm.OpcodeI32Sub, wasm.OpcodeLocalTee, 0,
+			wasm.OpcodeBrIf, 0,
+			wasm.OpcodeEnd,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []wasm.Export{{Name: "countdown", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	configs := map[string]RuntimeConfig{"interpreter": NewRuntimeConfigInterpreter()}
+	if platform.CompilerSupported() {
+		configs["compiler"] = NewRuntimeConfigCompiler()
+	}
+
+	for name, config := range configs {
+		config := config
+		t.Run(name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, config.WithExecutionLimit(10))
+			defer r.Close(testCtx)
+
+			m, err := r.Instantiate(testCtx, bin)
+			require.NoError(t, err)
+			countdown := m.ExportedFunction("countdown")
+
+			_, err = countdown.Call(testCtx, 10)
+			require.NoError(t, err)
+
+			_, err = countdown.Call(testCtx, 11)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "wasm error: execution limit exceeded")
+
+			// The module is still usable, and each call has its own budget.
+			require.Nil(t, m.(*wasm.ModuleInstance).FailIfClosed())
+			_, err = countdown.Call(testCtx, 10)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRuntime_WithNaNCanonicalization(t *testing.T) {
+	// add adds its f32 parameters, and sqrt returns the square root of its f64 parameter.
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection: []wasm.FunctionType{
+			{
+				Params:            []wasm.ValueType{wasm.ValueTypeF32, wasm.ValueTypeF32},
+				ParamNumInUint64:  2,
+				Results:           []wasm.ValueType{wasm.ValueTypeF32},
+				ResultNumInUint64: 1,
+			},
+			{
+				Params:            []wasm.ValueType{wasm.ValueTypeF64},
+				ParamNumInUint64:  1,
+				Results:           []wasm.ValueType{wasm.ValueTypeF64},
+				ResultNumInUint64: 1,
+			},
+		},
+		FunctionSection: []wasm.Index{0, 1},
+		CodeSection: []wasm.Code{
+			{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeLocalGet, 1, wasm.OpcodeF32Add, wasm.OpcodeEnd}},
+			{Body: []byte{wasm.OpcodeLocalGet, 0, wasm.OpcodeF64Sqrt, wasm.OpcodeEnd}},
+		},
+		ExportSection: []wasm.Export{
+			{Name: "add", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "sqrt", Type: wasm.ExternTypeFunc, Index: 1},
+		},
+	})
+
+	if platform.CompilerSupported() {
+		t.Run("compiler", func(t *testing.T) {
+			// Only the interpreter implements this, so the compiler is rejected.
+			config := NewRuntimeConfigCompiler().WithNaNCanonicalization(true)
+			err := require.CapturePanic(func() { NewRuntimeWithConfig(testCtx, config) })
+			require.EqualError(t, err, "WithNaNCanonicalization requires the interpreter: use NewRuntimeConfigInterpreter")
+		})
+	}
+
+	t.Run("interpreter", func(t *testing.T) {
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfigInterpreter().WithNaNCanonicalization(true))
+		defer r.Close(testCtx)
+
+		m, err := r.Instantiate(testCtx, bin)
+		require.NoError(t, err)
+
+		// A NaN with a payload and sign is replaced with the canonical NaN.
+		results, err := m.ExportedFunction("add").Call(testCtx, 0xffa00001, api.EncodeF32(1))
+		require.NoError(t, err)
+		require.Equal(t, []uint64{0x7fc00000}, results)
+
+		results, err = m.ExportedFunction("sqrt").Call(testCtx, api.EncodeF64(-1))
+		require.NoError(t, err)
+		require.Equal(t, []uint64{0x7ff8000000000000}, results)
+
+		// Other results are unchanged.
+		results, err = m.ExportedFunction("sqrt").Call(testCtx, api.EncodeF64(4))
+		require.NoError(t, err)
+		require.Equal(t, []uint64{api.EncodeF64(2)}, results)
+	})
+}
+
+func TestRuntime_WithHostFunctionPanicTrap(t *testing.T) {
+	// run calls the imported function env.fn.
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection:     []wasm.FunctionType{{}},
+		ImportSection:   []wasm.Import{{Module: "env", Name: "fn", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection:     []wasm.Code{{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeEnd}}},
+		ExportSection:   []wasm.Export{{Name: "run", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+	whoops := errors.New("whoops")
+
+	configs := map[string]RuntimeConfig{"interpreter": NewRuntimeConfigInterpreter()}
+	if platform.CompilerSupported() {
+		configs["compiler"] = NewRuntimeConfigCompiler()
+	}
+
+	for name, config := range configs {
+		config := config
+		t.Run(name, func(t *testing.T) {
+			r := NewRuntimeWithConfig(testCtx, config.WithHostFunctionPanicTrap(true))
+			defer r.Close(testCtx)
+
+			var fn func(context.Context, api.Module)
+			_, err := r.NewHostModuleBuilder("env").NewFunctionBuilder().
+				WithFunc(func(ctx context.Context, m api.Module) { fn(ctx, m) }).
+				Export("fn").Instantiate(testCtx)
+			require.NoError(t, err)
+
+			m, err := r.Instantiate(testCtx, bin)
+			require.NoError(t, err)
+
+			fn = func(context.Context, api.Module) { panic(whoops) }
+			_, err = m.ExportedFunction("run").Call(testCtx)
+			var hostErr *sys.HostFunctionError
+			require.True(t, errors.As(err, &hostErr))
+			require.Equal(t, "env.fn", hostErr.Function)
+			require.ErrorIs(t, err, whoops)
+			require.Contains(t, err.Error(), `host function env.fn panicked: whoops
+wasm stack trace:
+	env.fn()
+	.$1()`)
+			require.Contains(t, err.Error(), "Go runtime stack trace:")
+			require.Contains(t, err.Error(), "runtime_test.go")
+
+			// The module can still be called, as the panic was a trap.
+			fn = func(context.Context, api.Module) {}
+			_, err = m.ExportedFunction("run").Call(testCtx)
+			require.NoError(t, err)
+
+			// Exit errors are not converted.
+			fn = func(ctx context.Context, m api.Module) {
+				_ = m.CloseWithExitCode(ctx, 3)
+				panic(sys.NewExitError(3))
+			}
+			_, err = m.ExportedFunction("run").Call(testCtx)
+			require.Equal(t, sys.NewExitError(3), err)
+		})
+	}
+}
+
+func TestRuntime_InstantiationHooks(t *testing.T) {
+	// _start copies the i32 at offset 0 of its memory to offset 4.
+	bin := binaryencoding.EncodeModule(&wasm.Module{
+		TypeSection:     []wasm.FunctionType{{}},
+		FunctionSection: []wasm.Index{0},
+		MemorySection:   &wasm.Memory{Min: 1},
+		CodeSection: []wasm.Code{{Body: []byte{
+			wasm.OpcodeI32Const, 4,
+			wasm.OpcodeI32Const, 0, wasm.OpcodeI32Load, 0x2, 0x0,
+			wasm.OpcodeI32Store, 0x2, 0x0,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []wasm.Export{
+			{Name: "_start", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+		},
+	})
+
+	var calls []string
+	config := NewRuntimeConfig().
+		WithPreStartHook(func(ctx context.Context, mod api.Module) error {
+			calls = append(calls, "pre-start "+mod.Name())
+			if mod.Name() == "fail" {
+				return errors.New("whoops")
+			}
+			require.True(t, mod.Memory().WriteUint32Le(0, 42))
+			return nil
+		}).
+		WithPostStartHook(func(ctx context.Context, mod api.Module) error {
+			calls = append(calls, "post-start "+mod.Name())
+			v, ok := mod.Memory().ReadUint32Le(4)
+			require.True(t, ok)
+			require.Equal(t, uint32(42), v)
+			return nil
+		})
+	r := NewRuntimeWithConfig(testCtx, config)
+	defer r.Close(testCtx)
+
+	// Host modules don't call hooks.
+	_, err := r.NewHostModuleBuilder("env").Instantiate(testCtx)
+	require.NoError(t, err)
+	require.Nil(t, calls)
+
+	// The start function sees the memory written by the pre-start hook.
+	_, err = r.InstantiateWithConfig(testCtx, bin, NewModuleConfig().WithName("ok"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"pre-start ok", "post-start ok"}, calls)
+
+	// An error from a hook closes the module.
+	calls = nil
+	_, err = r.InstantiateWithConfig(testCtx, bin, NewModuleConfig().WithName("fail"))
+	require.EqualError(t, err, "module[fail] pre-start hook failed: whoops")
+	require.Equal(t, []string{"pre-start fail"}, calls)
+	require.Nil(t, r.Module("fail"))
+}
+
 func TestRuntime_CloseWithExitCode(t *testing.T) {
 	bin := binaryencoding.EncodeModule(&wasm.Module{
 		TypeSection:     []wasm.FunctionType{{}},
@@ -790,6 +1349,11 @@ func (e *mockEngine) CompiledModuleCount() uint32 {
 	return uint32(len(e.cachedModules))
 }
 
+// CompiledCodeSizes implements the same method as documented on wasm.Engine.
+func (e *mockEngine) CompiledCodeSizes(*wasm.Module) []uint64 {
+	return nil
+}
+
 // DeleteCompiledModule implements the same method as documented on wasm.Engine.
 func (e *mockEngine) DeleteCompiledModule(module *wasm.Module) {
 	delete(e.cachedModules, module)
@@ -81,3 +81,43 @@ func (e *ExitError) Is(err error) bool {
 	}
 	return false
 }
+
+// HostFunctionError is returned to a caller of api.Function when a host
+// function panicked, and wazero.RuntimeConfig WithHostFunctionPanicTrap is
+// enabled. The call traps like it would on a Wasm error, and the error
+// includes both the wasm and Go stack traces.
+//
+// Here's an example of how to find the panicking host function:
+//
+//	if err := main(ctx); err != nil {
+//		var hostErr *sys.HostFunctionError
+//		if errors.As(err, &hostErr) {
+//			log.Printf("%s panicked:\n%s", hostErr.Function, hostErr.Stack)
+//		}
+//	--snip--
+//
+// Note: A panic with ExitError, such as from "proc_exit", isn't converted.
+type HostFunctionError struct {
+	// Function is the debug name of the host function, e.g. "env.abort".
+	Function string
+
+	// Recovered is the value the host function panicked with.
+	Recovered interface{}
+
+	// Stack is the Go stack trace of the goroutine when it panicked.
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *HostFunctionError) Error() string {
+	return fmt.Sprintf("host function %s panicked: %v", e.Function, e.Recovered)
+}
+
+// Unwrap allows use of errors.Is and errors.As on Recovered, when it is an
+// error.
+func (e *HostFunctionError) Unwrap() error {
+	if err, ok := e.Recovered.(error); ok {
+		return err
+	}
+	return nil
+}